@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+// inActions reports whether the run is a GitHub Actions job.
+func inActions() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// reportToActions annotates the workflow run with the PRs that were skipped
+// by policy or failed, writing the workflow commands to w, and appends a
+// table of every decision to the job's step summary. It does nothing outside
+// GitHub Actions.
+func reportToActions(w io.Writer, command string, rr *runResults) {
+	if !inActions() {
+		return
+	}
+	for _, repoKey := range rr.order {
+		r := rr.byRepo[repoKey]
+		for _, pr := range r.PolicySkipped {
+			level := "notice"
+			if isDenial(pr.SkipCode) {
+				level = "warning"
+			}
+			workflowCommand(w, level, fmt.Sprintf("Skipped %s#%d", repoKey, pr.Number), pr.SkipReason+": "+pr.Title)
+		}
+		for _, pr := range r.PRs {
+			if len(pr.Errors) > 0 {
+				workflowCommand(w, "error", fmt.Sprintf("Failed %s#%d", repoKey, pr.Number), strings.Join(pr.Errors, "\n"))
+			}
+		}
+	}
+
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		log.Printf("Warning: failed to open the step summary: %v\n", err)
+		return
+	}
+	defer f.Close()
+	if err := writeStepSummary(f, command, rr); err != nil {
+		log.Printf("Warning: failed to write the step summary: %v\n", err)
+	}
+}
+
+// workflowCommand prints an annotation (notice, warning, or error) in the
+// workflow command syntax understood by the Actions runner.
+func workflowCommand(w io.Writer, level, title, message string) {
+	fmt.Fprintf(w, "::%s title=%s::%s\n", level, escapeProperty(title), escapeData(message))
+}
+
+// escapeData escapes a workflow command message.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a workflow command property value.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// writeStepSummary writes a Markdown table of the run's decisions: the PRs
+// acted on followed by the ones skipped by policy, per repository. The risk
+// summary of the approvals follows the table.
+func writeStepSummary(w io.Writer, command string, rr *runResults) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## dependabot-bouncer %s\n\n", command)
+
+	var rows []string
+	for _, repoKey := range rr.order {
+		r := rr.byRepo[repoKey]
+		for _, pr := range r.PRs {
+			decision, details := decisionName(pr.Action), pr.Details
+			if len(pr.Errors) > 0 {
+				decision, details = "failed", pr.Errors
+			}
+			rows = append(rows, summaryRow(repoKey, pr.Number, pr.URL, pr.Package, decision, strings.Join(details, ", ")))
+		}
+		for _, pr := range r.PolicySkipped {
+			rows = append(rows, summaryRow(repoKey, pr.Number, pr.URL, pr.PackageName, skipDecision(pr), pr.SkipReason))
+		}
+		if r.Err != nil && len(r.PRs) == 0 {
+			rows = append(rows, fmt.Sprintf("| %s | | | error | %s |", repoKey, escapeCell(r.Err.Error())))
+		}
+	}
+
+	if len(rows) == 0 {
+		b.WriteString("No pull requests were acted on or skipped.\n\n")
+	} else {
+		b.WriteString("| Repository | PR | Package | Decision | Details |\n")
+		b.WriteString("|---|---|---|---|---|\n")
+		for _, row := range rows {
+			b.WriteString(row + "\n")
+		}
+		b.WriteString("\n")
+	}
+	if rr.risk != nil && rr.risk.Approved > 0 {
+		b.WriteString("```\n" + strings.Join(rr.risk.Lines(), "\n") + "\n```\n\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// summaryRow formats one step summary table row.
+func summaryRow(repoKey string, number int, url, pkg, decision, details string) string {
+	pr := fmt.Sprintf("#%d", number)
+	if url != "" {
+		pr = fmt.Sprintf("[#%d](%s)", number, url)
+	}
+	return fmt.Sprintf("| %s | %s | %s | %s | %s |", repoKey, pr, escapeCell(pkg), decision, escapeCell(details))
+}
+
+// skipDecision is the step summary decision for a PR skipped by policy.
+func skipDecision(pr bouncer.PRInfo) string {
+	if isDenial(pr.SkipCode) {
+		return "denied"
+	}
+	return "skipped"
+}
+
+// escapeCell keeps text from breaking out of a Markdown table cell.
+func escapeCell(s string) string {
+	return strings.NewReplacer("|", "\\|", "\r", " ", "\n", " ").Replace(s)
+}
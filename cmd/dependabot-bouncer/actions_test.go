@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+func TestEscapeData(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain text", "plain text"},
+		{"100%", "100%25"},
+		{"line one\r\nline two", "line one%0D%0Aline two"},
+		{"a: b, c", "a: b, c"},
+		{"%0A", "%250A"},
+	}
+
+	for _, tt := range tests {
+		if got := escapeData(tt.in); got != tt.want {
+			t.Errorf("escapeData(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEscapeProperty(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Skipped acme/api#1", "Skipped acme/api#1"},
+		{"100%", "100%25"},
+		{"one\rtwo\nthree", "one%0Dtwo%0Athree"},
+		{"a: b", "a%3A b"},
+		{"a, b", "a%2C b"},
+		{"50%: x,y", "50%25%3A x%2Cy"},
+	}
+
+	for _, tt := range tests {
+		if got := escapeProperty(tt.in); got != tt.want {
+			t.Errorf("escapeProperty(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEscapeCell(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"lodash", "lodash"},
+		{"a|b", `a\|b`},
+		{"one\r\ntwo\nthree", "one  two three"},
+	}
+
+	for _, tt := range tests {
+		if got := escapeCell(tt.in); got != tt.want {
+			t.Errorf("escapeCell(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// actionsResults returns results with a PR of each kind the step summary
+// and annotations distinguish.
+func actionsResults() *runResults {
+	rr := newRunResults()
+	api := rr.repo("acme/api")
+	api.PRs = []prResult{
+		{Number: 1, URL: "https://github.com/acme/api/pull/1", Package: "lodash", Action: "Approved", Details: []string{"auto-merge enabled"}},
+		{Number: 2, Package: "react|dom", Action: "Approved", Errors: []string{"failed to approve PR: 50% done", "second\nline"}},
+	}
+	api.PolicySkipped = []bouncer.PRInfo{
+		{Number: 3, Title: "Bump left-pad from 1.1.0 to 1.2.0", PackageName: "left-pad", SkipCode: bouncer.SkipDeniedPackage, SkipReason: "denied package: left-pad"},
+		{Number: 4, Title: "Bump vue from 3.4.0 to 3.4.1", PackageName: "vue", SkipCode: bouncer.SkipDraft, SkipReason: "draft"},
+	}
+	rr.repo("acme/web").Err = errors.New("listing failed | rate limited")
+	return rr
+}
+
+func TestWriteStepSummary(t *testing.T) {
+	var b strings.Builder
+	if err := writeStepSummary(&b, "approve", actionsResults()); err != nil {
+		t.Fatalf("writeStepSummary() error = %v", err)
+	}
+	want := `## dependabot-bouncer approve
+
+| Repository | PR | Package | Decision | Details |
+|---|---|---|---|---|
+| acme/api | [#1](https://github.com/acme/api/pull/1) | lodash | approved | auto-merge enabled |
+| acme/api | #2 | react\|dom | failed | failed to approve PR: 50% done, second line |
+| acme/api | #3 | left-pad | denied | denied package: left-pad |
+| acme/api | #4 | vue | skipped | draft |
+| acme/web | | | error | listing failed \| rate limited |
+
+`
+	if got := b.String(); got != want {
+		t.Errorf("writeStepSummary() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteStepSummaryEmpty(t *testing.T) {
+	var b strings.Builder
+	if err := writeStepSummary(&b, "recreate", newRunResults()); err != nil {
+		t.Fatalf("writeStepSummary() error = %v", err)
+	}
+	want := "## dependabot-bouncer recreate\n\nNo pull requests were acted on or skipped.\n\n"
+	if got := b.String(); got != want {
+		t.Errorf("writeStepSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestReportToActions(t *testing.T) {
+	summary := filepath.Join(t.TempDir(), "summary.md")
+	if err := os.WriteFile(summary, []byte("earlier step\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_STEP_SUMMARY", summary)
+
+	var b strings.Builder
+	reportToActions(&b, "approve", actionsResults())
+	want := `::warning title=Skipped acme/api#3::denied package: left-pad: Bump left-pad from 1.1.0 to 1.2.0
+::notice title=Skipped acme/api#4::draft: Bump vue from 3.4.0 to 3.4.1
+::error title=Failed acme/api#2::failed to approve PR: 50%25 done%0Asecond%0Aline
+`
+	if got := b.String(); got != want {
+		t.Errorf("reportToActions() commands =\n%s\nwant\n%s", got, want)
+	}
+
+	data, err := os.ReadFile(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); !strings.HasPrefix(got, "earlier step\n## dependabot-bouncer approve\n") || !strings.Contains(got, "| acme/api | #3 | left-pad | denied |") {
+		t.Errorf("step summary = %q, want the table appended", got)
+	}
+}
+
+func TestReportToActionsOutsideActions(t *testing.T) {
+	summary := filepath.Join(t.TempDir(), "summary.md")
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("GITHUB_STEP_SUMMARY", summary)
+
+	var b strings.Builder
+	reportToActions(&b, "approve", actionsResults())
+	if b.Len() > 0 {
+		t.Errorf("reportToActions() wrote %q outside Actions", b.String())
+	}
+	if _, err := os.Stat(summary); !os.IsNotExist(err) {
+		t.Errorf("step summary written outside Actions: %v", err)
+	}
+}
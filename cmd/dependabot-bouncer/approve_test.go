@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/events"
+	"github.com/promiseofcake/dependabot-bouncer/internal/export"
+	"github.com/promiseofcake/dependabot-bouncer/internal/risk"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer/bouncertest"
+	"github.com/spf13/viper"
+)
+
+func TestRunApprove(t *testing.T) {
+	fake := bouncertest.NewFake()
+	fake.AddPR("acme", "api", "dependabot/npm_and_yarn/lodash-4.17.21", bouncer.PRInfo{Number: 1, Title: "Bump lodash from 4.17.20 to 4.17.21", CIStatus: "success"})
+	fake.AddPR("acme", "api", "dependabot/npm_and_yarn/left-pad-1.3.0", bouncer.PRInfo{Number: 2, Title: "Bump left-pad from 1.1.0 to 1.3.0", CIStatus: "success"})
+	fake.AddPR("acme", "api", "dependabot/npm_and_yarn/react-19.0.0", bouncer.PRInfo{Number: 3, Title: "Bump react from 18.3.1 to 19.0.0", CIStatus: "success"})
+	fake.AddPR("acme", "api", "dependabot/npm_and_yarn/vue-3.4.1", bouncer.PRInfo{Number: 4, Title: "Bump vue from 3.4.0 to 3.4.1", CIStatus: "failure"})
+
+	orig := newClient
+	newClient = func(bouncer.Options) (bouncer.Client, error) { return fake, nil }
+	t.Cleanup(func() { newClient = orig; viper.Reset() })
+	viper.Set("global.denied_packages", []string{"left-pad"})
+	viper.Set("global.deny_update_types", []string{bouncer.UpdateMajor})
+
+	var out repoResults
+	if err := runApprove(io.Discard, "acme", "api", risk.NewSummary(0), export.NewLog(), &actionLimit{remaining: -1}, 1, &out); err != nil {
+		t.Fatalf("runApprove() error = %v", err)
+	}
+
+	var approved []int
+	for _, c := range fake.Calls() {
+		if c.Method == "Approve" {
+			approved = append(approved, c.Number)
+		}
+	}
+	if len(approved) != 1 || approved[0] != 1 {
+		t.Errorf("approved PRs = %v, want [1]", approved)
+	}
+	skipped := make(map[int]string)
+	for _, pr := range out.PolicySkipped {
+		skipped[pr.Number] = pr.SkipCode
+	}
+	if skipped[2] != bouncer.SkipDeniedPackage || skipped[3] != bouncer.SkipUpdateType {
+		t.Errorf("policy skipped = %v, want #2 %s and #3 %s", skipped, bouncer.SkipDeniedPackage, bouncer.SkipUpdateType)
+	}
+}
+
+// eventLog is an events.Sink recording the events published to it.
+type eventLog struct {
+	events []string
+}
+
+func (l *eventLog) Send(payload []byte) error {
+	var e events.Event
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return err
+	}
+	l.events = append(l.events, fmt.Sprintf("%s #%d", e.Type, e.PullRequest))
+	return nil
+}
+
+func (l *eventLog) Close() error   { return nil }
+func (l *eventLog) String() string { return "event log" }
+
+func TestRunApprovePublishesEvents(t *testing.T) {
+	fake := bouncertest.NewFake()
+	fake.AddPR("acme", "api", "dependabot/npm_and_yarn/lodash-4.17.21", bouncer.PRInfo{Number: 1, Title: "Bump lodash from 4.17.20 to 4.17.21", CIStatus: "success"})
+	fake.AddPR("acme", "api", "dependabot/npm_and_yarn/left-pad-1.3.0", bouncer.PRInfo{Number: 2, Title: "Bump left-pad from 1.1.0 to 1.3.0", CIStatus: "success"})
+	fake.AddPR("acme", "api", "dependabot/npm_and_yarn/vue-3.4.1", bouncer.PRInfo{Number: 3, Title: "Bump vue from 3.4.0 to 3.4.1", CIStatus: "success", Draft: true})
+
+	sink := &eventLog{}
+	origClient, origBus := newClient, eventBus
+	newClient = func(bouncer.Options) (bouncer.Client, error) { return fake, nil }
+	eventBus = events.NewPublisher(sink)
+	t.Cleanup(func() { newClient, eventBus = origClient, origBus; viper.Reset() })
+	viper.Set("global.denied_packages", []string{"left-pad"})
+
+	var out repoResults
+	if err := runApprove(io.Discard, "acme", "api", risk.NewSummary(0), nil, &actionLimit{remaining: -1}, 1, &out); err != nil {
+		t.Fatalf("runApprove() error = %v", err)
+	}
+	if got, want := fmt.Sprint(sink.events), "[denied #2 approved #1]"; got != want {
+		t.Errorf("published events = %s, want %s", got, want)
+	}
+}
+
+func TestBuildReviewPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		settings   map[string]any
+		wantEvent  string
+		wantBody   string
+		wantMerges bool
+		wantErr    bool
+	}{
+		{name: "default", wantEvent: bouncer.ReviewApprove},
+		{
+			name:       "comment with the default body",
+			settings:   map[string]any{"global.review_event": "Comment"},
+			wantEvent:  bouncer.ReviewComment,
+			wantBody:   defaultReviewComment,
+			wantMerges: true,
+		},
+		{
+			name: "repository overrides global and group",
+			settings: map[string]any{
+				"global.review_event":                  "comment",
+				"policies.backend.review_comment":      "@dependabot merge",
+				"repositories.acme/api.policy":         "backend",
+				"repositories.acme/api.review_comment": "LGTM",
+			},
+			wantEvent: bouncer.ReviewComment,
+			wantBody:  "LGTM",
+		},
+		{name: "invalid event", settings: map[string]any{"global.review_event": "reject"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(viper.Reset)
+			for k, v := range tt.settings {
+				viper.Set(k, v)
+			}
+			p, err := buildReviewPolicy("acme", "api")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildReviewPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if p.Event != tt.wantEvent || p.Body != tt.wantBody || p.Merge.ReviewMerges != tt.wantMerges {
+				t.Errorf("buildReviewPolicy() = %s/%q (merges %v), want %s/%q (merges %v)",
+					p.Event, p.Body, p.Merge.ReviewMerges, tt.wantEvent, tt.wantBody, tt.wantMerges)
+			}
+		})
+	}
+}
+
+func TestReviewPolicySubmitted(t *testing.T) {
+	p := reviewPolicy{Event: bouncer.ReviewComment, Body: defaultReviewComment}
+	pr := bouncer.PRInfo{Reviews: []bouncer.Review{
+		{Author: "alice", State: "COMMENTED", Body: defaultReviewComment},
+		{Author: "Bouncer-Bot", State: "COMMENTED", Body: defaultReviewComment + "\n"},
+	}}
+
+	if !p.submitted(pr, "bouncer-bot") {
+		t.Error("submitted() = false for an earlier review by the same account")
+	}
+	if p.submitted(pr, "carol") {
+		t.Error("submitted() = true for reviews by other accounts")
+	}
+	if p.submitted(pr, "") {
+		t.Error("submitted() = true with the account unknown")
+	}
+	if other := (reviewPolicy{Event: bouncer.ReviewComment, Body: "@dependabot merge"}); other.submitted(pr, "bouncer-bot") {
+		t.Error("submitted() = true for a review with another body")
+	}
+}
+
+func TestRunApproveCommentsOnce(t *testing.T) {
+	actorOnce.Do(func() { actor = "bouncer-bot" })
+	fake := bouncertest.NewFake()
+	fake.AddPR("acme", "api", "dependabot/npm_and_yarn/lodash-4.17.21", bouncer.PRInfo{
+		Number:   1,
+		Title:    "Bump lodash from 4.17.20 to 4.17.21",
+		CIStatus: "success",
+		Reviews:  []bouncer.Review{{Author: actor, State: "COMMENTED", Body: defaultReviewComment}},
+	})
+	fake.AddPR("acme", "api", "dependabot/npm_and_yarn/vue-3.4.1", bouncer.PRInfo{Number: 2, Title: "Bump vue from 3.4.0 to 3.4.1", CIStatus: "success"})
+
+	orig := newClient
+	newClient = func(bouncer.Options) (bouncer.Client, error) { return fake, nil }
+	t.Cleanup(func() { newClient = orig; viper.Reset() })
+	viper.Set("global.review_event", bouncer.ReviewComment)
+
+	var out repoResults
+	if err := runApprove(io.Discard, "acme", "api", risk.NewSummary(0), export.NewLog(), &actionLimit{remaining: -1}, 1, &out); err != nil {
+		t.Fatalf("runApprove() error = %v", err)
+	}
+
+	var commented []int
+	for _, c := range fake.Calls() {
+		if c.Method == "Comment" {
+			commented = append(commented, c.Number)
+		}
+	}
+	if len(commented) != 1 || commented[0] != 2 {
+		t.Errorf("commented on PRs = %v, want [2]", commented)
+	}
+}
+
+func TestRunApproveRecordsNewApprovalsOnly(t *testing.T) {
+	fake := bouncertest.NewFake()
+	fake.AddPR("acme", "api", "dependabot/npm_and_yarn/lodash-4.17.21", bouncer.PRInfo{Number: 1, Title: "Bump lodash from 4.17.20 to 4.17.21", CIStatus: "success", ReviewDecision: "APPROVED"})
+	fake.AddPR("acme", "api", "dependabot/npm_and_yarn/vue-3.4.1", bouncer.PRInfo{Number: 2, Title: "Bump vue from 3.4.0 to 3.4.1", CIStatus: "success"})
+
+	orig := newClient
+	newClient = func(bouncer.Options) (bouncer.Client, error) { return fake, nil }
+	t.Cleanup(func() { newClient = orig; viper.Reset() })
+
+	summary, changes := risk.NewSummary(0), export.NewLog()
+	var out repoResults
+	if err := runApprove(io.Discard, "acme", "api", summary, changes, &actionLimit{remaining: -1}, 1, &out); err != nil {
+		t.Fatalf("runApprove() error = %v", err)
+	}
+
+	if summary.Approved != 1 || changes.Len() != 1 {
+		t.Errorf("recorded %d approvals and %d changes, want 1 each", summary.Approved, changes.Len())
+	}
+	for _, r := range out.PRs {
+		if !r.Approved || r.NewlyApproved != (r.Number == 2) {
+			t.Errorf("PR #%d Approved = %v, NewlyApproved = %v", r.Number, r.Approved, r.NewlyApproved)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"log"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/audit"
+	"github.com/spf13/viper"
+)
+
+var (
+	actorOnce sync.Once
+	actor     string
+)
+
+// auditActor returns the GitHub login the bouncer acts as, looked up once
+// per process, or "" when it cannot be determined.
+func auditActor() string {
+	actorOnce.Do(func() {
+		login, err := gitHub().AuthenticatedUser(runCtx)
+		if err != nil {
+			log.Printf("Warning: audit entries will not name the GitHub account: %v\n", err)
+			return
+		}
+		actor = login
+	})
+	return actor
+}
+
+// auditRun appends the PRs a run acted on to the audit log at audit.path,
+// one entry per PR with the account, user, and host behind the run and the
+// rule that let the PR through. PRs only skipped are left out. Nothing is
+// written without audit.path; failures are logged and never fail the run.
+func auditRun(command string, rr *runResults) {
+	path := viper.GetString("audit.path")
+	if path == "" {
+		return
+	}
+	if err := audit.Open(path).Append(auditEntries(command, rr, time.Now())...); err != nil {
+		log.Printf("Warning: %v\n", err)
+	}
+}
+
+// auditEntries returns the audit entries of the PRs acted on in a run.
+func auditEntries(command string, rr *runResults, now time.Time) []audit.Entry {
+	var entries []audit.Entry
+	run := audit.NewRunID(now)
+	for _, repoKey := range rr.order {
+		for _, pr := range rr.byRepo[repoKey].PRs {
+			decision := decisionName(pr.Action)
+			if decision == "skipped" {
+				continue
+			}
+			if len(pr.Errors) > 0 {
+				decision = "failed"
+			}
+			entries = append(entries, audit.Entry{
+				Time:     now.UTC(),
+				Run:      run,
+				Actor:    auditActor(),
+				User:     localUser(),
+				Host:     localHost(),
+				Command:  command,
+				Repo:     repoKey,
+				Number:   pr.Number,
+				URL:      pr.URL,
+				Package:  pr.Package,
+				Decision: decision,
+				Actions:  append([]string{}, pr.Details...),
+				Rule:     pr.Rule,
+				Config:   viper.ConfigFileUsed(),
+				Errors:   pr.Errors,
+			})
+		}
+	}
+	return entries
+}
+
+// localUser returns the name of the user running the bouncer.
+func localUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// localHost returns the name of the machine the bouncer runs on.
+func localHost() string {
+	host, _ := os.Hostname()
+	return host
+}
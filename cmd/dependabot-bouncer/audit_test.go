@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuditEntries(t *testing.T) {
+	actorOnce.Do(func() { actor = "bouncer-bot" })
+	rr := newRunResults()
+	rr.add("acme/api",
+		prResult{Number: 1, Package: "lodash", Action: "Approved", Approved: true, Details: []string{"approved", "auto-merge enabled"}, Rule: "lodash"},
+		prResult{Number: 2, Action: "Skipped"},
+		prResult{Number: 3, Action: "Recreated", Errors: []string{"failed to recreate: boom"}},
+	)
+
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	entries := auditEntries("automerge", rr, now)
+	if len(entries) != 2 {
+		t.Fatalf("auditEntries() = %d entries, want 2 (skipped PRs left out)", len(entries))
+	}
+	e := entries[0]
+	if e.Actor != "bouncer-bot" || e.Command != "automerge" || e.Repo != "acme/api" || e.Decision != "approved" || e.Rule != "lodash" || len(e.Actions) != 2 {
+		t.Errorf("entries[0] = %+v", e)
+	}
+	if entries[1].Decision != "failed" || entries[1].Run != e.Run {
+		t.Errorf("entries[1] = %+v, want a failure of the same run", entries[1])
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var automergeCmd = &cobra.Command{
+	Use:   "automerge [owner/repo | owner/repo#number | PR URL...]",
+	Short: "Approve dependency update pull requests and enable auto-merge",
+	Long: `Approve passing dependency update pull requests from Dependabot and enable
+GitHub's native auto-merge on them, so each merges as soon as its required
+checks pass. It is the same as approve --merge-method auto.
+
+Where a repository has auto-merge disabled in its settings, Dependabot is
+asked to merge the PR instead (@dependabot squash and merge, or
+@dependabot merge when merge_method is merge or rebase).
+
+Repositories and pull requests are given like in approve.` + exitCodesHelp,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		viper.Set("merge-method", mergeAuto)
+		return runApproveCommand(cmd, args)
+	},
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+)
+
+var bootstrapCmd = &cobra.Command{
+	Use:   "bootstrap --owner org [--team slug]",
+	Short: "Draft a config file for a team's repositories",
+	Long: `Set up the bouncer for a new team in one step: check that gh's token works,
+discover the repositories of the organization or of one of its teams, read
+the package ecosystems each one's .github/dependabot.yml configures, and
+write a config file with conservative defaults for them.
+
+Repositories without a Dependabot configuration are listed commented out.
+The config is written to --output, or else to --config, or else to
+$XDG_CONFIG_HOME/dependabot-bouncer/config.yaml, where every command finds
+it. An existing file is only replaced with --force.`,
+	Example: `  dependabot-bouncer bootstrap --owner acme --team platform
+  dependabot-bouncer bootstrap --owner acme --output ./bouncer.yaml
+  dependabot-bouncer bootstrap --owner acme --team platform --output -`,
+	RunE: runBootstrap,
+}
+
+// bootstrapRepo is a repository discovered by bootstrap, with the ecosystems
+// its Dependabot configuration lists.
+type bootstrapRepo struct {
+	name       string
+	ecosystems []string
+	configured bool // has a dependabot.yml
+}
+
+func runBootstrap(cmd *cobra.Command, args []string) error {
+	owner, _ := cmd.Flags().GetString("owner")
+	team, _ := cmd.Flags().GetString("team")
+	output, _ := cmd.Flags().GetString("output")
+	force, _ := cmd.Flags().GetBool("force")
+
+	if output == "" {
+		path, err := defaultConfigPath()
+		if err != nil {
+			return err
+		}
+		output = path
+	}
+	if output != "-" && !force {
+		if _, err := os.Stat(output); err == nil {
+			return fmt.Errorf("%s already exists; pass --force to replace it", output)
+		}
+	}
+
+	login, err := validateToken()
+	if err != nil {
+		return err
+	}
+	log.Printf("Authenticated as %s\n", login)
+
+	source := owner
+	var names []string
+	if team != "" {
+		source = owner + "/" + team
+		names, err = gitHub().ListTeamRepos(runCtx, owner, team, bouncer.RepoFilter{})
+	} else {
+		names, err = gitHub().ListOrgRepos(runCtx, owner, bouncer.RepoFilter{})
+	}
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no repositories found for %s", source)
+	}
+	sort.Strings(names)
+
+	repos := make([]bootstrapRepo, 0, len(names))
+	for _, name := range names {
+		r := bootstrapRepo{name: name}
+		o, n, _ := strings.Cut(name, "/")
+		r.ecosystems, r.configured, err = gitHub().DependabotEcosystems(runCtx, o, n)
+		if err != nil {
+			log.Printf("Warning: %s: %v\n", name, err)
+			r.configured = true // keep it; its ecosystems are just unknown
+		}
+		repos = append(repos, r)
+	}
+
+	config := draftConfig(owner, team, repos, time.Now())
+	if output == "-" {
+		fmt.Print(config)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(output, []byte(config), 0o644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	var configured int
+	var ecosystems []string
+	for _, r := range repos {
+		if r.configured {
+			configured++
+		}
+		for _, e := range r.ecosystems {
+			if !slices.Contains(ecosystems, e) {
+				ecosystems = append(ecosystems, e)
+			}
+		}
+	}
+	sort.Strings(ecosystems)
+	fmt.Printf("Found %d repositories of %s, %d with Dependabot configured", len(repos), source, configured)
+	if len(ecosystems) > 0 {
+		fmt.Printf(" (%s)", strings.Join(ecosystems, ", "))
+	}
+	fmt.Printf("\nWrote %s\n\n", output)
+
+	configFlag := ""
+	if path, err := defaultConfigPath(); err != nil || path != output {
+		configFlag = " --config " + output
+	}
+	fmt.Println("Next steps:")
+	fmt.Printf("  1. Review the drafted policy in %s\n", output)
+	fmt.Printf("  2. See what the bouncer would do:  dependabot-bouncer check%s\n", configFlag)
+	fmt.Printf("  3. Approve the passing updates:    dependabot-bouncer approve%s\n", configFlag)
+	fmt.Println("  4. Run it on a schedule with watch, cron, or the GitHub Action (see the README)")
+	return nil
+}
+
+// defaultConfigPath returns where bootstrap writes the config: the --config
+// file, or else the first location initConfig searches.
+func defaultConfigPath() (string, error) {
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "dependabot-bouncer", "config.yaml"), nil
+}
+
+// validateToken checks that gh is authenticated and returns the login. A
+// classic token with broad scopes or without the repo scope is warned about.
+func validateToken() (string, error) {
+	login, err := gitHub().AuthenticatedUser(runCtx)
+	if err != nil {
+		return "", fmt.Errorf("gh is not authenticated (run 'gh auth login' or set GH_TOKEN): %w", err)
+	}
+	scopes, ok, err := gitHub().TokenScopes(runCtx, "")
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		if !slices.Contains(scopes, "repo") {
+			log.Println("Warning: the token lacks the repo scope; PRs of private repositories cannot be approved")
+		}
+		if broad := bouncer.BroadScopes(scopes); len(broad) > 0 {
+			log.Printf("Warning: the token has scopes the bouncer does not need: %s\n", strings.Join(broad, ", "))
+		}
+	}
+	return login, nil
+}
+
+// draftConfig returns the config file bootstrap writes: a conservative global
+// policy, and the repositories with their ecosystems as comments.
+func draftConfig(owner, team string, repos []bootstrapRepo, now time.Time) string {
+	var b strings.Builder
+	source := "--owner " + owner
+	if team != "" {
+		source += " --team " + team
+	}
+	fmt.Fprintf(&b, "# Drafted by 'dependabot-bouncer bootstrap %s' on %s.\n", source, now.Format("2006-01-02"))
+	b.WriteString(`# See config.example.yaml in the dependabot-bouncer repository for every
+# setting.
+
+global:
+  # Major updates can break APIs; leave them for a human to review.
+  deny_update_types:
+    - major
+
+  # PRs whose title names no package are processed with a warning.
+  unparsed_titles: warn
+
+  # Security updates bypass the deny lists.
+  security_updates:
+    exempt_from_deny: true
+
+  # Hold back versions published less than this long ago.
+  # min_age: 72h
+`)
+	if team != "" {
+		fmt.Fprintf(&b, `
+  # Skip PRs that change files other teams own in CODEOWNERS.
+  # dependency_owners:
+  #   - "@%s/%s"
+`, owner, team)
+	}
+
+	b.WriteString("\nrepositories:\n")
+	var unconfigured []string
+	for _, r := range repos {
+		if !r.configured {
+			unconfigured = append(unconfigured, r.name)
+			continue
+		}
+		fmt.Fprintf(&b, "  %s: {}", r.name)
+		if len(r.ecosystems) > 0 {
+			fmt.Fprintf(&b, " # %s", strings.Join(r.ecosystems, ", "))
+		}
+		b.WriteString("\n")
+	}
+	if len(unconfigured) > 0 {
+		b.WriteString("\n  # No .github/dependabot.yml found:\n")
+		for _, name := range unconfigured {
+			fmt.Fprintf(&b, "  # %s: {}\n", name)
+		}
+	}
+	return b.String()
+}
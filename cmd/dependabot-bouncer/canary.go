@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/viper"
+)
+
+// canaryPolicyKey holds a policy change being rolled out: settings with the
+// same keys as global, layered on top of it.
+const canaryPolicyKey = "canary.policy"
+
+// canaryRollout is the rollout configured under canary.
+type canaryRollout struct {
+	percent int             // share of repositories in the canary
+	repos   map[string]bool // repositories always in the canary
+	until   time.Time       // end of the canary; zero while it lasts
+}
+
+// loadCanary reads the canary section, or returns nil when no canary policy
+// is configured.
+func loadCanary() (*canaryRollout, error) {
+	if !viper.IsSet(canaryPolicyKey) {
+		return nil, nil
+	}
+	c := &canaryRollout{percent: viper.GetInt("canary.percent"), repos: make(map[string]bool)}
+	if c.percent < 0 || c.percent > 100 {
+		return nil, fmt.Errorf("invalid canary.percent %d (expected 0 to 100)", c.percent)
+	}
+	for _, repo := range getStringSlice("canary.repositories") {
+		c.repos[strings.ToLower(repo)] = true
+	}
+	switch until := viper.Get("canary.until").(type) {
+	case nil:
+	case time.Time:
+		// YAML reads an unquoted date as midnight UTC; take it as local time.
+		if until.Location() == time.UTC && until.Equal(until.Truncate(24*time.Hour)) {
+			until = time.Date(until.Year(), until.Month(), until.Day(), 0, 0, 0, 0, time.Local)
+		}
+		c.until = until
+	default:
+		s := strings.TrimSpace(fmt.Sprint(until))
+		t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+		if err != nil {
+			if t, err = time.Parse(time.RFC3339, s); err != nil {
+				return nil, fmt.Errorf("invalid canary.until %q (expected a date such as 2026-07-01 or an RFC 3339 timestamp)", s)
+			}
+		}
+		c.until = t
+	}
+	return c, nil
+}
+
+// includes reports whether a repository is in the canary: listed in
+// canary.repositories, or among the canary.percent of repositories picked by
+// a hash of its name, which keeps the pick the same from run to run.
+func (c *canaryRollout) includes(repoKey string) bool {
+	key := strings.ToLower(repoKey)
+	if c.repos[key] {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32()%100) < c.percent
+}
+
+// over reports whether the canary has ended and its policy applies to every
+// repository.
+func (c *canaryRollout) over(now time.Time) bool {
+	return !c.until.IsZero() && !now.Before(c.until)
+}
+
+// canaryApplies reports whether the canary policy applies to a repository:
+// it is in the canary, or the canary is over. Invalid canary settings are
+// logged and leave the canary policy out.
+func canaryApplies(repoKey string, now time.Time) bool {
+	c, err := loadCanary()
+	if err != nil {
+		log.Printf("Warning: ignoring the canary policy: %v\n", err)
+		return false
+	}
+	return c != nil && (c.over(now) || c.includes(repoKey))
+}
+
+// canaryPreviewed reports whether a repository runs on the current policy
+// while the canary lasts, and is only compared against the canary policy.
+func canaryPreviewed(repoKey string, now time.Time) bool {
+	c, err := loadCanary()
+	return err == nil && c != nil && !c.over(now) && !c.includes(repoKey)
+}
+
+// logCanaryDiff logs the PRs of a repository that the canary policy would
+// decide differently from the current one. prs are the PRs listed with the
+// current policy, including the skipped ones; PRs skipped for reasons other
+// than policy, such as ignored_prs or snoozes, are left out.
+func logCanaryDiff(owner, repo string, prs []bouncer.PRInfo) error {
+	q, err := buildPolicyQuery(owner, repo, policyPrefixes(owner+"/"+repo, true))
+	if err != nil {
+		return fmt.Errorf("invalid canary policy: %w", err)
+	}
+	applyPolicyFlags(&q)
+
+	changed := 0
+	for _, pr := range prs {
+		if pr.Skipped && !isDenial(pr.SkipCode) && pr.SkipCode != bouncer.SkipUnparsed {
+			continue
+		}
+		code, reason := bouncer.PolicyDecision(pr, q)
+		if code == pr.SkipCode {
+			continue
+		}
+		changed++
+		now, then := "processed", "processed"
+		if pr.Skipped {
+			now = "skipped (" + pr.SkipReason + ")"
+		}
+		if code != "" {
+			then = "skipped (" + reason + ")"
+		}
+		log.Printf("Canary: %s/%s#%d %s: %s now, %s under the canary policy\n", owner, repo, pr.Number, pr.PackageName, now, then)
+	}
+	if changed > 0 {
+		log.Printf("Canary: the canary policy would decide %d PRs of %s/%s differently\n", changed, owner, repo)
+	}
+	return nil
+}
+
+// setupCanary validates the canary section and logs the rollout in effect.
+func setupCanary() error {
+	c, err := loadCanary()
+	if err != nil || c == nil {
+		return err
+	}
+	if c.over(time.Now()) {
+		log.Printf("Canary ended %s: the canary policy applies to every repository; move it into global\n", c.until.Format("2006-01-02"))
+		return nil
+	}
+	until := "until it is moved into global"
+	if !c.until.IsZero() {
+		until = "until " + c.until.Format("2006-01-02")
+	}
+	log.Printf("Canary: the canary policy applies to %d%% of repositories and %d listed ones %s; the others are compared against it\n", c.percent, len(c.repos), until)
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// exitInterrupted is the exit code of a run stopped by SIGINT or SIGTERM,
+// the code shells report for a process killed by SIGINT.
+const exitInterrupted = 130
+
+// runCtx is done once the run is interrupted with SIGINT or SIGTERM or runs
+// past --run-timeout. Every API call is made under it, so in-flight calls are
+// killed then, and no further repository is started.
+var runCtx = context.Background()
+
+// stopRun releases the signal handler and timer behind runCtx.
+var stopRun = func() {}
+
+// runLimit is the --run-timeout the run was started with.
+var runLimit time.Duration
+
+// ownSignals are the commands that handle SIGINT and SIGTERM themselves,
+// finishing the pass or request at hand before they exit.
+var ownSignals = map[string]bool{"watch": true, "serve": true}
+
+// errRunStopped marks a repository that was not processed because the run
+// was interrupted or timed out first.
+var errRunStopped = errors.New("not processed: the run was stopped")
+
+// setupRunContext sets up runCtx for cmd. The first SIGINT or SIGTERM
+// cancels the run; a second one ends the process right away.
+func setupRunContext(cmd *cobra.Command) error {
+	if ownSignals[cmd.Name()] {
+		return nil
+	}
+	runLimit = viper.GetDuration("global.run_timeout")
+	if runLimit < 0 {
+		return fmt.Errorf("invalid run timeout %s: must not be negative", runLimit)
+	}
+
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	cancel := context.CancelFunc(func() {})
+	if runLimit > 0 {
+		ctx, cancel = context.WithTimeout(ctx, runLimit)
+	}
+	go func() {
+		<-ctx.Done()
+		stopSignals()
+	}()
+	runCtx = ctx
+	stopRun = func() {
+		cancel()
+		stopSignals()
+	}
+	return nil
+}
+
+// runError returns the error a run that was cut short exits with: it names
+// the cause and exits with exitInterrupted after a signal, or 1 after
+// --run-timeout. Errors of runs that went on to the end are returned as is.
+func runError(err error) error {
+	if err == nil || runCtx.Err() == nil {
+		return err
+	}
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return &exitError{code: 1, msg: fmt.Sprintf("run timed out after %s: %v", runLimit, err)}
+	}
+	return &exitError{code: exitInterrupted, msg: fmt.Sprintf("interrupted: %v", err)}
+}
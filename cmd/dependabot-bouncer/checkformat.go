@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+)
+
+// Formats of check --format besides the --output ones: one row per open PR.
+const (
+	formatTable    = "table"    // aligned columns for the terminal (default)
+	formatCSV      = "csv"      // for spreadsheets and dashboards
+	formatMarkdown = "markdown" // a Markdown table for reports and chat
+)
+
+// checkFormat returns the validated format of check: --format, else --output
+// or --json, else table.
+func checkFormat(cmd *cobra.Command) (string, error) {
+	if !cmd.Flags().Changed("format") {
+		if cmd.Flags().Changed("output") || cmd.Flags().Changed("json") {
+			return outputFormat(cmd)
+		}
+		return formatTable, nil
+	}
+	format, _ := cmd.Flags().GetString("format")
+	switch format {
+	case formatTable, outputText, outputJSON, outputYAML, formatCSV, formatMarkdown:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q (use table, text, json, yaml, csv, or markdown)", format)
+	}
+}
+
+// checkStatus sums up a listed PR for the tabular formats: the skip code of
+// a skipped PR, else its CI status.
+func checkStatus(pr bouncer.PRInfo) string {
+	if pr.Skipped {
+		return pr.SkipCode
+	}
+	if pr.CIStatus == "" {
+		return "unknown"
+	}
+	return pr.CIStatus
+}
+
+// checkUpdate returns the "from → to" version change of a PR, or "" when
+// it is not known.
+func checkUpdate(pr bouncer.PRInfo) string {
+	if pr.FromVersion == "" && pr.ToVersion == "" {
+		return ""
+	}
+	return pr.FromVersion + " → " + pr.ToVersion
+}
+
+// writeCheckTable writes check results as aligned columns, one row per open
+// PR, with security updates marked. Repositories that could not be checked
+// and stale ignored_prs entries are listed after the table.
+func writeCheckTable(w io.Writer, results []checkResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REPOSITORY\tPR\tPACKAGE\tUPDATE\tTYPE\tSTATUS\tAGE\t")
+	var notes []string
+	for _, r := range results {
+		repoKey := r.Owner + "/" + r.Repo
+		switch {
+		case r.Invalid != nil:
+			notes = append(notes, fmt.Sprintf("invalid: %v", r.Invalid))
+			continue
+		case r.Err != nil:
+			notes = append(notes, fmt.Sprintf("%s: error: %v", repoKey, r.Err))
+			continue
+		}
+		for _, c := range r.StaleIgnored {
+			notes = append(notes, fmt.Sprintf("%s: stale ignore: #%d is %s; remove it from repositories.%s.ignored_prs", repoKey, c.Number, c.State, repoKey))
+		}
+		for _, pr := range r.PRs {
+			number := fmt.Sprintf("#%d", pr.Number)
+			if pr.Security {
+				number += " (security)"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", repoKey, number, pr.PackageName, checkUpdate(pr), pr.UpdateType, checkStatus(pr), formatAge(time.Since(pr.CreatedAt)))
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, note := range notes {
+		fmt.Fprintln(w, note)
+	}
+	return nil
+}
+
+// checkCSVHeader names the columns of check --format csv.
+var checkCSVHeader = []string{
+	"repository", "number", "title", "url", "package", "ecosystem",
+	"from_version", "to_version", "update_type", "security", "ci_status",
+	"skip_code", "skip_reason", "created_at", "error",
+}
+
+// writeCheckCSV writes check results as CSV, one row per open PR. A
+// repository that could not be checked is a row with only its error.
+func writeCheckCSV(w io.Writer, results []checkResult) error {
+	cw := csv.NewWriter(w)
+	cw.Write(checkCSVHeader)
+	for _, r := range results {
+		repoKey := r.Owner + "/" + r.Repo
+		switch {
+		case r.Invalid != nil:
+			cw.Write(csvErrorRow("", r.Invalid))
+			continue
+		case r.Err != nil:
+			cw.Write(csvErrorRow(repoKey, r.Err))
+			continue
+		}
+		for _, pr := range r.PRs {
+			cw.Write([]string{
+				repoKey, strconv.Itoa(pr.Number), pr.Title, pr.URL, pr.PackageName, pr.Ecosystem,
+				pr.FromVersion, pr.ToVersion, pr.UpdateType, strconv.FormatBool(pr.Security), pr.CIStatus,
+				pr.SkipCode, pr.SkipReason, pr.CreatedAt.UTC().Format(time.RFC3339), "",
+			})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvErrorRow returns the CSV row of a repository that could not be checked.
+func csvErrorRow(repoKey string, err error) []string {
+	row := make([]string, len(checkCSVHeader))
+	row[0] = repoKey
+	row[len(row)-1] = err.Error()
+	return row
+}
+
+// writeCheckMarkdown writes check results as a Markdown table, one row per
+// open PR, linking each PR. Repositories that could not be checked are listed
+// after the table.
+func writeCheckMarkdown(w io.Writer, results []checkResult) error {
+	fmt.Fprintln(w, "| Repository | PR | Package | Update | Type | Status |")
+	fmt.Fprintln(w, "|---|---|---|---|---|---|")
+	var notes []string
+	for _, r := range results {
+		repoKey := r.Owner + "/" + r.Repo
+		switch {
+		case r.Invalid != nil:
+			notes = append(notes, fmt.Sprintf("- Invalid: %v", r.Invalid))
+			continue
+		case r.Err != nil:
+			notes = append(notes, fmt.Sprintf("- `%s`: error: %v", repoKey, r.Err))
+			continue
+		}
+		for _, pr := range r.PRs {
+			number := fmt.Sprintf("[#%d](%s)", pr.Number, pr.URL)
+			if pr.Security {
+				number += " 🔒"
+			}
+			fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s |\n", repoKey, number, markdownCell(pr.PackageName), markdownCell(checkUpdate(pr)), pr.UpdateType, checkStatus(pr))
+		}
+	}
+	if len(notes) > 0 {
+		fmt.Fprintln(w)
+		for _, note := range notes {
+			fmt.Fprintln(w, note)
+		}
+	}
+	return nil
+}
+
+// markdownCell escapes the pipes of a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
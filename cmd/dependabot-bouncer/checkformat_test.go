@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+func TestCheckFormats(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	results := []checkResult{
+		{Owner: "acme", Repo: "api", PRs: []bouncer.PRInfo{
+			{Number: 1, Title: "Bump lodash from 4.17.20 to 4.17.21", URL: "https://github.com/acme/api/pull/1", PackageName: "lodash", Ecosystem: "npm", FromVersion: "4.17.20", ToVersion: "4.17.21", UpdateType: "patch", CIStatus: "success", CreatedAt: created},
+			{Number: 2, Title: "Bump left-pad from 1.1.0 to 1.3.0", URL: "https://github.com/acme/api/pull/2", PackageName: "left-pad", Skipped: true, SkipCode: bouncer.SkipDeniedPackage, SkipReason: "package denied", Security: true, CreatedAt: created},
+		}},
+		{Owner: "acme", Repo: "web", Err: errors.New("not found")},
+	}
+
+	tests := []struct {
+		name  string
+		write func(*strings.Builder) error
+		want  string
+	}{
+		{
+			name:  "csv",
+			write: func(b *strings.Builder) error { return writeCheckCSV(b, results) },
+			want: `repository,number,title,url,package,ecosystem,from_version,to_version,update_type,security,ci_status,skip_code,skip_reason,created_at,error
+acme/api,1,Bump lodash from 4.17.20 to 4.17.21,https://github.com/acme/api/pull/1,lodash,npm,4.17.20,4.17.21,patch,false,success,,,2026-03-01T12:00:00Z,
+acme/api,2,Bump left-pad from 1.1.0 to 1.3.0,https://github.com/acme/api/pull/2,left-pad,,,,,true,,DENIED_PACKAGE,package denied,2026-03-01T12:00:00Z,
+acme/web,,,,,,,,,,,,,,not found
+`,
+		},
+		{
+			name:  "markdown",
+			write: func(b *strings.Builder) error { return writeCheckMarkdown(b, results) },
+			want: "| Repository | PR | Package | Update | Type | Status |\n" +
+				"|---|---|---|---|---|---|\n" +
+				"| acme/api | [#1](https://github.com/acme/api/pull/1) | lodash | 4.17.20 → 4.17.21 | patch | success |\n" +
+				"| acme/api | [#2](https://github.com/acme/api/pull/2) 🔒 | left-pad |  |  | DENIED_PACKAGE |\n" +
+				"\n" +
+				"- `acme/web`: error: not found\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := tt.write(&b); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", b.String(), tt.want)
+			}
+		})
+	}
+}
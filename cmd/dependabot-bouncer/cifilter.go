@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// ciFlag is the CI filter selected on the command line, set by setupSCM;
+// empty when none of the CI flags were given.
+var ciFlag string
+
+// ciFlags maps each CI flag to the filter it selects.
+var ciFlags = []struct{ name, filter string }{
+	{"include-pending", bouncer.CIPassingOrPending},
+	{"include-failing", bouncer.CIAny},
+	{"only-failing", bouncer.CIFailing},
+}
+
+// addCIFlags registers the flags that choose which PRs a command acts on by
+// the status of their checks.
+func addCIFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("include-pending", false, "Also act on PRs whose checks are still running")
+	cmd.Flags().Bool("include-failing", false, "Act on PRs whatever the status of their checks")
+	cmd.Flags().Bool("only-failing", false, "Only act on PRs with failing checks")
+}
+
+// parseCIFlags returns the CI filter selected by the CI flags of cmd, if it
+// has them.
+func parseCIFlags(cmd *cobra.Command) (string, error) {
+	var filter string
+	var given []string
+	for _, f := range ciFlags {
+		if on, _ := cmd.Flags().GetBool(f.name); on {
+			filter = f.filter
+			given = append(given, "--"+f.name)
+		}
+	}
+	if len(given) > 1 {
+		return "", fmt.Errorf("%s cannot be combined", strings.Join(given, " and "))
+	}
+	return filter, nil
+}
+
+// ciFilter returns the CI filter of a command for a repository: the CI flags,
+// else ci_filter.<command> from the repository, policy group, or global
+// config, else def.
+func ciFilter(command, owner, repo, def string) (string, error) {
+	if ciFlag != "" {
+		return ciFlag, nil
+	}
+	filter, source := def, ""
+	for _, prefix := range settingPrefixes(owner + "/" + repo) {
+		key := prefix + "ci_filter." + command
+		if v := viper.GetString(key); v != "" {
+			filter, source = v, key
+		}
+	}
+	if !bouncer.ValidCIFilter(filter) {
+		return "", fmt.Errorf("invalid %s %q (expected %q, %q, %q, or %q)", source, filter, bouncer.CIPassing, bouncer.CIPassingOrPending, bouncer.CIAny, bouncer.CIFailing)
+	}
+	return filter, nil
+}
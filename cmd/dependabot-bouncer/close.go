@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+)
+
+var closeCmd = &cobra.Command{
+	Use:   "close --superseded [owner/repo...]",
+	Short: "Close Dependabot PRs superseded by newer ones",
+	Long: `Close the open PRs whose updates newer open PRs all propose again, leaving a
+comment that names the PRs superseding them. This clears out the single
+updates left behind when a grouped update takes over their packages, and
+older updates of a package when a newer one is open.
+
+A PR is superseded when every package it bumps is bumped by a PR opened
+later, in the same ecosystem and directory; a grouped update across several
+directories covers them all. An older grouped update is only superseded
+when all of its packages are. PRs listed in ignored_prs are left alone;
+PRs denied by policy are otherwise closed like the rest.
+
+--superseded is required: superseded PRs are the only ones close picks out.
+
+If no repositories are specified as arguments or with --org, processes all
+repositories configured in the 'repositories' and 'organizations' sections
+of your config file.` + exitCodesHelp,
+	RunE: runCloseCommand,
+}
+
+func runCloseCommand(cmd *cobra.Command, args []string) error {
+	if superseded, _ := cmd.Flags().GetBool("superseded"); !superseded {
+		return fmt.Errorf("nothing to close: pass --superseded")
+	}
+	repos, err := resolveRepos(cmd, args, true)
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories specified. Use command-line arguments, --org, or configure repositories in config file")
+	}
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	doc, human := outputWriters(format)
+
+	limit := newActionLimit(cmd)
+	workers := concurrency(cmd)
+	results := newRunResults()
+	err = forEachRepo(human, repos, func(owner, repo string) error {
+		out := results.repo(owner + "/" + repo)
+		out.Err = runCloseSuperseded(human, owner, repo, limit, workers, out)
+		return out.Err
+	})
+	reportToActions(human, cmd.Name(), results)
+	recordRun(cmd.Name(), results)
+	notifyRun(cmd.Name(), results, false)
+	if format != outputText {
+		if docErr := writeDocument(doc, format, resultsDocument(results)); docErr != nil {
+			return docErr
+		}
+	} else {
+		printResults(results)
+	}
+	return exitStatus(cmd, err, results.counts())
+}
+
+// runCloseSuperseded closes the superseded PRs of a repository, recording
+// the outcome for each in out.
+func runCloseSuperseded(w io.Writer, owner, repo string, limit *actionLimit, workers int, out *repoResults) error {
+	provider, err := providerFor(owner, repo)
+	if err != nil {
+		return err
+	}
+	var stats bouncer.ListStats
+	listed, err := listFilteredPRs(provider, owner, repo, out.numbers, bouncer.CIAny, &stats, &out.PolicySkipped)
+	if err != nil {
+		return err
+	}
+	out.Stats = &stats
+
+	open := listed
+	for _, pr := range out.PolicySkipped {
+		if pr.SkipCode != bouncer.SkipIgnored {
+			open = append(open, pr)
+		}
+	}
+	found := bouncer.FindSuperseded(open)
+	if len(found) == 0 {
+		fmt.Fprintf(w, "No superseded PRs among %d pull requests\n", len(open))
+		return nil
+	}
+
+	prs := make([]bouncer.PRInfo, len(found))
+	by := make(map[int][]int, len(found))
+	for i, s := range found {
+		prs[i] = s.PR
+		by[s.PR.Number] = s.By
+	}
+	if prs = limit.take(prs); len(prs) == 0 {
+		out.Skipped = "limit reached"
+		return nil
+	}
+	closing := make(map[int]bool, len(prs))
+	for _, pr := range prs {
+		closing[pr.Number] = true
+	}
+	var rest []bouncer.PRInfo
+	for _, pr := range out.PolicySkipped {
+		if !closing[pr.Number] {
+			rest = append(rest, pr)
+		}
+	}
+	out.PolicySkipped = rest
+
+	fmt.Fprintf(w, "Closing %d superseded pull requests...\n", len(prs))
+
+	results := make([]prResult, len(prs))
+	runOrdered(len(prs), workers, func(i int) {
+		results[i] = closeSuperseded(provider, owner, repo, prs[i], by[prs[i].Number])
+	}, func(i int) {
+		logResult(results[i])
+		out.PRs = append(out.PRs, results[i])
+	})
+	return resultsError(out.PRs)
+}
+
+// closeSuperseded explains on a PR which PRs supersede it and closes it.
+// The PR is left open when the comment cannot be posted.
+func closeSuperseded(provider bouncer.Client, owner, repo string, pr bouncer.PRInfo, by []int) prResult {
+	r := newPRResult(pr, "Closed")
+	refs := prRefs(by)
+	if err := provider.Comment(runCtx, owner, repo, pr.Number, supersededComment(refs)); err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("failed to comment: %v", err))
+		return r
+	}
+	if err := provider.Close(runCtx, owner, repo, pr.Number); err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("failed to close: %v", err))
+	} else {
+		r.Details = append(r.Details, "closed (superseded by "+refs+")")
+	}
+	return r
+}
+
+// supersededComment is the comment left on a PR closed as superseded by the
+// PRs refs.
+func supersededComment(refs string) string {
+	return fmt.Sprintf("Superseded by %s, which also updates every dependency of this pull request. Closing this one in favor of the newer pull request.", refs)
+}
+
+// prRefs formats PR numbers as "#1, #2".
+func prRefs(numbers []int) string {
+	refs := make([]string, len(numbers))
+	for i, n := range numbers {
+		refs[i] = fmt.Sprintf("#%d", n)
+	}
+	return strings.Join(refs, ", ")
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer/bouncertest"
+	"github.com/spf13/viper"
+)
+
+func TestRunCloseSuperseded(t *testing.T) {
+	fake := bouncertest.NewFake()
+	lodash := bouncer.ParsePR(bouncer.PRInfo{Number: 1, Title: "Bump lodash from 4.17.20 to 4.17.21"}, "dependabot/npm_and_yarn/lodash-4.17.21")
+	fake.AddPR("acme", "api", "", lodash)
+	fake.AddPR("acme", "api", "dependabot/npm_and_yarn/react-18.3.1", bouncer.PRInfo{Number: 2, Title: "Bump react from 18.3.0 to 18.3.1"})
+	fake.AddPR("acme", "api", "dependabot/npm_and_yarn/left-pad-1.2.0", bouncer.PRInfo{Number: 3, Title: "Bump left-pad from 1.1.0 to 1.2.0"})
+	fake.AddPR("acme", "api", "dependabot/npm_and_yarn/left-pad-1.3.0", bouncer.PRInfo{Number: 4, Title: "Bump left-pad from 1.1.0 to 1.3.0"})
+	fake.AddPR("acme", "api", "dependabot/npm_and_yarn/vue-3.4.1", bouncer.PRInfo{Number: 5, Title: "Bump vue from 3.4.0 to 3.4.1"})
+	fake.AddPR("acme", "api", "dependabot/npm_and_yarn/vue-3.4.2", bouncer.PRInfo{Number: 6, Title: "Bump vue from 3.4.0 to 3.4.2"})
+	fake.AddPR("acme", "api", "", bouncer.PRInfo{
+		Number:      7,
+		Title:       "Bump the web group with 2 updates",
+		PackageName: "web",
+		Ecosystem:   lodash.Ecosystem,
+		Updates:     []bouncer.Update{{Package: "lodash"}, {Package: "express"}},
+	})
+
+	orig := newClient
+	newClient = func(bouncer.Options) (bouncer.Client, error) { return fake, nil }
+	t.Cleanup(func() { newClient = orig; viper.Reset() })
+	viper.Set("global.denied_packages", []string{"left-pad"})
+	viper.Set("repositories.acme/api.ignored_prs", []int{5})
+
+	var out repoResults
+	if err := runCloseSuperseded(io.Discard, "acme", "api", &actionLimit{remaining: -1}, 1, &out); err != nil {
+		t.Fatalf("runCloseSuperseded() error = %v", err)
+	}
+
+	var closed []int
+	for _, c := range fake.Calls() {
+		switch c.Method {
+		case "Close":
+			closed = append(closed, c.Number)
+		case "Comment":
+			if c.Number == 1 && c.Arg != supersededComment("#7") {
+				t.Errorf("comment on #1 = %q", c.Arg)
+			}
+		}
+	}
+	slices.Sort(closed)
+	if want := []int{1, 3}; !slices.Equal(closed, want) {
+		t.Errorf("closed PRs = %v, want %v", closed, want)
+	}
+	for _, r := range out.PRs {
+		if r.Action != "Closed" || len(r.Errors) > 0 {
+			t.Errorf("result = %+v", r)
+		}
+	}
+	for _, pr := range out.PolicySkipped {
+		if pr.Number == 3 {
+			t.Errorf("closed PR #3 still listed as skipped by policy")
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+// dependencyOwners merges the global, policy group, and repo-specific
+// dependency_owners: the CODEOWNERS owners, such as "@myorg/platform", whose
+// files the bouncer may approve changes to on its own.
+func dependencyOwners(repoKey string) []string {
+	var owners []string
+	for _, prefix := range settingPrefixes(repoKey) {
+		owners = append(owners, getStringSlice(prefix+"dependency_owners")...)
+	}
+	return removeDuplicates(owners)
+}
+
+// gateCodeOwners moves the PRs that change files owned in CODEOWNERS by
+// someone other than the dependency owners from prs to out.PolicySkipped,
+// with skip code CODEOWNERS, and returns the rest. Files without owners, and
+// files one of whose owners is a dependency owner, do not hold a PR back.
+// It does nothing unless dependency_owners is set, and only on GitHub. A PR
+// whose changed files cannot be read is reported as failed.
+func gateCodeOwners(provider bouncer.Client, owner, repo string, prs []bouncer.PRInfo, stats *bouncer.ListStats, out *repoResults) ([]bouncer.PRInfo, error) {
+	repoKey := owner + "/" + repo
+	allowed := dependencyOwners(repoKey)
+	if len(allowed) == 0 || len(prs) == 0 {
+		return prs, nil
+	}
+	if _, ok := provider.(bouncer.GitHub); !ok {
+		log.Printf("Warning: dependency_owners is only supported on GitHub; not checking CODEOWNERS of %s\n", repoKey)
+		return prs, nil
+	}
+	codeOwners, err := gitHub().FetchCodeOwners(runCtx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []bouncer.PRInfo
+	for _, pr := range prs {
+		files, err := gitHub().ChangedFiles(runCtx, owner, repo, pr.Number)
+		if err != nil {
+			r := newPRResult(pr, "Skipped")
+			r.Errors = append(r.Errors, fmt.Sprintf("failed to check CODEOWNERS: %v", err))
+			logResult(r)
+			out.PRs = append(out.PRs, r)
+			continue
+		}
+		foreign, others := foreignFiles(codeOwners, files, allowed)
+		if len(foreign) == 0 {
+			kept = append(kept, pr)
+			continue
+		}
+		reason := fmt.Sprintf("owned by %s: %s", strings.Join(others, ", "), foreign[0])
+		if len(foreign) > 1 {
+			reason += fmt.Sprintf(" and %d more", len(foreign)-1)
+		}
+		skipListed(pr, bouncer.SkipCodeOwners, reason, stats, out)
+	}
+	return kept, nil
+}
+
+// foreignFiles returns the files owned by none of allowed, and the owners
+// of those files.
+func foreignFiles(codeOwners *bouncer.CodeOwners, files, allowed []string) (foreign, owners []string) {
+	for _, f := range files {
+		fileOwners := codeOwners.Owners(f)
+		if len(fileOwners) == 0 || slices.ContainsFunc(fileOwners, func(o string) bool {
+			return slices.ContainsFunc(allowed, func(a string) bool { return strings.EqualFold(a, o) })
+		}) {
+			continue
+		}
+		foreign = append(foreign, f)
+		for _, o := range fileOwners {
+			if !slices.Contains(owners, o) {
+				owners = append(owners, o)
+			}
+		}
+	}
+	return foreign, owners
+}
@@ -1,18 +1,34 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/huh"
-	"github.com/promiseofcake/dependabot-bouncer/internal/scm"
+	"github.com/promiseofcake/dependabot-bouncer/internal/events"
+	"github.com/promiseofcake/dependabot-bouncer/internal/export"
+	"github.com/promiseofcake/dependabot-bouncer/internal/risk"
+	"github.com/promiseofcake/dependabot-bouncer/internal/tracing"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-// parseRepo splits an "owner/repo" string into its parts.
+// parseRepo splits an "owner/repo" string, or the URL of a repository or of
+// a page in it, into its parts.
 func parseRepo(arg string) (owner, repo string, err error) {
+	if isRepoURL(arg) {
+		return parseRepoURL(arg)
+	}
 	parts := strings.Split(arg, "/")
 	if len(parts) != 2 {
 		return "", "", fmt.Errorf("invalid repository format: %s (expected owner/repo)", arg)
@@ -20,138 +36,779 @@ func parseRepo(arg string) (owner, repo string, err error) {
 	return parts[0], parts[1], nil
 }
 
-// buildDenyLists merges global and repo-specific deny lists from config.
-func buildDenyLists(repoKey string) (deniedPackages, deniedOrgs []string) {
-	deniedPackages = getStringSlice("global.denied_packages")
-	deniedOrgs = getStringSlice("global.denied_orgs")
-
-	deniedPackages = append(deniedPackages, getStringSlice("repositories."+repoKey+".denied_packages")...)
-	deniedOrgs = append(deniedOrgs, getStringSlice("repositories."+repoKey+".denied_orgs")...)
+// parsePRRef splits an "owner/repo#number" string or a pull request URL into
+// its parts.
+func parsePRRef(arg string) (owner, repo string, number int, err error) {
+	if strings.Contains(arg, "://") {
+		return parsePRURL(arg)
+	}
+	repoPart, numPart, ok := strings.Cut(arg, "#")
+	if !ok {
+		return "", "", 0, fmt.Errorf("invalid pull request reference: %s (expected owner/repo#number)", arg)
+	}
+	owner, repo, err = parseRepo(repoPart)
+	if err != nil {
+		return "", "", 0, err
+	}
+	number, err = strconv.Atoi(numPart)
+	if err != nil || number <= 0 {
+		return "", "", 0, fmt.Errorf("invalid pull request number in %s", arg)
+	}
+	return owner, repo, number, nil
+}
 
+// buildDenyLists merges the deny lists of the policy layers from config.
+// Entries that deny update types of a package are left to
+// buildUpdateTypeDenials.
+func buildDenyLists(layers []policyLayer) (deniedPackages, deniedOrgs []string) {
+	for _, layer := range layers {
+		packages, _ := splitDeniedPackages(layer.DeniedPackages)
+		deniedPackages = append(deniedPackages, packages...)
+		deniedOrgs = append(deniedOrgs, layer.DeniedOrgs...)
+	}
 	return removeDuplicates(deniedPackages), removeDuplicates(deniedOrgs)
 }
 
+// splitDeniedPackages splits off the entries of a denied_packages list that
+// only deny update types of a package, such as
+// "github.com/gin-gonic/gin: deny=major".
+func splitDeniedPackages(entries packageEntries) (packages []string, byType map[string][]string) {
+	byType = make(map[string][]string)
+	for _, entry := range entries {
+		if pattern, types, ok := bouncer.ParseUpdateTypeDenial(entry); ok {
+			byType[pattern] = append(byType[pattern], types...)
+			continue
+		}
+		packages = append(packages, entry)
+	}
+	return packages, byType
+}
+
+// buildAllowLists merges the allow lists of the policy layers from config.
+func buildAllowLists(layers []policyLayer) (allowedPackages, allowedOrgs []string) {
+	for _, layer := range layers {
+		allowedPackages = append(allowedPackages, layer.AllowedPackages...)
+		allowedOrgs = append(allowedOrgs, layer.AllowedOrgs...)
+	}
+	return removeDuplicates(allowedPackages), removeDuplicates(allowedOrgs)
+}
+
+// buildTrustLists merges the trusted lists of the policy layers from config.
+func buildTrustLists(layers []policyLayer) (trustedPackages, trustedOrgs []string) {
+	for _, layer := range layers {
+		trustedPackages = append(trustedPackages, layer.TrustedPackages...)
+		trustedOrgs = append(trustedOrgs, layer.TrustedOrgs...)
+	}
+	return removeDuplicates(trustedPackages), removeDuplicates(trustedOrgs)
+}
+
+// buildQuery builds a query for a repository from the configured allow and
+// deny lists, precedence, and unparsed-title policy. The repo-specific
+// precedence and policy override the global ones. During a canary rollout,
+// the canary policy is layered in between for the repositories it applies to.
+func buildQuery(owner, repo string) (bouncer.DependencyUpdateQuery, error) {
+	repoKey := fmt.Sprintf("%s/%s", owner, repo)
+	return buildPolicyQuery(owner, repo, policyPrefixes(repoKey, canaryApplies(repoKey, time.Now())))
+}
+
+// policyPrefixes returns the config prefixes whose policy settings apply to
+// a repository, lowest precedence first: global, the canary policy when
+// canary is set, the policy groups the repository names, then its own.
+func policyPrefixes(repoKey string, canary bool) []string {
+	prefixes := settingPrefixes(repoKey)
+	if canary {
+		prefixes = append([]string{prefixes[0], canaryPolicyKey + "."}, prefixes[1:]...)
+	}
+	return prefixes
+}
+
+// buildPolicyQuery builds the query of buildQuery from the policy settings
+// under prefixes.
+func buildPolicyQuery(owner, repo string, prefixes []string) (bouncer.DependencyUpdateQuery, error) {
+	repoKey := fmt.Sprintf("%s/%s", owner, repo)
+	layers, err := policyLayers(prefixes)
+	if err != nil {
+		return bouncer.DependencyUpdateQuery{}, err
+	}
+	deniedPackages, deniedOrgs := buildDenyLists(layers)
+	allowedPackages, allowedOrgs := buildAllowLists(layers)
+	trustedPackages, trustedOrgs := buildTrustLists(layers)
+
+	var precedence string
+	for _, layer := range layers {
+		if layer.Precedence != "" {
+			precedence = layer.Precedence
+		}
+	}
+	switch precedence {
+	case "":
+		precedence = bouncer.PrecedenceDeny
+	case bouncer.PrecedenceDeny, bouncer.PrecedenceAllow:
+	default:
+		return bouncer.DependencyUpdateQuery{}, fmt.Errorf("invalid precedence %q for %s (expected %q or %q)", precedence, repoKey, bouncer.PrecedenceDeny, bouncer.PrecedenceAllow)
+	}
+
+	unparsed := bouncer.UnparsedWarn
+	for _, layer := range layers {
+		if layer.UnparsedTitles != "" {
+			unparsed = strings.ToLower(layer.UnparsedTitles)
+		}
+	}
+	switch unparsed {
+	case bouncer.UnparsedWarn, bouncer.UnparsedProcess, bouncer.UnparsedSkip:
+	default:
+		return bouncer.DependencyUpdateQuery{}, fmt.Errorf("invalid unparsed_titles %q for %s (expected %q, %q, or %q)", unparsed, repoKey, bouncer.UnparsedWarn, bouncer.UnparsedProcess, bouncer.UnparsedSkip)
+	}
+
+	deniedTypes, deniedTypesByPackage, err := buildUpdateTypeDenials(repoKey, layers)
+	if err != nil {
+		return bouncer.DependencyUpdateQuery{}, err
+	}
+	internalPackages, internalTypes, externalTypes, err := buildInternalPolicy(repoKey, layers)
+	if err != nil {
+		return bouncer.DependencyUpdateQuery{}, err
+	}
+	ecoPackages, ecoOrgs := buildEcosystemDenyLists(layers)
+	if err := validateDenyEntries(repoKey, deniedPackages, deniedOrgs, ecoPackages, ecoOrgs); err != nil {
+		return bouncer.DependencyUpdateQuery{}, err
+	}
+
+	// Security updates bypass the allow and deny lists unless disabled.
+	exemptSecurity := true
+	var requiredChecks []string
+	minChecks := 0
+	for _, layer := range layers {
+		if layer.SecurityUpdates.ExemptFromDeny != nil {
+			exemptSecurity = *layer.SecurityUpdates.ExemptFromDeny
+		}
+		if layer.MinSuccessfulChecks != nil {
+			minChecks = *layer.MinSuccessfulChecks
+		}
+		requiredChecks = append(requiredChecks, layer.RequiredChecks...)
+	}
+
+	return bouncer.DependencyUpdateQuery{
+		Owner:                      owner,
+		Repo:                       repo,
+		DeniedPackages:             deniedPackages,
+		DeniedOrgs:                 deniedOrgs,
+		AllowedPackages:            allowedPackages,
+		AllowedOrgs:                allowedOrgs,
+		Precedence:                 precedence,
+		TrustedPackages:            trustedPackages,
+		TrustedOrgs:                trustedOrgs,
+		DeniedUpdateTypes:          deniedTypes,
+		DeniedUpdateTypesByPackage: deniedTypesByPackage,
+		InternalPackages:           internalPackages,
+		DeniedUpdateTypesInternal:  internalTypes,
+		DeniedUpdateTypesExternal:  externalTypes,
+		EcosystemDeniedPackages:    ecoPackages,
+		EcosystemDeniedOrgs:        ecoOrgs,
+		ExemptSecurity:             exemptSecurity,
+		UnparsedTitles:             unparsed,
+		CreatedAfter:               prWindow.CreatedAfter,
+		CreatedBefore:              prWindow.CreatedBefore,
+		UpdatedSince:               prWindow.UpdatedSince,
+		RequiredChecks:             removeDuplicates(requiredChecks),
+		MinSuccessfulChecks:        minChecks,
+	}, nil
+}
+
+// validateDenyEntries checks the regular expressions, version constraints,
+// and organization globs of the deny entries from config, per-ecosystem, and
+// --deny-packages and --deny-orgs, compiling the expressions once for the
+// whole run.
+func validateDenyEntries(repoKey string, deniedPackages, deniedOrgs []string, ecoPackages, ecoOrgs map[string][]string) error {
+	packages := append(append([]string(nil), deniedPackages...), viper.GetStringSlice("deny-packages")...)
+	orgs := append(append([]string(nil), deniedOrgs...), viper.GetStringSlice("deny-orgs")...)
+	for eco := range ecoPackages {
+		packages = append(packages, ecoPackages[eco]...)
+	}
+	for eco := range ecoOrgs {
+		orgs = append(orgs, ecoOrgs[eco]...)
+	}
+	for _, entry := range packages {
+		if err := bouncer.ValidateDenyEntry(entry); err != nil {
+			return fmt.Errorf("%w for %s", err, repoKey)
+		}
+	}
+	for _, entry := range orgs {
+		if err := bouncer.ValidateOrgEntry(entry); err != nil {
+			return fmt.Errorf("%w for %s", err, repoKey)
+		}
+	}
+	return nil
+}
+
+// buildEcosystemDenyLists merges the deny lists from the "ecosystems"
+// sections of the policy layers, keyed by package-ecosystem name. The global
+// layer's section is the top-level "ecosystems".
+func buildEcosystemDenyLists(layers []policyLayer) (deniedPackages, deniedOrgs map[string][]string) {
+	deniedPackages = make(map[string][]string)
+	deniedOrgs = make(map[string][]string)
+
+	for _, layer := range layers {
+		for eco, policy := range layer.Ecosystems {
+			deniedPackages[eco] = removeDuplicates(append(deniedPackages[eco], policy.DeniedPackages...))
+			deniedOrgs[eco] = removeDuplicates(append(deniedOrgs[eco], policy.DeniedOrgs...))
+		}
+	}
+
+	return deniedPackages, deniedOrgs
+}
+
+// buildUpdateTypeDenials merges the update-type denials of the policy
+// layers from config, both the list applied to every package and the
+// per-package map, which denied_packages entries such as
+// "github.com/gin-gonic/gin: deny=major" add to.
+func buildUpdateTypeDenials(repoKey string, layers []policyLayer) ([]string, map[string][]string, error) {
+	var types []string
+	byPackage := make(map[string][]string)
+	for _, layer := range layers {
+		types = append(types, layer.DenyUpdateTypes...)
+		for pkg, t := range layer.DenyUpdateTypesByPackage {
+			byPackage[pkg] = append(byPackage[pkg], t...)
+		}
+		_, entries := splitDeniedPackages(layer.DeniedPackages)
+		for pkg, t := range entries {
+			byPackage[pkg] = append(byPackage[pkg], t...)
+		}
+	}
+
+	all := append([]string(nil), types...)
+	for _, t := range byPackage {
+		all = append(all, t...)
+	}
+	if err := checkUpdateTypes(repoKey, all); err != nil {
+		return nil, nil, err
+	}
+
+	return removeDuplicates(types), byPackage, nil
+}
+
+// buildInternalPolicy merges the internal_packages and the update-type
+// denials for internal and external packages of the policy layers from
+// config.
+func buildInternalPolicy(repoKey string, layers []policyLayer) (packages, internalTypes, externalTypes []string, err error) {
+	for _, layer := range layers {
+		packages = append(packages, layer.InternalPackages...)
+		internalTypes = append(internalTypes, layer.DenyUpdateTypesInternal...)
+		externalTypes = append(externalTypes, layer.DenyUpdateTypesExternal...)
+	}
+	if err := checkUpdateTypes(repoKey, append(append([]string(nil), internalTypes...), externalTypes...)); err != nil {
+		return nil, nil, nil, err
+	}
+	return removeDuplicates(packages), removeDuplicates(internalTypes), removeDuplicates(externalTypes), nil
+}
+
+// checkUpdateTypes rejects configured update types other than major, minor,
+// and patch.
+func checkUpdateTypes(repoKey string, types []string) error {
+	for _, t := range types {
+		switch strings.ToLower(t) {
+		case bouncer.UpdateMajor, bouncer.UpdateMinor, bouncer.UpdatePatch:
+		default:
+			return fmt.Errorf("invalid update type %q for %s (expected major, minor, or patch)", t, repoKey)
+		}
+	}
+	return nil
+}
+
+// defaultReviewComment is the body of comment reviews when none is configured.
+const defaultReviewComment = "@dependabot squash and merge"
+
+// reviewPolicy is how approve reviews a PR: the review event and its body,
+// and how the PR is merged once reviewed.
+type reviewPolicy struct {
+	Event string // bouncer.ReviewApprove or bouncer.ReviewComment
+	Body  string
+	Merge mergePolicy
+}
+
+// buildReviewPolicy reads review_event, review_comment, and merge_method
+// from config; the policy group and repo-specific settings override the
+// global ones.
+func buildReviewPolicy(owner, repo string) (reviewPolicy, error) {
+	repoKey := fmt.Sprintf("%s/%s", owner, repo)
+	p := reviewPolicy{Event: bouncer.ReviewApprove}
+	for _, prefix := range settingPrefixes(repoKey) {
+		if e := viper.GetString(prefix + "review_event"); e != "" {
+			p.Event = strings.ToLower(e)
+		}
+		if b := viper.GetString(prefix + "review_comment"); b != "" {
+			p.Body = b
+		}
+	}
+	merge, err := buildMergePolicy(repoKey)
+	if err != nil {
+		return reviewPolicy{}, err
+	}
+	p.Merge = merge
+
+	switch p.Event {
+	case bouncer.ReviewApprove:
+	case bouncer.ReviewComment:
+		if p.Body == "" {
+			p.Body = defaultReviewComment
+		}
+	default:
+		return reviewPolicy{}, fmt.Errorf("invalid review_event %q for %s (expected %q or %q)", p.Event, repoKey, bouncer.ReviewApprove, bouncer.ReviewComment)
+	}
+	p.Merge.ReviewMerges = p.Event == bouncer.ReviewComment && strings.HasPrefix(strings.TrimSpace(p.Body), "@dependabot ")
+	return p, nil
+}
+
+// submit leaves the review on a PR.
+func (p reviewPolicy) submit(provider bouncer.Client, owner, repo string, number int) error {
+	if p.Event == bouncer.ReviewComment {
+		return provider.Comment(runCtx, owner, repo, number, p.Body)
+	}
+	return provider.Approve(runCtx, owner, repo, number)
+}
+
+// submitted reports whether login already left this review on pr, so a
+// comment review is not posted again on every run. Reviews are matched by
+// body and author. With login "" (the account is unknown) nothing counts:
+// the same body from a person or another bot must not stop the review.
+func (p reviewPolicy) submitted(pr bouncer.PRInfo, login string) bool {
+	if login == "" {
+		return false
+	}
+	for _, r := range pr.Reviews {
+		if strings.TrimSpace(r.Body) == strings.TrimSpace(p.Body) && strings.EqualFold(r.Author, login) {
+			return true
+		}
+	}
+	return false
+}
+
+// newClient creates the client providerFor returns. Tests replace it to run
+// commands against a bouncertest.Fake.
+var newClient = bouncer.NewClient
+
+// providerFor returns the SCM provider configured for a repository. The
+// policy group and repo-specific provider override the global one; GitHub is
+// the default.
+func providerFor(owner, repo string) (bouncer.Client, error) {
+	var name string
+	for _, prefix := range settingPrefixes(owner + "/" + repo) {
+		if p := viper.GetString(prefix + "provider"); p != "" {
+			name = p
+		}
+	}
+	name = strings.ToLower(name)
+
+	// Each provider reads its settings from the config section named after it.
+	opts := clientOptions
+	opts.Provider = name
+	opts.BotAuthor = viper.GetString(name + ".author")
+	opts.BaseURL = viper.GetString("gitea.url")
+	opts.Host = viper.GetString("github.host")
+	if name == bouncer.ProviderGitea {
+		opts.Token = os.Getenv("GITEA_TOKEN")
+	}
+	return newClient(opts)
+}
+
+// clientOptions are the client settings setupSCM reads from config: retries,
+// the Dependabot reply wait, and the ETag cache.
+var clientOptions bouncer.Options
+
+// gitHub returns the client of the commands that only work with GitHub, on
+// the configured host.
+func gitHub() bouncer.GitHub {
+	opts := clientOptions
+	opts.Host = viper.GetString("github.host")
+	return bouncer.NewGitHub(opts)
+}
+
+// past returns the log verb for a submitted review.
+func (p reviewPolicy) past() string {
+	if p.Event == bouncer.ReviewComment {
+		return "Commented on"
+	}
+	return "Approved"
+}
+
 var (
 	approveCmd = &cobra.Command{
-		Use:   "approve [owner/repo...]",
+		Use:   "approve [owner/repo | owner/repo#number | PR URL...]",
 		Short: "Approve dependency update pull requests",
 		Long: `Approve passing dependency update pull requests from Dependabot.
 
-In interactive mode (-i), if no repositories are specified, all repositories
-from the config file are used.`,
+Repositories can be given as arguments or discovered with --org. In
+interactive mode (-i), if neither is given, all repositories and
+organizations from the config file are used.
+
+Repositories and organizations can also be given as URLs copied from the
+browser. To act on specific pull requests only, give them as
+owner/repo#number or as URLs (https://github.com/owner/repo/pull/123), or
+give their numbers or URLs with --pr. They are fetched directly instead of listing the
+repository's PRs, and the policy still applies to them.` + exitCodesHelp,
+		RunE: runApproveCommand,
+	}
+
+	recreateCmd = &cobra.Command{
+		Use:   "recreate [owner/repo | owner/repo#number | PR URL...]",
+		Short: "Recreate dependency update pull requests",
+		Long: `Recreate all dependency update pull requests from Dependabot (including failing ones).
+
+Repositories can be given as arguments or discovered with --org. In
+interactive mode (-i), if neither is given, all repositories and
+organizations from the config file are used.
+
+Pull requests can be given like in approve to recreate only those.` + exitCodesHelp,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			interactive, _ := cmd.Flags().GetBool("interactive")
-			if interactive {
-				repos := args
-				if len(repos) == 0 {
-					repos = reposFromConfig()
-				}
-				if len(repos) == 0 {
-					return fmt.Errorf("no repositories specified. Use command-line arguments or configure repositories in config file")
-				}
-				return runApproveInteractiveMulti(repos)
-			}
-			if len(args) != 1 {
-				return fmt.Errorf("requires exactly 1 arg(s), or use -i for interactive mode with multiple repos")
+			args, targets, err := takePRTargets(cmd, args)
+			if err != nil {
+				return err
 			}
-			owner, repo, err := parseRepo(args[0])
+			repos, err := resolveRepos(cmd, args, interactive)
 			if err != nil {
 				return err
 			}
-			return runApprove(owner, repo)
-		},
-	}
-
-	recreateCmd = &cobra.Command{
-		Use:   "recreate owner/repo",
-		Short: "Recreate dependency update pull requests",
-		Long:  `Recreate all dependency update pull requests from Dependabot (including failing ones).`,
-		Args:  cobra.ExactArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			owner, repo, err := parseRepo(args[0])
+			if interactive {
+				return runInteractiveCommand(cmd, repos, targets)
+			}
+			if len(repos) == 0 {
+				return fmt.Errorf("requires at least 1 arg(s) or --org, or use -i for interactive mode with configured repos")
+			}
+			format, err := outputFormat(cmd)
 			if err != nil {
 				return err
 			}
-			return runRecreate(owner, repo)
+			doc, human := outputWriters(format)
+
+			limit := newActionLimit(cmd)
+			workers := concurrency(cmd)
+			results := newRunResults()
+			results.targets = targets
+			err = forEachRepo(human, repos, func(owner, repo string) error {
+				out := results.repo(owner + "/" + repo)
+				out.Err = runRecreate(human, owner, repo, limit, workers, out)
+				return out.Err
+			})
+			reportToActions(human, cmd.Name(), results)
+			recordRun(cmd.Name(), results)
+			notifyRun(cmd.Name(), results, false)
+			if format != outputText {
+				if docErr := writeDocument(doc, format, resultsDocument(results)); docErr != nil {
+					return docErr
+				}
+			} else {
+				printResults(results)
+				printRunSummary(resultsSummary(results))
+			}
+			return exitStatus(cmd, err, results.counts())
 		},
 	}
 
 	checkCmd = &cobra.Command{
-		Use:   "check [owner/repo...]",
+		Use:   "check [owner/repo | owner/repo#number | PR URL...]",
 		Short: "Check for open Dependabot PRs across repositories",
 		Long: `Check for open Dependabot pull requests across multiple repositories.
 
-If no repositories are specified as arguments, checks all repositories
-configured in the 'repositories' section of your config file.
+If no repositories are specified as arguments or with --org, checks all
+repositories configured in the 'repositories' and 'organizations' sections
+of your config file.
+
+You can specify multiple repositories: check owner1/repo1 owner2/repo2
 
-You can specify multiple repositories: check owner1/repo1 owner2/repo2`,
+Pull requests can be given like in approve to check only those.` + exitCodesHelp,
 		RunE: runCheck,
 	}
 )
 
-func runApprove(owner, repo string) error {
-	prs, err := listFilteredPRs(owner, repo, true)
-	if err != nil {
-		return err
+// baseBranchFailing reports whether skip_if_base_failing is enabled for the
+// repository (the policy group and repo-specific settings override the
+// global one) and its default branch is failing CI. Pending CI does not
+// count as failing.
+func baseBranchFailing(provider bouncer.Client, owner, repo string) (bool, error) {
+	enabled := false
+	for _, prefix := range settingPrefixes(owner + "/" + repo) {
+		if key := prefix + "skip_if_base_failing"; viper.IsSet(key) {
+			enabled = viper.GetBool(key)
+		}
 	}
-	if len(prs) == 0 {
-		fmt.Println("No dependency updates to process")
-		return nil
+	if !enabled {
+		return false, nil
 	}
 
-	fmt.Printf("Processing %d pull requests...\n", len(prs))
+	status, err := provider.BaseCIStatus(runCtx, owner, repo)
+	if err != nil {
+		return false, fmt.Errorf("failed to check default branch CI: %w", err)
+	}
+	return status == "failure", nil
+}
 
-	for _, pr := range prs {
-		switch pr.MergeStateStatus {
-		case "DIRTY":
-			// Conflicts — recreate the PR so Dependabot resolves them.
-			if err := scm.RecreatePR(owner, repo, pr.Number); err != nil {
-				log.Printf("Warning: failed to recreate PR #%d: %v\n", pr.Number, err)
-				continue
-			}
-			log.Printf("Recreated PR #%d (conflicts): %s\n", pr.Number, pr.Title)
+// actionLimit caps how many PRs a run acts on across all repositories.
+type actionLimit struct {
+	remaining int // -1 when unlimited
+}
 
-		case "BEHIND":
-			// Behind main — request a rebase.
-			if err := scm.RebasePR(owner, repo, pr.Number); err != nil {
-				log.Printf("Warning: failed to rebase PR #%d: %v\n", pr.Number, err)
-			} else {
-				log.Printf("Requested rebase on PR #%d (behind main): %s\n", pr.Number, pr.Title)
-			}
+// newActionLimit reads the --limit flag; zero or less means unlimited.
+func newActionLimit(cmd *cobra.Command) *actionLimit {
+	n, _ := cmd.Flags().GetInt("limit")
+	if n <= 0 {
+		n = -1
+	}
+	return &actionLimit{remaining: n}
+}
+
+// take returns the PRs that still fit within the limit and counts them.
+// Trusted PRs are always returned and not counted.
+func (l *actionLimit) take(prs []bouncer.PRInfo) []bouncer.PRInfo {
+	if l.remaining < 0 {
+		return prs
+	}
+	kept := make([]bouncer.PRInfo, 0, len(prs))
+	for _, pr := range prs {
+		switch {
+		case pr.Trusted:
+		case l.remaining > 0:
+			l.remaining--
+		default:
+			continue
 		}
+		kept = append(kept, pr)
+	}
+	if len(kept) < len(prs) {
+		log.Printf("Limit reached: acting on %d of %d pull requests\n", len(kept), len(prs))
+	}
+	return kept
+}
 
-		if pr.ReviewDecision == "APPROVED" {
-			log.Printf("Already approved PR #%d: %s\n", pr.Number, pr.Title)
-		} else {
-			if err := scm.ApprovePR(owner, repo, pr.Number); err != nil {
-				log.Printf("Warning: failed to approve PR #%d: %v\n", pr.Number, err)
-				continue
-			}
-			log.Printf("Approved PR #%d: %s (package: %s)\n", pr.Number, pr.Title, pr.PackageName)
+// runApproveCommand runs approve (or automerge) over the repositories and
+// PRs given as arguments.
+func runApproveCommand(cmd *cobra.Command, args []string) error {
+	interactive, _ := cmd.Flags().GetBool("interactive")
+	args, targets, err := takePRTargets(cmd, args)
+	if err != nil {
+		return err
+	}
+	repos, err := resolveRepos(cmd, args, interactive)
+	if err != nil {
+		return err
+	}
+	if interactive {
+		return runInteractiveCommand(cmd, repos, targets)
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("requires at least 1 arg(s) or --org, or use -i for interactive mode with configured repos")
+	}
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	doc, human := outputWriters(format)
+
+	summary := risk.NewSummary(viper.GetFloat64("risk.min_scorecard"))
+	changes := newChangeLog(cmd)
+	limit := newActionLimit(cmd)
+	workers := concurrency(cmd)
+	results := newRunResults()
+	results.risk = summary
+	results.targets = targets
+	if maxApprovals, _ := cmd.Flags().GetInt("max-approvals"); maxApprovals > 0 {
+		err = runPrioritizedApprove(human, repos, maxApprovals, summary, changes, limit, workers, results)
+	} else {
+		err = forEachRepo(human, repos, func(owner, repo string) error {
+			out := results.repo(owner + "/" + repo)
+			out.Err = runApprove(human, owner, repo, summary, changes, limit, workers, out)
+			return out.Err
+		})
+	}
+	reportToActions(human, cmd.Name(), results)
+	recordRun(cmd.Name(), results)
+	notifyRun(cmd.Name(), results, false)
+	if format != outputText {
+		if docErr := writeDocument(doc, format, resultsDocument(results)); docErr != nil {
+			return docErr
 		}
+	} else {
+		printResults(results)
+		printRunSummary(resultsSummary(results))
+		printRiskSummary(summary)
+	}
+	if exportErr := writeChangeLog(cmd, changes); exportErr != nil && err == nil {
+		err = exportErr
+	}
+	return exitStatus(cmd, err, results.counts())
+}
 
-		if err := scm.AutoMergePR(owner, repo, pr.Number); err != nil {
-			log.Printf("Warning: failed to enable auto-merge on PR #%d: %v\n", pr.Number, err)
-		} else {
-			log.Printf("Enabled auto-merge on PR #%d: %s\n", pr.Number, pr.Title)
+// runApprove approves the passing PRs of a repository, up to workers at a
+// time, recording the outcome for each PR in out in PR-list order and
+// writing its progress to w. With --wait-pending, PRs whose checks are still
+// running are approved once they pass. A failure on one PR does not stop the
+// others; the returned error aggregates them.
+func runApprove(w io.Writer, owner, repo string, summary *risk.Summary, changes *export.Log, limit *actionLimit, workers int, out *repoResults) error {
+	job, err := prepareApprove(w, owner, repo, out)
+	if err != nil || job == nil {
+		return err
+	}
+	return job.run(w, summary, changes, limit, workers, out)
+}
+
+// approveJob is what approve found to do in a repository.
+type approveJob struct {
+	provider    bouncer.Client
+	owner, repo string
+	review      reviewPolicy
+	prs         []bouncer.PRInfo // passing PRs to approve
+	pending     []bouncer.PRInfo // PRs to wait for with --wait-pending
+	wait        time.Duration
+	stats       *bouncer.ListStats
+}
+
+// prepareApprove lists the PRs of a repository that approve should act on,
+// recording the PRs skipped by policy in out. It returns nil when there is
+// nothing to do.
+func prepareApprove(w io.Writer, owner, repo string, out *repoResults) (*approveJob, error) {
+	provider, err := providerFor(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	if skip, err := baseBranchFailing(provider, owner, repo); err != nil {
+		return nil, err
+	} else if skip {
+		fmt.Fprintln(w, "Skipping: the default branch is failing CI")
+		out.Skipped = "default branch is failing CI"
+		return nil, nil
+	}
+	review, err := buildReviewPolicy(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := ciFilter("approve", owner, repo, bouncer.CIPassing)
+	if err != nil {
+		return nil, err
+	}
+	wait := viper.GetDuration("wait-pending")
+	if wait > 0 && filter == bouncer.CIPassing {
+		filter = bouncer.CIPassingOrPending
+	} else {
+		wait = 0
+	}
+	var stats bouncer.ListStats
+	prs, err := listFilteredPRs(provider, owner, repo, out.numbers, filter, &stats, &out.PolicySkipped)
+	if err != nil {
+		return nil, err
+	}
+	out.Stats = &stats
+	if prs, err = gateVulnerabilities(owner, repo, prs, &stats, out); err != nil {
+		return nil, err
+	}
+	if prs, err = gateMinAge(owner, repo, prs, &stats, out, time.Now()); err != nil {
+		return nil, err
+	}
+	if prs, err = gateCodeOwners(provider, owner, repo, prs, &stats, out); err != nil {
+		return nil, err
+	}
+	recordDenials(owner, repo, out.PolicySkipped)
+	var pending []bouncer.PRInfo
+	if wait > 0 {
+		prs, pending = splitPending(prs)
+	}
+	if len(prs) == 0 && len(pending) == 0 {
+		fmt.Fprintln(w, "No dependency updates to process")
+		printListStats(w, stats)
+		return nil, nil
+	}
+	return &approveJob{
+		provider: provider,
+		owner:    owner,
+		repo:     repo,
+		review:   review,
+		prs:      prs,
+		pending:  pending,
+		wait:     wait,
+		stats:    &stats,
+	}, nil
+}
+
+// run approves the job's PRs, then those of its pending PRs that pass in
+// time.
+func (j *approveJob) run(w io.Writer, summary *risk.Summary, changes *export.Log, limit *actionLimit, workers int, out *repoResults) error {
+	approveAll(w, j.provider, j.owner, j.repo, j.prs, j.review, summary, changes, limit, workers, out)
+	if len(j.pending) > 0 {
+		passed := waitForPending(j.provider, j.owner, j.repo, j.pending, j.wait, j.stats)
+		approveAll(w, j.provider, j.owner, j.repo, passed, j.review, summary, changes, limit, workers, out)
+	}
+	return resultsError(out.PRs)
+}
+
+// approveAll approves prs, up to workers at a time and within the limit,
+// appending their results to out in PR-list order.
+func approveAll(w io.Writer, provider bouncer.Client, owner, repo string, prs []bouncer.PRInfo, review reviewPolicy, summary *risk.Summary, changes *export.Log, limit *actionLimit, workers int, out *repoResults) {
+	if len(prs) == 0 {
+		return
+	}
+	prs = limit.take(prs)
+	if len(prs) == 0 {
+		if len(out.PRs) == 0 {
+			out.Skipped = "limit reached"
 		}
+		return
 	}
 
-	return nil
+	fmt.Fprintf(w, "Processing %d pull requests...\n", len(prs))
+
+	results := make([]prResult, len(prs))
+	runOrdered(len(prs), workers, func(i int) {
+		results[i] = newPRResult(prs[i], "Approved")
+		approvePR(provider, owner, repo, prs[i], review, &results[i])
+	}, func(i int) {
+		recordApproval(owner, repo, prs[i], results[i], summary, changes)
+		logResult(results[i])
+		out.PRs = append(out.PRs, results[i])
+	})
 }
 
-// prResult tracks the outcome of an interactive review for a single PR.
+// prResult tracks the outcome of the actions taken on a single PR.
 type prResult struct {
-	Number  int
-	Title   string
-	Action  string // "Approved", "Skipped", "Recreated"
-	Details []string
-	Errors  []string
+	Number        int
+	Title         string
+	URL           string
+	Package       string
+	Org           string
+	Action        string // "Approved", "Skipped", "Recreated", "Denied", "Rebased", "Closed", "Ignored", "Undone"
+	Details       []string
+	Errors        []string
+	Failing       []string // checks failing on the PR when it was acted on
+	Approved      bool     // the PR ended up approved, by this run or before it
+	NewlyApproved bool     // this run approved or reviewed the PR
+	Rule          string   // allow entry or exemption that let the PR through, if any
+}
+
+// newPRResult starts the result of taking action on pr.
+func newPRResult(pr bouncer.PRInfo, action string) prResult {
+	return prResult{Number: pr.Number, Title: pr.Title, URL: pr.URL, Package: pr.PackageName, Org: pr.OrgName, Action: action, Failing: pr.CIFailures, Rule: pr.AllowRule}
+}
+
+// runInteractiveCommand runs approve -i or recreate -i and prints the risk
+// summary of the approvals made.
+func runInteractiveCommand(cmd *cobra.Command, repos []string, targets map[string][]int) error {
+	if format, _ := cmd.Flags().GetString("output"); format != outputText {
+		return fmt.Errorf("--output %s cannot be combined with --interactive", format)
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories specified. Use command-line arguments, --org, or configure repositories in config file")
+	}
+	summary := risk.NewSummary(viper.GetFloat64("risk.min_scorecard"))
+	changes := newChangeLog(cmd)
+	if err := runInteractive(cmd.Name(), repos, targets, summary, changes); err != nil {
+		return err
+	}
+	printRiskSummary(summary)
+	return writeChangeLog(cmd, changes)
 }
 
-func runApproveInteractiveMulti(repos []string) error {
-	allResults := make(map[string][]prResult)
-	var repoOrder []string
+// runInteractive walks the PRs of each repository one at a time, prompting
+// to approve, recreate, skip, or permanently deny each one. Used by approve -i
+// and recreate -i. Repositories in targets are limited to the PRs listed.
+func runInteractive(command string, repos []string, targets map[string][]int, summary *risk.Summary, changes *export.Log) error {
+	results := newRunResults()
 
 	for _, repoPath := range repos {
 		owner, repo, err := parseRepo(repoPath)
@@ -159,22 +816,46 @@ func runApproveInteractiveMulti(repos []string) error {
 			return err
 		}
 		repoKey := fmt.Sprintf("%s/%s", owner, repo)
+		provider, err := providerFor(owner, repo)
+		if err != nil {
+			return err
+		}
+		review, err := buildReviewPolicy(owner, repo)
+		if err != nil {
+			return err
+		}
+
+		if failing, err := baseBranchFailing(provider, owner, repo); err != nil {
+			return err
+		} else if failing {
+			fmt.Printf("Warning: the default branch of %s is failing CI\n", repoKey)
+		}
 
 		fmt.Printf("Fetching Dependabot PRs for %s...\n", repoKey)
-		prs, err := listFilteredPRs(owner, repo, false)
+		var stats bouncer.ListStats
+		prs, err := listFilteredPRs(provider, owner, repo, targets[repoKey], bouncer.CIAny, &stats, nil)
 		if err != nil {
 			return err
 		}
 		if len(prs) == 0 {
-			fmt.Printf("No dependency updates for %s\n\n", repoKey)
+			fmt.Printf("No dependency updates for %s\n", repoKey)
+			printListStats(os.Stdout, stats)
+			fmt.Println()
 			continue
 		}
 
 		fmt.Printf("Found %d pull requests for %s\n\n", len(prs), repoKey)
-		repoOrder = append(repoOrder, repoKey)
+		results.repo(repoKey)
 		quit := false
+		denied := make(map[string]bool)
 
 		for i, pr := range prs {
+			if denied[pr.PackageName] {
+				fmt.Printf("Skipping PR #%d: %s was just denied\n", pr.Number, pr.PackageName)
+				results.add(repoKey, newPRResult(pr, "Skipped"))
+				continue
+			}
+
 			title := fmt.Sprintf("PR #%d: %s (%d/%d)", pr.Number, pr.Title, i+1, len(prs))
 			desc := fmt.Sprintf("URL:    %s\nCI:     %s", hyperlink(pr.URL, pr.URL), pr.CIStatus)
 			if len(pr.CIFailures) > 0 {
@@ -190,6 +871,7 @@ func runApproveInteractiveMulti(repos []string) error {
 					huh.NewOption("Approve", "approve"),
 					huh.NewOption("Skip", "skip"),
 					huh.NewOption("Recreate", "recreate"),
+					huh.NewOption(fmt.Sprintf("Deny %s permanently", pr.PackageName), "deny"),
 					huh.NewOption("Quit", "quit"),
 				).
 				Value(&action).
@@ -200,19 +882,32 @@ func runApproveInteractiveMulti(repos []string) error {
 
 			switch action {
 			case "approve":
-				r := prResult{Number: pr.Number, Title: pr.Title, Action: "Approved"}
-				approvePR(owner, repo, pr, &r)
-				allResults[repoKey] = append(allResults[repoKey], r)
+				r := newPRResult(pr, "Approved")
+				approvePR(provider, owner, repo, pr, review, &r)
+				recordApproval(owner, repo, pr, r, summary, changes)
+				results.add(repoKey, r)
 
 			case "skip":
-				allResults[repoKey] = append(allResults[repoKey], prResult{Number: pr.Number, Title: pr.Title, Action: "Skipped"})
+				results.add(repoKey, newPRResult(pr, "Skipped"))
 
 			case "recreate":
-				r := prResult{Number: pr.Number, Title: pr.Title, Action: "Recreated"}
-				if err := scm.RecreatePR(owner, repo, pr.Number); err != nil {
+				r := newPRResult(pr, "Recreated")
+				if err := provider.Recreate(runCtx, owner, repo, pr.Number); err != nil {
 					r.Errors = append(r.Errors, fmt.Sprintf("failed to recreate: %v", err))
 				}
-				allResults[repoKey] = append(allResults[repoKey], r)
+				recordRecreate(owner, repo, pr, r)
+				results.add(repoKey, r)
+
+			case "deny":
+				r := newPRResult(pr, "Denied")
+				if err := denyPackagePermanently(repoKey, pr.PackageName); err != nil {
+					r.Errors = append(r.Errors, fmt.Sprintf("failed to deny: %v", err))
+				} else {
+					denied[pr.PackageName] = true
+					eventBus.Publish(events.NewEvent(events.Denied, repoKey, pr, time.Now()))
+					r.Details = append(r.Details, fmt.Sprintf("added to repositories.%s.denied_packages in %s", repoKey, viper.ConfigFileUsed()))
+				}
+				results.add(repoKey, r)
 
 			case "quit":
 				quit = true
@@ -228,49 +923,270 @@ func runApproveInteractiveMulti(repos []string) error {
 		}
 	}
 
-	printInteractiveSummary(repoOrder, allResults)
+	auditRun(command, results)
+	printResults(results)
 	return nil
 }
 
-// approvePR handles the approval logic for a single PR, recording details and errors into the result.
-func approvePR(owner, repo string, pr scm.PRInfo, r *prResult) {
-	switch pr.MergeStateStatus {
-	case "DIRTY":
-		if err := scm.RecreatePR(owner, repo, pr.Number); err != nil {
-			r.Errors = append(r.Errors, fmt.Sprintf("failed to recreate (conflicts): %v", err))
-			return
-		}
-		r.Details = append(r.Details, "recreated (conflicts)")
-	case "BEHIND":
-		if err := scm.RebasePR(owner, repo, pr.Number); err != nil {
-			r.Errors = append(r.Errors, fmt.Sprintf("failed to rebase: %v", err))
-		} else {
-			r.Details = append(r.Details, "rebased")
-		}
-	}
+// skipCodeLabels describes each skip code in the zero-candidate breakdown.
+var skipCodeLabels = map[string]string{
+	bouncer.SkipDeniedPackage: "denied package",
+	bouncer.SkipDeniedOrg:     "denied organization",
+	bouncer.SkipNotAllowed:    "not in allow list",
+	bouncer.SkipUpdateType:    "denied update type",
+	bouncer.SkipDraft:         "draft",
+	bouncer.SkipUnparsed:      "package not in title",
+	bouncer.SkipSnoozed:       "snoozed",
+	bouncer.SkipCodeOwners:    "owned by another team",
+	bouncer.SkipTooNew:        "too new",
+	bouncer.SkipVulnerable:    "known vulnerabilities",
+	bouncer.SkipIgnored:       "listed in ignored_prs",
+}
+
+// printListStats explains why no PRs were left to approve, breaking the open
+// PRs down by the reason each was filtered out.
+func printListStats(w io.Writer, stats bouncer.ListStats) {
+	if stats.Open == 0 {
+		fmt.Fprintln(w, "  The repository has no open pull requests")
+		return
+	}
+	fmt.Fprintf(w, "  %d open pull requests, none eligible:\n", stats.Open)
+	line := func(n int, reason string) {
+		if n > 0 {
+			fmt.Fprintf(w, "    %4d %s\n", n, reason)
+		}
+	}
+	line(stats.OutOfWindow, "outside the --created-after/--created-before/--updated-since window")
+	line(stats.NotByBot, "not opened by a configured bot (see bots)")
+	line(stats.WrongBase, "not targeting main")
+	codes := make([]string, 0, len(stats.Skipped))
+	for code := range stats.Skipped {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		label := skipCodeLabels[code]
+		if label == "" {
+			label = code
+		}
+		line(stats.Skipped[code], fmt.Sprintf("%s (%s)", label, code))
+	}
+	line(stats.Passing, "passing checks (--only-failing)")
+	line(stats.Failing, "failing checks")
+	line(stats.Pending, "checks pending or missing")
+}
+
+// logResult logs the outcome of the actions taken on a PR as they happen,
+// ahead of the results table printed at the end of the run.
+func logResult(r prResult) {
+	for _, e := range r.Errors {
+		log.Printf("Warning: PR #%d: %s\n", r.Number, e)
+	}
+	if len(r.Details) > 0 {
+		log.Printf("PR #%d %s: %s\n", r.Number, strings.Join(r.Details, ", "), r.Title)
+	}
+}
+
+// resultsError aggregates the errors recorded for each PR, or returns nil
+// when every PR succeeded.
+func resultsError(results []prResult) error {
+	var errs []error
+	for _, r := range results {
+		for _, e := range r.Errors {
+			errs = append(errs, fmt.Errorf("PR #%d: %s", r.Number, e))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d %w: %w", failedCount(results), len(results), errActionsFailed, errors.Join(errs...))
+}
+
+// failedCount returns the number of PRs with at least one error.
+func failedCount(results []prResult) int {
+	n := 0
+	for _, r := range results {
+		if len(r.Errors) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// repoResults is the outcome of approve or recreate for one repository.
+type repoResults struct {
+	numbers []int // the PRs named on the command line; nil for all
+	PRs     []prResult
+	Skipped string             // why the whole repository was skipped
+	Stats   *bouncer.ListStats // what the PR listing filtered out
+	// PolicySkipped are the PRs the allow and deny lists, ignored_prs, or
+	// the draft rule left alone, with their skip code and reason.
+	PolicySkipped []bouncer.PRInfo
+	Err           error
+}
+
+// runResults collects results by repository, in processing order.
+type runResults struct {
+	order   []string
+	byRepo  map[string]*repoResults
+	risk    *risk.Summary    // of the PRs approved; nil for runs that approve none
+	targets map[string][]int // the PRs named on the command line, by repository
+}
+
+func newRunResults() *runResults {
+	return &runResults{byRepo: make(map[string]*repoResults)}
+}
+
+// repo returns the results of "owner/repo", adding the repository if needed.
+func (rr *runResults) repo(repoKey string) *repoResults {
+	r, ok := rr.byRepo[repoKey]
+	if !ok {
+		r = &repoResults{numbers: rr.targets[repoKey]}
+		rr.byRepo[repoKey] = r
+		rr.order = append(rr.order, repoKey)
+	}
+	return r
+}
+
+// counts tallies the denied and failing PRs seen across all repositories.
+func (rr *runResults) counts() prCounts {
+	var c prCounts
+	for _, r := range rr.byRepo {
+		c.addStats(r.Stats)
+		c.addResults(r.PRs)
+	}
+	return c
+}
+
+// add records PR results for "owner/repo".
+func (rr *runResults) add(repoKey string, results ...prResult) {
+	r := rr.repo(repoKey)
+	r.PRs = append(r.PRs, results...)
+}
+
+// approvePR handles the approval logic for a single PR, recording details and errors into the result.
+func approvePR(provider bouncer.Client, owner, repo string, pr bouncer.PRInfo, review reviewPolicy, r *prResult) {
+	if pr.Draft && viper.GetBool("mark-ready") {
+		if err := provider.MarkReady(runCtx, owner, repo, pr.Number); err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("failed to mark ready for review: %v", err))
+			return
+		}
+		r.Details = append(r.Details, "marked ready for review")
+	}
+
+	switch pr.MergeStateStatus {
+	case "DIRTY":
+		if err := provider.Recreate(runCtx, owner, repo, pr.Number); err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("failed to recreate (conflicts): %v", err))
+			return
+		}
+		r.Details = append(r.Details, "recreated (conflicts)")
+	case "BEHIND":
+		if err := provider.Rebase(runCtx, owner, repo, pr.Number); err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("failed to rebase: %v", err))
+		} else {
+			r.Details = append(r.Details, "rebased")
+		}
+	}
+
+	switch pr.CIStatus {
+	case "pending":
+		r.Details = append(r.Details, "checks pending")
+	case "failure":
+		r.Details = append(r.Details, "checks failing")
+	}
 
 	if pr.ReviewDecision == "APPROVED" {
 		r.Details = append(r.Details, "already approved")
+	} else if review.Event == bouncer.ReviewComment && len(pr.Reviews) > 0 && review.submitted(pr, auditActor()) {
+		r.Details = append(r.Details, "already commented")
 	} else {
-		if err := scm.ApprovePR(owner, repo, pr.Number); err != nil {
-			r.Errors = append(r.Errors, fmt.Sprintf("failed to approve: %v", err))
+		if err := review.submit(provider, owner, repo, pr.Number); err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("failed to %s: %v", review.Event, err))
 			return
 		}
-		r.Details = append(r.Details, "approved")
+		r.Details = append(r.Details, strings.ToLower(review.past()))
+		r.NewlyApproved = true
 	}
+	r.Approved = true
 
-	if err := scm.AutoMergePR(owner, repo, pr.Number); err != nil {
-		r.Errors = append(r.Errors, fmt.Sprintf("failed to enable auto-merge: %v", err))
-	} else {
-		r.Details = append(r.Details, "auto-merge enabled")
+	review.Merge.apply(provider, owner, repo, pr, r)
+}
+
+// recordApproval adds a PR approved by this run to the run's risk summary
+// and change log and publishes its event. PRs approved before the run are
+// left out.
+func recordApproval(owner, repo string, pr bouncer.PRInfo, r prResult, summary *risk.Summary, changes *export.Log) {
+	if !r.NewlyApproved {
+		return
 	}
+	summary.Record(owner+"/"+repo, pr)
+	changes.Record(owner+"/"+repo, pr, time.Now())
+	eventBus.Publish(events.NewEvent(events.Approved, owner+"/"+repo, pr, time.Now()))
 }
 
-func printInteractiveSummary(repoOrder []string, allResults map[string][]prResult) {
+// recordRecreate publishes the event of a successfully recreated PR.
+func recordRecreate(owner, repo string, pr bouncer.PRInfo, r prResult) {
+	if len(r.Errors) == 0 {
+		eventBus.Publish(events.NewEvent(events.Recreated, owner+"/"+repo, pr, time.Now()))
+	}
+}
+
+// recordDenials publishes the events of the PRs among skipped that a deny
+// rule left alone.
+func recordDenials(owner, repo string, skipped []bouncer.PRInfo) {
+	for _, pr := range skipped {
+		if isDenial(pr.SkipCode) {
+			eventBus.Publish(events.NewEvent(events.Denied, owner+"/"+repo, pr, time.Now()))
+		}
+	}
+}
+
+// newChangeLog returns a log for the approved changes when --export is set,
+// or nil when they are not exported.
+func newChangeLog(cmd *cobra.Command) *export.Log {
+	if path, _ := cmd.Flags().GetString("export"); path == "" {
+		return nil
+	}
+	return export.NewLog()
+}
+
+// writeChangeLog writes the approved changes to the --export file.
+func writeChangeLog(cmd *cobra.Command, changes *export.Log) error {
+	if changes == nil {
+		return nil
+	}
+	path, _ := cmd.Flags().GetString("export")
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer f.Close()
+	if err := changes.Write(f, time.Now()); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	log.Printf("Exported %d approved changes to %s\n", changes.Len(), path)
+	return nil
+}
+
+// printRiskSummary prints the risk summary of the approvals made in this run.
+func printRiskSummary(summary *risk.Summary) {
+	if summary.Approved == 0 {
+		return
+	}
+	fmt.Println()
+	for _, line := range summary.Lines() {
+		fmt.Println(line)
+	}
+}
+
+// printResults prints the outcome of every PR acted on, grouped by
+// repository, followed by totals per action.
+func printResults(rr *runResults) {
 	// Flatten all results to check if anything was done.
 	var totalCount int
-	for _, results := range allResults {
-		totalCount += len(results)
+	for _, r := range rr.byRepo {
+		totalCount += len(r.PRs)
 	}
 	if totalCount == 0 {
 		fmt.Println("\nResults: (no actions taken)")
@@ -281,13 +1197,13 @@ func printInteractiveSummary(repoOrder []string, allResults map[string][]prResul
 	fmt.Println(strings.Repeat("-", 60))
 
 	totals := map[string]int{}
-	for _, repoKey := range repoOrder {
-		results := allResults[repoKey]
+	for _, repoKey := range rr.order {
+		results := rr.byRepo[repoKey].PRs
 		if len(results) == 0 {
 			continue
 		}
 
-		if len(repoOrder) > 1 {
+		if len(rr.order) > 1 {
 			fmt.Printf("\n  %s\n\n", repoKey)
 		}
 
@@ -299,7 +1215,11 @@ func printInteractiveSummary(repoOrder []string, allResults map[string][]prResul
 			for _, e := range r.Errors {
 				fmt.Printf("                ! %s\n", e)
 			}
-			totals[r.Action]++
+			if len(r.Errors) > 0 {
+				totals["Failed"]++
+			} else {
+				totals[r.Action]++
+			}
 		}
 	}
 
@@ -314,32 +1234,264 @@ func printInteractiveSummary(repoOrder []string, allResults map[string][]prResul
 	if n := totals["Recreated"]; n > 0 {
 		parts = append(parts, fmt.Sprintf("%d recreated", n))
 	}
+	if n := totals["Rebased"]; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d rebased", n))
+	}
+	if n := totals["Closed"]; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d closed", n))
+	}
+	if n := totals["Denied"]; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d denied", n))
+	}
+	if n := totals["Ignored"]; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d ignored", n))
+	}
+	if n := totals["Undone"]; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d undone", n))
+	}
+	if n := totals["Failed"]; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", n))
+	}
 	fmt.Println(strings.Join(parts, ", "))
 }
 
-func runRecreate(owner, repo string) error {
-	prs, err := listFilteredPRs(owner, repo, false)
+// runRecreate asks the bot to recreate every PR of a repository, up to
+// workers at a time, recording the outcome for each PR in out in PR-list
+// order. A failure on one PR does not stop the others; the returned error
+// aggregates them.
+func runRecreate(w io.Writer, owner, repo string, limit *actionLimit, workers int, out *repoResults) error {
+	provider, err := providerFor(owner, repo)
+	if err != nil {
+		return err
+	}
+	filter, err := ciFilter("recreate", owner, repo, bouncer.CIAny)
+	if err != nil {
+		return err
+	}
+	var stats bouncer.ListStats
+	prs, err := listFilteredPRs(provider, owner, repo, out.numbers, filter, &stats, &out.PolicySkipped)
 	if err != nil {
 		return err
 	}
+	out.Stats = &stats
+	recordDenials(owner, repo, out.PolicySkipped)
+	if len(prs) == 0 {
+		fmt.Fprintln(w, "No dependency updates to process")
+		return nil
+	}
+	prs = limit.take(prs)
 	if len(prs) == 0 {
-		fmt.Println("No dependency updates to process")
+		out.Skipped = "limit reached"
 		return nil
 	}
 
-	fmt.Printf("Processing %d pull requests...\n", len(prs))
+	fmt.Fprintf(w, "Processing %d pull requests...\n", len(prs))
 
-	for _, pr := range prs {
-		if err := scm.RecreatePR(owner, repo, pr.Number); err != nil {
-			log.Printf("Warning: failed to recreate PR #%d: %v\n", pr.Number, err)
+	flaky := flakyPackages(owner + "/" + repo)
+	results := make([]prResult, len(prs))
+	runOrdered(len(prs), workers, func(i int) {
+		r := newPRResult(prs[i], "Recreated")
+		if err := provider.Recreate(runCtx, owner, repo, prs[i].Number); err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("failed to recreate: %v", err))
 		} else {
-			log.Printf("Recreated PR #%d: %s (package: %s)\n", pr.Number, pr.Title, pr.PackageName)
+			r.Details = append(r.Details, "recreated")
 		}
-	}
+		results[i] = r
+	}, func(i int) {
+		if f, ok := flaky[prs[i].PackageName]; ok && len(prs[i].CIFailures) > 0 {
+			log.Printf("Note: %d earlier PRs of %s failed (%s) and passed later; its checks may be flaky, consider re-running them instead of recreating\n", f.PRs, f.Package, strings.Join(f.Checks, ", "))
+		}
+		recordRecreate(owner, repo, prs[i], results[i])
+		logResult(results[i])
+		out.PRs = append(out.PRs, results[i])
+	})
 
+	return resultsError(out.PRs)
+}
+
+// forEachRepo runs fn for every "owner/repo" in repos under the repository's
+// time limit. A failure or timeout in one repository is logged and does not
+// stop the others; once the run is stopped, the remaining repositories are
+// skipped.
+func forEachRepo(w io.Writer, repos []string, fn func(owner, repo string) error) error {
+	var failed, actionsFailed int
+	var timedOut []string
+	var timings []repoTiming
+	defer func() { logRunStats(timings) }()
+
+	for i, repoPath := range repos {
+		if runCtx.Err() != nil {
+			log.Printf("Run stopped; %d of %d repositories not processed\n", len(repos)-i, len(repos))
+			failed += len(repos) - i
+			break
+		}
+		owner, repo, err := parseRepo(repoPath)
+		if err == nil {
+			if len(repos) > 1 {
+				fmt.Fprintf(w, "\n%s/%s\n", owner, repo)
+			}
+			start := time.Now()
+			span := runTracer.StartScope("repository", tracing.String("repository", owner+"/"+repo))
+			err = withRepoTimeout(owner, repo, func() error { return fn(owner, repo) })
+			span.End(err)
+			timings = append(timings, repoTiming{Repo: repoPath, Duration: time.Since(start)})
+			if errors.Is(err, errRepoTimeout) {
+				timedOut = append(timedOut, repoPath)
+			}
+		}
+		if err != nil {
+			if len(repos) == 1 {
+				return err
+			}
+			log.Printf("Warning: %s: %v\n", repoPath, err)
+			failed++
+			if errors.Is(err, errActionsFailed) {
+				actionsFailed++
+			}
+		}
+	}
+	if len(timedOut) > 0 {
+		log.Printf("Timed out: %s\n", strings.Join(timedOut, ", "))
+	}
+	if failed > 0 && failed == actionsFailed {
+		return fmt.Errorf("%w in %d of %d repositories", errActionsFailed, failed, len(repos))
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to process %d of %d repositories", failed, len(repos))
+	}
 	return nil
 }
 
+// errRepoTimeout marks a repository that ran past its time limit.
+var errRepoTimeout = errors.New("timed out")
+
+// repoTimeout returns the time limit for processing a repository: the
+// repo-specific or policy group timeout setting, else --timeout or
+// global.timeout. Zero means no limit.
+func repoTimeout(owner, repo string) time.Duration {
+	d := viper.GetDuration("global.timeout")
+	for _, prefix := range settingPrefixes(owner + "/" + repo)[1:] {
+		if key := prefix + "timeout"; viper.IsSet(key) {
+			d = viper.GetDuration(key)
+		}
+	}
+	return d
+}
+
+// withRepoTimeout runs fn with the repository's time limit applied to every
+// API call it makes. API calls still running at the limit are killed, and
+// the returned error wraps errRepoTimeout. Once the run is stopped, fn is
+// not run and errRunStopped is returned.
+func withRepoTimeout(owner, repo string, fn func() error) error {
+	if runCtx.Err() != nil {
+		return errRunStopped
+	}
+	limit := repoTimeout(owner, repo)
+	if limit <= 0 {
+		return fn()
+	}
+
+	ctx, cancel := context.WithTimeout(runCtx, limit)
+	defer cancel()
+	defer bouncer.SetRepoContext(owner+"/"+repo, ctx)()
+
+	err := fn()
+	if runCtx.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s", errRepoTimeout, limit)
+	}
+	return err
+}
+
+// repoTiming is how long processing one repository took.
+type repoTiming struct {
+	Repo     string
+	Duration time.Duration
+}
+
+// logRunStats logs the duration and API calls of each repository after a
+// multi-repository run, busiest repositories first, so the ones consuming
+// the rate budget stand out.
+func logRunStats(timings []repoTiming) {
+	if len(timings) < 2 {
+		return
+	}
+	sort.SliceStable(timings, func(i, j int) bool {
+		return bouncer.RepoCalls(timings[i].Repo).Total() > bouncer.RepoCalls(timings[j].Repo).Total()
+	})
+	for _, t := range timings {
+		c := bouncer.RepoCalls(t.Repo)
+		log.Printf("stats repo=%s duration=%s calls=%d core=%d graphql=%d search=%d\n",
+			t.Repo, t.Duration.Round(time.Millisecond), c.Total(), c.Core, c.GraphQL, c.Search)
+	}
+}
+
+// resolveRepos returns the repositories a command operates on: explicit
+// arguments plus every repository of the organizations given with --org.
+// When neither is given and fallbackToConfig is set, the repositories and
+// organizations from the config file are used instead.
+func resolveRepos(cmd *cobra.Command, args []string, fallbackToConfig bool) ([]string, error) {
+	orgs, _ := cmd.Flags().GetStringSlice("org")
+	for i, org := range orgs {
+		orgs[i] = parseOrg(org)
+	}
+	var repos []string
+	for _, arg := range args {
+		if isRepoURL(arg) {
+			owner, repo, err := parseRepoURL(arg)
+			if err != nil {
+				return nil, err
+			}
+			arg = owner + "/" + repo
+		}
+		repos = append(repos, arg)
+	}
+	if len(repos) == 0 && len(orgs) == 0 && fallbackToConfig {
+		repos = reposFromConfig()
+		orgs = orgsFromConfig()
+	}
+
+	for _, org := range orgs {
+		orgRepos, err := gitHub().ListOrgRepos(runCtx, org, orgFilter(cmd, org))
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover repositories in %s: %w", org, err)
+		}
+		log.Printf("Discovered %d repositories in %s\n", len(orgRepos), org)
+		repos = append(repos, orgRepos...)
+	}
+
+	return removeDuplicates(repos), nil
+}
+
+// orgFilter builds the repository filter for an organization from its config
+// section, overridden by any --include, --exclude, or --include-archived flags.
+func orgFilter(cmd *cobra.Command, org string) bouncer.RepoFilter {
+	key := "organizations." + org
+	f := bouncer.RepoFilter{
+		Include:         getStringSlice(key + ".include"),
+		Exclude:         getStringSlice(key + ".exclude"),
+		IncludeArchived: viper.GetBool(key + ".include_archived"),
+	}
+	if cmd.Flags().Changed("include") {
+		f.Include, _ = cmd.Flags().GetStringSlice("include")
+	}
+	if cmd.Flags().Changed("exclude") {
+		f.Exclude, _ = cmd.Flags().GetStringSlice("exclude")
+	}
+	if cmd.Flags().Changed("include-archived") {
+		f.IncludeArchived, _ = cmd.Flags().GetBool("include-archived")
+	}
+	return f
+}
+
+// orgsFromConfig returns the organizations listed in the config file.
+func orgsFromConfig() []string {
+	var orgs []string
+	for org := range viper.GetStringMap("organizations") {
+		orgs = append(orgs, org)
+	}
+	return orgs
+}
+
 // reposFromConfig returns the list of repositories from the config file.
 func reposFromConfig() []string {
 	var repos []string
@@ -353,99 +1505,657 @@ func reposFromConfig() []string {
 	return repos
 }
 
-func runCheck(cmd *cobra.Command, args []string) error {
-	var repos []string
+// setupSCM applies the settings that affect every SCM call: the run's
+// cancellation and time limit, the bot identities whose PRs are processed, the PR selection window and CI filter,
+// event publishing, write pacing, retries, the ETag cache, read-only mode,
+// the canary rollout, and the token pool, and checks the tokens' scopes.
+func setupSCM(cmd *cobra.Command, args []string) error {
+	if err := setupRunContext(cmd); err != nil {
+		return err
+	}
+	bouncer.SetBots(getStringSlice("bots"))
+	window, err := parseWindowFlags(cmd, time.Now())
+	if err != nil {
+		return err
+	}
+	prWindow = window
+	if ciFlag, err = parseCIFlags(cmd); err != nil {
+		return err
+	}
+	if err := setupEvents(); err != nil {
+		return err
+	}
+	if err := setupTracing(cmd); err != nil {
+		return err
+	}
+	if viper.IsSet("global.write_interval") {
+		bouncer.SetWriteInterval(viper.GetDuration("global.write_interval"))
+	}
+	if viper.IsSet("global.max_concurrent_calls") {
+		bouncer.SetMaxConcurrentCalls(viper.GetInt("global.max_concurrent_calls"))
+	}
+	clientOptions = bouncer.Options{Retry: bouncer.DefaultRetryPolicy}
+	if viper.IsSet("global.retry.attempts") {
+		clientOptions.Retry.Attempts = viper.GetInt("global.retry.attempts")
+	}
+	if viper.IsSet("global.retry.backoff") {
+		clientOptions.Retry.Backoff = viper.GetDuration("global.retry.backoff")
+	}
+	if viper.IsSet("global.retry.jitter") {
+		clientOptions.Retry.Jitter = viper.GetFloat64("global.retry.jitter")
+	}
+	if viper.IsSet("global.max_rate_limit_wait") {
+		clientOptions.Retry.MaxRateLimitWait = viper.GetDuration("global.max_rate_limit_wait")
+	}
+	if viper.IsSet("global.dependabot_reply_wait") {
+		// Zero in config stops waiting, which Options spells as negative.
+		clientOptions.ReplyWait = viper.GetDuration("global.dependabot_reply_wait")
+		if clientOptions.ReplyWait == 0 {
+			clientOptions.ReplyWait = -1
+		}
+	}
+	if host := viper.GetString("github.host"); host != "" {
+		log.Printf("Using GitHub Enterprise Server at %s\n", host)
+	}
+	if viper.GetBool("github.etag_cache") {
+		if err := setupETagCache(); err != nil {
+			return err
+		}
+	}
+	if viper.GetBool("read_only") {
+		bouncer.SetReadOnly(true)
+		log.Println("Read-only mode: changes to repositories and pull requests are refused")
+	}
+	if err := setupCanary(); err != nil {
+		return err
+	}
+	if err := checkPolicyGroups(); err != nil {
+		return err
+	}
+	if err := setupTokenPool(); err != nil {
+		return err
+	}
+	return checkTokenScopes(cmd)
+}
 
-	if len(args) > 0 {
-		repos = args
-	} else {
-		repos = reposFromConfig()
+// setupETagCache makes GitHub PR listings revalidate the previous run's with
+// conditional requests, keeping the cache in etags.json next to the state
+// file.
+func setupETagCache() error {
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+	cache, err := bouncer.OpenETagCache(filepath.Join(filepath.Dir(path), "etags.json"))
+	if err != nil {
+		return err
+	}
+	clientOptions.ETagCache = cache
+	return nil
+}
+
+// eventBus publishes the actions taken in the run; nil when no message queue
+// is configured.
+var eventBus *events.Publisher
+
+// setupEvents connects the event publisher to the message queues configured
+// under events.
+func setupEvents() error {
+	var sinks []events.Sink
+	if u := viper.GetString("events.nats.url"); u != "" {
+		subject := viper.GetString("events.nats.subject")
+		if subject == "" {
+			subject = "dependabot-bouncer.events"
+		}
+		sink, err := events.NewNATS(u, subject)
+		if err != nil {
+			return fmt.Errorf("invalid events.nats: %w", err)
+		}
+		sinks = append(sinks, sink)
+	}
+	if u := viper.GetString("events.kafka.rest_proxy"); u != "" {
+		topic := viper.GetString("events.kafka.topic")
+		if topic == "" {
+			topic = "dependabot-bouncer-events"
+		}
+		sink, err := events.NewKafka(u, topic)
+		if err != nil {
+			return fmt.Errorf("invalid events.kafka: %w", err)
+		}
+		sinks = append(sinks, sink)
+	}
+	if len(sinks) > 0 {
+		eventBus = events.NewPublisher(sinks...)
+	}
+	return nil
+}
+
+// setupTokenPool spreads gh calls across the tokens named in auth.tokens.
+// Each entry names an environment variable holding a token; repositories can
+// pin one of them with their own "token" key. Without auth.tokens, gh's own
+// authentication is used.
+func setupTokenPool() error {
+	names := getStringSlice("auth.tokens")
+	if len(names) == 0 {
+		return nil
+	}
+
+	var tokens []bouncer.Token
+	for _, name := range names {
+		tokens = append(tokens, bouncer.Token{Name: name, Value: os.Getenv(name)})
+	}
+	pinned := make(map[string]string)
+	for repo := range viper.GetStringMap("repositories") {
+		if name := viper.GetString("repositories." + repo + ".token"); name != "" {
+			pinned[repo] = name
+		}
+	}
+
+	pool, err := bouncer.NewTokenPool(viper.GetString("github.host"), tokens, pinned)
+	if err != nil {
+		return fmt.Errorf("invalid auth.tokens: %w", err)
+	}
+	bouncer.SetTokenPool(pool)
+	log.Printf("Rotating gh calls across %d tokens\n", len(tokens))
+	return nil
+}
+
+// Values of auth.scope_check.
+const (
+	scopeCheckWarn   = "warn"
+	scopeCheckRefuse = "refuse"
+)
+
+// localCommands work on local files only and never need a token.
+var localCommands = map[string]bool{"history": true, "snooze": true}
+
+// checkTokenScopes looks up the scopes of the tokens gh calls use, those of
+// auth.tokens or else gh's own, when auth.scope_check is set. A classic
+// token with admin or delete scopes is logged with "warn" and fails the run
+// with "refuse". Fine-grained and GitHub App tokens report no scopes and
+// pass.
+func checkTokenScopes(cmd *cobra.Command) error {
+	mode := viper.GetString("auth.scope_check")
+	switch mode {
+	case "", "off":
+		return nil
+	case scopeCheckWarn, scopeCheckRefuse:
+	default:
+		return fmt.Errorf("invalid auth.scope_check: %q (expected off, warn, or refuse)", mode)
+	}
+	if localCommands[cmd.Name()] {
+		return nil
+	}
+
+	type token struct{ name, value string }
+	tokens := []token{{name: "gh's own token"}}
+	if names := getStringSlice("auth.tokens"); len(names) > 0 {
+		tokens = tokens[:0]
+		for _, name := range names {
+			tokens = append(tokens, token{name: "token " + name, value: os.Getenv(name)})
+		}
+	}
+	var problems []string
+	for _, t := range tokens {
+		scopes, ok, err := gitHub().TokenScopes(runCtx, t.value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", t.name, err))
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if broad := bouncer.BroadScopes(scopes); len(broad) > 0 {
+			problems = append(problems, fmt.Sprintf("%s has scopes the bouncer does not need: %s", t.name, strings.Join(broad, ", ")))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	if mode == scopeCheckRefuse {
+		return fmt.Errorf("refusing to run (auth.scope_check is refuse): %s", strings.Join(problems, "; "))
+	}
+	for _, p := range problems {
+		log.Printf("Warning: %s\n", p)
+	}
+	return nil
+}
+
+// finishRun releases what setupSCM set up and reports token usage.
+func finishRun(cmd *cobra.Command, args []string) {
+	eventBus.Close()
+	logTokenUsage(cmd, args)
+}
+
+// logTokenUsage logs how many calls each pooled token served.
+func logTokenUsage(cmd *cobra.Command, args []string) {
+	pool := bouncer.ActiveTokenPool()
+	if pool == nil {
+		return
+	}
+	for _, u := range pool.Usage() {
+		log.Printf("Token %s: %d calls, ~%d requests left until %s\n", u.Name, u.Calls, u.Remaining, u.Reset.Format("15:04"))
+	}
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	args, targets, err := takePRTargets(cmd, args)
+	if err != nil {
+		return err
+	}
+	repos, err := resolveRepos(cmd, args, true)
+	if err != nil {
+		return err
 	}
 
 	if len(repos) == 0 {
 		return fmt.Errorf("no repositories specified. Use command-line arguments or configure repositories in config file")
 	}
+	format, err := checkFormat(cmd)
+	if err != nil {
+		return err
+	}
+	doc, _ := outputWriters(format)
+
+	results := make([]checkResult, len(repos))
+	durations := make([]time.Duration, len(repos))
+	setupErrs := make([]error, len(repos))
+	var timings []repoTiming
+	runOrdered(len(repos), repoConcurrency(cmd), func(i int) {
+		start := time.Now()
+		results[i], setupErrs[i] = checkRepo(repos[i], targets)
+		durations[i] = time.Since(start)
+	}, func(i int) {
+		if results[i].Invalid == nil {
+			timings = append(timings, repoTiming{Repo: repos[i], Duration: durations[i]})
+		}
+	})
+	for _, err := range setupErrs {
+		if err != nil {
+			return err
+		}
+	}
+	logRunStats(timings)
+
+	switch format {
+	case outputText:
+		printCheckText(results)
+		printRunSummary(checkSummary(results))
+	case formatTable:
+		if err = writeCheckTable(doc, results); err == nil {
+			writeRunSummary(doc, checkSummary(results))
+		}
+	case formatCSV:
+		err = writeCheckCSV(doc, results)
+	case formatMarkdown:
+		err = writeCheckMarkdown(doc, results)
+	default:
+		err = writeDocument(doc, format, checkDocument(results))
+	}
+	if err != nil {
+		return err
+	}
+	return exitStatus(cmd, checkError(results), checkCounts(results))
+}
+
+// printCheckText prints check results in the text format: the owner rollup,
+// then each repository's PRs, security updates first.
+func printCheckText(results []checkResult) {
+	printOwnerRollup(results)
 
 	fmt.Println("Open Dependabot PRs:")
 	fmt.Println("-------------------------")
 
-	for _, repoPath := range repos {
-		owner, repo, pErr := parseRepo(repoPath)
-		if pErr != nil {
-			fmt.Printf("  Invalid: %v\n\n", pErr)
+	for _, r := range results {
+		if r.Invalid != nil {
+			fmt.Printf("  Invalid: %v\n\n", r.Invalid)
 			continue
 		}
 
-		fmt.Printf("%s/%s\n", owner, repo)
-
-		repoKey := fmt.Sprintf("%s/%s", owner, repo)
-		deniedPackages, deniedOrgs := buildDenyLists(repoKey)
+		fmt.Printf("%s/%s\n", r.Owner, r.Repo)
 
-		q := scm.DependencyUpdateQuery{
-			Owner:          owner,
-			Repo:           repo,
-			DeniedPackages: deniedPackages,
-			DeniedOrgs:     deniedOrgs,
+		if r.Err != nil {
+			fmt.Printf("   Error: %v\n\n", r.Err)
+			continue
 		}
 
-		prs, err := scm.ListDependabotPRs(q, false)
-		if err != nil {
-			fmt.Printf("   Error: %v\n\n", err)
-			continue
+		for _, c := range r.StaleIgnored {
+			fmt.Printf("   Stale ignore: #%d is %s; remove it from repositories.%s/%s.ignored_prs\n", c.Number, c.State, r.Owner, r.Repo)
 		}
 
-		if len(prs) == 0 {
+		if len(r.PRs) == 0 {
 			fmt.Println("   (no open Dependabot PRs)")
 		} else {
-			for _, pr := range prs {
-				fmt.Printf("   #%d: %s\n", pr.Number, pr.Title)
-				fmt.Printf("   %s\n", pr.URL)
-				if pr.Skipped {
-					fmt.Printf("   Status: SKIPPED (%s)\n", pr.SkipReason)
+			var security, version []bouncer.PRInfo
+			for _, pr := range r.PRs {
+				if pr.Security {
+					security = append(security, pr)
 				} else {
-					fmt.Printf("   CI: %s | Merge: %s\n", pr.CIStatus, pr.MergeStateStatus)
+					version = append(version, pr)
+				}
+			}
+			if len(security) > 0 {
+				fmt.Printf("   Security updates (%d):\n\n", len(security))
+				printCheckPRs(security)
+				if len(version) > 0 {
+					fmt.Printf("   Version updates (%d):\n\n", len(version))
 				}
-				fmt.Println()
 			}
+			printCheckPRs(version)
 		}
 		fmt.Println()
 	}
+}
 
-	return nil
+// checkRepo lists the open PRs of repoPath for check, only those listed in
+// targets when the repository is there. An error setting up the
+// repository's query is returned as the second result and ends the run;
+// failures listing its PRs are recorded in the result.
+func checkRepo(repoPath string, targets map[string][]int) (checkResult, error) {
+	owner, repo, err := parseRepo(repoPath)
+	if err != nil {
+		return checkResult{Invalid: err}, nil
+	}
+	provider, err := providerFor(owner, repo)
+	if err != nil {
+		return checkResult{}, err
+	}
+	q, err := buildQuery(owner, repo)
+	if err != nil {
+		return checkResult{}, err
+	}
+	q.Numbers = targets[owner+"/"+repo]
+	q.IgnoredPRs = getIntSlice("repositories." + owner + "/" + repo + ".ignored_prs")
+	if q.Snoozed, err = snoozedPRs(owner + "/" + repo); err != nil {
+		return checkResult{}, err
+	}
+	q.IncludeSkipped = true
+
+	result := checkResult{Owner: owner, Repo: repo}
+	result.Err = withRepoTimeout(owner, repo, func() error {
+		prs, err := provider.List(runCtx, q, false)
+		if err != nil {
+			return err
+		}
+		result.PRs = prs
+		result.StaleIgnored = staleIgnoredPRs(provider, owner, repo, q.IgnoredPRs, prs)
+		return nil
+	})
+	return result, nil
 }
 
-// listFilteredPRs builds a query from config and returns filtered Dependabot PRs.
-func listFilteredPRs(owner, repo string, skipFailing bool) ([]scm.PRInfo, error) {
-	repoKey := fmt.Sprintf("%s/%s", owner, repo)
-	deniedPackages, deniedOrgs := buildDenyLists(repoKey)
-	ignoredPRs := getIntSlice("repositories." + repoKey + ".ignored_prs")
+// checkError returns an error when any repository could not be checked.
+func checkError(results []checkResult) error {
+	failed := 0
+	for _, r := range results {
+		if r.Invalid != nil || r.Err != nil {
+			failed++
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	return fmt.Errorf("failed to check %d of %d repositories", failed, len(results))
+}
 
-	if cmdPackages := viper.GetStringSlice("deny-packages"); len(cmdPackages) > 0 {
-		deniedPackages = removeDuplicates(append(deniedPackages, cmdPackages...))
+// checkCounts tallies the denied and failing PRs across check results.
+func checkCounts(results []checkResult) prCounts {
+	var c prCounts
+	for _, r := range results {
+		c.addPRs(r.PRs)
 	}
-	if cmdOrgs := viper.GetStringSlice("deny-orgs"); len(cmdOrgs) > 0 {
-		deniedOrgs = removeDuplicates(append(deniedOrgs, cmdOrgs...))
+	return c
+}
+
+// printCheckPRs prints the check details of each PR.
+func printCheckPRs(prs []bouncer.PRInfo) {
+	for _, pr := range prs {
+		fmt.Printf("   #%d: %s\n", pr.Number, pr.Title)
+		fmt.Printf("   %s\n", pr.URL)
+		if pr.Skipped {
+			fmt.Printf("   Status: SKIPPED %s (%s)\n", pr.SkipCode, pr.SkipReason)
+		} else {
+			fmt.Printf("   CI: %s | Merge: %s\n", pr.CIStatus, pr.MergeStateStatus)
+		}
+		fmt.Println()
+	}
+}
+
+// checkResult holds the PRs fetched for one repository by the check command.
+type checkResult struct {
+	Owner   string
+	Repo    string
+	PRs     []bouncer.PRInfo
+	Err     error // listing failed
+	Invalid error // the repository argument could not be parsed
+
+	StaleIgnored []bouncer.ClosedPR // ignored_prs entries whose PRs are closed or merged
+}
+
+// staleIgnoredPRs returns the configured ignored PRs that are no longer open.
+// PRs already seen in the open listing are not looked up again.
+func staleIgnoredPRs(provider bouncer.Client, owner, repo string, ignored []int, open []bouncer.PRInfo) []bouncer.ClosedPR {
+	seen := make(map[int]bool, len(open))
+	for _, pr := range open {
+		seen[pr.Number] = true
+	}
+
+	var unseen []int
+	for _, n := range ignored {
+		if !seen[n] {
+			unseen = append(unseen, n)
+		}
+	}
+	if len(unseen) == 0 {
+		return nil
+	}
+
+	closed, err := provider.FindClosed(runCtx, owner, repo, unseen)
+	if err != nil {
+		log.Printf("Warning: failed to check ignored PRs for %s/%s: %v\n", owner, repo, err)
+		return nil
+	}
+	return closed
+}
+
+// checkRepoDocument is the structured check result for one repository.
+type checkRepoDocument struct {
+	Repository   string             `json:"repository"`
+	Error        string             `json:"error,omitempty"`
+	PullRequests []bouncer.PRInfo   `json:"pull_requests"`
+	StaleIgnored []bouncer.ClosedPR `json:"stale_ignored_prs,omitempty"`
+}
+
+// checkDocument converts check results into their structured form, one entry
+// per repository.
+func checkDocument(results []checkResult) []checkRepoDocument {
+	out := make([]checkRepoDocument, 0, len(results))
+	for _, r := range results {
+		entry := checkRepoDocument{Repository: r.Owner + "/" + r.Repo, PullRequests: r.PRs, StaleIgnored: r.StaleIgnored}
+		switch {
+		case r.Invalid != nil:
+			entry.Repository = ""
+			entry.Error = r.Invalid.Error()
+		case r.Err != nil:
+			entry.Error = r.Err.Error()
+		}
+		if entry.PullRequests == nil {
+			entry.PullRequests = []bouncer.PRInfo{}
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// ownerRollup holds per-owner PR counts for the check summary.
+type ownerRollup struct {
+	Owner    string
+	Repos    int
+	Open     int
+	Passing  int
+	Failing  int
+	Pending  int
+	Denied   int
+	Ignored  int
+	Security int
+	Errors   int
+}
+
+// rollupByOwner aggregates check results per owner, in order of first appearance.
+func rollupByOwner(results []checkResult) []ownerRollup {
+	var rollups []ownerRollup
+	index := make(map[string]int)
+
+	for _, r := range results {
+		if r.Invalid != nil {
+			continue
+		}
+		key := strings.ToLower(r.Owner)
+		i, ok := index[key]
+		if !ok {
+			i = len(rollups)
+			index[key] = i
+			rollups = append(rollups, ownerRollup{Owner: r.Owner})
+		}
+		o := &rollups[i]
+		o.Repos++
+		if r.Err != nil {
+			o.Errors++
+			continue
+		}
+		for _, pr := range r.PRs {
+			if pr.Security {
+				o.Security++
+			}
+			if pr.SkipCode == bouncer.SkipIgnored {
+				o.Ignored++
+				continue
+			}
+			if pr.Skipped {
+				o.Denied++
+				continue
+			}
+			o.Open++
+			switch pr.CIStatus {
+			case "success":
+				o.Passing++
+			case "failure":
+				o.Failing++
+			default:
+				o.Pending++
+			}
+		}
 	}
 
-	if len(deniedPackages) > 0 {
-		log.Printf("Denying packages: %v\n", deniedPackages)
+	return rollups
+}
+
+// printOwnerRollup prints a per-owner summary table of check results.
+func printOwnerRollup(results []checkResult) {
+	rollups := rollupByOwner(results)
+	if len(rollups) == 0 {
+		return
 	}
-	if len(deniedOrgs) > 0 {
-		log.Printf("Denying organizations: %v\n", deniedOrgs)
+
+	fmt.Println("Summary by owner:")
+	fmt.Println("-------------------------")
+	fmt.Printf("   %-24s %6s %6s %8s %8s %8s %7s %8s %9s\n", "OWNER", "REPOS", "OPEN", "PASSING", "FAILING", "PENDING", "DENIED", "IGNORED", "SECURITY")
+	for _, o := range rollups {
+		fmt.Printf("   %-24s %6d %6d %8d %8d %8d %7d %8d %9d", o.Owner, o.Repos, o.Open, o.Passing, o.Failing, o.Pending, o.Denied, o.Ignored, o.Security)
+		if o.Errors > 0 {
+			fmt.Printf("  (%d repo errors)", o.Errors)
+		}
+		fmt.Println()
 	}
-	if len(ignoredPRs) > 0 {
-		log.Printf("Ignoring PRs: %v\n", ignoredPRs)
+	fmt.Println()
+}
+
+// listFilteredPRs builds a query from config and returns the Dependabot PRs
+// it selects whose CI status passes ciFilter, among numbers when given. When
+// skipped is non-nil, the PRs skipped by policy are appended to it.
+func listFilteredPRs(provider bouncer.Client, owner, repo string, numbers []int, ciFilter string, stats *bouncer.ListStats, skipped *[]bouncer.PRInfo) ([]bouncer.PRInfo, error) {
+	q, err := buildQuery(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	q.Numbers = numbers
+	q.IgnoredPRs = getIntSlice("repositories." + owner + "/" + repo + ".ignored_prs")
+	if q.Snoozed, err = snoozedPRs(owner + "/" + repo); err != nil {
+		return nil, err
+	}
+	q.IncludeDrafts = viper.GetBool("include-drafts") || viper.GetBool("mark-ready")
+	q.Stats = stats
+	preview := canaryPreviewed(owner+"/"+repo, time.Now())
+	q.IncludeSkipped = skipped != nil || preview
+	q.CIFilter = ciFilter
+	applyPolicyFlags(&q)
+
+	if len(q.DeniedPackages) > 0 {
+		log.Printf("Denying packages: %v\n", q.DeniedPackages)
+	}
+	if len(q.DeniedOrgs) > 0 {
+		log.Printf("Denying organizations: %v\n", q.DeniedOrgs)
+	}
+	if len(q.AllowedPackages) > 0 {
+		log.Printf("Allowing only packages: %v\n", q.AllowedPackages)
+	}
+	if len(q.AllowedOrgs) > 0 {
+		log.Printf("Allowing only organizations: %v\n", q.AllowedOrgs)
+	}
+	if len(q.IgnoredPRs) > 0 {
+		log.Printf("Ignoring PRs: %v\n", q.IgnoredPRs)
+	}
+
+	prs, err := provider.List(runCtx, q, false)
+	if err != nil || !q.IncludeSkipped {
+		return prs, err
+	}
+	if preview {
+		if err := logCanaryDiff(owner, repo, prs); err != nil {
+			return nil, err
+		}
 	}
+	var kept []bouncer.PRInfo
+	for _, pr := range prs {
+		if !pr.Skipped {
+			kept = append(kept, pr)
+		} else if skipped != nil {
+			*skipped = append(*skipped, pr)
+		}
+	}
+	return kept, nil
+}
 
-	q := scm.DependencyUpdateQuery{
-		Owner:          owner,
-		Repo:           repo,
-		IgnoredPRs:     ignoredPRs,
-		DeniedPackages: deniedPackages,
-		DeniedOrgs:     deniedOrgs,
+// skipListed skips a PR that passed listFilteredPRs after all, for a
+// reason only known once more is looked up: it is logged, moved to
+// out.PolicySkipped, and counted in stats under code.
+func skipListed(pr bouncer.PRInfo, code, reason string, stats *bouncer.ListStats, out *repoResults) {
+	log.Printf("Skipping PR #%d (%s): %s\n", pr.Number, pr.PackageName, reason)
+	pr.Skipped, pr.SkipCode, pr.SkipReason = true, code, reason
+	out.PolicySkipped = append(out.PolicySkipped, pr)
+	if stats != nil {
+		if stats.Skipped == nil {
+			stats.Skipped = make(map[string]int)
+		}
+		stats.Skipped[code]++
 	}
+}
 
-	return scm.ListDependabotPRs(q, skipFailing)
+// applyPolicyFlags merges the allow and deny lists given on the command line
+// into q.
+func applyPolicyFlags(q *bouncer.DependencyUpdateQuery) {
+	if cmdPackages := viper.GetStringSlice("deny-packages"); len(cmdPackages) > 0 {
+		q.DeniedPackages = removeDuplicates(append(q.DeniedPackages, cmdPackages...))
+	}
+	if cmdOrgs := viper.GetStringSlice("deny-orgs"); len(cmdOrgs) > 0 {
+		q.DeniedOrgs = removeDuplicates(append(q.DeniedOrgs, cmdOrgs...))
+	}
+	if cmdPackages := viper.GetStringSlice("allow-packages"); len(cmdPackages) > 0 {
+		q.AllowedPackages = removeDuplicates(append(q.AllowedPackages, cmdPackages...))
+	}
+	if cmdOrgs := viper.GetStringSlice("allow-orgs"); len(cmdOrgs) > 0 {
+		q.AllowedOrgs = removeDuplicates(append(q.AllowedOrgs, cmdOrgs...))
+	}
 }
 
 // hyperlink wraps text in an OSC 8 terminal hyperlink escape sequence.
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer/bouncertest"
+	"github.com/spf13/viper"
+)
+
+func TestResultsError(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []prResult
+		want    string
+	}{
+		{name: "no results"},
+		{
+			name:    "all succeeded",
+			results: []prResult{{Number: 1, Action: "Approved"}, {Number: 2, Action: "Recreated"}},
+		},
+		{
+			name: "some failed",
+			results: []prResult{
+				{Number: 1, Action: "Approved"},
+				{Number: 2, Action: "Approved", Errors: []string{"failed to approve PR: forbidden", "failed to enable auto-merge: disabled"}},
+				{Number: 3, Action: "Recreated", Errors: []string{"failed to recreate: not found"}},
+			},
+			want: "2 of 3 pull requests failed: PR #2: failed to approve PR: forbidden\nPR #2: failed to enable auto-merge: disabled\nPR #3: failed to recreate: not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := resultsError(tt.results)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("resultsError() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("resultsError() = %v, want %q", err, tt.want)
+			}
+			if !errors.Is(err, errActionsFailed) {
+				t.Errorf("resultsError() does not wrap errActionsFailed")
+			}
+		})
+	}
+}
+
+func TestFailedCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []prResult
+		want    int
+	}{
+		{name: "none", want: 0},
+		{name: "no errors", results: []prResult{{Number: 1}, {Number: 2}}, want: 0},
+		{name: "errors counted per PR", results: []prResult{{Number: 1, Errors: []string{"a", "b"}}, {Number: 2}, {Number: 3, Errors: []string{"c"}}}, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failedCount(tt.results); got != tt.want {
+				t.Errorf("failedCount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunResults(t *testing.T) {
+	rr := newRunResults()
+	rr.targets = map[string][]int{"acme/web": {7}}
+
+	rr.add("acme/web", prResult{Number: 7, Action: "Approved"})
+	api := rr.repo("acme/api")
+	rr.add("acme/api", prResult{Number: 1, Action: "Approved"}, prResult{Number: 2, Action: "Recreated"})
+	rr.add("acme/web", prResult{Number: 8, Action: "Approved"})
+
+	if want := []string{"acme/web", "acme/api"}; !slices.Equal(rr.order, want) {
+		t.Errorf("order = %v, want %v", rr.order, want)
+	}
+	if rr.repo("acme/api") != api {
+		t.Errorf("repo() returned a new result for a known repository")
+	}
+	if got := len(api.PRs); got != 2 {
+		t.Errorf("acme/api has %d results, want 2", got)
+	}
+	if got := len(rr.byRepo["acme/web"].PRs); got != 2 {
+		t.Errorf("acme/web has %d results, want 2", got)
+	}
+	if got := rr.repo("acme/web").numbers; !slices.Equal(got, []int{7}) {
+		t.Errorf("acme/web numbers = %v, want [7]", got)
+	}
+	if got := api.numbers; got != nil {
+		t.Errorf("acme/api numbers = %v, want nil", got)
+	}
+}
+
+func TestWithRepoTimeout(t *testing.T) {
+	errFn := errors.New("listing failed")
+	sleep := func(d time.Duration, err error) func() error {
+		return func() error {
+			time.Sleep(d)
+			return err
+		}
+	}
+
+	tests := []struct {
+		name     string
+		global   string
+		repo     string
+		fn       func() error
+		wantErr  error
+		wantNone bool
+	}{
+		{name: "no limit", fn: sleep(0, nil), wantNone: true},
+		{name: "no limit with error", fn: sleep(0, errFn), wantErr: errFn},
+		{name: "within the limit", global: "1s", fn: sleep(0, errFn), wantErr: errFn},
+		{name: "past the limit", global: "10ms", fn: sleep(50*time.Millisecond, errFn), wantErr: errRepoTimeout},
+		{name: "repository limit wins", global: "10ms", repo: "1s", fn: sleep(50*time.Millisecond, nil), wantNone: true},
+		{name: "repository limit only", repo: "10ms", fn: sleep(50*time.Millisecond, nil), wantErr: errRepoTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(viper.Reset)
+			if tt.global != "" {
+				viper.Set("global.timeout", tt.global)
+			}
+			if tt.repo != "" {
+				viper.Set("repositories.acme/api.timeout", tt.repo)
+			}
+
+			err := withRepoTimeout("acme", "api", tt.fn)
+			if tt.wantNone {
+				if err != nil {
+					t.Errorf("withRepoTimeout() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("withRepoTimeout() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithRepoTimeoutRunStopped(t *testing.T) {
+	orig := runCtx
+	t.Cleanup(func() { runCtx = orig; viper.Reset() })
+	viper.Set("global.timeout", "1s")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runCtx = ctx
+	errStopped := errors.New("interrupted")
+	err := withRepoTimeout("acme", "api", func() error {
+		cancel()
+		return errStopped
+	})
+	if !errors.Is(err, errStopped) {
+		t.Errorf("withRepoTimeout() interrupted mid-run error = %v, want the call's error", err)
+	}
+
+	called := false
+	err = withRepoTimeout("acme", "api", func() error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, errRunStopped) || called {
+		t.Errorf("withRepoTimeout() after the run stopped error = %v, called %v; want errRunStopped without calling", err, called)
+	}
+}
+
+func TestParsePRRef(t *testing.T) {
+	tests := []struct {
+		arg     string
+		want    string
+		wantErr bool
+	}{
+		{arg: "acme/api#42", want: "acme/api#42"},
+		{arg: "https://github.com/acme/api/pull/42", want: "acme/api#42"},
+		{arg: "https://gitlab.com/acme/api/-/merge_requests/42", want: "acme/api#42"},
+		{arg: "acme/api", wantErr: true},
+		{arg: "acme#42", wantErr: true},
+		{arg: "acme/api/extra#42", wantErr: true},
+		{arg: "acme/api#", wantErr: true},
+		{arg: "acme/api#0", wantErr: true},
+		{arg: "acme/api#-1", wantErr: true},
+		{arg: "acme/api#4x", wantErr: true},
+		{arg: "https://github.com/acme/api", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			owner, repo, number, err := parsePRRef(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePRRef(%q) error = %v, wantErr %v", tt.arg, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := fmt.Sprintf("%s/%s#%d", owner, repo, number); got != tt.want {
+				t.Errorf("parsePRRef(%q) = %s, want %s", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStaleIgnoredPRs(t *testing.T) {
+	fake := bouncertest.NewFake()
+	for n := 1; n <= 4; n++ {
+		fake.AddPR("acme", "api", "", bouncer.PRInfo{Number: n, Title: fmt.Sprintf("Bump pkg%d from 1.0.0 to 1.1.0", n)})
+	}
+	if err := fake.Merge(context.Background(), "acme", "api", 2, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.Close(context.Background(), "acme", "api", 3); err != nil {
+		t.Fatal(err)
+	}
+	open := []bouncer.PRInfo{{Number: 1}, {Number: 4}}
+
+	tests := []struct {
+		name      string
+		ignored   []int
+		want      []bouncer.ClosedPR
+		wantCalls int
+	}{
+		{"closed and merged", []int{1, 2, 3, 4}, []bouncer.ClosedPR{{Number: 2, State: "MERGED"}, {Number: 3, State: "CLOSED"}}, 1},
+		{"all open", []int{1, 4}, nil, 0},
+		{"none ignored", nil, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(fake.Calls())
+			got := staleIgnoredPRs(fake, "acme", "api", tt.ignored, open)
+			slices.SortFunc(got, func(a, b bouncer.ClosedPR) int { return a.Number - b.Number })
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("staleIgnoredPRs() = %+v, want %+v", got, tt.want)
+			}
+			calls := 0
+			for _, c := range fake.Calls()[before:] {
+				if c.Method == "FindClosed" {
+					calls++
+				}
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("FindClosed called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+)
+
+// configSchema is the layout of the config file. Every key the bouncer reads
+// has a field here; config validate reports keys that have none.
+type configSchema struct {
+	Bots          []string                   `mapstructure:"bots"`
+	ReadOnly      bool                       `mapstructure:"read_only"`
+	Auth          authConfig                 `mapstructure:"auth"`
+	Canary        canaryConfig               `mapstructure:"canary"`
+	State         stateConfig                `mapstructure:"state"`
+	Audit         auditConfig                `mapstructure:"audit"`
+	Events        eventsConfig               `mapstructure:"events"`
+	Webhook       webhookConfig              `mapstructure:"webhook"`
+	Notifications notificationsConfig        `mapstructure:"notifications"`
+	Tracing       tracingConfig              `mapstructure:"tracing"`
+	GitHub        githubConfig               `mapstructure:"github"`
+	GitLab        gitlabConfig               `mapstructure:"gitlab"`
+	Gitea         giteaConfig                `mapstructure:"gitea"`
+	Risk          riskConfig                 `mapstructure:"risk"`
+	Check         checkConfig                `mapstructure:"check"`
+	Report        reportConfig               `mapstructure:"report"`
+	Global        globalConfig               `mapstructure:"global"`
+	Ecosystems    map[string]ecosystemPolicy `mapstructure:"ecosystems"`
+	Organizations map[string]orgConfig       `mapstructure:"organizations"`
+	Policies      map[string]policyGroup     `mapstructure:"policies"`
+	Repositories  map[string]repoConfig      `mapstructure:"repositories"`
+	Profiles      map[string]any             `mapstructure:"profiles"` // each validated as a configSchema of its own
+}
+
+// policyConfig holds the allow, deny, and update-type lists of a policy
+// layer: global, canary.policy, or a repository.
+type policyConfig struct {
+	DeniedPackages           packageEntries      `mapstructure:"denied_packages"`
+	DeniedOrgs               []string            `mapstructure:"denied_orgs"`
+	AllowedPackages          []string            `mapstructure:"allowed_packages"`
+	AllowedOrgs              []string            `mapstructure:"allowed_orgs"`
+	TrustedPackages          []string            `mapstructure:"trusted_packages"`
+	TrustedOrgs              []string            `mapstructure:"trusted_orgs"`
+	Precedence               string              `mapstructure:"precedence"`
+	UnparsedTitles           string              `mapstructure:"unparsed_titles"`
+	DenyUpdateTypes          []string            `mapstructure:"deny_update_types"`
+	DenyUpdateTypesByPackage map[string][]string `mapstructure:"deny_update_types_by_package"`
+	InternalPackages         []string            `mapstructure:"internal_packages"`
+	DenyUpdateTypesInternal  []string            `mapstructure:"deny_update_types_internal"`
+	DenyUpdateTypesExternal  []string            `mapstructure:"deny_update_types_external"`
+	SecurityUpdates          securityConfig      `mapstructure:"security_updates"`
+	MinSuccessfulChecks      *int                `mapstructure:"min_successful_checks"`
+	RequiredChecks           []string            `mapstructure:"required_checks"`
+}
+
+// policyLayer is a policyConfig with its own ecosystem deny lists. The global
+// layer's are the top-level ecosystems section.
+type policyLayer struct {
+	policyConfig `mapstructure:",squash"`
+	Ecosystems   map[string]ecosystemPolicy `mapstructure:"ecosystems"`
+}
+
+type ecosystemPolicy struct {
+	DeniedPackages []string `mapstructure:"denied_packages"`
+	DeniedOrgs     []string `mapstructure:"denied_orgs"`
+}
+
+type securityConfig struct {
+	ExemptFromDeny *bool `mapstructure:"exempt_from_deny"`
+}
+
+// repoSettings are the settings of global that a repository can override.
+type repoSettings struct {
+	ReviewEvent        string            `mapstructure:"review_event"`
+	ReviewComment      string            `mapstructure:"review_comment"`
+	MergeMethod        string            `mapstructure:"merge_method"`
+	MergeMode          string            `mapstructure:"merge_mode"`
+	VulnerabilityCheck string            `mapstructure:"vulnerability_check"`
+	MinAge             string            `mapstructure:"min_age"` // an age such as 72h, 3d, or 1w
+	DependencyOwners   []string          `mapstructure:"dependency_owners"`
+	Timeout            time.Duration     `mapstructure:"timeout"`
+	SkipIfBaseFailing  *bool             `mapstructure:"skip_if_base_failing"`
+	Provider           string            `mapstructure:"provider"`
+	Maintenance        maintenanceConfig `mapstructure:"maintenance"`
+	CIFilter           map[string]string `mapstructure:"ci_filter"` // by command: approve, recreate
+}
+
+type maintenanceConfig struct {
+	CloseAfter         string `mapstructure:"close_after"` // a date or an age such as 60d
+	RecreateConflicted *bool  `mapstructure:"recreate_conflicted"`
+	RebaseBehind       *bool  `mapstructure:"rebase_behind"`
+}
+
+type globalConfig struct {
+	policyConfig        `mapstructure:",squash"`
+	repoSettings        `mapstructure:",squash"`
+	RunTimeout          time.Duration `mapstructure:"run_timeout"`
+	Concurrency         int           `mapstructure:"concurrency"`
+	RepoConcurrency     int           `mapstructure:"repo_concurrency"`
+	MaxConcurrentCalls  int           `mapstructure:"max_concurrent_calls"`
+	WriteInterval       time.Duration `mapstructure:"write_interval"`
+	MaxRateLimitWait    time.Duration `mapstructure:"max_rate_limit_wait"`
+	Retry               retryConfig   `mapstructure:"retry"`
+	DependabotReplyWait time.Duration `mapstructure:"dependabot_reply_wait"`
+}
+
+type retryConfig struct {
+	Attempts int           `mapstructure:"attempts"`
+	Backoff  time.Duration `mapstructure:"backoff"`
+	Jitter   float64       `mapstructure:"jitter"`
+}
+
+// policyGroup is a named set of policy and settings, under policies, that
+// repositories apply with their policy setting.
+type policyGroup struct {
+	policyLayer  `mapstructure:",squash"`
+	repoSettings `mapstructure:",squash"`
+}
+
+type repoConfig struct {
+	policyLayer   `mapstructure:",squash"`
+	repoSettings  `mapstructure:",squash"`
+	Policy        []string            `mapstructure:"policy"` // policy groups, lowest precedence first
+	Token         string              `mapstructure:"token"`  // environment variable holding the token to use
+	IgnoredPRs    []int               `mapstructure:"ignored_prs"`
+	Notifications notificationsConfig `mapstructure:"notifications"`
+}
+
+type orgConfig struct {
+	Include         []string `mapstructure:"include"`
+	Exclude         []string `mapstructure:"exclude"`
+	IncludeArchived bool     `mapstructure:"include_archived"`
+}
+
+type canaryConfig struct {
+	Percent      int         `mapstructure:"percent"`
+	Repositories []string    `mapstructure:"repositories"`
+	Until        any         `mapstructure:"until"` // a date or RFC 3339 timestamp; YAML may decode it as a time
+	Policy       policyLayer `mapstructure:"policy"`
+}
+
+type authConfig struct {
+	Tokens     []string `mapstructure:"tokens"`
+	ScopeCheck string   `mapstructure:"scope_check"`
+}
+
+type stateConfig struct {
+	Path          string `mapstructure:"path"`
+	RecordHistory bool   `mapstructure:"record_history"`
+}
+
+type auditConfig struct {
+	Path    string `mapstructure:"path"`
+	History bool   `mapstructure:"history"`
+}
+
+type eventsConfig struct {
+	NATS struct {
+		URL     string `mapstructure:"url"`
+		Subject string `mapstructure:"subject"`
+	} `mapstructure:"nats"`
+	Kafka struct {
+		RestProxy string `mapstructure:"rest_proxy"`
+		Topic     string `mapstructure:"topic"`
+	} `mapstructure:"kafka"`
+}
+
+type webhookConfig struct {
+	Secret string `mapstructure:"secret"`
+}
+
+type notificationsConfig struct {
+	Slack struct {
+		WebhookURL string `mapstructure:"webhook_url"`
+		Channel    string `mapstructure:"channel"`
+	} `mapstructure:"slack"`
+	Teams struct {
+		WebhookURL string `mapstructure:"webhook_url"`
+	} `mapstructure:"teams"`
+	Discord struct {
+		WebhookURL string `mapstructure:"webhook_url"`
+	} `mapstructure:"discord"`
+	Webhook struct {
+		URL     string            `mapstructure:"url"`
+		Headers map[string]string `mapstructure:"headers"`
+	} `mapstructure:"webhook"`
+	Email struct {
+		SMTPHost    string   `mapstructure:"smtp_host"`
+		SMTPPort    int      `mapstructure:"smtp_port"`
+		ImplicitTLS bool     `mapstructure:"implicit_tls"`
+		Username    string   `mapstructure:"username"`
+		Password    string   `mapstructure:"password"`
+		From        string   `mapstructure:"from"`
+		To          []string `mapstructure:"to"`
+	} `mapstructure:"email"`
+}
+
+type tracingConfig struct {
+	Endpoint string            `mapstructure:"endpoint"`
+	Headers  map[string]string `mapstructure:"headers"`
+}
+
+type githubConfig struct {
+	Host      string `mapstructure:"host"`
+	ETagCache bool   `mapstructure:"etag_cache"`
+}
+
+type gitlabConfig struct {
+	Author string `mapstructure:"author"`
+}
+
+type giteaConfig struct {
+	URL    string `mapstructure:"url"`
+	Author string `mapstructure:"author"`
+}
+
+type riskConfig struct {
+	MinScorecard float64 `mapstructure:"min_scorecard"`
+}
+
+type checkConfig struct {
+	Repositories []string `mapstructure:"repositories"`
+}
+
+type reportConfig struct {
+	Template string `mapstructure:"template"`
+}
+
+// packageEntries is a denied_packages list. Entries such as
+// "github.com/gin-gonic/gin: deny=major" are maps when unquoted in YAML;
+// they are read back as "key: value" strings.
+type packageEntries []string
+
+// decodeConfig decodes settings read from the config into out, accepting
+// what viper accepts: a string for a list is split on whitespace, and
+// durations are strings such as "2m". When strict is set, keys out has no
+// field for are errors.
+func decodeConfig(settings any, out any, strict bool) error {
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           out,
+		ErrorUnused:      strict,
+		WeaklyTypedInput: true,
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			packageEntriesHook,
+			stringToFieldsHook,
+			mapstructure.StringToTimeDurationHookFunc(),
+		),
+	})
+	if err != nil {
+		return err
+	}
+	return dec.Decode(settings)
+}
+
+// packageEntriesHook turns the map entries of a denied_packages list into
+// "key: value" strings.
+func packageEntriesHook(from, to reflect.Type, data any) (any, error) {
+	items, ok := data.([]any)
+	if to != reflect.TypeOf(packageEntries(nil)) || !ok {
+		return data, nil
+	}
+	var entries []string
+	for _, item := range items {
+		if m, ok := item.(map[string]any); ok {
+			for pkg, v := range m {
+				entries = append(entries, fmt.Sprintf("%s: %v", pkg, v))
+			}
+			continue
+		}
+		entries = append(entries, fmt.Sprint(item))
+	}
+	return entries, nil
+}
+
+// stringToFieldsHook splits a string given for a list on whitespace, as
+// viper.GetStringSlice does.
+func stringToFieldsHook(from, to reflect.Type, data any) (any, error) {
+	if s, ok := data.(string); ok && from.Kind() == reflect.String && to.Kind() == reflect.Slice {
+		return strings.Fields(s), nil
+	}
+	return data, nil
+}
+
+// configKeys returns the keys of a config struct type, including those of
+// its squashed fields.
+func configKeys(t reflect.Type) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+		if opts == "squash" {
+			keys = append(keys, configKeys(f.Type)...)
+			continue
+		}
+		keys = append(keys, name)
+	}
+	return keys
+}
+
+// policyLayers decodes the policy layers under prefixes, as returned by
+// policyPrefixes, from the config in use. Each key is looked up on its own,
+// as viper.Get keeps the dots in package names used as map keys and sees
+// values set with viper.Set.
+func policyLayers(prefixes []string) ([]policyLayer, error) {
+	keys := configKeys(reflect.TypeOf(policyLayer{}))
+	layers := make([]policyLayer, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		settings := make(map[string]any)
+		for _, key := range keys {
+			if key == "ecosystems" && prefix == "global." {
+				settings[key] = viper.Get("ecosystems")
+			} else if viper.IsSet(prefix + key) {
+				settings[key] = viper.Get(prefix + key)
+			}
+		}
+		var layer policyLayer
+		if err := decodeConfig(settings, &layer, false); err != nil {
+			return nil, fmt.Errorf("invalid %s settings: %w", strings.TrimSuffix(prefix, "."), err)
+		}
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// denyPackagePermanently adds a package to the repository's denied_packages
+// in the config file, keeping the file's comments and layout, and applies it
+// to the running process.
+func denyPackagePermanently(repoKey, pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("the PR's package could not be determined")
+	}
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return fmt.Errorf("no config file in use; create one or pass --config")
+	}
+	if err := appendConfigList(path, []string{"repositories", repoKey, "denied_packages"}, pkg); err != nil {
+		return err
+	}
+
+	key := "repositories." + repoKey + ".denied_packages"
+	viper.Set(key, removeDuplicates(append(getStringSlice(key), pkg)))
+	return nil
+}
+
+// appendConfigList appends value to the YAML list at keys in the file at
+// path, creating missing mappings and the list as needed. The value is not
+// added again if the list already holds it. Keys match case-insensitively,
+// as viper reads them. Only the lines of the change are written, so the
+// rest of the file keeps its comments, blank lines, and formatting.
+func appendConfigList(path string, keys []string, value string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	out, err := editConfigList(data, &doc, keys, value)
+	if err != nil {
+		return fmt.Errorf("failed to update config file: %w", err)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := os.WriteFile(path, out, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// editConfigList returns data, parsed as doc, with value appended to the
+// list at keys, or nil when the list already holds it.
+func editConfigList(data []byte, doc *yaml.Node, keys []string, value string) ([]byte, error) {
+	lines := strings.SplitAfter(string(data), "\n")
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		return insertLines(lines, len(lines), yamlBlock(keys, 0, value)), nil
+	}
+
+	node := doc.Content[0]
+	for i, key := range keys {
+		if node.Kind != yaml.MappingNode || node.Style&yaml.FlowStyle != 0 {
+			return nil, fmt.Errorf("%s: not a block mapping", strings.Join(keys[:i], "."))
+		}
+		k, v := mappingChild(node, key)
+		if k == nil {
+			indent := 0
+			if len(node.Content) > 0 {
+				indent = node.Content[0].Column - 1
+			}
+			return insertLines(lines, endLine(node), yamlBlock(keys[i:], indent, value)), nil
+		}
+
+		// An empty value ("myorg/api: {}" or "denied_packages:") gets the
+		// rest of the keys and the list nested under its key.
+		empty := v.Kind == yaml.ScalarNode && v.Tag == "!!null" && v.Value == ""
+		if v.Style&yaml.FlowStyle != 0 && len(v.Content) == 0 {
+			lines[v.Line-1] = removeFlow(lines[v.Line-1], v.Column-1)
+			empty = true
+		}
+		if empty {
+			return insertLines(lines, k.Line, yamlBlock(keys[i+1:], k.Column+1, value)), nil
+		}
+
+		if i < len(keys)-1 {
+			node = v
+			continue
+		}
+		if v.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("%s: not a list", strings.Join(keys, "."))
+		}
+		for _, item := range v.Content {
+			if item.Value == value {
+				return nil, nil
+			}
+		}
+		if v.Style&yaml.FlowStyle != 0 {
+			return nil, fmt.Errorf("%s: flow-style lists cannot be edited, write it as a block list", strings.Join(keys, "."))
+		}
+		// New items copy the indentation and dash of the first one.
+		first := v.Content[0]
+		prefix := lines[first.Line-1][:first.Column-1]
+		return insertLines(lines, endLine(v), prefix+yamlScalar(value)+"\n"), nil
+	}
+	return nil, fmt.Errorf("no keys given")
+}
+
+// mappingChild returns the key and value nodes of key in a YAML mapping,
+// matched case-insensitively, or nils when the mapping lacks it.
+func mappingChild(m *yaml.Node, key string) (k, v *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if strings.EqualFold(m.Content[i].Value, key) {
+			return m.Content[i], m.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// endLine returns the last line of the file a node or its children are on.
+func endLine(n *yaml.Node) int {
+	line := n.Line
+	for _, c := range n.Content {
+		line = max(line, endLine(c))
+	}
+	return line
+}
+
+// yamlBlock renders the nested mappings of keys, starting at indent, with a
+// list holding value under the last one.
+func yamlBlock(keys []string, indent int, value string) string {
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s%s:\n", strings.Repeat(" ", indent), yamlScalar(key))
+		indent += 2
+	}
+	fmt.Fprintf(&b, "%s- %s\n", strings.Repeat(" ", indent), yamlScalar(value))
+	return b.String()
+}
+
+// yamlScalar returns s as a YAML scalar, quoted when it needs to be.
+func yamlScalar(s string) string {
+	out, err := yaml.Marshal(s)
+	if err != nil {
+		return strconv.Quote(s)
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+// insertLines inserts text after the first n lines.
+func insertLines(lines []string, n int, text string) []byte {
+	n = min(n, len(lines))
+	var b strings.Builder
+	for _, l := range lines[:n] {
+		b.WriteString(l)
+	}
+	if s := b.String(); s != "" && !strings.HasSuffix(s, "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString(text)
+	for _, l := range lines[n:] {
+		b.WriteString(l)
+	}
+	return []byte(b.String())
+}
+
+// removeFlow removes the empty flow collection ("{}" or "[]") starting at
+// col from line, with the spaces before it.
+func removeFlow(line string, col int) string {
+	end := strings.IndexAny(line[col:], "}]")
+	if end < 0 {
+		return line
+	}
+	return strings.TrimRight(line[:col], " \t") + line[col+end+1:]
+}
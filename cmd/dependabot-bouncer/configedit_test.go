@@ -0,0 +1,147 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestAppendConfigList(t *testing.T) {
+	keys := []string{"repositories", "myorg/api", "denied_packages"}
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "empty file",
+			in:   "",
+			want: "repositories:\n  myorg/api:\n    denied_packages:\n      - left-pad\n",
+		},
+		{
+			name: "appends to the list keeping the layout",
+			in: `# Bouncer config
+global:
+  denied_packages:
+    - lodash
+
+repositories:
+  MyOrg/API:
+    denied_packages:
+        - react    # pinned
+
+    ignored_prs: [5]
+`,
+			want: `# Bouncer config
+global:
+  denied_packages:
+    - lodash
+
+repositories:
+  MyOrg/API:
+    denied_packages:
+        - react    # pinned
+        - left-pad
+
+    ignored_prs: [5]
+`,
+		},
+		{
+			name: "adds the repository",
+			in:   "repositories:\n  myorg/web:\n    denied_packages:\n    - react\n\n# trailing note\n",
+			want: "repositories:\n  myorg/web:\n    denied_packages:\n    - react\n  myorg/api:\n    denied_packages:\n      - left-pad\n\n# trailing note\n",
+		},
+		{
+			name: "fills an empty list",
+			in:   "repositories:\n  myorg/api:\n    denied_packages:  # none yet\n    ignored_prs: [5]\n",
+			want: "repositories:\n  myorg/api:\n    denied_packages:  # none yet\n      - left-pad\n    ignored_prs: [5]\n",
+		},
+		{
+			name: "fills an empty mapping",
+			in:   "repositories:\n  myorg/api: {}\nglobal: {}\n",
+			want: "repositories:\n  myorg/api:\n    denied_packages:\n      - left-pad\nglobal: {}\n",
+		},
+		{
+			name: "already listed",
+			in:   "repositories:\n  myorg/api:\n    denied_packages: [left-pad]\n",
+			want: "repositories:\n  myorg/api:\n    denied_packages: [left-pad]\n",
+		},
+		{
+			name: "key without a value",
+			in:   "repositories: # per repo\n",
+			want: "repositories: # per repo\n  myorg/api:\n    denied_packages:\n      - left-pad\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.in), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := appendConfigList(path, keys, "left-pad"); err != nil {
+				t.Fatalf("appendConfigList() error = %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("appendConfigList() wrote\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendConfigListQuotes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := appendConfigList(path, []string{"global", "denied_packages"}, "@aws-sdk/*"); err != nil {
+		t.Fatalf("appendConfigList() error = %v", err)
+	}
+	got, _ := os.ReadFile(path)
+	var cfg struct {
+		Global struct {
+			DeniedPackages []string `yaml:"denied_packages"`
+		}
+	}
+	if err := yaml.Unmarshal(got, &cfg); err != nil || len(cfg.Global.DeniedPackages) != 1 || cfg.Global.DeniedPackages[0] != "@aws-sdk/*" {
+		t.Errorf("appendConfigList() wrote %q (%v)", got, err)
+	}
+}
+
+func TestAppendConfigListRejectsFlowLists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	in := "repositories:\n  myorg/api:\n    denied_packages: [react]\n"
+	if err := os.WriteFile(path, []byte(in), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := appendConfigList(path, []string{"repositories", "myorg/api", "denied_packages"}, "left-pad"); err == nil {
+		t.Error("appendConfigList() error = nil for a flow-style list")
+	}
+	if got, _ := os.ReadFile(path); string(got) != in {
+		t.Errorf("config file changed to %q", got)
+	}
+}
+
+func TestMappingChild(t *testing.T) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte("MyOrg/API:\n  policy: backend\nother: 1\n"), &doc); err != nil {
+		t.Fatal(err)
+	}
+	m := doc.Content[0]
+
+	if k, v := mappingChild(m, "myorg/api"); k == nil || k.Value != "MyOrg/API" || v.Kind != yaml.MappingNode {
+		t.Errorf("mappingChild(myorg/api) = %v, %v", k, v)
+	}
+	if k, v := mappingChild(m, "OTHER"); k == nil || v.Value != "1" {
+		t.Errorf("mappingChild(OTHER) = %v, %v", k, v)
+	}
+	if k, v := mappingChild(m, "missing"); k != nil || v != nil {
+		t.Errorf("mappingChild(missing) = %v, %v, want nils", k, v)
+	}
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the config file",
+	// Config commands read the file only; they need no provider or token.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the config file for mistakes",
+	Long: `Check the config file in use (--config, or the default search) without
+running anything: keys the bouncer does not know, which are most likely
+misspelled and otherwise silently ignored; values of the wrong type, such as
+a duration that does not parse; repository names that are not owner/repo;
+malformed regular expressions, version constraints, and globs in the package,
+organization, and repository lists; and settings outside their accepted
+values, such as an unknown merge_method or update type.
+
+Every problem is printed. Exits with 1 when there are any.`,
+	Example: `  dependabot-bouncer config validate --config config.yaml`,
+	Args:    cobra.NoArgs,
+	RunE:    runConfigValidate,
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	file := viper.ConfigFileUsed()
+	if file == "" {
+		return fmt.Errorf("no config file found; create one or pass --config")
+	}
+	cmd.SilenceUsage = true
+	// The file is read as YAML rather than through viper, which would split
+	// package names used as map keys at their dots.
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	var settings map[string]any
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	problems := validateConfig(settings)
+	for _, p := range problems {
+		fmt.Printf("%s: %s\n", file, p)
+	}
+	if len(problems) > 0 {
+		return &exitError{code: 1, msg: fmt.Sprintf("%d problem(s) in %s", len(problems), file)}
+	}
+	fmt.Printf("%s is valid\n", file)
+	return nil
+}
+
+// validateConfig returns the problems of config settings: unknown keys,
+// values that do not decode into configSchema, and values that would make a
+// run fail or match nothing.
+func validateConfig(settings map[string]any) []string {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	var cfg configSchema
+	if err := decodeConfig(settings, &cfg, true); err != nil {
+		// Decoding goes on past a bad value and joins the errors of all.
+		for _, e := range splitErrors(err) {
+			add("%v", e)
+		}
+	}
+
+	for _, repoKey := range slices.Sorted(maps.Keys(cfg.Repositories)) {
+		if _, _, err := parseRepo(repoKey); err != nil || strings.Contains(repoKey, "://") {
+			add("repositories.%s: not an owner/repo name", repoKey)
+		}
+	}
+	for _, key := range []string{"check.repositories", "canary.repositories"} {
+		repos := cfg.Check.Repositories
+		if key == "canary.repositories" {
+			repos = cfg.Canary.Repositories
+		}
+		for _, repoKey := range repos {
+			if _, _, err := parseRepo(repoKey); err != nil || strings.Contains(repoKey, "://") {
+				add("%s: %q is not an owner/repo name", key, repoKey)
+			}
+		}
+	}
+	for _, org := range slices.Sorted(maps.Keys(cfg.Organizations)) {
+		for _, glob := range append(append([]string(nil), cfg.Organizations[org].Include...), cfg.Organizations[org].Exclude...) {
+			if _, err := path.Match(glob, ""); err != nil {
+				add("organizations.%s: invalid glob %q: %v", org, glob, err)
+			}
+		}
+	}
+
+	problems = append(problems, validatePolicy("global", policyLayer{policyConfig: cfg.Global.policyConfig, Ecosystems: cfg.Ecosystems})...)
+	problems = append(problems, validateSettings("global", cfg.Global.repoSettings)...)
+	problems = append(problems, validatePolicy(canaryPolicyKey, cfg.Canary.Policy)...)
+	groups := make(map[string]bool)
+	for _, name := range slices.Sorted(maps.Keys(cfg.Policies)) {
+		groups[strings.ToLower(name)] = true
+		group := cfg.Policies[name]
+		problems = append(problems, validatePolicy(policyGroupsKey+"."+name, group.policyLayer)...)
+		problems = append(problems, validateSettings(policyGroupsKey+"."+name, group.repoSettings)...)
+	}
+	for _, repoKey := range slices.Sorted(maps.Keys(cfg.Repositories)) {
+		repo := cfg.Repositories[repoKey]
+		for _, group := range repo.Policy {
+			if !groups[strings.ToLower(group)] {
+				add("repositories.%s.policy: unknown policy group %q", repoKey, group)
+			}
+		}
+		problems = append(problems, validatePolicy("repositories."+repoKey, repo.policyLayer)...)
+		problems = append(problems, validateSettings("repositories."+repoKey, repo.repoSettings)...)
+	}
+
+	if cfg.Canary.Percent < 0 || cfg.Canary.Percent > 100 {
+		add("canary.percent: %d is not between 0 and 100", cfg.Canary.Percent)
+	}
+	if s, ok := cfg.Canary.Until.(string); ok {
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			if _, err := time.Parse(time.RFC3339, s); err != nil {
+				add("canary.until: %q is not a date (2006-01-02) or RFC 3339 timestamp", s)
+			}
+		}
+	}
+	switch cfg.Auth.ScopeCheck {
+	case "", "off", scopeCheckWarn, scopeCheckRefuse:
+	default:
+		add("auth.scope_check: %q is not off, warn, or refuse", cfg.Auth.ScopeCheck)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Profiles)) {
+		profile, ok := cfg.Profiles[name].(map[string]any)
+		if !ok && cfg.Profiles[name] != nil {
+			add("profiles.%s: not a mapping of settings", name)
+			continue
+		}
+		if _, nested := profile["profiles"]; nested {
+			add("profiles.%s: profiles cannot be nested", name)
+			continue
+		}
+		for _, p := range validateConfig(profile) {
+			add("profiles.%s: %s", name, p)
+		}
+	}
+	return problems
+}
+
+// validatePolicy returns the problems of a policy layer named by its key.
+func validatePolicy(name string, p policyLayer) []string {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, name+"."+fmt.Sprintf(format, args...))
+	}
+
+	packages, byType := splitDeniedPackages(p.DeniedPackages)
+	packageLists := map[string][]string{
+		"denied_packages":              packages,
+		"allowed_packages":             p.AllowedPackages,
+		"trusted_packages":             p.TrustedPackages,
+		"internal_packages":            p.InternalPackages,
+		"deny_update_types_by_package": slices.Collect(maps.Keys(p.DenyUpdateTypesByPackage)),
+	}
+	for eco, policy := range p.Ecosystems {
+		packageLists["ecosystems."+eco+".denied_packages"] = policy.DeniedPackages
+	}
+	for _, key := range slices.Sorted(maps.Keys(packageLists)) {
+		for _, entry := range packageLists[key] {
+			if err := bouncer.ValidateDenyEntry(entry); err != nil {
+				add("%s: %v", key, err)
+			}
+		}
+	}
+
+	orgLists := map[string][]string{
+		"denied_orgs":  p.DeniedOrgs,
+		"allowed_orgs": p.AllowedOrgs,
+		"trusted_orgs": p.TrustedOrgs,
+	}
+	for eco, policy := range p.Ecosystems {
+		orgLists["ecosystems."+eco+".denied_orgs"] = policy.DeniedOrgs
+	}
+	for _, key := range slices.Sorted(maps.Keys(orgLists)) {
+		for _, entry := range orgLists[key] {
+			if err := bouncer.ValidateOrgEntry(entry); err != nil {
+				add("%s: %v", key, err)
+			}
+		}
+	}
+
+	typeLists := map[string][]string{
+		"deny_update_types":          p.DenyUpdateTypes,
+		"deny_update_types_internal": p.DenyUpdateTypesInternal,
+		"deny_update_types_external": p.DenyUpdateTypesExternal,
+	}
+	for pkg, types := range p.DenyUpdateTypesByPackage {
+		typeLists["deny_update_types_by_package."+pkg] = types
+	}
+	for pkg, types := range byType {
+		typeLists["denied_packages."+pkg] = types
+	}
+	for _, key := range slices.Sorted(maps.Keys(typeLists)) {
+		if err := checkUpdateTypes(name, typeLists[key]); err != nil {
+			add("%s: %v", key, err)
+		}
+	}
+
+	switch p.Precedence {
+	case "", bouncer.PrecedenceDeny, bouncer.PrecedenceAllow:
+	default:
+		add("precedence: %q is not %s or %s", p.Precedence, bouncer.PrecedenceDeny, bouncer.PrecedenceAllow)
+	}
+	switch strings.ToLower(p.UnparsedTitles) {
+	case "", bouncer.UnparsedWarn, bouncer.UnparsedProcess, bouncer.UnparsedSkip:
+	default:
+		add("unparsed_titles: %q is not %s, %s, or %s", p.UnparsedTitles, bouncer.UnparsedWarn, bouncer.UnparsedProcess, bouncer.UnparsedSkip)
+	}
+	if p.MinSuccessfulChecks != nil && *p.MinSuccessfulChecks < 0 {
+		add("min_successful_checks: %d is negative", *p.MinSuccessfulChecks)
+	}
+	return problems
+}
+
+// validateSettings returns the problems of the repoSettings of global or a
+// repository, named by its key.
+func validateSettings(name string, s repoSettings) []string {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, name+"."+fmt.Sprintf(format, args...))
+	}
+
+	switch strings.ToLower(s.ReviewEvent) {
+	case "", bouncer.ReviewApprove, bouncer.ReviewComment:
+	default:
+		add("review_event: %q is not %s or %s", s.ReviewEvent, bouncer.ReviewApprove, bouncer.ReviewComment)
+	}
+	switch strings.ToLower(s.MergeMethod) {
+	case "", bouncer.MergeSquash, bouncer.MergeMerge, bouncer.MergeRebase:
+	default:
+		add("merge_method: %q is not %s, %s, or %s", s.MergeMethod, bouncer.MergeSquash, bouncer.MergeMerge, bouncer.MergeRebase)
+	}
+	switch strings.ToLower(s.MergeMode) {
+	case "", mergeAuto, mergeAPI, mergeComment:
+	default:
+		add("merge_mode: %q is not %s, %s, or %s", s.MergeMode, mergeAuto, mergeAPI, mergeComment)
+	}
+	switch strings.ToLower(s.VulnerabilityCheck) {
+	case "", vulnCheckOff, vulnCheckWarn, vulnCheckBlock:
+	default:
+		add("vulnerability_check: %q is not %s, %s, or %s", s.VulnerabilityCheck, vulnCheckBlock, vulnCheckWarn, vulnCheckOff)
+	}
+	switch s.Provider {
+	case "", bouncer.ProviderGitHub, bouncer.ProviderGitLab, bouncer.ProviderGitea:
+	default:
+		add("provider: %q is not %s, %s, or %s", s.Provider, bouncer.ProviderGitHub, bouncer.ProviderGitLab, bouncer.ProviderGitea)
+	}
+	for _, command := range slices.Sorted(maps.Keys(s.CIFilter)) {
+		if !bouncer.ValidCIFilter(s.CIFilter[command]) {
+			add("ci_filter.%s: %q is not %s, %s, %s, or %s", command, s.CIFilter[command], bouncer.CIPassing, bouncer.CIPassingOrPending, bouncer.CIAny, bouncer.CIFailing)
+		}
+	}
+	if s.MinAge != "" {
+		if _, err := parseAge(s.MinAge); err != nil {
+			add("min_age: %v", err)
+		}
+	}
+	if s.Maintenance.CloseAfter != "" {
+		if _, err := parseWindowTime(s.Maintenance.CloseAfter, time.Now()); err != nil {
+			add("maintenance.close_after: %v", err)
+		}
+	}
+	return problems
+}
+
+// splitErrors returns the errors joined in err, at any depth.
+func splitErrors(err error) []error {
+	var joined interface{ Unwrap() []error }
+	if !errors.As(err, &joined) {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, splitErrors(e)...)
+	}
+	return errs
+}
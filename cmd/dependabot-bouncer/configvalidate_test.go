@@ -0,0 +1,137 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []string // substrings of the problems, in order
+	}{
+		{
+			name: "valid",
+			config: `
+global:
+  denied_packages:
+    - "re:^@types/"
+    - github.com/gin-gonic/gin: deny=major
+  deny_update_types_by_package:
+    github.com/aws/aws-sdk-go-v2: [minor]
+  timeout: 2m
+ecosystems:
+  npm:
+    denied_orgs: ["@babel"]
+repositories:
+  myorg/api:
+    min_age: 3d
+    ignored_prs: [123]
+`,
+		},
+		{
+			name: "unknown key",
+			config: `
+global:
+  denied_pakages: [lodash]
+`,
+			want: []string{"'global' has invalid keys: denied_pakages"},
+		},
+		{
+			name: "bad duration",
+			config: `
+global:
+  timeout: 2 minutes
+`,
+			want: []string{"'global.timeout'"},
+		},
+		{
+			name: "repo name",
+			config: `
+repositories:
+  myorg-api:
+    denied_packages: [lodash]
+check:
+  repositories: [myorg]
+`,
+			want: []string{"repositories.myorg-api: not an owner/repo name", `check.repositories: "myorg"`},
+		},
+		{
+			name: "patterns",
+			config: `
+global:
+  denied_packages: ["re:(["]
+  denied_orgs: ["[a"]
+organizations:
+  myorg:
+    include: ["api-["]
+`,
+			want: []string{`organizations.myorg: invalid glob "api-["`, "global.denied_packages: invalid regular expression", "global.denied_orgs: invalid organization pattern"},
+		},
+		{
+			name: "settings",
+			config: `
+global:
+  deny_update_types: [huge]
+repositories:
+  myorg/api:
+    merge_method: fast
+    min_age: soon
+`,
+			want: []string{`global.deny_update_types: invalid update type "huge"`, `repositories.myorg/api.merge_method: "fast"`, `repositories.myorg/api.min_age: "soon"`},
+		},
+		{
+			name: "policy groups",
+			config: `
+policies:
+  backend:
+    denied_packages: [github.com/aws/aws-sdk-go]
+    merge_method: squash
+  frontend:
+    merge_method: fast
+repositories:
+  myorg/api:
+    policy: backend
+  myorg/web:
+    policy: [frontend, design]
+`,
+			want: []string{`policies.frontend.merge_method: "fast"`, `repositories.myorg/web.policy: unknown policy group "design"`},
+		},
+		{
+			name: "profiles",
+			config: `
+profiles:
+  work:
+    auth:
+      tokens: [GH_TOKEN_WORK]
+    repositories:
+      acme/api: {}
+  oss:
+    global:
+      merge_methd: squash
+`,
+			want: []string{"profiles.oss: 'global' has invalid keys: merge_methd"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var settings map[string]any
+			if err := yaml.Unmarshal([]byte(tt.config), &settings); err != nil {
+				t.Fatal(err)
+			}
+			got := validateConfig(settings)
+			if len(got) != len(tt.want) {
+				t.Fatalf("validateConfig() = %q, want %d problems", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("problem %d = %q, want it to contain %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/notify"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var digestCmd = &cobra.Command{
+	Use:   "digest [owner/repo...]",
+	Short: "Email a digest of open, blocked, and merged dependency PRs",
+	Long: `Email a daily or weekly digest of the dependency update queue to the
+recipients configured under notifications.email: the open PRs of each
+repository, the ones blocked by the allow and deny lists, and the ones
+merged during the period. Run it from cron at the same cadence as --period.
+
+With --print, the digest is written to stdout instead of being sent.
+
+If no repositories are specified as arguments or with --org, covers all
+repositories configured in the 'repositories' and 'organizations' sections
+of your config file.`,
+	RunE: runDigest,
+}
+
+// digestPeriods maps --period values to their length in days.
+var digestPeriods = map[string]int{"daily": 1, "weekly": 7}
+
+func runDigest(cmd *cobra.Command, args []string) error {
+	period, _ := cmd.Flags().GetString("period")
+	days, ok := digestPeriods[period]
+	if !ok {
+		return fmt.Errorf("invalid --period %q (expected daily or weekly)", period)
+	}
+	print, _ := cmd.Flags().GetBool("print")
+	cmd.SilenceUsage = true
+	var email notify.Email
+	if !print {
+		var err error
+		if email, err = emailConfig(); err != nil {
+			return err
+		}
+	}
+
+	repos, err := resolveRepos(cmd, args, true)
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories specified. Use command-line arguments, --org, or configure repositories in config file")
+	}
+
+	now := time.Now()
+	d := notify.Digest{Period: period, Since: now.AddDate(0, 0, -days), Until: now}
+	for _, repoPath := range repos {
+		owner, repo, err := parseRepo(repoPath)
+		if err != nil {
+			return err
+		}
+		log.Printf("Collecting %s...\n", repoPath)
+		entry := notify.DigestRepo{Name: owner + "/" + repo}
+		err = withRepoTimeout(owner, repo, func() error {
+			return collectDigest(owner, repo, d.Since, &entry)
+		})
+		if err != nil {
+			log.Printf("Warning: %s: %v\n", repoPath, err)
+			entry = notify.DigestRepo{Name: entry.Name, Error: err.Error()}
+		}
+		d.Repos = append(d.Repos, entry)
+	}
+
+	if print {
+		return notify.WriteDigest(os.Stdout, d)
+	}
+	var body strings.Builder
+	if err := notify.WriteDigest(&body, d); err != nil {
+		return err
+	}
+	if err := email.Send(d.Subject(), body.String()); err != nil {
+		return fmt.Errorf("failed to send the digest: %w", err)
+	}
+	log.Printf("Sent the %s digest to %s\n", period, strings.Join(email.To, ", "))
+	return nil
+}
+
+// collectDigest fills in the open, blocked, and merged PRs of a repository.
+// Merge history is only read from GitHub.
+func collectDigest(owner, repo string, since time.Time, entry *notify.DigestRepo) error {
+	provider, err := providerFor(owner, repo)
+	if err != nil {
+		return err
+	}
+	var skipped []bouncer.PRInfo
+	prs, err := listFilteredPRs(provider, owner, repo, nil, bouncer.CIAny, nil, &skipped)
+	if err != nil {
+		return fmt.Errorf("failed to list PRs: %w", err)
+	}
+	for _, pr := range prs {
+		entry.Open = append(entry.Open, notify.DigestPR{Number: pr.Number, Title: pr.Title, URL: pr.URL, Time: pr.CreatedAt})
+	}
+	for _, pr := range skipped {
+		if isDenial(pr.SkipCode) {
+			entry.Blocked = append(entry.Blocked, notify.DigestPR{Number: pr.Number, Title: pr.Title, URL: pr.URL, Time: pr.CreatedAt, Reason: pr.SkipReason})
+		}
+	}
+
+	if _, ok := provider.(bouncer.GitHub); ok {
+		merged, err := gitHub().ListMergedDependabotPRs(runCtx, owner, repo, since)
+		if err != nil {
+			log.Printf("Warning: failed to list merged PRs for %s/%s: %v\n", owner, repo, err)
+		}
+		for _, m := range merged {
+			entry.Merged = append(entry.Merged, notify.DigestPR{Number: m.Number, Title: m.Title, URL: m.URL, Time: m.MergedAt})
+		}
+	}
+	return nil
+}
+
+// emailConfig reads the SMTP settings under notifications.email.
+func emailConfig() (notify.Email, error) {
+	e := notify.Email{
+		Host:        viper.GetString("notifications.email.smtp_host"),
+		Port:        viper.GetInt("notifications.email.smtp_port"),
+		Username:    viper.GetString("notifications.email.username"),
+		Password:    viper.GetString("notifications.email.password"),
+		From:        viper.GetString("notifications.email.from"),
+		To:          getStringSlice("notifications.email.to"),
+		ImplicitTLS: viper.GetBool("notifications.email.implicit_tls"),
+	}
+	if e.Host == "" || e.From == "" || len(e.To) == 0 {
+		return notify.Email{}, fmt.Errorf("notifications.email needs smtp_host, from, and to (or use --print)")
+	}
+	return e, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes returned by approve, recreate, and check. Any other error exits
+// with 1, or exitInterrupted after a signal; track defines its own codes.
+const (
+	exitActionsFailed = 2 // an action on at least one PR failed
+	exitDeniedPRs     = 3 // with --exit-code: PRs denied by policy are open
+	exitFailingPRs    = 4 // with --exit-code: PRs with failing checks are open
+)
+
+// exitCodesHelp documents the exit code contract in command help.
+const exitCodesHelp = `
+
+Exit codes:
+  0    nothing to do, or every action succeeded
+  1    an error occurred (configuration, API access, timeouts)
+  2    an action on at least one PR failed
+  3    with --exit-code, PRs denied by policy are open
+  4    with --exit-code, PRs with failing checks are open
+  130  interrupted with SIGINT or SIGTERM`
+
+// errActionsFailed marks errors caused only by failed PR actions, as opposed
+// to the bouncer itself failing to process a repository.
+var errActionsFailed = errors.New("pull requests failed")
+
+// addExitCodeFlag registers --exit-code on a command that follows the exit
+// code contract.
+func addExitCodeFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("exit-code", false, "Exit with 3 when denied PRs are open and 4 when failing PRs are open")
+}
+
+// prCounts tallies the open PRs that --exit-code reports on.
+type prCounts struct {
+	Denied  int
+	Failing int
+}
+
+// isDenial reports whether a skip code means the PR was denied by policy, as
+// opposed to ignored or deferred.
+func isDenial(code string) bool {
+	switch code {
+	case bouncer.SkipDeniedPackage, bouncer.SkipDeniedOrg, bouncer.SkipNotAllowed, bouncer.SkipUpdateType, bouncer.SkipCodeOwners, bouncer.SkipVulnerable:
+		return true
+	}
+	return false
+}
+
+// addStats adds the denied and failing PRs counted while listing.
+func (c *prCounts) addStats(stats *bouncer.ListStats) {
+	if stats == nil {
+		return
+	}
+	for code, n := range stats.Skipped {
+		if isDenial(code) {
+			c.Denied += n
+		}
+	}
+	c.Failing += stats.Failing
+}
+
+// addResults adds the PRs acted on that had failing checks and are still
+// open. Listings that keep failing PRs, as recreate's do, count them only
+// here.
+func (c *prCounts) addResults(results []prResult) {
+	for _, r := range results {
+		if len(r.Failing) > 0 && r.Action != "Closed" && r.Action != "Ignored" {
+			c.Failing++
+		}
+	}
+}
+
+// addPRs adds the denied and failing PRs of a listing that kept skipped PRs.
+func (c *prCounts) addPRs(prs []bouncer.PRInfo) {
+	for _, pr := range prs {
+		switch {
+		case pr.Skipped && isDenial(pr.SkipCode):
+			c.Denied++
+		case !pr.Skipped && pr.CIStatus == "failure":
+			c.Failing++
+		}
+	}
+}
+
+// exitStatus maps the outcome of a run onto the exit code contract: errors
+// from failed PR actions exit with exitActionsFailed, other errors with 1,
+// and a successful run with --exit-code set reports failing PRs before
+// denied ones. It returns nil for exit code 0.
+func exitStatus(cmd *cobra.Command, err error, counts prCounts) error {
+	if err != nil {
+		if errors.Is(err, errActionsFailed) {
+			return &exitError{code: exitActionsFailed, msg: err.Error()}
+		}
+		return err
+	}
+	if gate, _ := cmd.Flags().GetBool("exit-code"); !gate {
+		return nil
+	}
+	switch {
+	case counts.Failing > 0:
+		return &exitError{code: exitFailingPRs, msg: fmt.Sprintf("%d open PR(s) with failing checks", counts.Failing)}
+	case counts.Denied > 0:
+		return &exitError{code: exitDeniedPRs, msg: fmt.Sprintf("%d open PR(s) denied by policy", counts.Denied)}
+	}
+	return nil
+}
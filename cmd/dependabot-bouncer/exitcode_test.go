@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+)
+
+func TestExitStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		gate     bool
+		counts   prCounts
+		wantCode int // 0 for a nil error, 1 for an error other than exitError
+	}{
+		{name: "success", wantCode: 0},
+		{name: "error", err: errors.New("gh: not logged in"), wantCode: 1},
+		{name: "failed actions", err: fmt.Errorf("%w in 1 of 2 repositories", errActionsFailed), wantCode: exitActionsFailed},
+		{name: "failed actions win over counts", err: errActionsFailed, gate: true, counts: prCounts{Denied: 1, Failing: 1}, wantCode: exitActionsFailed},
+		{name: "counts without --exit-code", counts: prCounts{Denied: 1, Failing: 1}, wantCode: 0},
+		{name: "denied", gate: true, counts: prCounts{Denied: 2}, wantCode: exitDeniedPRs},
+		{name: "failing before denied", gate: true, counts: prCounts{Denied: 2, Failing: 1}, wantCode: exitFailingPRs},
+		{name: "nothing open", gate: true, wantCode: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			addExitCodeFlag(cmd)
+			if tt.gate {
+				if err := cmd.Flags().Set("exit-code", "true"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := exitStatus(cmd, tt.err, tt.counts)
+			code := 0
+			var exitErr *exitError
+			switch {
+			case errors.As(err, &exitErr):
+				code = exitErr.code
+			case err != nil:
+				code = 1
+			}
+			if code != tt.wantCode {
+				t.Errorf("exitStatus() = %v (code %d), want code %d", err, code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRunResultsCounts(t *testing.T) {
+	rr := newRunResults()
+	rr.repo("acme/api").Stats = &bouncer.ListStats{
+		Skipped: map[string]int{bouncer.SkipDeniedPackage: 2, bouncer.SkipIgnored: 1},
+		Failing: 1,
+	}
+	rr.add("acme/web",
+		prResult{Number: 1, Action: "Recreated", Failing: []string{"build"}},
+		prResult{Number: 2, Action: "Recreated"},
+		prResult{Number: 3, Action: "Closed", Failing: []string{"lint"}},
+	)
+
+	if got, want := rr.counts(), (prCounts{Denied: 2, Failing: 2}); got != want {
+		t.Errorf("counts() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/report"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+)
+
+var freshnessCmd = &cobra.Command{
+	Use:   "freshness [owner/repo...]",
+	Short: "Rank repositories by how stale their dependency updates are",
+	Long: `Score each repository by its open Dependabot backlog and how long ago an
+update was last merged, and list them from stalest to freshest so you know
+where to run an approve or recreate sweep first.
+
+The score is the number of actionable open PRs, plus the age of the oldest
+one in weeks, plus the weeks since an update was last merged (capped at
+--days). Repositories with nothing actionable score zero.
+
+If no repositories are specified as arguments or with --org, scores all
+repositories configured in the 'repositories' and 'organizations' sections
+of your config file.`,
+	RunE: runFreshness,
+}
+
+func runFreshness(cmd *cobra.Command, args []string) error {
+	days, _ := cmd.Flags().GetInt("days")
+
+	repos, err := resolveRepos(cmd, args, true)
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories specified. Use command-line arguments or configure repositories in config file")
+	}
+
+	now := time.Now()
+	since := now.AddDate(0, 0, -days)
+	var scores []report.Freshness
+	for _, repoPath := range repos {
+		owner, repo, err := parseRepo(repoPath)
+		if err != nil {
+			return err
+		}
+		log.Printf("Collecting %s...\n", repoPath)
+
+		provider, err := providerFor(owner, repo)
+		if err != nil {
+			return err
+		}
+		q, err := buildQuery(owner, repo)
+		if err != nil {
+			return err
+		}
+		q.IgnoredPRs = getIntSlice("repositories." + repoPath + ".ignored_prs")
+		q.IncludeSkipped = true
+
+		err = withRepoTimeout(owner, repo, func() error {
+			prs, err := provider.List(runCtx, q, false)
+			if err != nil {
+				return fmt.Errorf("failed to list PRs: %w", err)
+			}
+
+			// Merge history is only available from GitHub; elsewhere the score
+			// treats nothing as merged within the window.
+			var merged []bouncer.MergedPR
+			if _, ok := provider.(bouncer.GitHub); ok {
+				merged, err = gitHub().ListMergedDependabotPRs(runCtx, owner, repo, since)
+				if err != nil {
+					log.Printf("Warning: failed to list merged PRs for %s: %v\n", repoPath, err)
+				}
+			}
+
+			scores = append(scores, report.ScoreFreshness(repoPath, prs, merged, now, days))
+			return nil
+		})
+		if err != nil {
+			log.Printf("Warning: %s: %v\n", repoPath, err)
+		}
+	}
+	report.SortFreshness(scores)
+
+	fmt.Printf("%-40s %6s %6s %12s %12s\n", "REPOSITORY", "SCORE", "OPEN", "OLDEST", "LAST MERGED")
+	for _, f := range scores {
+		oldest, lastMerged := "-", "-"
+		if f.Open > 0 {
+			oldest = fmt.Sprintf("%dd", int(f.OldestOpen.Hours()/24))
+		}
+		if !f.LastMerged.IsZero() {
+			lastMerged = f.LastMerged.Format("2006-01-02")
+		}
+		fmt.Printf("%-40s %6d %6d %12s %12s\n", f.Repo, f.Score, f.Open, oldest, lastMerged)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/report"
+	"github.com/promiseofcake/dependabot-bouncer/internal/state"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [owner/repo | owner/repo#number...]",
+	Short: "Show the decisions recorded for pull requests",
+	Long: `Show what approve, automerge, recreate, maintain, ignore, close, undo, watch,
+and serve did to each pull request, newest last: every action taken,
+successful or not, and every denial by policy. A PR denied again with the same reason on a later run
+is only recorded the first time.
+
+The history is kept on this machine in history.jsonl next to the state file
+(~/.dependabot-bouncer/ unless state.path is set) and is never rewritten.
+Set state.record_history to false to stop recording.
+
+With no arguments, shows the history of every repository.`,
+	RunE: runHistory,
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	days, _ := cmd.Flags().GetInt("days")
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	repos := map[string]bool{}
+	prs := map[string]bool{}
+	for _, arg := range args {
+		if isPRArg(arg) {
+			owner, repo, number, err := parsePRRef(arg)
+			if err != nil {
+				return err
+			}
+			prs[fmt.Sprintf("%s/%s#%d", owner, repo, number)] = true
+			continue
+		}
+		owner, repo, err := parseRepo(arg)
+		if err != nil {
+			return err
+		}
+		repos[owner+"/"+repo] = true
+	}
+
+	h, err := openHistory()
+	if err != nil {
+		return err
+	}
+	var since time.Time
+	if days > 0 {
+		since = time.Now().AddDate(0, 0, -days)
+	}
+	all, err := h.Records(since)
+	if err != nil {
+		return err
+	}
+	records := []state.Record{}
+	for _, r := range all {
+		if r.Decision == state.DecisionRun {
+			continue
+		}
+		if len(args) == 0 || repos[r.Repo] || prs[fmt.Sprintf("%s#%d", r.Repo, r.Number)] {
+			records = append(records, r)
+		}
+	}
+
+	if format != outputText {
+		return writeDocument(cmd.OutOrStdout(), format, records)
+	}
+	if len(records) == 0 {
+		fmt.Println("No decisions recorded")
+		return nil
+	}
+	for _, r := range records {
+		what := r.Decision
+		if r.Reason != "" {
+			what += " (" + r.Reason + ")"
+		}
+		fmt.Printf("%s  %-9s %s#%d  %s  %s\n", r.Time.Local().Format("2006-01-02 15:04"), r.Command, r.Repo, r.Number, r.Package, what)
+		for _, d := range r.Details {
+			fmt.Printf("    %s\n", d)
+		}
+		for _, e := range r.Errors {
+			fmt.Printf("  ! %s\n", e)
+		}
+	}
+	return nil
+}
+
+var (
+	historyOnce sync.Once
+	history     *state.History
+	historyErr  error
+
+	recordedCallsMu sync.Mutex
+	recordedCalls   = make(map[string]int) // API calls per repository already recorded by this process
+)
+
+// openHistory returns the history kept next to the state file. It is opened
+// once per process, so watch and serve read it only on their first run.
+func openHistory() (*state.History, error) {
+	historyOnce.Do(func() {
+		path, err := statePath()
+		if err != nil {
+			historyErr = err
+			return
+		}
+		history = state.OpenHistory(state.HistoryPath(path))
+	})
+	return history, historyErr
+}
+
+// recordRun appends the decisions of a run to the history: each PR acted on,
+// each PR denied by policy unless its last record is the same denial, and
+// the API calls made on each repository since the last run recorded by this
+// process. With audit.history, the PRs acted on also record the account and
+// rule behind them. The PRs acted on go to the audit log too (see auditRun).
+// Failures are logged and never fail the run.
+func recordRun(command string, rr *runResults) {
+	auditRun(command, rr)
+	if viper.IsSet("state.record_history") && !viper.GetBool("state.record_history") {
+		return
+	}
+	h, err := openHistory()
+	if err != nil {
+		log.Printf("Warning: not recording history: %v\n", err)
+		return
+	}
+	now := time.Now()
+	var recs []state.Record
+	for _, repoKey := range rr.order {
+		r := rr.byRepo[repoKey]
+		if calls := newCalls(repoKey); calls > 0 {
+			recs = append(recs, state.Record{
+				Time:     now,
+				Command:  command,
+				Repo:     repoKey,
+				Decision: state.DecisionRun,
+				Calls:    calls,
+			})
+		}
+		for _, pr := range r.PRs {
+			decision := decisionName(pr.Action)
+			if len(pr.Errors) > 0 {
+				decision = "failed"
+			}
+			rec := state.Record{
+				Time:     now,
+				Command:  command,
+				Repo:     repoKey,
+				Number:   pr.Number,
+				Package:  pr.Package,
+				Decision: decision,
+				Details:  pr.Details,
+				Errors:   pr.Errors,
+				Failing:  pr.Failing,
+			}
+			if viper.GetBool("audit.history") {
+				rec.Actor, rec.Rule = auditActor(), pr.Rule
+			}
+			recs = append(recs, rec)
+		}
+		for _, pr := range r.PolicySkipped {
+			if !isDenial(pr.SkipCode) {
+				continue
+			}
+			last, ok, err := h.Last(repoKey, pr.Number)
+			if err != nil {
+				log.Printf("Warning: not recording history: %v\n", err)
+				return
+			}
+			if ok && last.Decision == "denied" && last.Reason == pr.SkipCode {
+				continue
+			}
+			recs = append(recs, state.Record{
+				Time:     now,
+				Command:  command,
+				Repo:     repoKey,
+				Number:   pr.Number,
+				Package:  pr.PackageName,
+				Decision: "denied",
+				Reason:   pr.SkipCode,
+				Details:  []string{pr.SkipReason},
+			})
+		}
+	}
+	if err := h.Append(recs...); err != nil {
+		log.Printf("Warning: %v\n", err)
+	}
+}
+
+// newCalls returns the API calls made on "owner/repo" that this process has
+// not recorded yet. The bouncer package counts calls for the life of the
+// process, which spans many runs under watch and serve.
+func newCalls(repoKey string) int {
+	recordedCallsMu.Lock()
+	defer recordedCallsMu.Unlock()
+	total := bouncer.RepoCalls(repoKey).Total()
+	calls := total - recordedCalls[repoKey]
+	recordedCalls[repoKey] = total
+	return calls
+}
+
+// flakyWindow is how far back the history is searched for flaky checks.
+const flakyWindow = 90 * 24 * time.Hour
+
+// flakyPackages returns the packages of a repository whose PRs the history
+// shows failing and then passing, keyed by package. Failures to read the
+// history are logged and leave the result empty.
+func flakyPackages(repoKey string) map[string]state.FlakyPackage {
+	h, err := openHistory()
+	if err != nil {
+		return nil
+	}
+	recs, err := h.Records(time.Now().Add(-flakyWindow))
+	if err != nil {
+		log.Printf("Warning: not checking for flaky checks: %v\n", err)
+		return nil
+	}
+	flaky := make(map[string]state.FlakyPackage)
+	for _, p := range state.FlakyPackages(recs, report.FlakyMinPRs) {
+		if strings.EqualFold(p.Repo, repoKey) {
+			flaky[p.Package] = p
+		}
+	}
+	return flaky
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+)
+
+var ignoreCmd = &cobra.Command{
+	Use:   "ignore [owner/repo | owner/repo#number | PR URL...]",
+	Short: "Tell Dependabot to stop proposing denied updates and close their PRs",
+	Long: `Clear out PRs the policy will never approve, so they stop piling up.
+
+Each PR denied by the deny lists gets an @dependabot ignore command and is
+closed:
+  - PRs of a denied package or organization: "ignore this dependency"
+  - PRs denied by update type: "ignore this major version" (or minor, patch)
+
+PRs skipped for any other reason (allow lists, ignored_prs, drafts) and
+grouped updates, whose title names no single package, are left alone. On
+GitLab and Gitea the PRs are only closed, which is how dependabot-gitlab and
+Renovate learn to leave an update alone.
+
+maintain --ignore-denied does the same as part of the nightly maintenance.
+
+If no repositories are specified as arguments or with --org, processes all
+repositories configured in the 'repositories' and 'organizations' sections
+of your config file.` + exitCodesHelp,
+	RunE: runIgnoreCommand,
+}
+
+func runIgnoreCommand(cmd *cobra.Command, args []string) error {
+	args, targets, err := takePRTargets(cmd, args)
+	if err != nil {
+		return err
+	}
+	repos, err := resolveRepos(cmd, args, true)
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories specified. Use command-line arguments, --org, or configure repositories in config file")
+	}
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	doc, human := outputWriters(format)
+
+	limit := newActionLimit(cmd)
+	workers := concurrency(cmd)
+	results := newRunResults()
+	results.targets = targets
+	err = forEachRepo(human, repos, func(owner, repo string) error {
+		out := results.repo(owner + "/" + repo)
+		out.Err = runIgnore(human, owner, repo, limit, workers, out)
+		return out.Err
+	})
+	reportToActions(human, cmd.Name(), results)
+	recordRun(cmd.Name(), results)
+	notifyRun(cmd.Name(), results, false)
+	if format != outputText {
+		if docErr := writeDocument(doc, format, resultsDocument(results)); docErr != nil {
+			return docErr
+		}
+	} else {
+		printResults(results)
+	}
+	return exitStatus(cmd, err, results.counts())
+}
+
+// runIgnore ignores and closes the denied PRs of a repository, recording the
+// outcome for each in out.
+func runIgnore(w io.Writer, owner, repo string, limit *actionLimit, workers int, out *repoResults) error {
+	provider, err := providerFor(owner, repo)
+	if err != nil {
+		return err
+	}
+	var stats bouncer.ListStats
+	if _, err := listFilteredPRs(provider, owner, repo, out.numbers, bouncer.CIAny, &stats, &out.PolicySkipped); err != nil {
+		return err
+	}
+	out.Stats = &stats
+	recordDenials(owner, repo, out.PolicySkipped)
+	ignoreDenied(w, provider, owner, repo, limit, workers, out)
+	return resultsError(out.PRs)
+}
+
+// ignoreScope returns the update type to ignore for a PR skipped by policy,
+// empty to ignore the whole dependency, and whether the PR should be ignored
+// at all. Grouped updates are not: their commands would apply to the group.
+func ignoreScope(pr bouncer.PRInfo) (updateType string, ok bool) {
+	if pr.PackageName == "" || len(pr.Updates) > 0 {
+		return "", false
+	}
+	switch pr.SkipCode {
+	case bouncer.SkipDeniedPackage, bouncer.SkipDeniedOrg:
+		return "", true
+	case bouncer.SkipUpdateType:
+		return pr.UpdateType, pr.UpdateType != ""
+	}
+	return "", false
+}
+
+// ignoreDenied ignores and closes the denied PRs among out.PolicySkipped, up
+// to workers at a time and within the limit, appending their results to out
+// in PR-list order. The PRs acted on are removed from out.PolicySkipped.
+func ignoreDenied(w io.Writer, provider bouncer.Client, owner, repo string, limit *actionLimit, workers int, out *repoResults) {
+	var prs, rest []bouncer.PRInfo
+	for _, pr := range out.PolicySkipped {
+		if _, ok := ignoreScope(pr); ok {
+			prs = append(prs, pr)
+		} else {
+			rest = append(rest, pr)
+		}
+	}
+	if len(prs) == 0 {
+		return
+	}
+	taken := limit.take(prs) // a prefix of prs: skipped PRs are never trusted
+	out.PolicySkipped = append(rest, prs[len(taken):]...)
+	if len(taken) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "Ignoring %d denied pull requests...\n", len(taken))
+
+	results := make([]prResult, len(taken))
+	runOrdered(len(taken), workers, func(i int) {
+		results[i] = ignorePR(provider, owner, repo, taken[i])
+	}, func(i int) {
+		logResult(results[i])
+		out.PRs = append(out.PRs, results[i])
+	})
+}
+
+// ignorePR tells the bot to ignore a denied PR's update and closes the PR.
+func ignorePR(provider bouncer.Client, owner, repo string, pr bouncer.PRInfo) prResult {
+	r := newPRResult(pr, "Ignored")
+	typ, _ := ignoreScope(pr)
+	if err := provider.Ignore(runCtx, owner, repo, pr.Number, typ); err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("failed to ignore: %v", err))
+		return r
+	}
+	if typ == "" {
+		r.Details = append(r.Details, "ignored "+pr.PackageName)
+	} else {
+		r.Details = append(r.Details, fmt.Sprintf("ignored %s updates of %s", typ, pr.PackageName))
+	}
+	if err := provider.Close(runCtx, owner, repo, pr.Number); err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("failed to close: %v", err))
+	} else {
+		r.Details = append(r.Details, "closed ("+pr.SkipReason+")")
+	}
+	return r
+}
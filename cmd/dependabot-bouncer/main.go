@@ -1,9 +1,11 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -19,6 +21,8 @@ var (
 Supports both approve and recreate modes with flexible deny lists for
 packages and organizations. Configuration can be provided via YAML file
 or command-line flags.`,
+		PersistentPreRunE: setupSCM,
+		PersistentPostRun: finishRun,
 	}
 )
 
@@ -26,15 +30,162 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default search: $XDG_CONFIG_HOME/dependabot-bouncer/config.yaml, or $HOME/.config/dependabot-bouncer/config.yaml if XDG_CONFIG_HOME is unset, then $HOME/.dependabot-bouncer/config.yaml)")
+	rootCmd.PersistentFlags().String("profile", "", "Apply the settings of this profile from the config file's profiles section (or set DEPENDABOT_BOUNCER_PROFILE)")
+	viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
 	rootCmd.PersistentFlags().StringSlice("deny-packages", []string{}, "Packages to deny")
 	rootCmd.PersistentFlags().StringSlice("deny-orgs", []string{}, "Organizations to deny")
+	rootCmd.PersistentFlags().StringSlice("allow-packages", []string{}, "Only process these packages")
+	rootCmd.PersistentFlags().StringSlice("allow-orgs", []string{}, "Only process packages from these organizations")
+
+	rootCmd.PersistentFlags().Duration("timeout", 0, "Time limit for processing each repository (0 for no limit)")
+	viper.BindPFlag("global.timeout", rootCmd.PersistentFlags().Lookup("timeout"))
+	rootCmd.PersistentFlags().Duration("run-timeout", 0, "Time limit for the whole run (0 for no limit)")
+	viper.BindPFlag("global.run_timeout", rootCmd.PersistentFlags().Lookup("run-timeout"))
+	rootCmd.PersistentFlags().Bool("read-only", false, "Refuse every API call that would change a repository or pull request")
+	viper.BindPFlag("read_only", rootCmd.PersistentFlags().Lookup("read-only"))
+	rootCmd.PersistentFlags().String("otlp-endpoint", "", "Export traces of API calls to this OpenTelemetry collector (OTLP/HTTP), e.g. http://localhost:4318")
+	viper.BindPFlag("tracing.endpoint", rootCmd.PersistentFlags().Lookup("otlp-endpoint"))
 
 	viper.BindPFlag("deny-packages", rootCmd.PersistentFlags().Lookup("deny-packages"))
 	viper.BindPFlag("deny-orgs", rootCmd.PersistentFlags().Lookup("deny-orgs"))
+	viper.BindPFlag("allow-packages", rootCmd.PersistentFlags().Lookup("allow-packages"))
+	viper.BindPFlag("allow-orgs", rootCmd.PersistentFlags().Lookup("allow-orgs"))
 
 	approveCmd.Flags().BoolP("interactive", "i", false, "Review and approve PRs one at a time")
+	recreateCmd.Flags().BoolP("interactive", "i", false, "Review PRs one at a time, choosing to recreate, approve, skip, or deny each")
+	approveCmd.Flags().Int("limit", 0, "Act on at most this many PRs across all repositories (0 for no limit)")
+	approveCmd.Flags().Bool("include-drafts", false, "Process draft PRs instead of skipping them")
+	approveCmd.Flags().Bool("mark-ready", false, "Mark draft PRs ready for review before approving them (implies --include-drafts)")
+	viper.BindPFlag("include-drafts", approveCmd.Flags().Lookup("include-drafts"))
+	viper.BindPFlag("mark-ready", approveCmd.Flags().Lookup("mark-ready"))
+	approveCmd.Flags().String("merge-method", "", "How approved PRs are merged: auto (enable auto-merge), api (merge passing PRs right away), or comment (ask Dependabot to merge); overrides merge_mode (default auto)")
+	viper.BindPFlag("merge-method", approveCmd.Flags().Lookup("merge-method"))
+	approveCmd.Flags().Duration("wait-pending", 0, "Wait up to this long for PRs whose checks are still running and approve the ones that pass (e.g. 20m)")
+	viper.BindPFlag("wait-pending", approveCmd.Flags().Lookup("wait-pending"))
+	approveCmd.Flags().String("export", "", "Write the approved dependency changes to this file as JSON")
+	recreateCmd.Flags().Int("limit", 0, "Act on at most this many PRs across all repositories (0 for no limit)")
+	automergeCmd.Flags().Int("limit", 0, "Act on at most this many PRs across all repositories (0 for no limit)")
+	for _, cmd := range []*cobra.Command{approveCmd, automergeCmd} {
+		cmd.Flags().Int("max-approvals", 0, "Act on at most this many PRs across all repositories, security updates first, then patch, minor, and major ones (0 for no limit)")
+	}
+	approveCmd.MarkFlagsMutuallyExclusive("max-approvals", "wait-pending")
+	approveCmd.MarkFlagsMutuallyExclusive("max-approvals", "interactive")
+	for _, cmd := range []*cobra.Command{approveCmd, recreateCmd, watchCmd, automergeCmd} {
+		addCIFlags(cmd)
+		cmd.Flags().Int("concurrency", defaultConcurrency, "How many PRs of a repository to act on at once")
+	}
+
+	checkCmd.Flags().Bool("json", false, "Print results as JSON (same as --output json)")
+	checkCmd.Flags().String("format", formatTable, "Output format: table, text, json, yaml, csv, or markdown")
+	checkCmd.Flags().Int("repo-concurrency", 1, "How many repositories to check at once")
+
+	maintainCmd.Flags().Int("limit", 0, "Act on at most this many PRs across all repositories (0 for no limit)")
+	maintainCmd.Flags().Int("concurrency", defaultConcurrency, "How many PRs of a repository to act on at once")
+	maintainCmd.Flags().Bool("ignore-denied", false, "Also tell Dependabot to ignore denied updates and close their PRs, as the ignore command does")
+	viper.BindPFlag("ignore-denied", maintainCmd.Flags().Lookup("ignore-denied"))
+	ignoreCmd.Flags().Int("limit", 0, "Act on at most this many PRs across all repositories (0 for no limit)")
+	ignoreCmd.Flags().Int("concurrency", defaultConcurrency, "How many PRs of a repository to act on at once")
+	closeCmd.Flags().Bool("superseded", false, "Close PRs whose updates newer open PRs all propose again (required)")
+	closeCmd.Flags().Int("limit", 0, "Act on at most this many PRs across all repositories (0 for no limit)")
+	closeCmd.Flags().Int("concurrency", defaultConcurrency, "How many PRs of a repository to act on at once")
+	addOutputFlag(closeCmd)
+	addExitCodeFlag(closeCmd)
+	addOrgFlags(closeCmd)
+
+	for _, cmd := range []*cobra.Command{approveCmd, recreateCmd, checkCmd, maintainCmd, automergeCmd, ignoreCmd} {
+		cmd.Flags().StringSlice("pr", nil, "Only act on these pull requests: numbers of the repository given, or URLs (can be repeated)")
+		addOutputFlag(cmd)
+		addExitCodeFlag(cmd)
+	}
+	checkCmd.MarkFlagsMutuallyExclusive("format", "output", "json")
+
+	freshnessCmd.Flags().Int("days", 90, "Lookback window in days for the last merged update")
+
+	statsCmd.Flags().Int("days", 90, "Summarize the PRs closed in the last this many days")
+	statsCmd.Flags().Int("top", 10, "How many of the most updated packages to list")
+	addOutputFlag(statsCmd)
+	addOrgFlags(statsCmd)
+
+	watchCmd.Flags().Duration("interval", 15*time.Minute, "How long to wait between cycles")
+	watchCmd.Flags().Int("limit", 0, "Act on at most this many PRs per cycle (0 for no limit)")
+	watchCmd.Flags().Bool("spread", false, "Spread the repositories of each cycle across the interval instead of processing them back to back")
+	watchCmd.Flags().Duration("jitter", 0, "Delay each cycle by a random amount up to this long (e.g. 2m)")
+	watchCmd.Flags().String("metrics-addr", "", "Serve Prometheus metrics at /metrics on this address, e.g. :9090 (off by default)")
+
+	for _, cmd := range []*cobra.Command{approveCmd, recreateCmd, checkCmd, freshnessCmd, watchCmd, maintainCmd, automergeCmd, ignoreCmd} {
+		addOrgFlags(cmd)
+		addWindowFlags(cmd)
+	}
+
+	serveCmd.Flags().String("addr", ":8080", "Address to listen on for webhooks")
+	viper.BindEnv("webhook.secret", "DEPENDABOT_BOUNCER_WEBHOOK_SECRET")
+	viper.BindEnv("notifications.slack.webhook_url", "DEPENDABOT_BOUNCER_SLACK_WEBHOOK_URL")
+	viper.BindEnv("notifications.teams.webhook_url", "DEPENDABOT_BOUNCER_TEAMS_WEBHOOK_URL")
+	viper.BindEnv("notifications.discord.webhook_url", "DEPENDABOT_BOUNCER_DISCORD_WEBHOOK_URL")
+	viper.BindEnv("notifications.webhook.url", "DEPENDABOT_BOUNCER_NOTIFY_WEBHOOK_URL")
+
+	digestCmd.Flags().String("period", "weekly", "Period covered by the digest: daily or weekly")
+	digestCmd.Flags().Bool("print", false, "Write the digest to stdout instead of emailing it")
+	addOrgFlags(digestCmd)
+	viper.BindEnv("notifications.email.password", "DEPENDABOT_BOUNCER_SMTP_PASSWORD")
+
+	reportCmd.Flags().String("owner", "", "GitHub user or organization to report on (default: the configured repositories)")
+	reportCmd.Flags().String("format", "markdown", "Report format: markdown or html")
+	reportCmd.Flags().StringP("output", "o", "", "Write the report to this file instead of stdout")
+	reportCmd.Flags().Int("days", 90, "Lookback window in days for merged PRs and time-to-merge trends")
+	reportCmd.Flags().Int("stale-days", 14, "Count open PRs older than this many days as stale (0 to count none)")
+	reportCmd.Flags().Int("review-minutes", 10, "Estimated reviewer minutes saved per PR the bouncer handled")
+	reportCmd.Flags().String("template", "", "Render the report with this Go template file (overrides report.template)")
+	reportCmd.Flags().Bool("print-template", false, "Print the built-in template of --format and exit")
+	reportCmd.Flags().Bool("notify", false, "Also post the Markdown report to the notification destinations of its repositories")
+	viper.BindPFlag("report.template", reportCmd.Flags().Lookup("template"))
+	addOrgFlags(reportCmd)
+
+	bootstrapCmd.Flags().String("owner", "", "GitHub organization or user whose repositories to set up (required)")
+	bootstrapCmd.Flags().String("team", "", "Only set up the repositories of this team of the organization (its slug)")
+	bootstrapCmd.Flags().StringP("output", "o", "", "Write the config to this file, or - for stdout (default: --config, or $XDG_CONFIG_HOME/dependabot-bouncer/config.yaml)")
+	bootstrapCmd.Flags().Bool("force", false, "Replace the config file if it exists")
+	bootstrapCmd.MarkFlagRequired("owner")
+
+	trackCmd.Flags().Duration("interval", 30*time.Second, "How often to poll the pull request")
+	trackCmd.Flags().Duration("timeout", 0, "Give up after this long (0 waits indefinitely)")
+
+	revertCmd.Flags().String("package", "", "Package whose last merged update should be reverted")
+	revertCmd.Flags().Int("days", 30, "How far back to look for the merged PR, in days")
+	revertCmd.Flags().Bool("no-ignore", false, "Do not print the dependabot.yml ignore entry for the reverted version")
+	revertCmd.MarkFlagRequired("package")
 
-	rootCmd.AddCommand(approveCmd, recreateCmd, checkCmd)
+	policyDiffCmd.Flags().String("old", "", "Config file with the current policy (required)")
+	policyDiffCmd.Flags().String("new", "", "Config file with the proposed policy (required)")
+	policyDiffCmd.MarkFlagRequired("old")
+	policyDiffCmd.MarkFlagRequired("new")
+	policyDiffCmd.Flags().Bool("exit-code", false, "Exit with 2 when any PR would change outcome")
+	addOrgFlags(policyDiffCmd)
+	addOutputFlag(policyDiffCmd)
+	policyCmd.AddCommand(policyDiffCmd)
+
+	addOutputFlag(undoCmd)
+
+	historyCmd.Flags().Int("days", 30, "Show the decisions of the last this many days (0 for all)")
+	addOutputFlag(historyCmd)
+
+	snoozeCmd.Flags().String("until", "", "Date or time from now to snooze the PRs until (e.g. 2026-07-01 or 2w)")
+	snoozeCmd.Flags().String("reason", "", "Why the PRs are snoozed, shown by --list")
+	snoozeCmd.Flags().Bool("list", false, "List the snoozed PRs, of the repositories given or of all")
+	snoozeCmd.Flags().Bool("clear", false, "Remove the snoozes of the PRs given")
+	snoozeCmd.MarkFlagsMutuallyExclusive("list", "clear")
+
+	configCmd.AddCommand(configValidateCmd)
+
+	rootCmd.AddCommand(approveCmd, automergeCmd, recreateCmd, checkCmd, reportCmd, trackCmd, revertCmd, snoozeCmd, historyCmd, policyCmd, freshnessCmd, statsCmd, watchCmd, serveCmd, maintainCmd, ignoreCmd, closeCmd, undoCmd, digestCmd, bootstrapCmd, configCmd)
+}
+
+// addOrgFlags registers the flags used to discover repositories in an organization.
+func addOrgFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("org", []string{}, "Operate on all repositories in this organization (can be repeated)")
+	cmd.Flags().StringSlice("include", []string{}, "Only include organization repositories matching these globs")
+	cmd.Flags().StringSlice("exclude", []string{}, "Exclude organization repositories matching these globs")
+	cmd.Flags().Bool("include-archived", false, "Include archived organization repositories")
 }
 
 func initConfig() {
@@ -68,13 +219,38 @@ func initConfig() {
 
 	// Read config file if it exists
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
+
+	if name := viper.GetString("profile"); name != "" {
+		if err := applyProfile(name); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stderr, "Using profile:", name)
+	}
+}
+
+// exitError carries a specific process exit code out of a command.
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e *exitError) Error() string {
+	return e.msg
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	err := runError(rootCmd.Execute())
+	stopRun()
+	finishTracing(err)
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		var ee *exitError
+		if errors.As(err, &ee) {
+			os.Exit(ee.code)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var maintainCmd = &cobra.Command{
+	Use:   "maintain [owner/repo | owner/repo#number | PR URL...]",
+	Short: "Close stale, recreate conflicted, and rebase outdated dependency PRs",
+	Long: `Keep the dependency update queue of every repository healthy without
+approving anything. Meant as the single nightly cron entry point next to
+approve or watch.
+
+For each PR, in this order:
+  - PRs opened before maintenance.close_after (an age such as 60d, or a date)
+    are closed. Off unless configured.
+  - PRs with merge conflicts are recreated (maintenance.recreate_conflicted,
+    default true).
+  - PRs behind their base branch are rebased (maintenance.rebase_behind,
+    default true).
+
+Settings are read under global and per repository; the repository setting
+wins. The allow and deny lists, ignored_prs, and the draft rule apply as in
+approve. With --ignore-denied, PRs denied by the deny lists are also ignored
+and closed, as the ignore command does.
+
+If no repositories are specified as arguments or with --org, maintains all
+repositories configured in the 'repositories' and 'organizations' sections
+of your config file.` + exitCodesHelp,
+	RunE: runMaintainCommand,
+}
+
+func runMaintainCommand(cmd *cobra.Command, args []string) error {
+	args, targets, err := takePRTargets(cmd, args)
+	if err != nil {
+		return err
+	}
+	repos, err := resolveRepos(cmd, args, true)
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories specified. Use command-line arguments, --org, or configure repositories in config file")
+	}
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	doc, human := outputWriters(format)
+
+	limit := newActionLimit(cmd)
+	workers := concurrency(cmd)
+	results := newRunResults()
+	results.targets = targets
+	err = forEachRepo(human, repos, func(owner, repo string) error {
+		out := results.repo(owner + "/" + repo)
+		out.Err = runMaintain(human, owner, repo, limit, workers, out)
+		return out.Err
+	})
+	reportToActions(human, cmd.Name(), results)
+	recordRun(cmd.Name(), results)
+	notifyRun(cmd.Name(), results, false)
+	if format != outputText {
+		if docErr := writeDocument(doc, format, resultsDocument(results)); docErr != nil {
+			return docErr
+		}
+	} else {
+		printResults(results)
+	}
+	return exitStatus(cmd, err, results.counts())
+}
+
+// maintenancePolicy is what maintain does to a repository's PRs.
+type maintenancePolicy struct {
+	CloseBefore        time.Time // close PRs opened before this; zero never closes
+	RecreateConflicted bool
+	RebaseBehind       bool
+}
+
+// buildMaintenancePolicy reads the maintenance settings of a repository; the
+// policy group and repo-specific settings override the global ones.
+func buildMaintenancePolicy(owner, repo string, now time.Time) (maintenancePolicy, error) {
+	repoKey := owner + "/" + repo
+	p := maintenancePolicy{RecreateConflicted: true, RebaseBehind: true}
+	closeAfter := ""
+	for _, prefix := range settingPrefixes(repoKey) {
+		prefix += "maintenance."
+		if viper.IsSet(prefix + "close_after") {
+			closeAfter = viper.GetString(prefix + "close_after")
+		}
+		if viper.IsSet(prefix + "recreate_conflicted") {
+			p.RecreateConflicted = viper.GetBool(prefix + "recreate_conflicted")
+		}
+		if viper.IsSet(prefix + "rebase_behind") {
+			p.RebaseBehind = viper.GetBool(prefix + "rebase_behind")
+		}
+	}
+	before, err := parseWindowTime(closeAfter, now)
+	if err != nil {
+		return maintenancePolicy{}, fmt.Errorf("invalid maintenance.close_after for %s: %w", repoKey, err)
+	}
+	p.CloseBefore = before
+	return p, nil
+}
+
+// action returns what the policy does to pr: "Closed", "Recreated",
+// "Rebased", or "" to leave it alone.
+func (p maintenancePolicy) action(pr bouncer.PRInfo) string {
+	switch {
+	case !p.CloseBefore.IsZero() && pr.CreatedAt.Before(p.CloseBefore):
+		return "Closed"
+	case p.RecreateConflicted && pr.MergeStateStatus == "DIRTY":
+		return "Recreated"
+	case p.RebaseBehind && pr.MergeStateStatus == "BEHIND":
+		return "Rebased"
+	}
+	return ""
+}
+
+// runMaintain applies the maintenance policy to every PR of a repository,
+// and with --ignore-denied ignores its denied PRs, up to workers at a time,
+// recording the outcome for each PR acted on in out in PR-list order. A
+// failure on one PR does not stop the others; the returned error aggregates
+// them.
+func runMaintain(w io.Writer, owner, repo string, limit *actionLimit, workers int, out *repoResults) error {
+	provider, err := providerFor(owner, repo)
+	if err != nil {
+		return err
+	}
+	policy, err := buildMaintenancePolicy(owner, repo, time.Now())
+	if err != nil {
+		return err
+	}
+	var stats bouncer.ListStats
+	listed, err := listFilteredPRs(provider, owner, repo, out.numbers, bouncer.CIAny, &stats, &out.PolicySkipped)
+	if err != nil {
+		return err
+	}
+	out.Stats = &stats
+	recordDenials(owner, repo, out.PolicySkipped)
+
+	var prs []bouncer.PRInfo
+	for _, pr := range listed {
+		if policy.action(pr) != "" {
+			prs = append(prs, pr)
+		}
+	}
+	if len(prs) == 0 {
+		fmt.Fprintf(w, "Nothing to maintain among %d pull requests\n", len(listed))
+	} else if prs = limit.take(prs); len(prs) == 0 {
+		out.Skipped = "limit reached"
+	} else {
+		fmt.Fprintf(w, "Processing %d pull requests...\n", len(prs))
+
+		results := make([]prResult, len(prs))
+		runOrdered(len(prs), workers, func(i int) {
+			results[i] = maintainPR(provider, owner, repo, prs[i], policy)
+		}, func(i int) {
+			if results[i].Action == "Recreated" {
+				recordRecreate(owner, repo, prs[i], results[i])
+			}
+			logResult(results[i])
+			out.PRs = append(out.PRs, results[i])
+		})
+	}
+
+	if viper.GetBool("ignore-denied") {
+		ignoreDenied(w, provider, owner, repo, limit, workers, out)
+	}
+	return resultsError(out.PRs)
+}
+
+// maintainPR takes the policy's action on a single PR.
+func maintainPR(provider bouncer.Client, owner, repo string, pr bouncer.PRInfo, policy maintenancePolicy) prResult {
+	r := newPRResult(pr, policy.action(pr))
+	switch r.Action {
+	case "Closed":
+		if err := provider.Close(runCtx, owner, repo, pr.Number); err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("failed to close: %v", err))
+		} else {
+			r.Details = append(r.Details, fmt.Sprintf("closed (open since %s)", pr.CreatedAt.Format("2006-01-02")))
+		}
+	case "Recreated":
+		if err := provider.Recreate(runCtx, owner, repo, pr.Number); err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("failed to recreate (conflicts): %v", err))
+		} else {
+			r.Details = append(r.Details, "recreated (conflicts)")
+		}
+	case "Rebased":
+		if err := provider.Rebase(runCtx, owner, repo, pr.Number); err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("failed to rebase: %v", err))
+		} else {
+			r.Details = append(r.Details, "rebased")
+		}
+	}
+	return r
+}
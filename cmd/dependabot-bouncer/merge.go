@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/viper"
+)
+
+// How approve merges a PR after reviewing it, chosen with merge_mode or
+// --merge-method.
+const (
+	mergeAuto    = "auto"    // enable auto-merge and let the host merge once checks pass
+	mergeAPI     = "api"     // merge right away through the API
+	mergeComment = "comment" // ask Dependabot to merge once checks pass
+)
+
+// mergePolicy is how an approved PR gets merged.
+type mergePolicy struct {
+	Mode   string // mergeAuto, mergeAPI, or mergeComment
+	Method string // bouncer.MergeSquash, bouncer.MergeMerge, or bouncer.MergeRebase; empty for the repository's preferred method
+	// ReviewMerges is set when the review itself asks Dependabot to merge,
+	// so no merge comment is needed where auto-merge is disabled.
+	ReviewMerges bool
+}
+
+// buildMergePolicy reads merge_mode and merge_method from config; the policy
+// group and repo-specific settings override the global ones, and
+// --merge-method overrides merge_mode. merge_method applies to every mode.
+func buildMergePolicy(repoKey string) (mergePolicy, error) {
+	p := mergePolicy{Mode: mergeAuto}
+	source := "merge_mode"
+	for _, prefix := range settingPrefixes(repoKey) {
+		if m := viper.GetString(prefix + "merge_mode"); m != "" {
+			p.Mode = strings.ToLower(m)
+		}
+		if m := viper.GetString(prefix + "merge_method"); m != "" {
+			p.Method = strings.ToLower(m)
+		}
+	}
+	if viper.IsSet("merge-method") {
+		p.Mode, source = strings.ToLower(viper.GetString("merge-method")), "--merge-method"
+	}
+	switch p.Mode {
+	case mergeAuto, mergeAPI, mergeComment:
+	default:
+		return mergePolicy{}, fmt.Errorf("invalid %s %q for %s (expected %q, %q, or %q)", source, p.Mode, repoKey, mergeAuto, mergeAPI, mergeComment)
+	}
+
+	switch p.Method {
+	case "", bouncer.MergeSquash, bouncer.MergeMerge, bouncer.MergeRebase:
+	default:
+		return mergePolicy{}, fmt.Errorf("invalid merge_method %q for %s (expected %q, %q, or %q)", p.Method, repoKey, bouncer.MergeSquash, bouncer.MergeMerge, bouncer.MergeRebase)
+	}
+	return p, nil
+}
+
+// dependabotCommand is the comment asking Dependabot to merge a PR once its
+// checks pass, for repositories without auto-merge. Dependabot can squash or
+// create a merge commit but not rebase.
+func (p mergePolicy) dependabotCommand() string {
+	if p.Method == "" || p.Method == bouncer.MergeSquash {
+		return "@dependabot squash and merge"
+	}
+	return "@dependabot merge"
+}
+
+// apply merges an approved PR, or enables auto-merge on it, recording the
+// outcome into the result. Where the repository has auto-merge disabled, or
+// in comment mode, Dependabot is asked to merge the PR instead. In API mode
+// only PRs whose checks all pass and whose branch is not being rebased or
+// recreated are merged; the others are left for a later run.
+func (p mergePolicy) apply(provider bouncer.Client, owner, repo string, pr bouncer.PRInfo, r *prResult) {
+	switch p.Mode {
+	case mergeComment:
+		p.askDependabot(provider, owner, repo, pr, r, "")
+		return
+	case mergeAuto:
+		err := provider.EnableAutoMerge(runCtx, owner, repo, pr.Number, p.Method)
+		switch {
+		case err == nil:
+			r.Details = append(r.Details, "auto-merge enabled")
+		case errors.Is(err, bouncer.ErrAutoMergeDisabled):
+			p.askDependabot(provider, owner, repo, pr, r, "auto-merge disabled, ")
+		default:
+			r.Errors = append(r.Errors, fmt.Sprintf("failed to enable auto-merge: %v", err))
+		}
+		return
+	}
+
+	switch {
+	case pr.MergeStateStatus == "DIRTY" || pr.MergeStateStatus == "BEHIND":
+		r.Details = append(r.Details, "not merged: branch is being updated")
+	case pr.CIStatus != "success":
+		r.Details = append(r.Details, "not merged: checks not passing")
+	default:
+		if err := provider.Merge(runCtx, owner, repo, pr.Number, p.Method); err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("failed to merge: %v", err))
+		} else {
+			r.Details = append(r.Details, "merged")
+		}
+	}
+}
+
+// askDependabot comments the Dependabot merge command on a PR, unless the
+// review already asked Dependabot to merge it. why prefixes the outcome
+// recorded into the result.
+func (p mergePolicy) askDependabot(provider bouncer.Client, owner, repo string, pr bouncer.PRInfo, r *prResult, why string) {
+	if p.ReviewMerges {
+		r.Details = append(r.Details, why+"left to Dependabot")
+		return
+	}
+	if err := provider.Comment(runCtx, owner, repo, pr.Number, p.dependabotCommand()); err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("%sfailed to ask Dependabot to merge: %v", why, err))
+		return
+	}
+	r.Details = append(r.Details, why+"asked Dependabot to merge")
+}
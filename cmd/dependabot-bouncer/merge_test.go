@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer/bouncertest"
+	"github.com/spf13/viper"
+)
+
+func TestBuildMergePolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		settings   map[string]any
+		wantMode   string
+		wantMethod string
+		wantErr    bool
+	}{
+		{name: "default", wantMode: mergeAuto},
+		{
+			name:       "global",
+			settings:   map[string]any{"global.merge_mode": "api", "global.merge_method": "squash"},
+			wantMode:   mergeAPI,
+			wantMethod: bouncer.MergeSquash,
+		},
+		{
+			name: "repository overrides global and group",
+			settings: map[string]any{
+				"global.merge_mode":                  "api",
+				"policies.backend.merge_mode":        "auto",
+				"policies.backend.merge_method":      "rebase",
+				"repositories.acme/api.policy":       "backend",
+				"repositories.acme/api.merge_mode":   "Comment",
+				"repositories.acme/api.merge_method": "merge",
+			},
+			wantMode:   mergeComment,
+			wantMethod: bouncer.MergeMerge,
+		},
+		{
+			name:     "flag overrides config",
+			settings: map[string]any{"repositories.acme/api.merge_mode": "comment", "merge-method": "api"},
+			wantMode: mergeAPI,
+		},
+		{name: "invalid mode", settings: map[string]any{"global.merge_mode": "later"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(viper.Reset)
+			for k, v := range tt.settings {
+				viper.Set(k, v)
+			}
+			p, err := buildMergePolicy("acme/api")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildMergePolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if p.Mode != tt.wantMode || p.Method != tt.wantMethod {
+				t.Errorf("buildMergePolicy() = %s/%q, want %s/%q", p.Mode, p.Method, tt.wantMode, tt.wantMethod)
+			}
+		})
+	}
+}
+
+func TestMergePolicyApplyComment(t *testing.T) {
+	fake := bouncertest.NewFake()
+	pr := bouncer.PRInfo{Number: 1, Title: "Bump lodash from 4.17.20 to 4.17.21", CIStatus: "success"}
+	fake.AddPR("acme", "api", "dependabot/npm_and_yarn/lodash-4.17.21", pr)
+
+	var r prResult
+	mergePolicy{Mode: mergeComment}.apply(fake, "acme", "api", pr, &r)
+
+	calls := fake.Calls()
+	if len(calls) != 1 || calls[0].Method != "Comment" || calls[0].Arg != "@dependabot squash and merge" {
+		t.Errorf("calls = %+v, want one Dependabot merge comment", calls)
+	}
+	if len(r.Errors) > 0 {
+		t.Errorf("errors = %v", r.Errors)
+	}
+}
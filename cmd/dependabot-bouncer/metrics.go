@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/metrics"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+// runMetrics counts what watch and serve do, for their /metrics endpoint.
+var runMetrics = newBouncerMetrics()
+
+// bouncerMetrics are the counters exposed at /metrics.
+type bouncerMetrics struct {
+	registry      *metrics.Registry
+	approved      *metrics.Counter
+	recreated     *metrics.Counter
+	skippedByDeny *metrics.Counter
+	failing       *metrics.Counter
+	apiErrors     *metrics.Counter
+}
+
+func newBouncerMetrics() *bouncerMetrics {
+	r := metrics.NewRegistry()
+	m := &bouncerMetrics{
+		registry:      r,
+		approved:      r.Counter("dependabot_bouncer_prs_approved_total", "Pull requests approved.", "repository", "org"),
+		recreated:     r.Counter("dependabot_bouncer_prs_recreated_total", "Pull requests recreated, including those recreated for conflicts before approval.", "repository", "org"),
+		skippedByDeny: r.Counter("dependabot_bouncer_prs_skipped_by_deny_total", "Pull requests skipped by the allow and deny lists or an update-type rule, by skip code.", "repository", "org", "reason"),
+		failing:       r.Counter("dependabot_bouncer_prs_failing_total", "Pull requests left alone because their checks failed, counted each time a run finds them.", "repository"),
+		apiErrors:     r.Counter("dependabot_bouncer_api_errors_total", "API calls that failed.", "repository"),
+	}
+	r.OnScrape(m.collectAPIErrors)
+	return m
+}
+
+// record adds the outcome of processing one repository.
+func (m *bouncerMetrics) record(repoKey string, r *repoResults) {
+	for _, pr := range r.PRs {
+		if pr.NewlyApproved {
+			m.approved.Inc(repoKey, pr.Org)
+		}
+		if len(pr.Errors) == 0 && (pr.Action == "Recreated" || slices.Contains(pr.Details, "recreated (conflicts)")) {
+			m.recreated.Inc(repoKey, pr.Org)
+		}
+	}
+	for _, pr := range r.PolicySkipped {
+		if isDenial(pr.SkipCode) {
+			m.skippedByDeny.Inc(repoKey, pr.OrgName, pr.SkipCode)
+		}
+	}
+	if r.Stats != nil && r.Stats.Failing > 0 {
+		m.failing.Add(float64(r.Stats.Failing), repoKey)
+	}
+}
+
+// collectAPIErrors mirrors the failed API calls counted by the bouncer package.
+func (m *bouncerMetrics) collectAPIErrors() {
+	for repo, c := range bouncer.AllCalls() {
+		if c.Errors > 0 {
+			m.apiErrors.Set(float64(c.Errors), repo)
+		}
+	}
+}
+
+// serveMetrics serves /metrics on addr in the background and returns the
+// server, or nil when addr is empty.
+func serveMetrics(addr string) *http.Server {
+	if addr == "" {
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", runMetrics.registry)
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		log.Printf("Serving metrics on %s/metrics\n", addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Warning: metrics server: %v\n", err)
+		}
+	}()
+	return srv
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/registry"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/viper"
+)
+
+// releases looks up publish times for min_age; they never change, so the
+// cache is kept for the life of the process.
+var releases = registry.NewClient()
+
+// minAge returns the min_age of a repository: its own setting, or else its
+// policy groups', or else the global one. Zero means no cooling-off period.
+func minAge(repoKey string) (time.Duration, error) {
+	value := ""
+	for _, prefix := range settingPrefixes(repoKey) {
+		if key := prefix + "min_age"; viper.IsSet(key) {
+			value = viper.GetString(key)
+		}
+	}
+	if value == "" {
+		return 0, nil
+	}
+	d, err := parseAge(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid min_age for %s: %w", repoKey, err)
+	}
+	return d, nil
+}
+
+// gateMinAge moves the PRs whose new version is younger than min_age from
+// prs to out.PolicySkipped, with skip code TOO_NEW, and returns the rest.
+// A version's age is counted from its publish time in the package registry
+// when it can be looked up, and from the PR's creation otherwise. Security
+// updates and trusted packages are not held back.
+func gateMinAge(owner, repo string, prs []bouncer.PRInfo, stats *bouncer.ListStats, out *repoResults, now time.Time) ([]bouncer.PRInfo, error) {
+	age, err := minAge(owner + "/" + repo)
+	if err != nil || age == 0 {
+		return prs, err
+	}
+
+	var kept []bouncer.PRInfo
+	for _, pr := range prs {
+		if pr.Security || pr.Trusted {
+			kept = append(kept, pr)
+			continue
+		}
+		since, what := pr.CreatedAt, "opened"
+		published, ok, err := releases.PublishedAt(pr.Ecosystem, pr.PackageName, pr.ToVersion)
+		if err != nil {
+			log.Printf("Warning: %v; using the creation time of PR #%d\n", err, pr.Number)
+		} else if ok {
+			since, what = published, pr.ToVersion+" published"
+		}
+		if now.Sub(since) >= age {
+			kept = append(kept, pr)
+			continue
+		}
+		reason := fmt.Sprintf("%s %s ago, min_age is %s", what, formatAge(now.Sub(since)), formatAge(age))
+		skipListed(pr, bouncer.SkipTooNew, reason, stats, out)
+	}
+	return kept, nil
+}
+
+// formatAge renders an age in whole days, or hours below two days.
+func formatAge(d time.Duration) string {
+	if d >= 48*time.Hour {
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+	return fmt.Sprintf("%dh", int(d/time.Hour))
+}
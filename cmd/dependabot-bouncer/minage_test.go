@@ -0,0 +1,42 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/viper"
+)
+
+func TestGateMinAge(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("global.min_age", "3d")
+	now := time.Date(2026, 6, 10, 0, 0, 0, 0, time.UTC)
+	pr := func(n int, age time.Duration) bouncer.PRInfo {
+		return bouncer.PRInfo{Number: n, PackageName: "lodash", CreatedAt: now.Add(-age)}
+	}
+
+	security := pr(2, time.Hour)
+	security.Security = true
+	trusted := pr(3, time.Hour)
+	trusted.Trusted = true
+	prs := []bouncer.PRInfo{pr(1, 4*24*time.Hour), security, trusted, pr(4, time.Hour)}
+
+	var out repoResults
+	var stats bouncer.ListStats
+	kept, err := gateMinAge("acme", "api", prs, &stats, &out, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int
+	for _, pr := range kept {
+		got = append(got, pr.Number)
+	}
+	if want := []int{1, 2, 3}; !slices.Equal(got, want) {
+		t.Errorf("kept PRs = %v, want %v", got, want)
+	}
+	if len(out.PolicySkipped) != 1 || out.PolicySkipped[0].Number != 4 || out.PolicySkipped[0].SkipCode != bouncer.SkipTooNew {
+		t.Errorf("skipped = %+v, want #4 as TOO_NEW", out.PolicySkipped)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/notify"
+	"github.com/promiseofcake/dependabot-bouncer/internal/risk"
+	"github.com/spf13/viper"
+)
+
+// Notification backends, in the order a run posts to them.
+var notifyKinds = []string{"slack", "teams", "discord", "webhook"}
+
+// notifyRoute is one destination of a repository's part of the run summary.
+type notifyRoute struct {
+	Kind    string // slack, teams, discord, or webhook
+	URL     string
+	Channel string            // slack only
+	Headers map[string]string // webhook only
+}
+
+// key identifies the destination, so repositories routed to the same one
+// share a message.
+func (r notifyRoute) key() string {
+	names := slices.Sorted(maps.Keys(r.Headers))
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\x00%s\x00%s", r.Kind, r.URL, r.Channel)
+	for _, name := range names {
+		fmt.Fprintf(&b, "\x00%s=%s", name, r.Headers[name])
+	}
+	return b.String()
+}
+
+// notifyBackend is a destination of run summaries and documents.
+type notifyBackend interface {
+	notify.Notifier
+	notify.Poster
+}
+
+// notifier returns the notifier posting to the destination.
+func (r notifyRoute) notifier() (notifyBackend, error) {
+	switch r.Kind {
+	case "slack":
+		return notify.NewSlack(r.URL, r.Channel)
+	case "teams":
+		return notify.NewTeams(r.URL)
+	case "discord":
+		return notify.NewDiscord(r.URL)
+	default:
+		return notify.NewWebhook(r.URL, r.Headers)
+	}
+}
+
+// notifyRoutesFor returns the destinations of a repository from
+// notifications.slack, .teams, .discord, and .webhook; the repository's own
+// notifications settings override the global ones. A backend without a URL
+// is not posted to.
+func notifyRoutesFor(repoKey string) []notifyRoute {
+	var routes []notifyRoute
+	for _, kind := range notifyKinds {
+		r := notifyRoute{Kind: kind}
+		urlKey := "webhook_url"
+		if kind == "webhook" {
+			urlKey = "url"
+		}
+		for _, prefix := range []string{"notifications." + kind + ".", "repositories." + repoKey + ".notifications." + kind + "."} {
+			if u := viper.GetString(prefix + urlKey); u != "" {
+				r.URL = u
+			}
+			if c := viper.GetString(prefix + "channel"); c != "" && kind == "slack" {
+				r.Channel = c
+			}
+			if h := viper.GetStringMapString(prefix + "headers"); len(h) > 0 && kind == "webhook" {
+				if r.Headers == nil {
+					r.Headers = make(map[string]string)
+				}
+				maps.Copy(r.Headers, h)
+			}
+		}
+		if r.URL != "" {
+			routes = append(routes, r)
+		}
+	}
+	return routes
+}
+
+// notifyRoutesForRepos returns the destinations of any of repos, each once,
+// in the order the repositories name them.
+func notifyRoutesForRepos(repos []string) []notifyRoute {
+	seen := make(map[string]bool)
+	var routes []notifyRoute
+	for _, repoKey := range repos {
+		for _, route := range notifyRoutesFor(repoKey) {
+			if key := route.key(); !seen[key] {
+				seen[key] = true
+				routes = append(routes, route)
+			}
+		}
+	}
+	return routes
+}
+
+// notifyRun posts a summary of the run to each configured destination, one
+// message per destination with the repositories routed there. With quiet,
+// destinations whose repositories saw no action and no failure are not posted
+// to. Delivery failures are logged and never fail the run.
+func notifyRun(command string, rr *runResults, quiet bool) {
+	routes := make(map[string]notifyRoute)
+	summaries := make(map[string]*notify.Summary)
+	var order []string
+	for _, repoKey := range rr.order {
+		for _, route := range notifyRoutesFor(repoKey) {
+			key := route.key()
+			sum, ok := summaries[key]
+			if !ok {
+				sum = &notify.Summary{Command: command}
+				summaries[key], routes[key] = sum, route
+				order = append(order, key)
+			}
+			sum.Repos = append(sum.Repos, repoSummary(repoKey, rr.byRepo[repoKey]))
+		}
+	}
+
+	for _, key := range order {
+		sum := summaries[key]
+		if rr.risk != nil {
+			sum.Risk = notifyRisk(rr.risk.For(summaryRepos(sum)))
+		}
+		if quiet && sum.Empty() {
+			continue
+		}
+		n, err := routes[key].notifier()
+		if err != nil {
+			log.Printf("Warning: %v\n", err)
+			continue
+		}
+		if err := n.Notify(*sum); err != nil {
+			log.Printf("Warning: failed to notify %s: %v\n", n, err)
+		}
+	}
+}
+
+// summaryRepos returns the names of the repositories in a summary.
+func summaryRepos(sum *notify.Summary) []string {
+	repos := make([]string, len(sum.Repos))
+	for i, r := range sum.Repos {
+		repos[i] = r.Name
+	}
+	return repos
+}
+
+// notifyRisk converts a risk summary for a notification, or returns nil when
+// nothing was approved.
+func notifyRisk(s *risk.Summary) *notify.Risk {
+	if s.Approved == 0 {
+		return nil
+	}
+	return &notify.Risk{Level: s.Level(), Major: s.Major, Unknown: s.Unknown, Security: s.Security, LowScore: s.LowScore}
+}
+
+// repoSummary condenses the results of one repository for a notification.
+func repoSummary(repoKey string, r *repoResults) notify.Repo {
+	out := notify.Repo{Name: repoKey, Actions: make(map[string]int), Skipped: make(map[string]int)}
+	for _, pr := range r.PRs {
+		if len(pr.Errors) > 0 {
+			out.Failures = append(out.Failures, notify.Failure{
+				Number: pr.Number,
+				Title:  pr.Title,
+				URL:    pr.URL,
+				Error:  strings.Join(pr.Errors, "; "),
+			})
+			continue
+		}
+		out.Actions[decisionName(pr.Action)]++
+	}
+	if s := r.Stats; s != nil {
+		for code, n := range s.Skipped {
+			out.Skipped[code] += n
+		}
+		if s.Failing > 0 {
+			out.Skipped["failing checks"] += s.Failing
+		}
+		if s.Pending > 0 {
+			out.Skipped["pending checks"] += s.Pending
+		}
+	}
+	if r.Err != nil && len(r.PRs) == 0 {
+		out.Error = r.Err.Error()
+	}
+	return out
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+)
+
+// Output formats accepted by --output.
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+// addOutputFlag registers --output on a command that can emit structured results.
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().String("output", outputText, "Output format: text, json, or yaml")
+}
+
+// outputFormat returns the validated --output format. check's --json flag is
+// shorthand for --output json.
+func outputFormat(cmd *cobra.Command) (string, error) {
+	format, _ := cmd.Flags().GetString("output")
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		format = outputJSON
+	}
+	switch format {
+	case outputText, outputJSON, outputYAML:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported output format %q (use text, json, or yaml)", format)
+	}
+}
+
+// outputWriters returns where a command writes the structured document of
+// its results and the progress meant for people. For the json and yaml
+// formats, progress goes to stderr so stdout holds nothing but the document.
+func outputWriters(format string) (doc, human io.Writer) {
+	if format != outputText {
+		return os.Stdout, os.Stderr
+	}
+	return os.Stdout, os.Stdout
+}
+
+// writeDocument encodes v to w as indented JSON or as YAML.
+func writeDocument(w io.Writer, format string, v any) error {
+	if format == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	// Round-trip through JSON so the YAML keys follow the json tags.
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(generic); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// prDocument is the structured result of acting on one PR.
+type prDocument struct {
+	Number   int      `json:"number"`
+	Title    string   `json:"title"`
+	URL      string   `json:"url,omitempty"`
+	Package  string   `json:"package,omitempty"`
+	Decision string   `json:"decision"` // approved, skipped, recreated, denied, rebased, closed, ignored, undone
+	Actions  []string `json:"actions"`  // what was done, e.g. "rebased", "auto-merge enabled"
+	Errors   []string `json:"errors,omitempty"`
+	Success  bool     `json:"success"`
+}
+
+// repoDocument is the structured result of approve or recreate for one
+// repository.
+type repoDocument struct {
+	Repository   string             `json:"repository"`
+	Error        string             `json:"error,omitempty"`
+	Skipped      string             `json:"skipped,omitempty"`      // why the repository was skipped
+	NotEligible  *bouncer.ListStats `json:"not_eligible,omitempty"` // why no PR was eligible
+	PullRequests []prDocument       `json:"pull_requests"`
+}
+
+// resultsDocument converts run results into their structured form, one entry
+// per repository in processing order.
+func resultsDocument(rr *runResults) []repoDocument {
+	out := make([]repoDocument, 0, len(rr.order))
+	for _, repoKey := range rr.order {
+		r := rr.byRepo[repoKey]
+		entry := repoDocument{
+			Repository:   repoKey,
+			Skipped:      r.Skipped,
+			PullRequests: make([]prDocument, 0, len(r.PRs)),
+		}
+		if len(r.PRs) == 0 && r.Skipped == "" {
+			entry.NotEligible = r.Stats
+		}
+		if r.Err != nil && len(r.PRs) == 0 {
+			// PR failures are reported on each PR; only surface repository
+			// level errors here.
+			entry.Error = r.Err.Error()
+		}
+		for _, pr := range r.PRs {
+			actions := pr.Details
+			if actions == nil {
+				actions = []string{}
+			}
+			entry.PullRequests = append(entry.PullRequests, prDocument{
+				Number:   pr.Number,
+				Title:    pr.Title,
+				URL:      pr.URL,
+				Package:  pr.Package,
+				Decision: decisionName(pr.Action),
+				Actions:  actions,
+				Errors:   pr.Errors,
+				Success:  len(pr.Errors) == 0,
+			})
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// decisionName returns the lower-case decision for a prResult action.
+func decisionName(action string) string {
+	switch action {
+	case "Approved":
+		return "approved"
+	case "Recreated":
+		return "recreated"
+	case "Denied":
+		return "denied"
+	case "Rebased":
+		return "rebased"
+	case "Closed":
+		return "closed"
+	case "Ignored":
+		return "ignored"
+	case "Undone":
+		return "undone"
+	default:
+		return "skipped"
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestOutputWriters(t *testing.T) {
+	stdout := os.Stdout
+	for _, tt := range []struct {
+		format string
+		human  *os.File
+	}{
+		{outputText, os.Stdout},
+		{outputJSON, os.Stderr},
+		{outputYAML, os.Stderr},
+	} {
+		doc, human := outputWriters(tt.format)
+		if doc != os.Stdout || human != tt.human {
+			t.Errorf("outputWriters(%s) = %v, %v", tt.format, doc, human)
+		}
+		if os.Stdout != stdout {
+			t.Fatalf("outputWriters(%s) replaced os.Stdout", tt.format)
+		}
+	}
+}
+
+func TestWriteDocument(t *testing.T) {
+	rr := newRunResults()
+	rr.add("acme/api", prResult{Number: 1, Title: "Bump lodash", Package: "lodash", Action: "Approved", Details: []string{"approved"}})
+	rr.add("acme/api", prResult{Number: 2, Title: "Bump vue", Action: "Recreated", Errors: []string{"failed to recreate: boom"}})
+	rr.repo("acme/web").Err = errors.New("not found")
+	doc := resultsDocument(rr)
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: outputJSON,
+			want: `[
+  {
+    "repository": "acme/api",
+    "pull_requests": [
+      {
+        "number": 1,
+        "title": "Bump lodash",
+        "package": "lodash",
+        "decision": "approved",
+        "actions": [
+          "approved"
+        ],
+        "success": true
+      },
+      {
+        "number": 2,
+        "title": "Bump vue",
+        "decision": "recreated",
+        "actions": [],
+        "errors": [
+          "failed to recreate: boom"
+        ],
+        "success": false
+      }
+    ]
+  },
+  {
+    "repository": "acme/web",
+    "error": "not found",
+    "pull_requests": []
+  }
+]
+`,
+		},
+		{
+			format: outputYAML,
+			want: `- pull_requests:
+    - actions:
+        - approved
+      decision: approved
+      number: 1
+      package: lodash
+      success: true
+      title: Bump lodash
+    - actions: []
+      decision: recreated
+      errors:
+        - 'failed to recreate: boom'
+      number: 2
+      success: false
+      title: Bump vue
+  repository: acme/api
+- error: not found
+  pull_requests: []
+  repository: acme/web
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeDocument(&buf, tt.format, doc); err != nil {
+				t.Fatalf("writeDocument() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("writeDocument() =\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// defaultConcurrency is how many PRs of a repository are acted on at once.
+const defaultConcurrency = 4
+
+// concurrency returns the number of PRs to act on at once: --concurrency,
+// else global.concurrency, else defaultConcurrency.
+func concurrency(cmd *cobra.Command) int {
+	n := defaultConcurrency
+	if viper.IsSet("global.concurrency") {
+		n = viper.GetInt("global.concurrency")
+	}
+	if f := cmd.Flags().Lookup("concurrency"); f != nil && f.Changed {
+		n, _ = cmd.Flags().GetInt("concurrency")
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// repoConcurrency returns the number of repositories to process at once:
+// --repo-concurrency, else global.repo_concurrency, else 1.
+func repoConcurrency(cmd *cobra.Command) int {
+	n := 1
+	if viper.IsSet("global.repo_concurrency") {
+		n = viper.GetInt("global.repo_concurrency")
+	}
+	if f := cmd.Flags().Lookup("repo-concurrency"); f != nil && f.Changed {
+		n, _ = cmd.Flags().GetInt("repo-concurrency")
+	}
+	return max(n, 1)
+}
+
+// runOrdered calls work for 0..n-1 on up to workers goroutines, and calls
+// done for each index in order as soon as its work and that of every earlier
+// index has finished. done runs on the calling goroutine, so logging and
+// result collection stay deterministic whatever order the work completes in.
+// Fewer than one worker counts as one.
+func runOrdered(n, workers int, work func(i int), done func(i int)) {
+	finished := make([]chan struct{}, n)
+	for i := range finished {
+		finished[i] = make(chan struct{})
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(max(workers, 1), n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				work(i)
+				close(finished[i])
+			}
+		}()
+	}
+	go func() {
+		for i := 0; i < n; i++ {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+
+	for i := 0; i < n; i++ {
+		<-finished[i]
+		done(i)
+	}
+	wg.Wait()
+}
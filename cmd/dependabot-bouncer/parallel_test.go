@@ -0,0 +1,63 @@
+package main
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunOrdered(t *testing.T) {
+	tests := []struct {
+		name        string
+		n, workers  int
+		wantMaxBusy int // most items worked on at once
+	}{
+		{name: "more items than workers", n: 8, workers: 3, wantMaxBusy: 3},
+		{name: "more workers than items", n: 2, workers: 5, wantMaxBusy: 2},
+		{name: "one worker", n: 4, workers: 1, wantMaxBusy: 1},
+		{name: "no workers", n: 4, workers: 0, wantMaxBusy: 1},
+		{name: "no items", n: 0, workers: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var busy, maxBusy int
+			var worked, done []int
+			runOrdered(tt.n, tt.workers, func(i int) {
+				mu.Lock()
+				busy++
+				maxBusy = max(maxBusy, busy)
+				mu.Unlock()
+				// Later items finish first, so done must wait for earlier ones.
+				time.Sleep(time.Duration(tt.n-i) * time.Millisecond)
+				mu.Lock()
+				busy--
+				worked = append(worked, i)
+				mu.Unlock()
+			}, func(i int) {
+				mu.Lock()
+				defer mu.Unlock()
+				if !slices.Contains(worked, i) {
+					t.Errorf("done(%d) called before its work finished", i)
+				}
+				done = append(done, i)
+			})
+
+			want := make([]int, tt.n)
+			for i := range want {
+				want[i] = i
+			}
+			if !slices.Equal(done, want) {
+				t.Errorf("done order = %v, want %v", done, want)
+			}
+			if len(worked) != tt.n {
+				t.Errorf("work called %d times, want %d", len(worked), tt.n)
+			}
+			if maxBusy > tt.wantMaxBusy {
+				t.Errorf("%d items worked on at once, want at most %d", maxBusy, tt.wantMaxBusy)
+			}
+		})
+	}
+}
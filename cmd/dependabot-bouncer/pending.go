@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/viper"
+)
+
+// pendingPollInterval is how often approve --wait-pending looks at the
+// checks of the PRs it is waiting for.
+const pendingPollInterval = 30 * time.Second
+
+// splitPending separates the PRs whose checks passed from the ones whose
+// checks are still running.
+func splitPending(prs []bouncer.PRInfo) (passing, pending []bouncer.PRInfo) {
+	for _, pr := range prs {
+		if pr.CIStatus == "success" {
+			passing = append(passing, pr)
+		} else {
+			pending = append(pending, pr)
+		}
+	}
+	return passing, pending
+}
+
+// waitForPending polls the pending PRs until their checks finish or wait
+// elapses, and returns the ones whose checks passed, in their original
+// order. PRs whose checks failed or are still running at the deadline are
+// added to stats as failing or pending, like the PRs dropped by the listing.
+// PRs closed in the meantime are forgotten.
+func waitForPending(provider bouncer.Client, owner, repo string, pending []bouncer.PRInfo, wait time.Duration, stats *bouncer.ListStats) []bouncer.PRInfo {
+	deadline := time.Now().Add(wait)
+	var passed []bouncer.PRInfo
+	for len(pending) > 0 {
+		left := time.Until(deadline)
+		if left <= 0 {
+			for _, pr := range pending {
+				log.Printf("Skipping PR #%d: checks still pending after %s\n", pr.Number, wait)
+			}
+			stats.Pending += len(pending)
+			break
+		}
+		fmt.Printf("Waiting for checks on %d pull requests...\n", len(pending))
+		time.Sleep(min(pendingPollInterval, left))
+
+		current, err := refreshPRs(provider, owner, repo, pending)
+		if err != nil {
+			log.Printf("Warning: failed to refresh pending PRs: %v\n", err)
+			continue
+		}
+		pending = pending[:0]
+		for _, pr := range current {
+			switch pr.CIStatus {
+			case "success":
+				passed = append(passed, pr)
+			case "failure":
+				log.Printf("Skipping PR #%d: checks failed\n", pr.Number)
+				stats.Failing++
+			default:
+				pending = append(pending, pr)
+			}
+		}
+	}
+	return passed
+}
+
+// refreshPRs fetches prs again with their current CI status, applying the
+// repository's policy as when they were listed.
+func refreshPRs(provider bouncer.Client, owner, repo string, prs []bouncer.PRInfo) ([]bouncer.PRInfo, error) {
+	q, err := buildQuery(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	q.Numbers = make([]int, len(prs))
+	for i, pr := range prs {
+		q.Numbers[i] = pr.Number
+	}
+	q.IncludeDrafts = viper.GetBool("include-drafts") || viper.GetBool("mark-ready")
+	q.CIFilter = bouncer.CIAny
+	return provider.List(runCtx, q, false)
+}
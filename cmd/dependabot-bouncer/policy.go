@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// policyDiffExitChanged is the exit code of policy diff --exit-code when the
+// new config decides a PR differently.
+const policyDiffExitChanged = 2
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Inspect the effect of policy changes",
+}
+
+var policyDiffCmd = &cobra.Command{
+	Use:   "diff --old old.yaml --new new.yaml [owner/repo...]",
+	Short: "Show the open PRs two configs decide differently",
+	Long: `Evaluate the open PRs under two config files and list the ones whose outcome
+would change, so deny-list edits can be reviewed in the pull request that
+makes them. The outcome of a PR is the skip code it gets from the allow,
+deny, and update-type lists and ignored_prs, or "processed" when it passes
+them. CI status, drafts, and snoozes are the same under both configs and are
+not compared.
+
+PRs are listed once, with the new config's provider and bot settings. If no
+repositories are specified as arguments or with --org, the repositories and
+organizations of the new config are used.
+
+With --exit-code, exits with 2 when any PR would change outcome.`,
+	Example: `  git show main:config.yaml > /tmp/old.yaml
+  dependabot-bouncer policy diff --old /tmp/old.yaml --new config.yaml --exit-code`,
+	RunE: runPolicyDiff,
+}
+
+// policyChange is a PR whose outcome differs between the two configs.
+type policyChange struct {
+	Repo      string `json:"repo"`
+	Number    int    `json:"number"`
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	Package   string `json:"package,omitempty"`
+	Old       string `json:"old"`
+	OldReason string `json:"old_reason,omitempty"`
+	New       string `json:"new"`
+	NewReason string `json:"new_reason,omitempty"`
+}
+
+// policyDiff is the document policy diff prints.
+type policyDiff struct {
+	Repositories int            `json:"repositories"`
+	PRs          int            `json:"prs"`
+	Changes      []policyChange `json:"changes"`
+}
+
+// outcomeProcessed is the outcome of a PR that passes the policy.
+const outcomeProcessed = "processed"
+
+func runPolicyDiff(cmd *cobra.Command, args []string) error {
+	oldPath, _ := cmd.Flags().GetString("old")
+	newPath, _ := cmd.Flags().GetString("new")
+	exitCode, _ := cmd.Flags().GetBool("exit-code")
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	// Both files must load before anything is listed.
+	if err := useConfig(oldPath); err != nil {
+		return err
+	}
+	if err := useConfig(newPath); err != nil {
+		return err
+	}
+	repos, err := resolveRepos(cmd, args, true)
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories specified. Use command-line arguments, --org, or configure repositories in the new config file")
+	}
+
+	diff := policyDiff{Changes: []policyChange{}}
+	for _, repoKey := range repos {
+		owner, repo, err := parseRepo(repoKey)
+		if err != nil {
+			return err
+		}
+		log.Printf("Comparing %s...\n", repoKey)
+		err = withRepoTimeout(owner, repo, func() error {
+			changes, listed, err := diffRepoPolicy(owner, repo, oldPath, newPath)
+			diff.Changes = append(diff.Changes, changes...)
+			diff.PRs += listed
+			return err
+		})
+		if err != nil {
+			if len(repos) == 1 {
+				return err
+			}
+			log.Printf("Warning: %s: %v\n", repoKey, err)
+			continue
+		}
+		diff.Repositories++
+	}
+
+	if format != outputText {
+		if err := writeDocument(cmd.OutOrStdout(), format, diff); err != nil {
+			return err
+		}
+	} else {
+		printPolicyDiff(diff)
+	}
+	if exitCode && len(diff.Changes) > 0 {
+		return &exitError{code: policyDiffExitChanged, msg: fmt.Sprintf("%d PR(s) would change outcome", len(diff.Changes))}
+	}
+	return nil
+}
+
+// useConfig replaces the loaded config with the file at path. Flags and
+// environment variables keep overriding it.
+func useConfig(path string) error {
+	viper.SetConfigFile(path)
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	return nil
+}
+
+// diffRepoPolicy lists a repository's open PRs under the new config and
+// returns those the two configs decide differently, and how many were
+// listed. It leaves the new config loaded.
+func diffRepoPolicy(owner, repo, oldPath, newPath string) ([]policyChange, int, error) {
+	if err := useConfig(oldPath); err != nil {
+		return nil, 0, err
+	}
+	oldQuery, err := policyQuery(owner, repo)
+	if err != nil {
+		return nil, 0, fmt.Errorf("old config: %w", err)
+	}
+	if err := useConfig(newPath); err != nil {
+		return nil, 0, err
+	}
+	newQuery, err := policyQuery(owner, repo)
+	if err != nil {
+		return nil, 0, fmt.Errorf("new config: %w", err)
+	}
+
+	provider, err := providerFor(owner, repo)
+	if err != nil {
+		return nil, 0, err
+	}
+	q := newQuery
+	q.IgnoredPRs = nil
+	q.IncludeSkipped = true
+	q.IncludeDrafts = true
+	q.CIFilter = bouncer.CIAny
+	prs, err := provider.List(runCtx, q, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var changes []policyChange
+	for _, pr := range prs {
+		oldOutcome, oldReason := policyOutcome(pr, oldQuery)
+		newOutcome, newReason := policyOutcome(pr, newQuery)
+		if oldOutcome == newOutcome {
+			continue
+		}
+		changes = append(changes, policyChange{
+			Repo:      owner + "/" + repo,
+			Number:    pr.Number,
+			Title:     pr.Title,
+			URL:       pr.URL,
+			Package:   pr.PackageName,
+			Old:       oldOutcome,
+			OldReason: oldReason,
+			New:       newOutcome,
+			NewReason: newReason,
+		})
+	}
+	return changes, len(prs), nil
+}
+
+// policyQuery builds the query of the loaded config for a repository, with
+// its ignored_prs and the lists given on the command line.
+func policyQuery(owner, repo string) (bouncer.DependencyUpdateQuery, error) {
+	q, err := buildQuery(owner, repo)
+	if err != nil {
+		return q, err
+	}
+	q.IgnoredPRs = getIntSlice("repositories." + owner + "/" + repo + ".ignored_prs")
+	applyPolicyFlags(&q)
+	return q, nil
+}
+
+// policyOutcome returns the skip code a query gives a PR and its reason, or
+// outcomeProcessed when the PR passes.
+func policyOutcome(pr bouncer.PRInfo, q bouncer.DependencyUpdateQuery) (string, string) {
+	if slices.Contains(q.IgnoredPRs, pr.Number) {
+		return bouncer.SkipIgnored, "listed in ignored_prs"
+	}
+	if code, reason := bouncer.PolicyDecision(pr, q); code != "" {
+		return code, reason
+	}
+	return outcomeProcessed, ""
+}
+
+// printPolicyDiff prints the PRs that change outcome, one per line.
+func printPolicyDiff(diff policyDiff) {
+	for _, c := range diff.Changes {
+		fmt.Printf("%s#%d %s: %s -> %s", c.Repo, c.Number, c.Package, c.Old, c.New)
+		if c.NewReason != "" {
+			fmt.Printf(" (%s)", c.NewReason)
+		} else if c.OldReason != "" {
+			fmt.Printf(" (was %s)", c.OldReason)
+		}
+		fmt.Println()
+	}
+	if len(diff.Changes) == 0 {
+		fmt.Printf("No change: the %d open PRs of %d repositories get the same outcome under both configs\n", diff.PRs, diff.Repositories)
+		return
+	}
+	fmt.Printf("%d of %d open PRs in %d repositories would change outcome\n", len(diff.Changes), diff.PRs, diff.Repositories)
+}
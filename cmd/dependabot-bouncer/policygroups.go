@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// policyGroupsKey holds the named policy groups, such as policies.backend:
+// deny lists and merge settings shared by the repositories that name them
+// with "policy".
+const policyGroupsKey = "policies"
+
+// repoPolicyGroups returns the policy groups a repository names with its
+// policy setting, a name or a list of names, in the order given.
+func repoPolicyGroups(repoKey string) []string {
+	var groups []string
+	for _, name := range getStringSlice("repositories." + repoKey + ".policy") {
+		groups = append(groups, strings.ToLower(name))
+	}
+	return groups
+}
+
+// settingPrefixes returns the config prefixes whose settings apply to a
+// repository, lowest precedence first: global, the policy groups it names,
+// then its own.
+func settingPrefixes(repoKey string) []string {
+	prefixes := []string{"global."}
+	for _, group := range repoPolicyGroups(repoKey) {
+		prefixes = append(prefixes, policyGroupsKey+"."+group+".")
+	}
+	return append(prefixes, "repositories."+repoKey+".")
+}
+
+// checkPolicyGroups rejects repositories that name a policy group missing
+// from policies, which would otherwise leave the group's deny lists out
+// without a word.
+func checkPolicyGroups() error {
+	groups := viper.GetStringMap(policyGroupsKey)
+	for repoKey := range viper.GetStringMap("repositories") {
+		for _, group := range repoPolicyGroups(repoKey) {
+			if _, ok := groups[group]; !ok {
+				return fmt.Errorf("unknown policy group %q for %s (define it under %s)", group, repoKey, policyGroupsKey)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestBuildQueryPolicyGroups(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("global.denied_packages", []string{"left-pad"})
+	viper.Set("policies.backend.denied_packages", []string{"github.com/aws/aws-sdk-go"})
+	viper.Set("policies.backend.precedence", "allow")
+	viper.Set("policies.strict.deny_update_types", []string{"major"})
+	viper.Set("repositories.acme/api.policy", []string{"backend", "strict"})
+	viper.Set("repositories.acme/api.precedence", "deny")
+
+	q, err := buildQuery("acme", "api")
+	if err != nil {
+		t.Fatalf("buildQuery() error = %v", err)
+	}
+	if want := []string{"left-pad", "github.com/aws/aws-sdk-go"}; !slices.Equal(q.DeniedPackages, want) {
+		t.Errorf("DeniedPackages = %v, want %v", q.DeniedPackages, want)
+	}
+	if want := []string{"major"}; !slices.Equal(q.DeniedUpdateTypes, want) {
+		t.Errorf("DeniedUpdateTypes = %v, want %v", q.DeniedUpdateTypes, want)
+	}
+	if q.Precedence != "deny" {
+		t.Errorf("Precedence = %q, want the repository's deny", q.Precedence)
+	}
+
+	q, err = buildQuery("acme", "web")
+	if err != nil {
+		t.Fatalf("buildQuery() error = %v", err)
+	}
+	if want := []string{"left-pad"}; !slices.Equal(q.DeniedPackages, want) {
+		t.Errorf("DeniedPackages without a group = %v, want %v", q.DeniedPackages, want)
+	}
+
+	viper.Set("repositories.acme/web.policy", "frontend")
+	if err := checkPolicyGroups(); err == nil {
+		t.Error("checkPolicyGroups() error = nil, want unknown policy group")
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"slices"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/export"
+	"github.com/promiseofcake/dependabot-bouncer/internal/risk"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+// approvalPriority ranks a PR for --max-approvals, lowest first: security
+// updates, then patch, minor, and major updates, then updates of unknown
+// type such as grouped ones.
+func approvalPriority(pr bouncer.PRInfo) int {
+	if pr.Security {
+		return 0
+	}
+	switch pr.UpdateType {
+	case bouncer.UpdatePatch:
+		return 1
+	case bouncer.UpdateMinor:
+		return 2
+	case bouncer.UpdateMajor:
+		return 3
+	}
+	return 4
+}
+
+// runPrioritizedApprove runs approve with --max-approvals: it lists the PRs
+// of every repository first, then acts on the maxApprovals highest-ranked
+// PRs across all of them (see capApprovals). The rest wait for a later run.
+func runPrioritizedApprove(w io.Writer, repos []string, maxApprovals int, summary *risk.Summary, changes *export.Log, limit *actionLimit, workers int, results *runResults) error {
+	jobs := make(map[string]*approveJob)
+	var order []string
+	listErr := forEachRepo(w, repos, func(owner, repo string) error {
+		out := results.repo(owner + "/" + repo)
+		job, err := prepareApprove(w, owner, repo, out)
+		out.Err = err
+		if job != nil {
+			jobs[owner+"/"+repo] = job
+			order = append(order, owner+"/"+repo)
+		}
+		return err
+	})
+
+	candidates, deferred := capApprovals(jobs, order, maxApprovals)
+	if len(deferred) > 0 {
+		fmt.Fprintf(w, "\nActing on %d of %d PRs (--max-approvals)\n", maxApprovals, candidates)
+		for _, c := range deferred {
+			log.Printf("Deferring %s#%d (%s): over --max-approvals\n", c.repo, c.pr.Number, c.pr.PackageName)
+		}
+	}
+	var act []string
+	for _, repoKey := range order {
+		if j := jobs[repoKey]; len(j.prs) > 0 || len(j.pending) > 0 {
+			act = append(act, repoKey)
+		}
+	}
+
+	actErr := forEachRepo(w, act, func(owner, repo string) error {
+		out := results.repo(owner + "/" + repo)
+		out.Err = jobs[owner+"/"+repo].run(w, summary, changes, limit, workers, out)
+		return out.Err
+	})
+	if listErr != nil {
+		return listErr
+	}
+	return actErr
+}
+
+// prCandidate is a PR of a repository competing for --max-approvals.
+type prCandidate struct {
+	repo string
+	pr   bouncer.PRInfo
+}
+
+// capApprovals removes from jobs all but the maxApprovals highest-ranked PRs
+// across them, oldest first within a rank, and returns how many PRs competed
+// and the ones removed. Pending PRs compete too, since they are approved if
+// they pass in time. Trusted PRs, which --limit does not count either, and
+// PRs already approved, which use no approval, are kept without counting.
+func capApprovals(jobs map[string]*approveJob, order []string, maxApprovals int) (candidates int, deferred []prCandidate) {
+	var ranked []prCandidate
+	for _, repoKey := range order {
+		j := jobs[repoKey]
+		for _, pr := range slices.Concat(j.prs, j.pending) {
+			if !uncapped(pr) {
+				ranked = append(ranked, prCandidate{repoKey, pr})
+			}
+		}
+	}
+	if len(ranked) <= maxApprovals {
+		return len(ranked), nil
+	}
+	slices.SortStableFunc(ranked, func(a, b prCandidate) int {
+		if d := approvalPriority(a.pr) - approvalPriority(b.pr); d != 0 {
+			return d
+		}
+		return a.pr.CreatedAt.Compare(b.pr.CreatedAt)
+	})
+
+	selected := make(map[string][]int)
+	for _, c := range ranked[:maxApprovals] {
+		selected[c.repo] = append(selected[c.repo], c.pr.Number)
+	}
+	for _, repoKey := range order {
+		j := jobs[repoKey]
+		drop := func(pr bouncer.PRInfo) bool {
+			return !uncapped(pr) && !slices.Contains(selected[repoKey], pr.Number)
+		}
+		j.prs = slices.DeleteFunc(j.prs, drop)
+		j.pending = slices.DeleteFunc(j.pending, drop)
+	}
+	return len(ranked), ranked[maxApprovals:]
+}
+
+// uncapped reports whether a PR is acted on regardless of --max-approvals.
+func uncapped(pr bouncer.PRInfo) bool {
+	return pr.Trusted || pr.ReviewDecision == "APPROVED"
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+func TestCapApprovals(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 6, d, 0, 0, 0, 0, time.UTC) }
+	pr := func(n, d int, typ string) bouncer.PRInfo {
+		return bouncer.PRInfo{Number: n, UpdateType: typ, CreatedAt: day(d)}
+	}
+	numbers := func(prs []bouncer.PRInfo) []int {
+		var ns []int
+		for _, pr := range prs {
+			ns = append(ns, pr.Number)
+		}
+		return ns
+	}
+
+	t.Run("pending PRs compete", func(t *testing.T) {
+		jobs := map[string]*approveJob{
+			"acme/api": {prs: []bouncer.PRInfo{pr(1, 1, bouncer.UpdateMajor)}, pending: []bouncer.PRInfo{pr(2, 2, bouncer.UpdatePatch)}},
+			"acme/web": {pending: []bouncer.PRInfo{pr(3, 3, bouncer.UpdateMinor)}},
+		}
+		candidates, deferred := capApprovals(jobs, []string{"acme/api", "acme/web"}, 2)
+		if candidates != 3 || len(deferred) != 1 || deferred[0].pr.Number != 1 {
+			t.Errorf("capApprovals() = %d, %+v, want 3 candidates and #1 deferred", candidates, deferred)
+		}
+		if got := numbers(jobs["acme/api"].prs); len(got) != 0 {
+			t.Errorf("acme/api PRs = %v, want none", got)
+		}
+		if got := numbers(jobs["acme/api"].pending); !slices.Equal(got, []int{2}) {
+			t.Errorf("acme/api pending = %v, want [2]", got)
+		}
+		if got := numbers(jobs["acme/web"].pending); !slices.Equal(got, []int{3}) {
+			t.Errorf("acme/web pending = %v, want [3]", got)
+		}
+	})
+
+	t.Run("trusted and approved PRs are not counted", func(t *testing.T) {
+		trusted := pr(1, 1, bouncer.UpdateMajor)
+		trusted.Trusted = true
+		approved := pr(2, 2, bouncer.UpdateMajor)
+		approved.ReviewDecision = "APPROVED"
+		jobs := map[string]*approveJob{
+			"acme/api": {prs: []bouncer.PRInfo{trusted, approved, pr(3, 3, bouncer.UpdateMinor), pr(4, 4, bouncer.UpdatePatch)}},
+		}
+		candidates, deferred := capApprovals(jobs, []string{"acme/api"}, 1)
+		if candidates != 2 || len(deferred) != 1 || deferred[0].pr.Number != 3 {
+			t.Errorf("capApprovals() = %d, %+v, want 2 candidates and #3 deferred", candidates, deferred)
+		}
+		if got := numbers(jobs["acme/api"].prs); !slices.Equal(got, []int{1, 2, 4}) {
+			t.Errorf("acme/api PRs = %v, want [1 2 4]", got)
+		}
+	})
+
+	t.Run("under the cap", func(t *testing.T) {
+		jobs := map[string]*approveJob{"acme/api": {prs: []bouncer.PRInfo{pr(1, 1, "")}}}
+		if candidates, deferred := capApprovals(jobs, []string{"acme/api"}, 1); candidates != 1 || deferred != nil {
+			t.Errorf("capApprovals() = %d, %+v, want nothing deferred", candidates, deferred)
+		}
+	})
+}
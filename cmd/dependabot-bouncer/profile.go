@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// applyProfile merges the settings of profiles.<name> over the top-level
+// ones: sections are merged key by key, and values, lists included, replace
+// the top-level ones. An empty name leaves the config as it is.
+func applyProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+	profiles := viper.GetStringMap("profiles")
+	raw, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		if len(profiles) == 0 {
+			return fmt.Errorf("unknown profile %q: the config has no profiles", name)
+		}
+		return fmt.Errorf("unknown profile %q (configured: %s)", name, strings.Join(slices.Sorted(maps.Keys(profiles)), ", "))
+	}
+	if raw == nil {
+		return nil // an empty profile
+	}
+	settings, ok := raw.(map[string]any)
+	if !ok {
+		return fmt.Errorf("invalid profile %q: expected a mapping of settings", name)
+	}
+	if _, nested := settings["profiles"]; nested {
+		return fmt.Errorf("invalid profile %q: profiles cannot be nested", name)
+	}
+	return viper.MergeConfigMap(settings)
+}
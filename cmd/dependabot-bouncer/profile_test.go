@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestApplyProfile(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+global:
+  denied_packages: [left-pad]
+  merge_method: squash
+profiles:
+  work:
+    global:
+      denied_packages: [lodash]
+    auth:
+      tokens: [GH_TOKEN_WORK]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := applyProfile("oss"); err == nil {
+		t.Error("applyProfile(oss) error = nil, want unknown profile")
+	}
+	if err := applyProfile("Work"); err != nil {
+		t.Fatalf("applyProfile(Work) error = %v", err)
+	}
+	if got := viper.GetStringSlice("global.denied_packages"); len(got) != 1 || got[0] != "lodash" {
+		t.Errorf("global.denied_packages = %v, want [lodash]", got)
+	}
+	if got := viper.GetString("global.merge_method"); got != "squash" {
+		t.Errorf("global.merge_method = %q, want squash", got)
+	}
+	if got := viper.GetStringSlice("auth.tokens"); len(got) != 1 || got[0] != "GH_TOKEN_WORK" {
+		t.Errorf("auth.tokens = %v, want [GH_TOKEN_WORK]", got)
+	}
+}
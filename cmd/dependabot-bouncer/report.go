@@ -0,0 +1,259 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/report"
+	"github.com/promiseofcake/dependabot-bouncer/internal/state"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report [owner/repo...]",
+	Short: "Generate a dependency-health report or weekly digest",
+	Long: `Generate a dependency-health report covering every non-archived repository
+of a GitHub user or organization given with --owner. Without --owner, it
+covers the repositories given as arguments or with --org, or else all
+repositories configured in the 'repositories' and 'organizations' sections
+of your config file.
+
+The report combines the open Dependabot PR backlog, the PRs merged and gone
+stale, a breakdown of PRs denied by policy, the oldest open PRs, Dependabot
+alert coverage, and weekly time-to-merge trends. It is written as Markdown
+(default) or HTML to stdout or to the file given with --output. Run it with
+--days 7 for a weekly digest to post to a team channel or commit to a docs
+repository.
+
+The layout comes from a Go template. To use your own, start from the
+built-in one printed by --print-template and pass it with --template, or set
+report.template in the config file.
+
+When this machine has a history of the bouncer's decisions (see history), the
+report also sums up its work by month: the PRs it handled and denied, how
+many merged PRs it approved versus how many were merged by hand, the API
+calls it made, and the reviewer time saved, estimated as --review-minutes
+per PR handled. Run it with --days 30 for a monthly summary.
+
+With --notify, the Markdown report is also posted to the notification
+destinations (Slack, Teams, Discord, or webhook) of its repositories.`,
+	RunE: runReport,
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	owner, _ := cmd.Flags().GetString("owner")
+	owner = parseOrg(owner)
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+	days, _ := cmd.Flags().GetInt("days")
+	staleDays, _ := cmd.Flags().GetInt("stale-days")
+	reviewMinutes, _ := cmd.Flags().GetInt("review-minutes")
+
+	if format == "md" {
+		format = report.FormatMarkdown
+	}
+	post, _ := cmd.Flags().GetBool("notify")
+	if post && format != report.FormatMarkdown {
+		return fmt.Errorf("--notify posts the Markdown report and cannot be combined with --format %s", format)
+	}
+	text, err := report.DefaultTemplate(format)
+	if err != nil {
+		return err
+	}
+	if printTemplate, _ := cmd.Flags().GetBool("print-template"); printTemplate {
+		_, err := fmt.Fprint(os.Stdout, text)
+		return err
+	}
+	if path := viper.GetString("report.template"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read report template: %w", err)
+		}
+		text = string(data)
+	}
+	// Fail before the scan on a template that does not work on a report.
+	if err := report.WriteTemplate(io.Discard, report.New(owner, time.Now(), days), format, text); err != nil {
+		return err
+	}
+	write := func(w io.Writer, r *report.Report) error {
+		return report.WriteTemplate(w, r, format, text)
+	}
+
+	repos, title, err := reportRepos(cmd, owner, args)
+	if err != nil {
+		return err
+	}
+	var routes []notifyRoute
+	if post {
+		if routes = notifyRoutesForRepos(repos); len(routes) == 0 {
+			return fmt.Errorf("--notify requires a destination under notifications (slack, teams, discord, or webhook)")
+		}
+	}
+
+	now := time.Now()
+	since := now.AddDate(0, 0, -days)
+	r := report.New(title, now, days)
+	r.StaleAfter = time.Duration(staleDays) * 24 * time.Hour
+	scanned := map[string]bool{}
+
+	for _, repoPath := range repos {
+		repoOwner, repo, err := parseRepo(repoPath)
+		if err != nil {
+			return err
+		}
+		repoKey := repoOwner + "/" + repo
+		scanned[repoKey] = true
+		log.Printf("Collecting %s...\n", repoKey)
+
+		q, err := buildQuery(repoOwner, repo)
+		if err != nil {
+			return err
+		}
+		q.IgnoredPRs = getIntSlice("repositories." + repoKey + ".ignored_prs")
+		q.IncludeSkipped = true
+
+		err = withRepoTimeout(repoOwner, repo, func() error {
+			prs, err := gitHub().ListDependabotPRs(runCtx, q, false)
+			if err != nil {
+				return fmt.Errorf("failed to list PRs: %w", err)
+			}
+
+			merged, err := gitHub().ListMergedDependabotPRs(runCtx, repoOwner, repo, since)
+			if err != nil {
+				log.Printf("Warning: failed to list merged PRs for %s: %v\n", repoKey, err)
+			}
+
+			alerts := "unknown"
+			if enabled, err := gitHub().DependabotAlertsEnabled(runCtx, repoOwner, repo); err != nil {
+				log.Printf("Warning: %s: %v\n", repoKey, err)
+			} else if enabled {
+				alerts = "enabled"
+			} else {
+				alerts = "disabled"
+			}
+
+			r.AddRepo(repoKey, prs, merged, alerts)
+			return nil
+		})
+		if err != nil {
+			log.Printf("Warning: %s: %v\n", repoKey, err)
+		}
+	}
+	if recs, err := ownerHistory(scanned, since); err != nil {
+		log.Printf("Warning: leaving out the automation summary: %v\n", err)
+	} else {
+		r.AddHistory(recs, time.Duration(reviewMinutes)*time.Minute)
+	}
+	r.Finalize()
+
+	if output == "" {
+		err = write(os.Stdout, r)
+	} else {
+		err = writeReportFile(output, r, write)
+	}
+	if err != nil || !post {
+		return err
+	}
+	var doc strings.Builder
+	if err := write(&doc, r); err != nil {
+		return err
+	}
+	return postReport(routes, "Dependency report: "+title, doc.String())
+}
+
+// writeReportFile writes the report to the file at path.
+func writeReportFile(path string, r *report.Report, write func(io.Writer, *report.Report) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer f.Close()
+	if err := write(f, r); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	log.Printf("Wrote report to %s\n", path)
+	return nil
+}
+
+// postReport posts a Markdown report to each destination. A failure to post
+// to one does not stop the others; the returned error aggregates them.
+func postReport(routes []notifyRoute, title, markdown string) error {
+	var errs []error
+	for _, route := range routes {
+		n, err := route.notifier()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := n.Post(title, markdown); err != nil {
+			errs = append(errs, fmt.Errorf("failed to post the report to %s: %w", n, err))
+			continue
+		}
+		log.Printf("Posted the report to %s\n", n)
+	}
+	return errors.Join(errs...)
+}
+
+// reportRepos returns the repositories report covers and the name of the
+// report: the non-archived repositories of owner, or without an owner those
+// selected as for check, named after their owners.
+func reportRepos(cmd *cobra.Command, owner string, args []string) ([]string, string, error) {
+	if owner != "" {
+		if len(args) > 0 {
+			return nil, "", fmt.Errorf("--owner cannot be combined with repository arguments")
+		}
+		infos, err := gitHub().ListOwnerRepos(runCtx, owner)
+		if err != nil {
+			return nil, "", err
+		}
+		var repos []string
+		for _, info := range infos {
+			if !info.IsArchived {
+				repos = append(repos, info.NameWithOwner)
+			}
+		}
+		return repos, owner, nil
+	}
+
+	repos, err := resolveRepos(cmd, args, true)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(repos) == 0 {
+		return nil, "", fmt.Errorf("no repositories specified. Use --owner, command-line arguments, --org, or configure repositories in config file")
+	}
+	var owners []string
+	for _, repoPath := range repos {
+		if repoOwner, _, err := parseRepo(repoPath); err == nil && !slices.Contains(owners, repoOwner) {
+			owners = append(owners, repoOwner)
+		}
+	}
+	return repos, strings.Join(owners, ", "), nil
+}
+
+// ownerHistory returns the records of the local history made since then on
+// the given repositories.
+func ownerHistory(repos map[string]bool, since time.Time) ([]state.Record, error) {
+	h, err := openHistory()
+	if err != nil {
+		return nil, err
+	}
+	all, err := h.Records(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	var recs []state.Record
+	for _, rec := range all {
+		if repos[rec.Repo] && (rec.Decision == "approved" || !rec.Time.Before(since)) {
+			recs = append(recs, rec)
+		}
+	}
+	return recs, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestNotifyRoutesForRepos(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("notifications.slack.webhook_url", "https://hooks.slack.com/services/T/B/X")
+	viper.Set("repositories.acme/web.notifications.slack.channel", "#web")
+	viper.Set("repositories.acme/ops.notifications.webhook.url", "https://bot.internal/hook")
+
+	var got []string
+	for _, r := range notifyRoutesForRepos([]string{"acme/api", "acme/web", "acme/ops", "acme/lib"}) {
+		got = append(got, strings.TrimSpace(r.Kind+" "+r.Channel))
+	}
+	if want := []string{"slack", "slack #web", "webhook"}; !slices.Equal(got, want) {
+		t.Errorf("routes = %q, want %q", got, want)
+	}
+}
+
+func TestPostReport(t *testing.T) {
+	var posted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var doc struct {
+			Text     string `json:"text"`
+			Markdown string `json:"markdown"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		if r.URL.Path == "/down" {
+			http.Error(w, "bot is down", http.StatusBadGateway)
+			return
+		}
+		posted = append(posted, r.URL.Path+" "+doc.Text+" "+doc.Markdown)
+	}))
+	defer srv.Close()
+
+	routes := []notifyRoute{
+		{Kind: "webhook", URL: srv.URL + "/down"},
+		{Kind: "webhook", URL: srv.URL + "/team"},
+	}
+	err := postReport(routes, "Dependency report: acme", "# Report\n")
+	if err == nil || !strings.Contains(err.Error(), "HTTP 502") {
+		t.Errorf("postReport() error = %v, want the failed destination", err)
+	}
+	if want := "/team Dependency report: acme # Report\n"; len(posted) != 1 || posted[0] != want {
+		t.Errorf("posted = %q, want [%q]", posted, want)
+	}
+}
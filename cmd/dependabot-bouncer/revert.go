@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+)
+
+var revertCmd = &cobra.Command{
+	Use:   "revert owner/repo",
+	Short: "Revert the last merged Dependabot update of a package",
+	Long: `Find the most recently merged Dependabot PR for a package, open a PR
+reverting it, and print the dependabot.yml ignore entry that keeps
+Dependabot from proposing the bad version again. Dependabot ignores
+commands left on merged PRs, so the entry has to be added to the
+repository's dependabot.yml.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRevert,
+}
+
+func runRevert(cmd *cobra.Command, args []string) error {
+	owner, repo, err := parseRepo(args[0])
+	if err != nil {
+		return err
+	}
+	packageName, _ := cmd.Flags().GetString("package")
+	days, _ := cmd.Flags().GetInt("days")
+	noIgnore, _ := cmd.Flags().GetBool("no-ignore")
+	cmd.SilenceUsage = true
+
+	since := time.Now().AddDate(0, 0, -days)
+	pr, err := gitHub().FindMergedPR(runCtx, owner, repo, packageName, since)
+	if err != nil {
+		return err
+	}
+	log.Printf("Found PR #%d merged %s: %s\n", pr.Number, pr.MergedAt.Format("2006-01-02"), pr.Title)
+
+	number, url, err := gitHub().RevertPR(runCtx, owner, repo, pr)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Opened revert PR #%d: %s\n", number, url)
+
+	if noIgnore {
+		return nil
+	}
+	entry, err := bouncer.IgnoreEntry(pr)
+	if err != nil {
+		log.Printf("Warning: %v; ignore the version in dependabot.yml manually\n", err)
+		return nil
+	}
+	fmt.Printf("Add this to the matching updates block of dependabot.yml so Dependabot does not propose %s %s again:\n\n%s", pr.PackageName, pr.ToVersion, entry)
+	return nil
+}
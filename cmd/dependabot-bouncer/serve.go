@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/risk"
+	"github.com/promiseofcake/dependabot-bouncer/internal/tracing"
+	"github.com/promiseofcake/dependabot-bouncer/internal/webhook"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Webhook server limits.
+const (
+	serveQueueSize    = 100              // deliveries waiting to be processed before new ones are refused
+	serveMaxBody      = 25 << 20         // GitHub caps payloads at 25 MB
+	serveShutdownWait = 30 * time.Second // time for in-flight requests to finish on shutdown
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Apply the approve policy when GitHub webhooks arrive",
+	Long: `Listen for GitHub pull_request and check_suite webhooks and apply the approve
+policy to the pull request as soon as Dependabot opens or updates it, or its
+checks complete, instead of polling.
+
+Point a repository or organization webhook at http://<host>:<port>/webhook
+with content type application/json, the "Pull requests" and "Check suites"
+events, and a secret. The same secret must be set in webhook.secret or the
+DEPENDABOT_BOUNCER_WEBHOOK_SECRET environment variable; deliveries whose
+signature does not match are rejected.
+
+Prometheus counters of approved, recreated, denied, and failing PRs and of
+failed API calls are served at /metrics.
+
+Deliveries are acknowledged immediately and processed one at a time. SIGINT
+or SIGTERM stops accepting deliveries and exits once the current one is done.`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	secret := viper.GetString("webhook.secret")
+	if secret == "" {
+		return fmt.Errorf("a webhook secret is required: set webhook.secret or DEPENDABOT_BOUNCER_WEBHOOK_SECRET")
+	}
+	addr, _ := cmd.Flags().GetString("addr")
+	cmd.SilenceUsage = true
+
+	queue := make(chan webhook.Target, serveQueueSize)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /webhook", webhookHandler([]byte(secret), queue))
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.Handle("GET /metrics", runMetrics.registry)
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for t := range queue {
+			approveTarget(cmd, t)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("Listening for webhooks on %s\n", addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		close(queue)
+		<-done
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownWait)
+	defer cancel()
+	err := srv.Shutdown(shutdownCtx)
+	if n := len(queue); n > 0 {
+		log.Printf("Dropping %d queued pull requests\n", n)
+		for range n {
+			<-queue
+		}
+	}
+	close(queue)
+	<-done
+	return err
+}
+
+// webhookHandler verifies each delivery against secret and queues the pull
+// requests it is about. PRs opened by someone other than the configured bots
+// are ignored.
+func webhookHandler(secret []byte, queue chan<- webhook.Target) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, serveMaxBody))
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		if err := webhook.Verify(secret, body, r.Header.Get(webhook.SignatureHeader)); err != nil {
+			log.Printf("Warning: rejected delivery %s: %v\n", r.Header.Get(webhook.DeliveryHeader), err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		event := r.Header.Get(webhook.EventHeader)
+		targets, err := webhook.Targets(event, body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, t := range targets {
+			if t.Author != "" && !bouncer.IsBot(t.Author, t.AuthorID) {
+				continue
+			}
+			select {
+			case queue <- t:
+				log.Printf("Queued %s#%d (%s)\n", t.Repo, t.Number, event)
+			default:
+				http.Error(w, "queue full", http.StatusServiceUnavailable)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// approveTarget applies the approve policy to a single pull request.
+func approveTarget(cmd *cobra.Command, t webhook.Target) {
+	owner, repo, err := parseRepo(t.Repo)
+	if err != nil {
+		log.Printf("Warning: %v\n", err)
+		return
+	}
+	span := runTracer.StartScope("webhook", tracing.String("repository", t.Repo), tracing.Int("pull_request", t.Number))
+	defer flushTraces()
+
+	summary := risk.NewSummary(viper.GetFloat64("risk.min_scorecard"))
+	results := newRunResults()
+	results.risk = summary
+	results.targets = map[string][]int{t.Repo: {t.Number}}
+	err = forEachRepo(os.Stdout, []string{t.Repo}, func(owner, repo string) error {
+		out := results.repo(owner + "/" + repo)
+		out.Err = runApprove(os.Stdout, owner, repo, summary, nil, &actionLimit{remaining: -1}, 1, out)
+		runMetrics.record(owner+"/"+repo, out)
+		return out.Err
+	})
+	span.End(err)
+	recordRun("serve", results)
+	notifyRun("serve", results, true)
+	if err != nil && !errors.Is(err, errActionsFailed) {
+		log.Printf("Warning: %s/%s#%d: %v\n", owner, repo, t.Number, err)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/state"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var snoozeCmd = &cobra.Command{
+	Use:   "snooze owner/repo#number... --until date",
+	Short: "Defer pull requests until a date",
+	Long: `Skip pull requests until a date without adding permanent deny entries, e.g.
+to revisit a major bump next sprint. approve, recreate, maintain, and watch
+leave snoozed PRs alone, and check lists them as SKIPPED with reason code
+SNOOZED, until the date passes.
+
+--until takes a date (2026-07-01), an RFC 3339 timestamp, or a time from now
+in days (14d), weeks (2w), or any Go duration (36h). Snoozing a PR again
+replaces its date.
+
+Snoozes are kept in a local state file: state.path in config, or
+~/.dependabot-bouncer/state.json. Machines that run the bouncer from cron
+or CI need the same file to see them.`,
+	Example: `  dependabot-bouncer snooze acme/api#123 --until 2026-07-01 --reason "next sprint"
+  dependabot-bouncer snooze --list
+  dependabot-bouncer snooze --clear acme/api#123`,
+	RunE: runSnooze,
+}
+
+func runSnooze(cmd *cobra.Command, args []string) error {
+	list, _ := cmd.Flags().GetBool("list")
+	unsnooze, _ := cmd.Flags().GetBool("clear")
+	untilFlag, _ := cmd.Flags().GetString("until")
+	reason, _ := cmd.Flags().GetString("reason")
+	now := time.Now()
+
+	st, err := openState()
+	if err != nil {
+		return err
+	}
+	if list {
+		return listSnoozes(st, args, now)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("no pull requests specified (expected owner/repo#number or a PR URL)")
+	}
+
+	var until time.Time
+	if !unsnooze {
+		if untilFlag == "" {
+			return fmt.Errorf("--until is required")
+		}
+		if until, err = parseUntil(untilFlag, now); err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+	for _, arg := range args {
+		owner, repo, number, err := parsePRRef(arg)
+		if err != nil {
+			return err
+		}
+		repoKey := owner + "/" + repo
+		if unsnooze {
+			ok, err := st.Unsnooze(repoKey, number)
+			if err != nil {
+				return err
+			}
+			if ok {
+				fmt.Printf("Unsnoozed %s#%d\n", repoKey, number)
+			} else {
+				fmt.Printf("%s#%d was not snoozed\n", repoKey, number)
+			}
+			continue
+		}
+		if err := st.Snooze(state.Snooze{Repo: repoKey, Number: number, Until: until, Reason: reason, CreatedAt: now}, now); err != nil {
+			return err
+		}
+		fmt.Printf("Snoozed %s#%d until %s\n", repoKey, number, until.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+// listSnoozes prints the snoozes in effect, of the repositories in args or
+// of every repository.
+func listSnoozes(st *state.Store, args []string, now time.Time) error {
+	repos := []string{""}
+	if len(args) > 0 {
+		repos = repos[:0]
+		for _, arg := range args {
+			owner, repo, err := parseRepo(arg)
+			if err != nil {
+				return err
+			}
+			repos = append(repos, owner+"/"+repo)
+		}
+	}
+	var snoozes []state.Snooze
+	for _, repoKey := range repos {
+		snoozes = append(snoozes, st.Snoozes(repoKey, now)...)
+	}
+	if len(snoozes) == 0 {
+		fmt.Println("No pull requests are snoozed")
+		return nil
+	}
+	for _, s := range snoozes {
+		line := fmt.Sprintf("%s#%d until %s", s.Repo, s.Number, s.Until.Format("2006-01-02 15:04"))
+		if s.Reason != "" {
+			line += ": " + s.Reason
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// parseUntil parses the end of a snooze: a date or timestamp as for the
+// selection window flags, or a time from now (14d, 2w, 36h). It must be in
+// the future.
+func parseUntil(value string, now time.Time) (time.Time, error) {
+	t, err := parseWindowTime(value, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	value = strings.TrimSpace(value)
+	if _, err := time.ParseInLocation("2006-01-02", value, time.Local); err != nil {
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			t = now.Add(now.Sub(t)) // an age, counted forward from now
+		}
+	}
+	if !t.After(now) {
+		return time.Time{}, fmt.Errorf("%q is not in the future", value)
+	}
+	return t, nil
+}
+
+// statePath returns the state file: state.path from config, or the default
+// under the home directory.
+func statePath() (string, error) {
+	if path := viper.GetString("state.path"); path != "" {
+		return path, nil
+	}
+	return state.DefaultPath()
+}
+
+// openState opens the state file.
+func openState() (*state.Store, error) {
+	path, err := statePath()
+	if err != nil {
+		return nil, err
+	}
+	return state.Open(path)
+}
+
+// snoozedPRs returns the snoozed PRs of "owner/repo" and the time each is
+// snoozed until. The state file is read on every call, so snoozes added while
+// watch is running take effect on its next cycle.
+func snoozedPRs(repoKey string) (map[int]time.Time, error) {
+	st, err := openState()
+	if err != nil {
+		return nil, err
+	}
+	snoozes := st.Snoozes(repoKey, time.Now())
+	if len(snoozes) == 0 {
+		return nil, nil
+	}
+	until := make(map[int]time.Time, len(snoozes))
+	for _, s := range snoozes {
+		until[s.Number] = s.Until
+	}
+	return until, nil
+}
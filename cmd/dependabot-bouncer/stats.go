@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/report"
+	"github.com/promiseofcake/dependabot-bouncer/internal/state"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [owner/repo...]",
+	Short: "Summarize the Dependabot PRs closed over the last days",
+	Long: `Summarize the Dependabot PRs closed over the last --days days: how many there
+were, the share that was merged, the median time from opening to merge, the
+packages updated most, and how many PRs the bouncer denied by policy.
+
+Closed and merged PRs are read from GitHub; repositories on other providers
+are left out. The denials come from the history kept on this machine (see
+history), so they only cover the runs made here.
+
+If no repositories are specified as arguments or with --org, summarizes all
+repositories configured in the 'repositories' and 'organizations' sections
+of your config file.`,
+	RunE: runStats,
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	days, _ := cmd.Flags().GetInt("days")
+	top, _ := cmd.Flags().GetInt("top")
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	doc, _ := outputWriters(format)
+
+	repos, err := resolveRepos(cmd, args, true)
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories specified. Use command-line arguments or configure repositories in config file")
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	s := report.NewStats(days)
+	scanned := map[string]bool{}
+	for _, repoPath := range repos {
+		owner, repo, err := parseRepo(repoPath)
+		if err != nil {
+			return err
+		}
+		provider, err := providerFor(owner, repo)
+		if err != nil {
+			return err
+		}
+		if _, ok := provider.(bouncer.GitHub); !ok {
+			log.Printf("Warning: skipping %s: merge history is only read from GitHub\n", repoPath)
+			continue
+		}
+		log.Printf("Collecting %s...\n", repoPath)
+
+		err = withRepoTimeout(owner, repo, func() error {
+			closed, err := gitHub().ListClosedDependabotPRs(runCtx, owner, repo, since)
+			if err != nil {
+				return fmt.Errorf("failed to list closed PRs: %w", err)
+			}
+			s.AddRepo(closed)
+			scanned[owner+"/"+repo] = true
+			return nil
+		})
+		if err != nil {
+			log.Printf("Warning: %s: %v\n", repoPath, err)
+		}
+	}
+	if recs, err := windowHistory(scanned, since); err != nil {
+		log.Printf("Warning: leaving out the PRs denied by policy: %v\n", err)
+	} else {
+		s.AddHistory(recs)
+	}
+	s.Finalize(top)
+
+	if format != outputText {
+		return writeDocument(doc, format, statsDocumentOf(s))
+	}
+	printStats(s)
+	return nil
+}
+
+// windowHistory returns the records of the local history made since then on
+// the given repositories.
+func windowHistory(repos map[string]bool, since time.Time) ([]state.Record, error) {
+	h, err := openHistory()
+	if err != nil {
+		return nil, err
+	}
+	all, err := h.Records(since)
+	if err != nil {
+		return nil, err
+	}
+	var recs []state.Record
+	for _, rec := range all {
+		if repos[rec.Repo] {
+			recs = append(recs, rec)
+		}
+	}
+	return recs, nil
+}
+
+// printStats prints the stats as text.
+func printStats(s *report.Stats) {
+	fmt.Printf("Dependabot PRs closed in the last %d days across %d repositories\n\n", s.Days, s.Repos)
+	fmt.Printf("  Closed:               %d\n", s.Closed)
+	fmt.Printf("  Merged:               %d (%.1f%%)\n", s.Merged, 100*s.MergeRate())
+	ttm := "-"
+	if s.Merged > 0 {
+		ttm = formatAge(s.MedianTimeToMerge)
+	}
+	fmt.Printf("  Median time to merge: %s\n", ttm)
+	bounced := "unknown (no history)"
+	if s.HistoryKnown {
+		bounced = fmt.Sprint(s.Bounced)
+	}
+	fmt.Printf("  Denied by policy:     %s\n", bounced)
+
+	if len(s.TopPackages) > 0 {
+		fmt.Println("\nTop packages by merged updates:")
+		for _, p := range s.TopPackages {
+			fmt.Printf("  %4d %s\n", p.Merged, p.Package)
+		}
+	}
+}
+
+// statsDocument is the structured form of stats.
+type statsDocument struct {
+	Days               int            `json:"days"`
+	Repositories       int            `json:"repositories"`
+	Closed             int            `json:"closed"`
+	Merged             int            `json:"merged"`
+	MergeRate          float64        `json:"merge_rate"`
+	MedianHoursToMerge float64        `json:"median_hours_to_merge"`
+	Denied             *int           `json:"denied,omitempty"` // absent without a history
+	TopPackages        []packageCount `json:"top_packages"`
+}
+
+// packageCount is the structured form of report.PackageCount.
+type packageCount struct {
+	Package string `json:"package"`
+	Merged  int    `json:"merged"`
+}
+
+// statsDocumentOf converts stats into their structured form.
+func statsDocumentOf(s *report.Stats) statsDocument {
+	d := statsDocument{
+		Days:               s.Days,
+		Repositories:       s.Repos,
+		Closed:             s.Closed,
+		Merged:             s.Merged,
+		MergeRate:          s.MergeRate(),
+		MedianHoursToMerge: s.MedianTimeToMerge.Hours(),
+		TopPackages:        []packageCount{},
+	}
+	if s.HistoryKnown {
+		d.Denied = &s.Bounced
+	}
+	for _, p := range s.TopPackages {
+		d.TopPackages = append(d.TopPackages, packageCount{Package: p.Package, Merged: p.Merged})
+	}
+	return d
+}
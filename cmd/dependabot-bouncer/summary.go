@@ -0,0 +1,167 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+// summaryCounts tallies the PRs of a run, or of one repository in it.
+type summaryCounts struct {
+	PRs       int
+	Approved  int
+	Recreated int
+	Denied    int // skipped by the allow and deny lists, CODEOWNERS, or the vulnerability check
+	Skipped   int // ignored, snoozed, drafts, and other PRs deferred rather than denied
+	Failing   int // left alone with failing checks
+	Pending   int // left alone with checks pending or missing
+}
+
+// runSummary is the roll-up printed at the end of check, approve, and
+// recreate: PR counts for the run and per repository, and how many PRs each
+// denying rule held back, so that a single deny pattern blocking much of the
+// backlog stands out.
+type runSummary struct {
+	Total  summaryCounts
+	Repos  []string // in processing order
+	ByRepo map[string]*summaryCounts
+	Rules  map[string]int // denied PRs by deny entry or rule
+}
+
+func newRunSummary() *runSummary {
+	return &runSummary{ByRepo: make(map[string]*summaryCounts), Rules: make(map[string]int)}
+}
+
+// count applies f to the counts of "owner/repo" and to the run's totals.
+func (s *runSummary) count(repoKey string, f func(*summaryCounts)) {
+	c, ok := s.ByRepo[repoKey]
+	if !ok {
+		c = &summaryCounts{}
+		s.ByRepo[repoKey] = c
+		s.Repos = append(s.Repos, repoKey)
+	}
+	f(c)
+	f(&s.Total)
+}
+
+// addSkipped counts a PR left alone by policy, and its rule when it was
+// denied. PRs skipped without a recorded rule, such as CODEOWNERS skips,
+// are counted under their skip code's label.
+func (s *runSummary) addSkipped(repoKey string, pr bouncer.PRInfo) {
+	if !isDenial(pr.SkipCode) {
+		s.count(repoKey, func(c *summaryCounts) { c.PRs++; c.Skipped++ })
+		return
+	}
+	s.count(repoKey, func(c *summaryCounts) { c.PRs++; c.Denied++ })
+	rule := pr.SkipRule
+	if rule == "" {
+		rule = cmp.Or(skipCodeLabels[pr.SkipCode], pr.SkipCode)
+	}
+	s.Rules[rule]++
+}
+
+// checkSummary rolls up the results of check.
+func checkSummary(results []checkResult) *runSummary {
+	s := newRunSummary()
+	for _, r := range results {
+		if r.Invalid != nil || r.Err != nil {
+			continue
+		}
+		repoKey := r.Owner + "/" + r.Repo
+		s.count(repoKey, func(*summaryCounts) {})
+		for _, pr := range r.PRs {
+			if pr.Skipped {
+				s.addSkipped(repoKey, pr)
+				continue
+			}
+			s.count(repoKey, func(c *summaryCounts) {
+				c.PRs++
+				if pr.CIStatus == "failure" {
+					c.Failing++
+				} else if pr.CIStatus != "success" {
+					c.Pending++
+				}
+			})
+		}
+	}
+	return s
+}
+
+// resultsSummary rolls up the results of approve or recreate: the PRs acted
+// on, the PRs policy skipped, and the PRs the CI filter left alone.
+func resultsSummary(rr *runResults) *runSummary {
+	s := newRunSummary()
+	for _, repoKey := range rr.order {
+		r := rr.byRepo[repoKey]
+		if r.Err != nil {
+			continue
+		}
+		s.count(repoKey, func(*summaryCounts) {})
+		for _, pr := range r.PRs {
+			s.count(repoKey, func(c *summaryCounts) {
+				c.PRs++
+				switch {
+				case pr.Approved:
+					c.Approved++
+				case pr.Action == "Recreated" && len(pr.Errors) == 0:
+					c.Recreated++
+				}
+			})
+		}
+		for _, pr := range r.PolicySkipped {
+			s.addSkipped(repoKey, pr)
+		}
+		if r.Stats != nil {
+			s.count(repoKey, func(c *summaryCounts) {
+				c.PRs += r.Stats.Passing + r.Stats.Failing + r.Stats.Pending
+				c.Failing += r.Stats.Failing
+				c.Pending += r.Stats.Pending
+			})
+		}
+	}
+	return s
+}
+
+// printRunSummary prints the run's totals, a row per repository when there
+// are several, and the denied PRs per rule, most first.
+func printRunSummary(s *runSummary) {
+	writeRunSummary(os.Stdout, s)
+}
+
+// writeRunSummary writes the summary printed by printRunSummary to w.
+func writeRunSummary(w io.Writer, s *runSummary) {
+	if s.Total.PRs == 0 {
+		return
+	}
+	t := s.Total
+	fmt.Fprintf(w, "\nSummary: %d PRs, %d approved, %d recreated, %d denied, %d skipped, %d failing CI, %d pending checks\n",
+		t.PRs, t.Approved, t.Recreated, t.Denied, t.Skipped, t.Failing, t.Pending)
+
+	if len(s.Repos) > 1 {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "REPOSITORY\tPRS\tAPPROVED\tRECREATED\tDENIED\tSKIPPED\tFAILING\tPENDING\t")
+		for _, repoKey := range s.Repos {
+			c := s.ByRepo[repoKey]
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t\n", repoKey, c.PRs, c.Approved, c.Recreated, c.Denied, c.Skipped, c.Failing, c.Pending)
+		}
+		fmt.Fprintln(w)
+		tw.Flush()
+	}
+
+	if len(s.Rules) > 0 {
+		rules := slices.SortedFunc(maps.Keys(s.Rules), func(a, b string) int {
+			return cmp.Or(cmp.Compare(s.Rules[b], s.Rules[a]), strings.Compare(a, b))
+		})
+		fmt.Fprintln(w, "\nDenied by rule:")
+		for _, rule := range rules {
+			fmt.Fprintf(w, "  %4d %s\n", s.Rules[rule], rule)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+func TestCheckSummary(t *testing.T) {
+	denied := func(n int, rule string) bouncer.PRInfo {
+		return bouncer.PRInfo{Number: n, Skipped: true, SkipCode: bouncer.SkipDeniedPackage, SkipRule: rule}
+	}
+	results := []checkResult{
+		{Owner: "acme", Repo: "api", PRs: []bouncer.PRInfo{
+			{Number: 1, CIStatus: "success"},
+			{Number: 2, CIStatus: "failure"},
+			denied(3, "@aws-sdk/*"),
+			denied(4, "@aws-sdk/*"),
+			{Number: 5, Skipped: true, SkipCode: bouncer.SkipIgnored},
+		}},
+		{Owner: "acme", Repo: "web", PRs: []bouncer.PRInfo{
+			{Number: 6, CIStatus: "pending"},
+			denied(7, "@aws-sdk/*"),
+			{Number: 8, Skipped: true, SkipCode: bouncer.SkipCodeOwners},
+		}},
+		{Owner: "acme", Repo: "down", Err: errors.New("not found")},
+	}
+
+	s := checkSummary(results)
+	want := summaryCounts{PRs: 8, Denied: 4, Skipped: 1, Failing: 1, Pending: 1}
+	if s.Total != want {
+		t.Errorf("Total = %+v, want %+v", s.Total, want)
+	}
+	if got := *s.ByRepo["acme/web"]; got != (summaryCounts{PRs: 3, Denied: 2, Pending: 1}) {
+		t.Errorf("acme/web = %+v", got)
+	}
+	if len(s.Repos) != 2 {
+		t.Errorf("Repos = %v, want the two checked repositories", s.Repos)
+	}
+
+	var buf bytes.Buffer
+	writeRunSummary(&buf, s)
+	out := buf.String()
+	for _, line := range []string{"Summary: 8 PRs, 0 approved, 0 recreated, 4 denied, 1 skipped, 1 failing CI, 1 pending checks", "     3 @aws-sdk/*", "     1 owned by another team"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("summary missing %q:\n%s", line, out)
+		}
+	}
+	if strings.Index(out, "@aws-sdk/*") > strings.Index(out, "owned by another team") {
+		t.Errorf("rules not sorted by count:\n%s", out)
+	}
+}
+
+func TestResultsSummary(t *testing.T) {
+	rr := newRunResults()
+	r := rr.repo("acme/api")
+	r.PRs = []prResult{
+		{Number: 1, Action: "Approved", Approved: true},
+		{Number: 2, Action: "Recreated"},
+		{Number: 3, Action: "Recreated", Errors: []string{"boom"}},
+	}
+	r.PolicySkipped = []bouncer.PRInfo{{Number: 4, Skipped: true, SkipCode: bouncer.SkipUpdateType, SkipRule: "major updates"}}
+	r.Stats = &bouncer.ListStats{Failing: 2, Pending: 1}
+
+	s := resultsSummary(rr)
+	want := summaryCounts{PRs: 7, Approved: 1, Recreated: 1, Denied: 1, Failing: 2, Pending: 1}
+	if s.Total != want {
+		t.Errorf("Total = %+v, want %+v", s.Total, want)
+	}
+	if s.Rules["major updates"] != 1 {
+		t.Errorf("Rules = %v, want major updates counted", s.Rules)
+	}
+}
+
+func TestRollupByOwner(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []checkResult
+		want    []ownerRollup
+	}{
+		{name: "no results"},
+		{
+			name: "grouped by owner in order of appearance",
+			results: []checkResult{
+				{Owner: "acme", Repo: "api", PRs: []bouncer.PRInfo{
+					{Number: 1, CIStatus: "success"},
+					{Number: 2, CIStatus: "failure", Security: true},
+					{Number: 3, CIStatus: "pending"},
+					{Number: 4},
+				}},
+				{Owner: "globex", Repo: "web", PRs: []bouncer.PRInfo{
+					{Number: 5, Skipped: true, SkipCode: bouncer.SkipDeniedPackage},
+					{Number: 6, Skipped: true, SkipCode: bouncer.SkipIgnored, Security: true},
+				}},
+				{Owner: "Acme", Repo: "web", PRs: []bouncer.PRInfo{
+					{Number: 7, CIStatus: "success"},
+				}},
+			},
+			want: []ownerRollup{
+				{Owner: "acme", Repos: 2, Open: 5, Passing: 2, Failing: 1, Pending: 2, Security: 1},
+				{Owner: "globex", Repos: 1, Denied: 1, Ignored: 1, Security: 1},
+			},
+		},
+		{
+			name: "errors and invalid repositories",
+			results: []checkResult{
+				{Owner: "acme", Repo: "api", Err: errors.New("not found"), PRs: []bouncer.PRInfo{{Number: 1, CIStatus: "success"}}},
+				{Invalid: errors.New("invalid repository format")},
+				{Owner: "acme", Repo: "web"},
+			},
+			want: []ownerRollup{
+				{Owner: "acme", Repos: 2, Errors: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rollupByOwner(tt.results); !slices.Equal(got, tt.want) {
+				t.Errorf("rollupByOwner() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
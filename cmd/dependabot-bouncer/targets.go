@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// isPRArg reports whether a command-line argument names a pull request
+// (owner/repo#number or a pull request URL) rather than a repository.
+func isPRArg(arg string) bool {
+	if strings.Contains(arg, "://") {
+		_, _, _, err := parsePRURL(arg)
+		return err == nil
+	}
+	return strings.Contains(arg, "/") && strings.Contains(arg, "#")
+}
+
+// scpURLRe matches the scp-like SSH form of clone URLs, such as
+// git@github.com:owner/repo.git.
+var scpURLRe = regexp.MustCompile(`^[\w.-]+@[\w.-]+:`)
+
+// isRepoURL reports whether a repository argument is a URL rather than
+// owner/repo: an https, http, or ssh URL, or an scp-like clone URL.
+func isRepoURL(arg string) bool {
+	return strings.Contains(arg, "://") || scpURLRe.MatchString(arg)
+}
+
+// parseRepoURL returns the repository of a URL pointing at it or at any page
+// in it, such as https://github.com/owner/repo/pull/42, or of an SSH clone
+// URL such as git@github.com:owner/repo.git. A .git suffix is dropped.
+func parseRepoURL(arg string) (owner, repo string, err error) {
+	raw := arg
+	if m := scpURLRe.FindString(arg); m != "" {
+		raw = "ssh://" + strings.TrimSuffix(m, ":") + "/" + arg[len(m):]
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http" && u.Scheme != "ssh") || u.Host == "" {
+		return "", "", fmt.Errorf("invalid repository URL: %s", arg)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" || parts[1] == ".git" {
+		return "", "", fmt.Errorf("invalid repository URL: %s (expected https://github.com/owner/repo)", arg)
+	}
+	return parts[0], strings.TrimSuffix(parts[1], ".git"), nil
+}
+
+// parseOrg returns the organization named by an --org value, which is either
+// a name or a URL such as https://github.com/acme or
+// https://github.com/orgs/acme/repositories.
+func parseOrg(arg string) string {
+	u, err := url.Parse(arg)
+	if err != nil || u.Host == "" {
+		return arg
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if parts[0] == "orgs" && len(parts) > 1 {
+		return parts[1]
+	}
+	return parts[0]
+}
+
+// parsePRURL parses a pull or merge request URL as copied from GitHub
+// (/owner/repo/pull/42), GitLab (/owner/repo/-/merge_requests/42), or Gitea
+// (/owner/repo/pulls/42). Anything after the number, such as /files or a
+// #fragment, is ignored.
+func parsePRURL(arg string) (owner, repo string, number int, err error) {
+	u, err := url.Parse(arg)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", "", 0, fmt.Errorf("invalid pull request URL: %s", arg)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) >= 5 && parts[2] == "-" {
+		parts = append(parts[:2:2], parts[3:]...)
+	}
+	if len(parts) < 4 || (parts[2] != "pull" && parts[2] != "pulls" && parts[2] != "merge_requests") {
+		return "", "", 0, fmt.Errorf("invalid pull request URL: %s (expected https://github.com/owner/repo/pull/number)", arg)
+	}
+	number, err = strconv.Atoi(parts[3])
+	if err != nil || number <= 0 {
+		return "", "", 0, fmt.Errorf("invalid pull request number in %s", arg)
+	}
+	return parts[0], parts[1], number, nil
+}
+
+// takePRTargets splits the pull request arguments, and the pull requests
+// given with --pr, from the repository arguments; plain numbers given with
+// --pr apply to the one repository argument. It returns the repositories to
+// process, with URLs normalized to owner/repo, and the pull requests named
+// in each, keyed by "owner/repo". Only those PRs of a repository in targets
+// are fetched, directly instead of listed; targets is nil when no pull
+// request was named.
+func takePRTargets(cmd *cobra.Command, args []string) (repos []string, targets map[string][]int, err error) {
+	flagPRs, _ := cmd.Flags().GetStringSlice("pr")
+	named := map[string][]int{}
+	var numbers []string
+	addTarget := func(arg string) error {
+		owner, repo, number, err := parsePRRef(arg)
+		if err != nil {
+			return err
+		}
+		key := owner + "/" + repo
+		named[key] = append(named[key], number)
+		repos = append(repos, key)
+		return nil
+	}
+	for _, arg := range args {
+		if !isPRArg(arg) {
+			if isRepoURL(arg) {
+				owner, repo, err := parseRepoURL(arg)
+				if err != nil {
+					return nil, nil, err
+				}
+				arg = owner + "/" + repo
+			}
+			repos = append(repos, arg)
+			continue
+		}
+		if err := addTarget(arg); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, arg := range flagPRs {
+		if !isPRArg(arg) {
+			numbers = append(numbers, arg)
+			continue
+		}
+		if err := addTarget(arg); err != nil {
+			return nil, nil, err
+		}
+	}
+	repos = removeDuplicates(repos)
+
+	if len(numbers) > 0 {
+		if len(repos) != 1 {
+			return nil, nil, fmt.Errorf("--pr with a number requires exactly one owner/repo argument")
+		}
+		for _, arg := range numbers {
+			n, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+			if err != nil || n <= 0 {
+				return nil, nil, fmt.Errorf("invalid pull request %q for --pr (expected a number or URL)", arg)
+			}
+			named[repos[0]] = append(named[repos[0]], n)
+		}
+	}
+	if len(named) == 0 {
+		return repos, nil, nil
+	}
+	if orgs, _ := cmd.Flags().GetStringSlice("org"); len(orgs) > 0 {
+		return nil, nil, fmt.Errorf("pull request arguments and --pr cannot be combined with --org")
+	}
+
+	targets = make(map[string][]int, len(named))
+	for key, nums := range named {
+		targets[key] = removeDuplicateInts(nums)
+	}
+	return repos, targets, nil
+}
+
+// removeDuplicateInts returns the numbers in order with repeats dropped.
+func removeDuplicateInts(numbers []int) []int {
+	seen := make(map[int]bool, len(numbers))
+	var result []int
+	for _, n := range numbers {
+		if !seen[n] {
+			seen[n] = true
+			result = append(result, n)
+		}
+	}
+	return result
+}
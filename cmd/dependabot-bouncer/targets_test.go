@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestParseRepoURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		arg       string
+		wantOwner string
+		wantRepo  string
+		wantErr   bool
+	}{
+		{name: "repository", arg: "https://github.com/acme/api", wantOwner: "acme", wantRepo: "api"},
+		{name: "trailing slash", arg: "https://github.com/acme/api/", wantOwner: "acme", wantRepo: "api"},
+		{name: "clone URL", arg: "https://github.com/acme/api.git", wantOwner: "acme", wantRepo: "api"},
+		{name: "page in repository", arg: "https://github.com/acme/api/pull/42/files", wantOwner: "acme", wantRepo: "api"},
+		{name: "http", arg: "http://github.com/acme/api", wantOwner: "acme", wantRepo: "api"},
+		{name: "enterprise host", arg: "https://github.example.com/acme/api", wantOwner: "acme", wantRepo: "api"},
+		{name: "ssh URL", arg: "ssh://git@github.com/acme/api.git", wantOwner: "acme", wantRepo: "api"},
+		{name: "scp-like", arg: "git@github.com:acme/api.git", wantOwner: "acme", wantRepo: "api"},
+		{name: "scp-like enterprise host", arg: "git@github.example.com:acme/api", wantOwner: "acme", wantRepo: "api"},
+		{name: "owner only", arg: "https://github.com/acme", wantErr: true},
+		{name: "no path", arg: "https://github.com/", wantErr: true},
+		{name: "bare .git", arg: "https://github.com/acme/.git", wantErr: true},
+		{name: "other scheme", arg: "ftp://github.com/acme/api", wantErr: true},
+		{name: "no host", arg: "https:///acme/api", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, repo, err := parseRepoURL(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRepoURL(%q) error = %v, wantErr %v", tt.arg, err, tt.wantErr)
+			}
+			if owner != tt.wantOwner || repo != tt.wantRepo {
+				t.Errorf("parseRepoURL(%q) = %q, %q, want %q, %q", tt.arg, owner, repo, tt.wantOwner, tt.wantRepo)
+			}
+		})
+	}
+}
+
+func TestIsRepoURL(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want bool
+	}{
+		{"acme/api", false},
+		{"acme/api#42", false},
+		{"https://github.com/acme/api", true},
+		{"ssh://git@github.com/acme/api.git", true},
+		{"git@github.com:acme/api.git", true},
+	}
+
+	for _, tt := range tests {
+		if got := isRepoURL(tt.arg); got != tt.want {
+			t.Errorf("isRepoURL(%q) = %v, want %v", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestParseOrg(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  string
+		want string
+	}{
+		{name: "name", arg: "acme", want: "acme"},
+		{name: "profile URL", arg: "https://github.com/acme", want: "acme"},
+		{name: "trailing slash", arg: "https://github.com/acme/", want: "acme"},
+		{name: "repositories page", arg: "https://github.com/orgs/acme/repositories", want: "acme"},
+		{name: "orgs page", arg: "https://github.com/orgs/acme", want: "acme"},
+		{name: "repository URL", arg: "https://github.com/acme/api", want: "acme"},
+		{name: "enterprise host", arg: "https://github.example.com/orgs/acme/people", want: "acme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseOrg(tt.arg); got != tt.want {
+				t.Errorf("parseOrg(%q) = %q, want %q", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePRURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		want    string
+		wantErr bool
+	}{
+		{name: "GitHub", arg: "https://github.com/acme/api/pull/42", want: "acme/api#42"},
+		{name: "files tab", arg: "https://github.com/acme/api/pull/42/files", want: "acme/api#42"},
+		{name: "fragment", arg: "https://github.com/acme/api/pull/42#issuecomment-1", want: "acme/api#42"},
+		{name: "enterprise host", arg: "https://github.example.com/acme/api/pull/42", want: "acme/api#42"},
+		{name: "GitLab", arg: "https://gitlab.com/acme/api/-/merge_requests/42", want: "acme/api#42"},
+		{name: "Gitea", arg: "https://gitea.example.com/acme/api/pulls/42", want: "acme/api#42"},
+		{name: "repository", arg: "https://github.com/acme/api", wantErr: true},
+		{name: "issue", arg: "https://github.com/acme/api/issues/42", wantErr: true},
+		{name: "not a number", arg: "https://github.com/acme/api/pull/new", wantErr: true},
+		{name: "zero", arg: "https://github.com/acme/api/pull/0", wantErr: true},
+		{name: "ssh", arg: "ssh://github.com/acme/api/pull/42", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, repo, number, err := parsePRURL(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePRURL(%q) error = %v, wantErr %v", tt.arg, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := fmt.Sprintf("%s/%s#%d", owner, repo, number); got != tt.want {
+				t.Errorf("parsePRURL(%q) = %s, want %s", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTakePRTargets(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		prs         []string
+		orgs        []string
+		wantRepos   []string
+		wantTargets map[string][]int
+		wantErr     bool
+	}{
+		{
+			name:      "repositories only",
+			args:      []string{"acme/api", "https://github.com/acme/web.git"},
+			wantRepos: []string{"acme/api", "acme/web"},
+		},
+		{
+			name:        "references and URLs",
+			args:        []string{"acme/api#1", "https://github.com/acme/api/pull/2", "acme/web#3", "acme/api#1"},
+			wantRepos:   []string{"acme/api", "acme/web"},
+			wantTargets: map[string][]int{"acme/api": {1, 2}, "acme/web": {3}},
+		},
+		{
+			name:        "numbers with --pr",
+			args:        []string{"acme/api"},
+			prs:         []string{"4", "#5", "4"},
+			wantRepos:   []string{"acme/api"},
+			wantTargets: map[string][]int{"acme/api": {4, 5}},
+		},
+		{
+			name:        "URLs with --pr",
+			args:        []string{"acme/api"},
+			prs:         []string{"https://github.com/acme/web/pull/6"},
+			wantRepos:   []string{"acme/api", "acme/web"},
+			wantTargets: map[string][]int{"acme/web": {6}},
+		},
+		{
+			name:    "numbers with --pr and several repositories",
+			args:    []string{"acme/api", "acme/web"},
+			prs:     []string{"4"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid number",
+			args:    []string{"acme/api"},
+			prs:     []string{"four"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid reference",
+			args:    []string{"acme/api#0"},
+			wantErr: true,
+		},
+		{
+			name:    "with --org",
+			args:    []string{"acme/api#1"},
+			orgs:    []string{"acme"},
+			wantErr: true,
+		},
+		{
+			name:      "repositories with --org",
+			args:      []string{"acme/api"},
+			orgs:      []string{"acme"},
+			wantRepos: []string{"acme/api"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.Flags().StringSlice("pr", tt.prs, "")
+			cmd.Flags().StringSlice("org", tt.orgs, "")
+
+			repos, targets, err := takePRTargets(cmd, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("takePRTargets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(repos, tt.wantRepos) {
+				t.Errorf("takePRTargets() repos = %v, want %v", repos, tt.wantRepos)
+			}
+			if got, want := fmt.Sprint(targets), fmt.Sprint(tt.wantTargets); got != want {
+				t.Errorf("takePRTargets() targets = %s, want %s", got, want)
+			}
+		})
+	}
+}
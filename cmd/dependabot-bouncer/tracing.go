@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"os"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/tracing"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	// runTracer exports the run's spans; nil when no OTLP endpoint is
+	// configured.
+	runTracer *tracing.Tracer
+	// runSpan is the root span of a one-shot command. watch and serve start
+	// a trace per cycle or delivery instead.
+	runSpan *tracing.Span
+)
+
+// setupTracing starts exporting spans to the OTLP endpoint set with
+// --otlp-endpoint or tracing.endpoint, falling back to the standard
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT and OTEL_EXPORTER_OTLP_ENDPOINT
+// variables.
+func setupTracing(cmd *cobra.Command) error {
+	var tracesURL string
+	switch {
+	case viper.GetString("tracing.endpoint") != "":
+		tracesURL = tracing.TracesURL(viper.GetString("tracing.endpoint"))
+	case os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "":
+		tracesURL = os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	case os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "":
+		tracesURL = tracing.TracesURL(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"))
+	default:
+		return nil
+	}
+
+	headers, err := tracing.ParseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	if err != nil {
+		return fmt.Errorf("invalid OTEL_EXPORTER_OTLP_HEADERS: %w", err)
+	}
+	maps.Copy(headers, viper.GetStringMapString("tracing.headers"))
+	service := os.Getenv("OTEL_SERVICE_NAME")
+	if service == "" {
+		service = "dependabot-bouncer"
+	}
+
+	t, err := tracing.New(tracesURL, headers, service)
+	if err != nil {
+		return err
+	}
+	runTracer = t
+	bouncer.SetTracer(t)
+	if cmd != watchCmd && cmd != serveCmd {
+		runSpan = t.StartScope(cmd.CommandPath())
+	}
+	return nil
+}
+
+// flushTraces exports the spans recorded so far. Failures are logged and
+// never fail the run.
+func flushTraces() {
+	if err := runTracer.Flush(); err != nil {
+		log.Printf("Warning: %v\n", err)
+	}
+}
+
+// finishTracing ends the run's root span with the command's result and
+// exports the remaining spans.
+func finishTracing(err error) {
+	runSpan.End(err)
+	flushTraces()
+}
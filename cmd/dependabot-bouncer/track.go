@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes returned by the track command.
+const (
+	trackExitClosed  = 2 // PR was closed without merging
+	trackExitTimeout = 3 // --timeout elapsed before the PR was merged or closed
+)
+
+var trackCmd = &cobra.Command{
+	Use:   "track owner/repo#number",
+	Short: "Follow a single pull request until it is merged or closed",
+	Long: `Follow a single pull request, printing each status transition (new commits
+from a rebase or recreate, CI results, merge state, review decision) until the
+PR is merged or closed.
+
+Exit codes:
+  0  the PR was merged
+  1  an error occurred
+  2  the PR was closed without merging
+  3  --timeout elapsed first`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTrack,
+}
+
+func runTrack(cmd *cobra.Command, args []string) error {
+	owner, repo, number, err := parsePRRef(args[0])
+	if err != nil {
+		return err
+	}
+	interval, _ := cmd.Flags().GetDuration("interval")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	cmd.SilenceUsage = true
+
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	var last *bouncer.PRInfo
+	for {
+		pr, err := gitHub().GetPR(runCtx, owner, repo, number)
+		if err != nil {
+			if last == nil {
+				return err
+			}
+			log.Printf("Warning: %v\n", err)
+		} else {
+			for _, change := range trackTransitions(last, pr) {
+				log.Printf("PR #%d: %s\n", pr.Number, change)
+			}
+			last = &pr
+
+			switch pr.State {
+			case "MERGED":
+				return nil
+			case "CLOSED":
+				return &exitError{code: trackExitClosed, msg: fmt.Sprintf("PR #%d was closed without merging", number)}
+			}
+		}
+
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return &exitError{code: trackExitTimeout, msg: fmt.Sprintf("timed out after %s waiting for PR #%d", timeout, number)}
+		}
+		time.Sleep(interval)
+	}
+}
+
+// trackTransitions describes what changed between two observations of a PR.
+// When prev is nil the full initial status is returned.
+func trackTransitions(prev *bouncer.PRInfo, cur bouncer.PRInfo) []string {
+	ci := cur.CIStatus
+	if len(cur.CIFailures) > 0 {
+		ci += " (" + strings.Join(cur.CIFailures, ", ") + ")"
+	}
+
+	if prev == nil {
+		return []string{
+			cur.Title,
+			fmt.Sprintf("state: %s, CI: %s, merge: %s, review: %s", cur.State, ci, cur.MergeStateStatus, cur.ReviewDecision),
+		}
+	}
+
+	var changes []string
+	if cur.HeadSHA != prev.HeadSHA {
+		changes = append(changes, fmt.Sprintf("new commit %s (rebased or recreated)", shortSHA(cur.HeadSHA)))
+	}
+	if cur.CIStatus != prev.CIStatus || strings.Join(cur.CIFailures, ",") != strings.Join(prev.CIFailures, ",") {
+		changes = append(changes, fmt.Sprintf("CI: %s -> %s", prev.CIStatus, ci))
+	}
+	if cur.MergeStateStatus != prev.MergeStateStatus {
+		changes = append(changes, fmt.Sprintf("merge: %s -> %s", prev.MergeStateStatus, cur.MergeStateStatus))
+	}
+	if cur.ReviewDecision != prev.ReviewDecision {
+		changes = append(changes, fmt.Sprintf("review: %s -> %s", prev.ReviewDecision, cur.ReviewDecision))
+	}
+	if cur.State != prev.State {
+		changes = append(changes, fmt.Sprintf("state: %s -> %s", prev.State, cur.State))
+	}
+	return changes
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+)
+
+func TestTrackTransitions(t *testing.T) {
+	base := bouncer.PRInfo{
+		Number:           42,
+		Title:            "Bump lodash from 4.17.20 to 4.17.21",
+		State:            "OPEN",
+		HeadSHA:          "0123456789abcdef",
+		CIStatus:         "pending",
+		MergeStateStatus: "BLOCKED",
+		ReviewDecision:   "REVIEW_REQUIRED",
+	}
+	with := func(f func(*bouncer.PRInfo)) bouncer.PRInfo {
+		pr := base
+		f(&pr)
+		return pr
+	}
+
+	tests := []struct {
+		name string
+		prev *bouncer.PRInfo
+		cur  bouncer.PRInfo
+		want []string
+	}{
+		{
+			name: "initial status",
+			cur:  with(func(pr *bouncer.PRInfo) { pr.CIStatus, pr.CIFailures = "failure", []string{"lint", "test"} }),
+			want: []string{
+				"Bump lodash from 4.17.20 to 4.17.21",
+				"state: OPEN, CI: failure (lint, test), merge: BLOCKED, review: REVIEW_REQUIRED",
+			},
+		},
+		{
+			name: "unchanged",
+			prev: &base,
+			cur:  base,
+		},
+		{
+			name: "new commit",
+			prev: &base,
+			cur:  with(func(pr *bouncer.PRInfo) { pr.HeadSHA = "fedcba9876543210" }),
+			want: []string{"new commit fedcba9 (rebased or recreated)"},
+		},
+		{
+			name: "CI failures change",
+			prev: &base,
+			cur:  with(func(pr *bouncer.PRInfo) { pr.CIStatus, pr.CIFailures = "failure", []string{"test"} }),
+			want: []string{"CI: pending -> failure (test)"},
+		},
+		{
+			name: "approved and merged",
+			prev: &base,
+			cur: with(func(pr *bouncer.PRInfo) {
+				pr.CIStatus, pr.MergeStateStatus, pr.ReviewDecision, pr.State = "success", "CLEAN", "APPROVED", "MERGED"
+			}),
+			want: []string{
+				"CI: pending -> success",
+				"merge: BLOCKED -> CLEAN",
+				"review: REVIEW_REQUIRED -> APPROVED",
+				"state: OPEN -> MERGED",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trackTransitions(tt.prev, tt.cur); !slices.Equal(got, tt.want) {
+				t.Errorf("trackTransitions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunTrackRejectsInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		cmd := &cobra.Command{}
+		cmd.Flags().Duration("interval", interval, "")
+		cmd.Flags().Duration("timeout", 0, "")
+		if err := runTrack(cmd, []string{"acme/api#42"}); err == nil {
+			t.Errorf("runTrack() with --interval %s succeeded, want error", interval)
+		}
+	}
+}
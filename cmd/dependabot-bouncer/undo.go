@@ -0,0 +1,232 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/audit"
+	"github.com/promiseofcake/dependabot-bouncer/internal/state"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [owner/repo...]",
+	Short: "Take back the approvals of the last run",
+	Long: `Take back what the last run that approved PRs did, for when it approved a
+batch it should not have, e.g. against the wrong deny list. For each PR the
+run approved that is still open:
+  - auto-merge is disabled if the run enabled it
+  - "@dependabot cancel merge" is commented if the run asked Dependabot to
+    merge it
+  - the approving reviews of the account the run acted as are dismissed
+
+The run is read from the audit log when audit.path is set, and otherwise
+from the history kept on this machine (see history). With repositories as
+arguments, the last run that approved PRs in any of them is undone, and only
+on those repositories. Undo runs are recorded like any other but are never
+undone themselves. Only GitHub repositories are supported.`,
+	RunE: runUndo,
+}
+
+// undoMessage is the reason given on the reviews undo dismisses.
+const undoMessage = "Approval withdrawn by dependabot-bouncer undo."
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	repos := map[string]bool{}
+	for _, arg := range args {
+		owner, repo, err := parseRepo(arg)
+		if err != nil {
+			return err
+		}
+		repos[owner+"/"+repo] = true
+	}
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	doc, human := outputWriters(format)
+
+	entries, source, err := undoLog()
+	if err != nil {
+		return err
+	}
+	run := audit.LastRun(entries, func(e audit.Entry) bool {
+		return e.Command != cmd.Name() && (len(repos) == 0 || repos[e.Repo]) && undoable(e)
+	})
+	if len(run) == 0 {
+		return fmt.Errorf("no approvals to undo in %s", source)
+	}
+	log.Printf("Undoing %d approvals of the %s run at %s (from %s)\n",
+		len(run), run[0].Command, run[0].Time.Local().Format(time.DateTime), source)
+
+	var order []string
+	byRepo := map[string][]audit.Entry{}
+	for _, e := range run {
+		if _, ok := byRepo[e.Repo]; !ok {
+			order = append(order, e.Repo)
+		}
+		byRepo[e.Repo] = append(byRepo[e.Repo], e)
+	}
+
+	results := newRunResults()
+	err = forEachRepo(human, order, func(owner, repo string) error {
+		out := results.repo(owner + "/" + repo)
+		out.Err = undoRepo(owner, repo, byRepo[owner+"/"+repo], out)
+		return out.Err
+	})
+	recordRun(cmd.Name(), results)
+	if format != outputText {
+		if docErr := writeDocument(doc, format, resultsDocument(results)); docErr != nil {
+			return docErr
+		}
+	} else {
+		printResults(results)
+	}
+	return exitStatus(cmd, err, results.counts())
+}
+
+// undoLog returns the actions of past runs as audit entries, from the audit
+// log when audit.path is set and otherwise from the history, along with the
+// file they were read from.
+func undoLog() ([]audit.Entry, string, error) {
+	if path := viper.GetString("audit.path"); path != "" {
+		entries, err := audit.Open(path).Entries()
+		return entries, path, err
+	}
+	h, err := openHistory()
+	if err != nil {
+		return nil, "", err
+	}
+	recs, err := h.Records(time.Time{})
+	if err != nil {
+		return nil, "", err
+	}
+	return historyEntries(recs), h.Path(), nil
+}
+
+// historyEntries converts history records into audit entries. The records
+// of a run share their time and command, which stand in for the run ID.
+func historyEntries(recs []state.Record) []audit.Entry {
+	var entries []audit.Entry
+	for _, rec := range recs {
+		if rec.Decision == state.DecisionRun {
+			continue
+		}
+		entries = append(entries, audit.Entry{
+			Time:     rec.Time,
+			Run:      rec.Time.UTC().Format(time.RFC3339Nano) + " " + rec.Command,
+			Actor:    rec.Actor,
+			Command:  rec.Command,
+			Repo:     rec.Repo,
+			Number:   rec.Number,
+			Package:  rec.Package,
+			Decision: rec.Decision,
+			Actions:  rec.Details,
+			Rule:     rec.Rule,
+			Errors:   rec.Errors,
+		})
+	}
+	return entries
+}
+
+// undoable reports whether a run reviewed the PR of an entry, approving it
+// or, with review_event: comment, commenting on it, or set up its merge,
+// even if a later step such as enabling auto-merge failed.
+func undoable(e audit.Entry) bool {
+	if e.Decision != "approved" && e.Decision != "failed" {
+		return false
+	}
+	return slices.ContainsFunc(e.Actions, func(action string) bool {
+		return action == "approved" || action == "commented on" || action == "auto-merge enabled" || askedDependabotToMerge(action)
+	})
+}
+
+// undoRepo undoes the approvals of entries on a repository, recording the
+// outcome for each PR in out.
+func undoRepo(owner, repo string, entries []audit.Entry, out *repoResults) error {
+	provider, err := providerFor(owner, repo)
+	if err != nil {
+		return err
+	}
+	if _, ok := provider.(bouncer.GitHub); !ok {
+		out.Skipped = "undo only supports GitHub"
+		return nil
+	}
+
+	numbers := make([]int, len(entries))
+	for i, e := range entries {
+		numbers[i] = e.Number
+	}
+	closed, err := provider.FindClosed(runCtx, owner, repo, numbers)
+	if err != nil {
+		return fmt.Errorf("failed to look up PR states: %w", err)
+	}
+	states := map[int]string{}
+	for _, c := range closed {
+		states[c.Number] = c.State
+	}
+
+	for _, e := range entries {
+		r := prResult{Number: e.Number, Title: e.Package, URL: e.URL, Package: e.Package, Action: "Undone"}
+		if st, ok := states[e.Number]; ok {
+			r.Action = "Skipped"
+			if st == "MERGED" {
+				r.Details = append(r.Details, "already merged")
+			} else {
+				r.Details = append(r.Details, "already closed")
+			}
+		} else {
+			undoPR(provider, owner, repo, e, &r)
+		}
+		out.PRs = append(out.PRs, r)
+	}
+	return resultsError(out.PRs)
+}
+
+// undoPR stops the merge a run set up on a PR and dismisses its approval,
+// recording what was done into r.
+func undoPR(provider bouncer.Client, owner, repo string, e audit.Entry, r *prResult) {
+	if slices.Contains(e.Actions, "auto-merge enabled") {
+		if err := gitHub().DisableAutoMergePR(runCtx, owner, repo, e.Number); err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("failed to disable auto-merge: %v", err))
+		} else {
+			r.Details = append(r.Details, "auto-merge disabled")
+		}
+	}
+	if slices.ContainsFunc(e.Actions, askedDependabotToMerge) {
+		if err := provider.Comment(runCtx, owner, repo, e.Number, "@dependabot cancel merge"); err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("failed to cancel Dependabot's merge: %v", err))
+		} else {
+			r.Details = append(r.Details, "asked Dependabot to cancel the merge")
+		}
+	}
+
+	login := cmp.Or(e.Actor, auditActor())
+	if login == "" {
+		r.Errors = append(r.Errors, "cannot dismiss the approval: the account the run acted as is unknown")
+		return
+	}
+	n, err := gitHub().DismissApprovals(runCtx, owner, repo, e.Number, login, undoMessage)
+	switch {
+	case err != nil:
+		r.Errors = append(r.Errors, fmt.Sprintf("failed to dismiss the approval: %v", err))
+	case n == 0:
+		r.Details = append(r.Details, "no approval by "+login+" to dismiss")
+	default:
+		r.Details = append(r.Details, "approval dismissed")
+	}
+}
+
+// askedDependabotToMerge reports whether an action recorded for a PR left
+// its merge to Dependabot, by comment or in the approving review.
+func askedDependabotToMerge(action string) bool {
+	// mergePolicy.askDependabot may prefix the reason auto-merge was not used.
+	action = strings.TrimPrefix(action, "auto-merge disabled, ")
+	return action == "asked Dependabot to merge" || action == "left to Dependabot"
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/audit"
+	"github.com/promiseofcake/dependabot-bouncer/internal/state"
+)
+
+func TestHistoryEntries(t *testing.T) {
+	t1 := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	recs := []state.Record{
+		{Time: t1, Command: "approve", Repo: "acme/api", Decision: state.DecisionRun, Calls: 12},
+		{Time: t1, Command: "approve", Repo: "acme/api", Number: 1, Decision: "approved", Details: []string{"approved", "auto-merge enabled"}, Actor: "bouncer-bot"},
+		{Time: t2, Command: "approve", Repo: "acme/api", Number: 2, Decision: "approved", Details: []string{"approved"}},
+		{Time: t2, Command: "recreate", Repo: "acme/api", Number: 3, Decision: "recreated", Details: []string{"recreated"}},
+	}
+
+	entries := historyEntries(recs)
+	if len(entries) != 3 {
+		t.Fatalf("historyEntries() = %+v, want the 3 PR records", entries)
+	}
+	if e := entries[0]; e.Number != 1 || e.Actor != "bouncer-bot" || len(e.Actions) != 2 {
+		t.Errorf("entries[0] = %+v", e)
+	}
+	if entries[0].Run == entries[1].Run || entries[1].Run == entries[2].Run {
+		t.Errorf("runs = %q, %q, %q, want each record in its own run", entries[0].Run, entries[1].Run, entries[2].Run)
+	}
+
+	run := audit.LastRun(entries, undoable)
+	if len(run) != 1 || run[0].Number != 2 {
+		t.Errorf("LastRun(undoable) = %+v, want #2", run)
+	}
+}
+
+func TestLastRunCommentReview(t *testing.T) {
+	t1 := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	recs := []state.Record{
+		{Time: t1, Command: "approve", Repo: "acme/api", Number: 1, Decision: "approved", Details: []string{"approved", "auto-merge enabled"}},
+		{Time: t2, Command: "approve", Repo: "acme/api", Number: 2, Decision: "approved", Details: []string{"commented on", "left to Dependabot"}},
+		{Time: t2, Command: "approve", Repo: "acme/api", Number: 3, Decision: "approved", Details: []string{"commented on", "asked Dependabot to merge"}},
+	}
+
+	run := audit.LastRun(historyEntries(recs), undoable)
+	if len(run) != 2 || run[0].Number != 2 || run[1].Number != 3 {
+		t.Fatalf("LastRun(undoable) = %+v, want #2 and #3", run)
+	}
+	for _, e := range run {
+		if !slices.ContainsFunc(e.Actions, askedDependabotToMerge) {
+			t.Errorf("#%d actions = %v, want Dependabot's merge to be cancelled", e.Number, e.Actions)
+		}
+	}
+}
+
+func TestUndoable(t *testing.T) {
+	tests := []struct {
+		name string
+		e    audit.Entry
+		want bool
+	}{
+		{"approved", audit.Entry{Decision: "approved", Actions: []string{"approved", "auto-merge enabled"}}, true},
+		{"auto-merge failed", audit.Entry{Decision: "failed", Actions: []string{"approved"}}, true},
+		{"already approved", audit.Entry{Decision: "approved", Actions: []string{"already approved"}}, false},
+		{"auto-merge on an approved PR", audit.Entry{Decision: "approved", Actions: []string{"already approved", "auto-merge enabled"}}, true},
+		{"comment review", audit.Entry{Decision: "approved", Actions: []string{"commented on", "left to Dependabot"}}, true},
+		{"already commented", audit.Entry{Decision: "approved", Actions: []string{"already commented", "auto-merge disabled, asked Dependabot to merge"}}, true},
+		{"approval failed", audit.Entry{Decision: "failed", Actions: []string{"rebased"}}, false},
+		{"recreated", audit.Entry{Decision: "recreated", Actions: []string{"recreated"}}, false},
+	}
+	for _, tt := range tests {
+		if got := undoable(tt.e); got != tt.want {
+			t.Errorf("%s: undoable() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAskedDependabotToMerge(t *testing.T) {
+	for action, want := range map[string]bool{
+		"asked Dependabot to merge":                      true,
+		"auto-merge disabled, asked Dependabot to merge": true,
+		"left to Dependabot":                             true,
+		"auto-merge enabled":                             false,
+		"merged":                                         false,
+	} {
+		if got := askedDependabotToMerge(action); got != want {
+			t.Errorf("askedDependabotToMerge(%q) = %v, want %v", action, got, want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/osv"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/viper"
+)
+
+// vulnerability_check modes.
+const (
+	vulnCheckOff   = "off"   // no lookups (default)
+	vulnCheckWarn  = "warn"  // approve vulnerable versions with a warning
+	vulnCheckBlock = "block" // skip vulnerable versions with SkipVulnerable
+)
+
+// advisories looks up known vulnerabilities for vulnerability_check for the
+// life of the process.
+var advisories = osv.NewClient()
+
+// vulnerabilityCheck returns the vulnerability_check mode of a repository:
+// its own setting, or else its policy groups', or else the global one.
+func vulnerabilityCheck(repoKey string) (string, error) {
+	mode := vulnCheckOff
+	for _, prefix := range settingPrefixes(repoKey) {
+		if v := viper.GetString(prefix + "vulnerability_check"); v != "" {
+			mode = strings.ToLower(v)
+		}
+	}
+	switch mode {
+	case vulnCheckOff, vulnCheckWarn, vulnCheckBlock:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid vulnerability_check %q for %s (expected %q, %q, or %q)", mode, repoKey, vulnCheckBlock, vulnCheckWarn, vulnCheckOff)
+}
+
+// gateVulnerabilities looks up the known vulnerabilities of each PR's new
+// version on OSV. With vulnerability_check set to block, PRs to a vulnerable
+// version are moved from prs to out.PolicySkipped with skip code VULNERABLE;
+// with warn, they are kept with a warning. PRs whose update fixes a
+// vulnerability of the old version are marked as security updates and
+// moved to the front, so --limit and --max-approvals reach them first. A
+// failed lookup is logged and leaves the PR as it is.
+func gateVulnerabilities(owner, repo string, prs []bouncer.PRInfo, stats *bouncer.ListStats, out *repoResults) ([]bouncer.PRInfo, error) {
+	mode, err := vulnerabilityCheck(owner + "/" + repo)
+	if err != nil || mode == vulnCheckOff {
+		return prs, err
+	}
+
+	var fixes, rest []bouncer.PRInfo
+	for _, pr := range prs {
+		after, ok, err := advisories.Vulnerabilities(pr.Ecosystem, pr.PackageName, pr.ToVersion)
+		if err != nil {
+			log.Printf("Warning: %v; PR #%d not checked for vulnerabilities\n", err, pr.Number)
+		}
+		if !ok {
+			rest = append(rest, pr)
+			continue
+		}
+		if len(after) > 0 {
+			reason := fmt.Sprintf("%s has known vulnerabilities: %s", pr.ToVersion, strings.Join(after, ", "))
+			if mode == vulnCheckBlock {
+				skipListed(pr, bouncer.SkipVulnerable, reason, stats, out)
+				continue
+			}
+			log.Printf("Warning: PR #%d (%s): %s\n", pr.Number, pr.PackageName, reason)
+		}
+
+		before, _, err := advisories.Vulnerabilities(pr.Ecosystem, pr.PackageName, pr.FromVersion)
+		if err != nil {
+			log.Printf("Warning: %v\n", err)
+		}
+		var fixed []string
+		for _, id := range before {
+			if !slices.Contains(after, id) {
+				fixed = append(fixed, id)
+			}
+		}
+		if len(fixed) == 0 {
+			rest = append(rest, pr)
+			continue
+		}
+		log.Printf("PR #%d (%s) fixes %s\n", pr.Number, pr.PackageName, strings.Join(fixed, ", "))
+		pr.Security = true
+		fixes = append(fixes, pr)
+	}
+	return append(fixes, rest...), nil
+}
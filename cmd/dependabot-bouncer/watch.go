@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/risk"
+	"github.com/promiseofcake/dependabot-bouncer/internal/tracing"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// watchMinBudget is the rate-limit budget below which watch waits for the
+// window to reset before starting a cycle.
+const watchMinBudget = 200
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [owner/repo...]",
+	Short: "Apply the approve policy continuously on an interval",
+	Long: `Run the approve policy over and over, once every --interval, instead of
+relying on cron. Each cycle approves passing PRs and rebases or recreates the
+ones that need it, exactly like approve.
+
+Before each cycle the config file is read again, organization repositories
+are rediscovered, the token pool is rebuilt, and, when the rate-limit budget
+is low, the cycle waits for it to reset. Errors are logged and retried on the
+next cycle.
+
+With --spread, the repositories of a cycle are spread across the interval
+instead of being processed back to back: each starts at a random time within
+its share of the interval, and the next cycle starts one interval after the
+previous one started. --jitter delays the start of every cycle by a random
+amount up to the given duration, so several watch processes started at once
+drift apart.
+
+SIGINT or SIGTERM stops watch once the repository being processed is done.
+
+With --metrics-addr, Prometheus counters of approved, recreated, denied, and
+failing PRs and of failed API calls are served at /metrics on that address.
+
+If no repositories are specified as arguments or with --org, watches all
+repositories configured in the 'repositories' and 'organizations' sections
+of your config file.`,
+	RunE: runWatch,
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	interval, _ := cmd.Flags().GetDuration("interval")
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	metricsAddr, _ := cmd.Flags().GetString("metrics-addr")
+	spread, _ := cmd.Flags().GetBool("spread")
+	jitter, _ := cmd.Flags().GetDuration("jitter")
+	if jitter < 0 {
+		return fmt.Errorf("--jitter must not be negative")
+	}
+	cmd.SilenceUsage = true
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if srv := serveMetrics(metricsAddr); srv != nil {
+		defer srv.Close()
+	}
+
+	if !sleepCtx(ctx, randomDelay(jitter)) {
+		log.Println("Shutting down")
+		return nil
+	}
+	for cycle := 1; ; cycle++ {
+		log.Printf("Watch cycle %d\n", cycle)
+		start := time.Now()
+		var slot time.Duration
+		if spread {
+			slot = interval
+		}
+		span := runTracer.StartScope("watch cycle", tracing.Int("cycle", cycle))
+		err := watchCycle(ctx, cmd, args, slot)
+		span.End(err)
+		flushTraces()
+		if err != nil {
+			log.Printf("Warning: cycle %d: %v\n", cycle, err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		next := time.Now().Add(interval)
+		if spread {
+			next = start.Add(interval)
+		}
+		next = next.Add(randomDelay(jitter))
+		log.Printf("Next cycle at %s\n", next.Format("15:04:05"))
+		if !sleepCtx(ctx, time.Until(next)) {
+			break
+		}
+	}
+	log.Println("Shutting down")
+	return nil
+}
+
+// watchCycle refreshes the configuration and credentials, then runs the
+// approve policy once over every repository. When spread is positive, the
+// repositories are spread across that much time: each waits for a random
+// moment within its equal share of it.
+func watchCycle(ctx context.Context, cmd *cobra.Command, args []string, spread time.Duration) error {
+	if viper.ConfigFileUsed() != "" {
+		if err := viper.ReadInConfig(); err != nil {
+			log.Printf("Warning: failed to reload config, keeping the previous one: %v\n", err)
+		}
+	}
+	bouncer.SetBots(getStringSlice("bots"))
+	bouncer.SetTokenPool(nil)
+	if err := setupTokenPool(); err != nil {
+		return err
+	}
+	if err := waitForRateLimit(ctx); err != nil {
+		return err
+	}
+	// Ages such as --created-after 7d are relative to each cycle.
+	window, err := parseWindowFlags(cmd, time.Now())
+	if err != nil {
+		return err
+	}
+	prWindow = window
+
+	repos, err := resolveRepos(cmd, args, true)
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories specified. Use command-line arguments, --org, or configure repositories in config file")
+	}
+
+	summary := risk.NewSummary(viper.GetFloat64("risk.min_scorecard"))
+	limit := newActionLimit(cmd)
+	workers := concurrency(cmd)
+	results := newRunResults()
+	results.risk = summary
+	start := time.Now()
+	share := spread / time.Duration(len(repos))
+	next := 0
+	err = forEachRepo(os.Stdout, repos, func(owner, repo string) error {
+		if share > 0 {
+			at := start.Add(time.Duration(next)*share + randomDelay(share))
+			next++
+			sleepCtx(ctx, time.Until(at))
+		}
+		if ctx.Err() != nil {
+			return nil // shutting down; leave the remaining repositories alone
+		}
+		out := results.repo(owner + "/" + repo)
+		out.Err = runApprove(os.Stdout, owner, repo, summary, nil, limit, workers, out)
+		runMetrics.record(owner+"/"+repo, out)
+		return out.Err
+	})
+	printResults(results)
+	recordRun("watch", results)
+	notifyRun("watch", results, true)
+	return err
+}
+
+// waitForRateLimit sleeps until the rate-limit window resets when fewer than
+// watchMinBudget requests are left. Lookup failures are logged and ignored.
+func waitForRateLimit(ctx context.Context) error {
+	remaining, reset, err := gitHub().RateLimit(runCtx)
+	if err != nil {
+		log.Printf("Warning: %v\n", err)
+		return nil
+	}
+	if remaining >= watchMinBudget {
+		return nil
+	}
+	log.Printf("Only %d API requests left; waiting until %s\n", remaining, reset.Format("15:04:05"))
+	if !sleepCtx(ctx, time.Until(reset)) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// randomDelay returns a random duration in [0, limit), or 0 when limit is not
+// positive.
+func randomDelay(limit time.Duration) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+	return rand.N(limit)
+}
+
+// sleepCtx sleeps for d and reports whether it was not interrupted by ctx.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
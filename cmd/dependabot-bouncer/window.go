@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// selectionWindow limits listing commands to PRs opened or updated within a
+// time range. Zero times leave that side of the window open.
+type selectionWindow struct {
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedSince  time.Time
+}
+
+// prWindow is the window given on the command line, set by setupSCM.
+var prWindow selectionWindow
+
+// addWindowFlags registers the PR selection window flags.
+func addWindowFlags(cmd *cobra.Command) {
+	cmd.Flags().String("created-after", "", "Only consider PRs opened after this date or age (e.g. 2026-03-01 or 7d)")
+	cmd.Flags().String("created-before", "", "Only consider PRs opened before this date or age (e.g. 2026-01-01 or 90d)")
+	cmd.Flags().String("updated-since", "", "Only consider PRs updated since this date or age (e.g. 2026-03-01 or 24h)")
+}
+
+// parseWindowFlags reads the selection window flags of cmd, if it has them.
+func parseWindowFlags(cmd *cobra.Command, now time.Time) (selectionWindow, error) {
+	var w selectionWindow
+	for _, f := range []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"created-after", &w.CreatedAfter},
+		{"created-before", &w.CreatedBefore},
+		{"updated-since", &w.UpdatedSince},
+	} {
+		if cmd.Flags().Lookup(f.name) == nil {
+			continue
+		}
+		value, _ := cmd.Flags().GetString(f.name)
+		t, err := parseWindowTime(value, now)
+		if err != nil {
+			return selectionWindow{}, fmt.Errorf("invalid --%s: %w", f.name, err)
+		}
+		*f.dst = t
+	}
+	if !w.CreatedAfter.IsZero() && !w.CreatedBefore.IsZero() && !w.CreatedAfter.Before(w.CreatedBefore) {
+		return selectionWindow{}, fmt.Errorf("--created-after must be earlier than --created-before")
+	}
+	return w, nil
+}
+
+// parseWindowTime parses a window bound: a date (2006-01-02, local time), an
+// RFC 3339 timestamp, or an age before now in days (7d), weeks (2w), or any
+// Go duration (36h). An empty value is the zero time.
+func parseWindowTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if d, err := parseAge(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date (2006-01-02), timestamp, or age (7d, 2w, 36h)", value)
+}
+
+// parseAge parses an age in days (7d), weeks (2w), or any Go duration (36h).
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, err := strconv.Atoi(strings.TrimSuffix(value, suffix)); err == nil && strings.HasSuffix(value, suffix) && n >= 0 {
+			return time.Duration(n) * unit, nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("%q is not an age (7d, 2w, 36h)", value)
+}
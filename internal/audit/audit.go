@@ -0,0 +1,137 @@
+// Package audit keeps an append-only log of the actions the bouncer takes on
+// pull requests, one JSON object per line, so that every approval, recreate,
+// close, and merge can be traced to the run, the account, and the rule behind
+// it.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry is one PR acted on by a run.
+type Entry struct {
+	Time     time.Time `json:"time"`
+	Run      string    `json:"run"`             // identifies the run, shared by its entries
+	Actor    string    `json:"actor,omitempty"` // GitHub login the actions were taken as
+	User     string    `json:"user,omitempty"`  // local user who ran the bouncer
+	Host     string    `json:"host,omitempty"`  // machine the bouncer ran on
+	Command  string    `json:"command"`         // approve, automerge, recreate, maintain, ignore, close, undo, watch, serve
+	Repo     string    `json:"repo"`            // owner/repo
+	Number   int       `json:"number"`
+	URL      string    `json:"url,omitempty"`
+	Package  string    `json:"package,omitempty"`
+	Decision string    `json:"decision"`         // approved, recreated, rebased, closed, ignored, undone, or failed
+	Actions  []string  `json:"actions"`          // what was done, e.g. "approved", "auto-merge enabled"
+	Rule     string    `json:"rule,omitempty"`   // allow entry or exemption that let the PR through; empty when nothing but the deny lists applied
+	Config   string    `json:"config,omitempty"` // config file in effect
+	Errors   []string  `json:"errors,omitempty"`
+}
+
+// Log is an append-only audit log. Appending never rewrites earlier entries,
+// so concurrent runs can share it.
+type Log struct {
+	path string
+	mu   sync.Mutex
+}
+
+// Open returns the log at path. The file is created on the first append.
+func Open(path string) *Log {
+	return &Log{path: path}
+}
+
+// Path returns the file the log is kept in.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append adds entries to the end of the log.
+func (l *Log) Append(entries ...Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	var b []byte
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		b = append(append(b, line...), '\n')
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// Entries returns the entries of the log, oldest first. Lines that cannot be
+// parsed, such as one cut short by a crash, are skipped.
+func (l *Log) Entries() ([]Entry, error) {
+	f, err := os.Open(l.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer f.Close()
+	var out []Entry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	for sc.Scan() {
+		var e Entry
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			out = append(out, e)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return out, nil
+}
+
+// LastRun returns the entries of the latest run among entries for which keep
+// returns true, in log order. Entries of the run that keep rejects are left
+// out too.
+func LastRun(entries []Entry, keep func(Entry) bool) []Entry {
+	var last string
+	for _, e := range entries {
+		if keep(e) {
+			last = e.Run
+		}
+	}
+	var out []Entry
+	for _, e := range entries {
+		if last != "" && e.Run == last && keep(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// NewRunID returns an identifier for a run starting at t, unique enough to
+// tell the runs of one log apart.
+func NewRunID(t time.Time) string {
+	return fmt.Sprintf("%s-%d", t.UTC().Format("20060102T150405.000Z"), os.Getpid())
+}
@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	l := Open(path)
+
+	if err := l.Append(
+		Entry{Time: now, Run: "r1", Command: "approve", Repo: "acme/api", Number: 7, Decision: "approved", Actions: []string{"approved", "auto-merge enabled"}, Rule: "acme"},
+		Entry{Time: now, Run: "r1", Command: "approve", Repo: "acme/api", Number: 8, Decision: "failed", Actions: []string{}, Errors: []string{"boom"}},
+	); err != nil {
+		t.Fatalf("Append(): %v", err)
+	}
+	if err := Open(path).Append(Entry{Time: now.Add(time.Hour), Run: "r2", Command: "recreate", Repo: "acme/web", Number: 3, Decision: "recreated"}); err != nil {
+		t.Fatalf("Append(): %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got []Entry
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		got = append(got, e)
+	}
+	if len(got) != 3 || got[0].Rule != "acme" || got[1].Errors[0] != "boom" || got[2].Run != "r2" {
+		t.Errorf("entries = %+v", got)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("audit log mode = %v, %v, want 0600", info.Mode().Perm(), err)
+	}
+}
+
+func TestEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if got, err := Open(path).Entries(); err != nil || len(got) != 0 {
+		t.Fatalf("Entries() of a missing log = %v, %v", got, err)
+	}
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := Open(path).Append(Entry{Time: now, Run: "r1", Command: "approve", Repo: "acme/api", Number: 7, Decision: "approved"}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"time":"2026-06-01T13:00:00Z","run":"r2","comm` + "\n")
+	f.Close()
+
+	got, err := Open(path).Entries()
+	if err != nil {
+		t.Fatalf("Entries(): %v", err)
+	}
+	if len(got) != 1 || got[0].Number != 7 || !got[0].Time.Equal(now) {
+		t.Errorf("Entries() = %+v, want the one complete entry", got)
+	}
+}
+
+func TestLastRun(t *testing.T) {
+	entries := []Entry{
+		{Run: "r1", Command: "approve", Repo: "acme/api", Number: 1},
+		{Run: "r1", Command: "approve", Repo: "acme/web", Number: 2},
+		{Run: "r2", Command: "approve", Repo: "acme/api", Number: 3},
+		{Run: "r2", Command: "approve", Repo: "acme/api", Number: 4},
+		{Run: "r3", Command: "undo", Repo: "acme/api", Number: 3},
+	}
+	notUndo := func(e Entry) bool { return e.Command != "undo" }
+
+	got := LastRun(entries, notUndo)
+	if len(got) != 2 || got[0].Number != 3 || got[1].Number != 4 {
+		t.Errorf("LastRun() = %+v, want #3 and #4 of r2", got)
+	}
+	got = LastRun(entries, func(e Entry) bool { return notUndo(e) && e.Repo == "acme/web" })
+	if len(got) != 1 || got[0].Number != 2 {
+		t.Errorf("LastRun(acme/web) = %+v, want #2 of r1", got)
+	}
+	if got := LastRun(entries, func(Entry) bool { return false }); len(got) != 0 {
+		t.Errorf("LastRun() = %+v, want none", got)
+	}
+}
@@ -0,0 +1,104 @@
+// Package events publishes the actions taken by a run to a message queue, so
+// other systems can build dashboards and workflows on top of them.
+package events
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+// Event types.
+const (
+	Approved  = "approved"
+	Recreated = "recreated"
+	Denied    = "denied"
+)
+
+// Source identifies the events' producer.
+const Source = "dependabot-bouncer"
+
+// Event is one action taken on a pull request.
+type Event struct {
+	Type        string    `json:"type"`
+	Source      string    `json:"source"`
+	Time        time.Time `json:"time"`
+	Repository  string    `json:"repository"`
+	PullRequest int       `json:"pull_request"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Package     string    `json:"package,omitempty"`
+	Ecosystem   string    `json:"ecosystem,omitempty"`
+	FromVersion string    `json:"from_version,omitempty"`
+	ToVersion   string    `json:"to_version,omitempty"`
+	UpdateType  string    `json:"update_type,omitempty"`
+	Security    bool      `json:"security"`
+}
+
+// NewEvent returns an event of the given type for a PR of "owner/repo".
+func NewEvent(typ, repo string, pr bouncer.PRInfo, at time.Time) Event {
+	return Event{
+		Type:        typ,
+		Source:      Source,
+		Time:        at.UTC(),
+		Repository:  repo,
+		PullRequest: pr.Number,
+		Title:       pr.Title,
+		URL:         pr.URL,
+		Package:     pr.PackageName,
+		Ecosystem:   pr.Ecosystem,
+		FromVersion: pr.FromVersion,
+		ToVersion:   pr.ToVersion,
+		UpdateType:  pr.UpdateType,
+		Security:    pr.Security,
+	}
+}
+
+// Sink delivers encoded events to one message queue.
+type Sink interface {
+	Send(payload []byte) error
+	Close() error
+	String() string // names the destination in warnings
+}
+
+// Publisher fans events out to its sinks. Delivery failures are logged and
+// never fail the run. A nil Publisher discards events.
+type Publisher struct {
+	sinks []Sink
+}
+
+// NewPublisher returns a publisher delivering to sinks.
+func NewPublisher(sinks ...Sink) *Publisher {
+	return &Publisher{sinks: sinks}
+}
+
+// Publish delivers an event to every sink.
+func (p *Publisher) Publish(e Event) {
+	if p == nil || len(p.sinks) == 0 {
+		return
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("Warning: failed to encode %s event: %v\n", e.Type, err)
+		return
+	}
+	for _, s := range p.sinks {
+		if err := s.Send(payload); err != nil {
+			log.Printf("Warning: failed to publish %s event for %s#%d to %s: %v\n", e.Type, e.Repository, e.PullRequest, s, err)
+		}
+	}
+}
+
+// Close closes every sink.
+func (p *Publisher) Close() {
+	if p == nil {
+		return
+	}
+	for _, s := range p.sinks {
+		if err := s.Close(); err != nil {
+			log.Printf("Warning: failed to close %s: %v\n", s, err)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package events
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+var testEvent = NewEvent(Approved, "acme/api", bouncer.PRInfo{
+	Number: 7, Title: "Bump lodash from 4.17.20 to 4.17.21", PackageName: "lodash",
+}, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+
+// fakeNATS accepts one connection, answers like a NATS server, and sends the
+// payload of each PUB to msgs. With a TLS config, the server requires TLS.
+func fakeNATS(t *testing.T, msgs chan<- string, config *tls.Config) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if config == nil {
+			fmt.Fprint(conn, "INFO {\"server_id\":\"test\"}\r\n")
+		} else {
+			fmt.Fprint(conn, "INFO {\"server_id\":\"test\",\"tls_required\":true}\r\n")
+			conn = tls.Server(conn, config)
+		}
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch fields := strings.Fields(line); {
+			case len(fields) == 0:
+			case fields[0] == "PING":
+				fmt.Fprint(conn, "PONG\r\n")
+			case fields[0] == "PUB" && len(fields) == 3:
+				var size int
+				fmt.Sscan(fields[2], &size)
+				buf := make([]byte, size+2)
+				if _, err := io.ReadFull(r, buf); err != nil {
+					return
+				}
+				msgs <- fields[1] + " " + string(buf[:size])
+			}
+		}
+	}()
+	return "nats://" + ln.Addr().String()
+}
+
+func TestNATS(t *testing.T) {
+	msgs := make(chan string, 2)
+	sink, err := NewNATS(fakeNATS(t, msgs, nil), "bouncer.events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	publishTwice(t, sink, msgs)
+}
+
+func TestNATSTLS(t *testing.T) {
+	// Borrow the test server's certificate, valid for 127.0.0.1.
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	roots := srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	msgs := make(chan string, 2)
+	sink, err := NewNATS(fakeNATS(t, msgs, &tls.Config{Certificates: srv.TLS.Certificates}), "bouncer.events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.TLS = &tls.Config{RootCAs: roots}
+	publishTwice(t, sink, msgs)
+}
+
+func TestNATSTLSUnverified(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	msgs := make(chan string, 1)
+	sink, err := NewNATS(fakeNATS(t, msgs, &tls.Config{Certificates: srv.TLS.Certificates}), "bouncer.events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	if err := sink.Send([]byte("{}")); err == nil || !strings.Contains(err.Error(), "TLS handshake failed") {
+		t.Errorf("Send() error = %v, want a TLS handshake failure", err)
+	}
+}
+
+// publishTwice publishes testEvent twice through sink and checks that both
+// reach msgs.
+func publishTwice(t *testing.T, sink *NATS, msgs <-chan string) {
+	t.Helper()
+	p := NewPublisher(sink)
+	defer p.Close()
+
+	p.Publish(testEvent)
+	p.Publish(testEvent)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-msgs:
+			subject, payload, _ := strings.Cut(msg, " ")
+			var got Event
+			if err := json.Unmarshal([]byte(payload), &got); err != nil {
+				t.Fatalf("payload is not an event: %v", err)
+			}
+			if subject != "bouncer.events" || got != testEvent {
+				t.Errorf("published %s %+v", subject, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("no message published")
+		}
+	}
+}
+
+func TestNewNATSValidation(t *testing.T) {
+	for _, tt := range []struct{ url, subject string }{
+		{"http://localhost:4222", "events"},
+		{"tls://", "events"},
+		{"nats://localhost:4222", ""},
+		{"nats://localhost:4222", "two words"},
+	} {
+		if _, err := NewNATS(tt.url, tt.subject); err == nil {
+			t.Errorf("NewNATS(%q, %q) succeeded, want error", tt.url, tt.subject)
+		}
+	}
+}
+
+func TestKafka(t *testing.T) {
+	var got Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/topics/bouncer-events" || r.Header.Get("Content-Type") != "application/vnd.kafka.json.v2+json" {
+			t.Errorf("unexpected request %s %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		var body struct {
+			Records []struct {
+				Value Event `json:"value"`
+			} `json:"records"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Records) != 1 {
+			t.Errorf("unexpected body: %v", err)
+			return
+		}
+		got = body.Records[0].Value
+		w.Write([]byte(`{"offsets":[{"partition":0,"offset":1}]}`))
+	}))
+	defer srv.Close()
+
+	sink, err := NewKafka(srv.URL+"/", "bouncer-events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Send(mustMarshal(t, testEvent)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got != testEvent {
+		t.Errorf("produced %+v, want %+v", got, testEvent)
+	}
+}
+
+func TestKafkaRecordError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"offsets":[{"error_code":40403,"error":"Schema not found"}]}`))
+	}))
+	defer srv.Close()
+
+	sink, _ := NewKafka(srv.URL, "bouncer-events")
+	if err := sink.Send(mustMarshal(t, testEvent)); err == nil || !strings.Contains(err.Error(), "Schema not found") {
+		t.Errorf("Send() error = %v, want the record error", err)
+	}
+}
+
+func TestNilPublisher(t *testing.T) {
+	var p *Publisher
+	p.Publish(testEvent) // must not panic
+	p.Close()
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,184 @@
+package events
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsTimeout bounds connecting to and each round trip with the NATS server.
+const natsTimeout = 10 * time.Second
+
+// NATS publishes events to a subject on a NATS server using the core NATS
+// text protocol. Each publish is confirmed with a PING/PONG round trip, so a
+// rejected message surfaces as an error. The connection is upgraded to TLS
+// when the URL's scheme is tls or the server requires it.
+type NATS struct {
+	URL     string // nats://[user:password@ | token@]host:port, or tls://...
+	Subject string
+	TLS     *tls.Config // for the TLS handshake; nil verifies against the system roots
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// NewNATS returns a sink publishing to subject on the server at rawURL. The
+// connection is made on the first publish.
+func NewNATS(rawURL, subject string) (*NATS, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "nats" && u.Scheme != "tls") || u.Host == "" {
+		return nil, fmt.Errorf("invalid NATS URL %q (expected nats://host:port or tls://host:port)", rawURL)
+	}
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return nil, fmt.Errorf("invalid NATS subject %q", subject)
+	}
+	return &NATS{URL: rawURL, Subject: subject}, nil
+}
+
+func (n *NATS) String() string {
+	return "NATS subject " + n.Subject
+}
+
+// Send publishes payload to the subject.
+func (n *NATS) Send(payload []byte) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.conn == nil {
+		if err := n.connect(); err != nil {
+			return err
+		}
+	}
+	msg := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", n.Subject, len(payload), payload)
+	if err := n.roundTrip(msg); err != nil {
+		n.reset()
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection, if any.
+func (n *NATS) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.conn == nil {
+		return nil
+	}
+	err := n.conn.Close()
+	n.conn, n.r = nil, nil
+	return err
+}
+
+// connect dials the server, reads its INFO, switches to TLS if needed, and
+// authenticates.
+func (n *NATS) connect() error {
+	u, _ := url.Parse(n.URL)
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	conn, err := net.DialTimeout("tcp", host, natsTimeout)
+	if err != nil {
+		return err
+	}
+	n.conn, n.r = conn, bufio.NewReader(conn)
+
+	conn.SetDeadline(time.Now().Add(natsTimeout))
+	line, err := n.r.ReadString('\n')
+	if err != nil {
+		n.reset()
+		return fmt.Errorf("failed to read server INFO: %w", err)
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		n.reset()
+		return fmt.Errorf("unexpected greeting %q", strings.TrimSpace(line))
+	}
+	var info struct {
+		TLSRequired bool `json:"tls_required"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "INFO ")), &info); err != nil {
+		n.reset()
+		return fmt.Errorf("failed to parse server INFO: %w", err)
+	}
+	secure := u.Scheme == "tls" || info.TLSRequired
+	if secure {
+		if err := n.startTLS(u.Hostname()); err != nil {
+			n.reset()
+			return err
+		}
+	}
+
+	opts := map[string]any{"verbose": false, "pedantic": false, "tls_required": secure, "name": Source, "lang": "go", "version": "1"}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			opts["user"], opts["pass"] = u.User.Username(), pass
+		} else {
+			opts["auth_token"] = u.User.Username()
+		}
+	}
+	connect, _ := json.Marshal(opts)
+	if err := n.roundTrip("CONNECT " + string(connect) + "\r\nPING\r\n"); err != nil {
+		n.reset()
+		return err
+	}
+	return nil
+}
+
+// startTLS performs the TLS handshake on the connection, verifying the
+// server's certificate for host unless n.TLS names another server.
+func (n *NATS) startTLS(host string) error {
+	cfg := &tls.Config{}
+	if n.TLS != nil {
+		cfg = n.TLS.Clone()
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = host
+	}
+	conn := tls.Client(n.conn, cfg)
+	if err := conn.Handshake(); err != nil {
+		return fmt.Errorf("TLS handshake failed: %w", err)
+	}
+	n.conn, n.r = conn, bufio.NewReader(conn)
+	return nil
+}
+
+// roundTrip writes msg, which must end with PING, and waits for the PONG.
+// Server PINGs are answered; -ERR responses are returned as errors.
+func (n *NATS) roundTrip(msg string) error {
+	n.conn.SetDeadline(time.Now().Add(natsTimeout))
+	if _, err := n.conn.Write([]byte(msg)); err != nil {
+		return err
+	}
+	for {
+		line, err := n.r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := n.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+// reset drops a broken connection so the next publish reconnects.
+func (n *NATS) reset() {
+	if n.conn != nil {
+		n.conn.Close()
+	}
+	n.conn, n.r = nil, nil
+}
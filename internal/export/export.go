@@ -0,0 +1,137 @@
+// Package export records the dependency version changes approved in a run in
+// a JSON format that SBOM and compliance tooling can ingest.
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+// Format identifies the document layout; bump FormatVersion on breaking changes.
+const (
+	Format        = "dependabot-bouncer/changes"
+	FormatVersion = 2
+)
+
+// purlTypes maps Dependabot package-ecosystem names to package URL types
+// (https://github.com/package-url/purl-spec).
+var purlTypes = map[string]string{
+	"gomod":          "golang",
+	"npm":            "npm",
+	"pip":            "pypi",
+	"docker":         "docker",
+	"github-actions": "github",
+	"bundler":        "gem",
+	"maven":          "maven",
+	"gradle":         "maven",
+	"cargo":          "cargo",
+	"composer":       "composer",
+	"nuget":          "nuget",
+	"mix":            "hex",
+	"pub":            "pub",
+}
+
+// Change is one approved dependency version change. It carries no merge
+// time: approve leaves merging to auto-merge or Dependabot, so PRs merge after
+// the document is written. Version 1 had an always-null merged_at field.
+type Change struct {
+	Repository  string    `json:"repository"`
+	Package     string    `json:"package"`
+	Ecosystem   string    `json:"ecosystem,omitempty"`
+	PURL        string    `json:"purl,omitempty"` // package URL of the new version; empty when the ecosystem or version is unknown
+	FromVersion string    `json:"from_version,omitempty"`
+	ToVersion   string    `json:"to_version,omitempty"`
+	UpdateType  string    `json:"update_type,omitempty"`
+	Security    bool      `json:"security"`
+	PullRequest int       `json:"pull_request"`
+	URL         string    `json:"url"`
+	ApprovedAt  time.Time `json:"approved_at"`
+}
+
+// Document is the exported record of a run.
+type Document struct {
+	Format      string    `json:"format"`
+	Version     int       `json:"version"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Changes     []Change  `json:"changes"`
+}
+
+// Log accumulates the changes approved in a run. A nil Log discards them.
+type Log struct {
+	mu      sync.Mutex
+	changes []Change
+}
+
+// NewLog returns an empty log.
+func NewLog() *Log {
+	return &Log{}
+}
+
+// Record adds an approved PR of "owner/repo".
+func (l *Log) Record(repo string, pr bouncer.PRInfo, at time.Time) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.changes = append(l.changes, Change{
+		Repository:  repo,
+		Package:     pr.PackageName,
+		Ecosystem:   pr.Ecosystem,
+		PURL:        purl(pr.Ecosystem, pr.PackageName, pr.ToVersion),
+		FromVersion: pr.FromVersion,
+		ToVersion:   pr.ToVersion,
+		UpdateType:  pr.UpdateType,
+		Security:    pr.Security,
+		PullRequest: pr.Number,
+		URL:         pr.URL,
+		ApprovedAt:  at.UTC(),
+	})
+}
+
+// Len returns the number of recorded changes.
+func (l *Log) Len() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.changes)
+}
+
+// Write writes the recorded changes as an indented JSON document.
+func (l *Log) Write(w io.Writer, now time.Time) error {
+	l.mu.Lock()
+	doc := Document{
+		Format:      Format,
+		Version:     FormatVersion,
+		GeneratedAt: now.UTC(),
+		Changes:     append([]Change{}, l.changes...),
+	}
+	l.mu.Unlock()
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// purl returns the package URL of a package version, or "" when the ecosystem
+// has no package URL type or the version is unknown.
+func purl(ecosystem, name, version string) string {
+	typ, ok := purlTypes[ecosystem]
+	if !ok || name == "" || version == "" {
+		return ""
+	}
+	if typ == "maven" {
+		// group:artifact becomes the namespace/name pair.
+		name = strings.Replace(name, ":", "/", 1)
+	}
+	name = strings.ReplaceAll(name, "@", "%40")
+	return "pkg:" + typ + "/" + name + "@" + version
+}
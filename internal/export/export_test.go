@@ -0,0 +1,58 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+func TestPURL(t *testing.T) {
+	tests := []struct {
+		ecosystem, name, version string
+		want                     string
+	}{
+		{"npm", "lodash", "4.17.21", "pkg:npm/lodash@4.17.21"},
+		{"npm", "@types/node", "20.1.0", "pkg:npm/%40types/node@20.1.0"},
+		{"gomod", "github.com/stretchr/testify", "1.9.0", "pkg:golang/github.com/stretchr/testify@1.9.0"},
+		{"maven", "com.google.guava:guava", "33.0.0", "pkg:maven/com.google.guava/guava@33.0.0"},
+		{"terraform", "hashicorp/aws", "5.0.0", ""},
+		{"npm", "lodash", "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := purl(tt.ecosystem, tt.name, tt.version); got != tt.want {
+			t.Errorf("purl(%q, %q, %q) = %q, want %q", tt.ecosystem, tt.name, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestLogWrite(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	l := NewLog()
+	l.Record("acme/api", bouncer.PRInfo{
+		Number: 7, URL: "https://github.com/acme/api/pull/7", PackageName: "lodash", Ecosystem: "npm",
+		FromVersion: "4.17.20", ToVersion: "4.17.21", UpdateType: bouncer.UpdatePatch,
+	}, now)
+
+	var buf bytes.Buffer
+	if err := l.Write(&buf, now); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	var doc Document
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if doc.Format != Format || len(doc.Changes) != 1 {
+		t.Fatalf("Write() = %+v", doc)
+	}
+	if c := doc.Changes[0]; c.Repository != "acme/api" || c.PURL != "pkg:npm/lodash@4.17.21" {
+		t.Errorf("change = %+v", c)
+	}
+
+	var nilLog *Log
+	nilLog.Record("acme/api", bouncer.PRInfo{}, now) // must not panic
+}
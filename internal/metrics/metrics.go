@@ -0,0 +1,159 @@
+// Package metrics keeps counters for long-running modes and exposes them in
+// the Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the media type of the Prometheus text format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Registry holds counters and serves them at a metrics endpoint.
+type Registry struct {
+	mu       sync.Mutex
+	counters []*Counter
+	collect  []func()
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Counter registers a counter family with the given label names.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	c := &Counter{name: name, help: help, labels: labels, values: make(map[string]*sample)}
+	r.mu.Lock()
+	r.counters = append(r.counters, c)
+	r.mu.Unlock()
+	return c
+}
+
+// OnScrape registers fn to run before each scrape, to bring counters mirrored
+// from elsewhere up to date.
+func (r *Registry) OnScrape(fn func()) {
+	r.mu.Lock()
+	r.collect = append(r.collect, fn)
+	r.mu.Unlock()
+}
+
+// ServeHTTP writes every counter in the text exposition format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	r.WriteTo(w)
+}
+
+// WriteTo writes every counter in the text exposition format, families in
+// registration order and series sorted by label values.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	collect := append([]func(){}, r.collect...)
+	counters := append([]*Counter{}, r.counters...)
+	r.mu.Unlock()
+	for _, fn := range collect {
+		fn()
+	}
+
+	var b strings.Builder
+	for _, c := range counters {
+		c.write(&b)
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Counter is a family of monotonically increasing values, one per
+// combination of label values.
+type Counter struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]*sample
+}
+
+type sample struct {
+	labels []string
+	value  float64
+}
+
+// Inc adds one to the series with the given label values.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v to the series with the given label values.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.series(labelValues).value += v
+}
+
+// Set sets the series with the given label values, for counters that mirror
+// a count kept elsewhere.
+func (c *Counter) Set(v float64, labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.series(labelValues).value = v
+}
+
+// series returns the sample for labelValues, creating it at zero; c.mu must
+// be held.
+func (c *Counter) series(labelValues []string) *sample {
+	if len(labelValues) != len(c.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", c.name, len(c.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := c.values[key]
+	if !ok {
+		s = &sample{labels: append([]string(nil), labelValues...)}
+		c.values[key] = s
+	}
+	return s
+}
+
+func (c *Counter) write(b *strings.Builder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fmt.Fprintf(b, "# HELP %s %s\n", c.name, escapeHelp(c.help))
+	fmt.Fprintf(b, "# TYPE %s counter\n", c.name)
+	keys := make([]string, 0, len(c.values))
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := c.values[k]
+		b.WriteString(c.name)
+		if len(c.labels) > 0 {
+			b.WriteByte('{')
+			for i, name := range c.labels {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				fmt.Fprintf(b, "%s=\"%s\"", name, escapeLabel(s.labels[i]))
+			}
+			b.WriteByte('}')
+		}
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatFloat(s.value, 'g', -1, 64))
+		b.WriteByte('\n')
+	}
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(s)
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	approved := r.Counter("bouncer_prs_approved_total", "PRs approved.", "repository", "org")
+	errors := r.Counter("bouncer_api_errors_total", "Failed API calls.", "repository")
+
+	approved.Inc("acme/web", "aws")
+	approved.Inc("acme/api", "")
+	approved.Add(2, "acme/api", "")
+	approved.Inc("acme/api", `we"ird\`)
+	mirrored := 0
+	r.OnScrape(func() {
+		mirrored++
+		errors.Set(float64(mirrored), "acme/api")
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	want := `# HELP bouncer_prs_approved_total PRs approved.
+# TYPE bouncer_prs_approved_total counter
+bouncer_prs_approved_total{repository="acme/api",org=""} 3
+bouncer_prs_approved_total{repository="acme/api",org="we\"ird\\"} 1
+bouncer_prs_approved_total{repository="acme/web",org="aws"} 1
+# HELP bouncer_api_errors_total Failed API calls.
+# TYPE bouncer_api_errors_total counter
+bouncer_api_errors_total{repository="acme/api"} 1
+`
+	if got := rec.Body.String(); got != want {
+		t.Errorf("scrape =\n%s\nwant\n%s", got, want)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
+
+func TestCounterLabelMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Inc() with the wrong number of labels did not panic")
+		}
+	}()
+	NewRegistry().Counter("c_total", "", "repository").Inc()
+}
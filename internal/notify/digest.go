@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Digest is a periodic overview of the dependency update queue of a set of
+// repositories.
+type Digest struct {
+	Period string // "daily" or "weekly"
+	Since  time.Time
+	Until  time.Time
+	Repos  []DigestRepo
+}
+
+// DigestRepo is the part of a digest about one repository.
+type DigestRepo struct {
+	Name    string
+	Open    []DigestPR // open PRs the policy lets through
+	Blocked []DigestPR // open PRs left alone by the allow and deny lists
+	Merged  []DigestPR // PRs merged during the period
+	Error   string     // the repository could not be read
+}
+
+// DigestPR is a pull request listed in a digest.
+type DigestPR struct {
+	Number int
+	Title  string
+	URL    string
+	Time   time.Time // opened, or merged for merged PRs
+	Reason string    // why a blocked PR is left alone
+}
+
+// totals returns the number of open, blocked, and merged PRs.
+func (d Digest) totals() (open, blocked, merged int) {
+	for _, r := range d.Repos {
+		open += len(r.Open)
+		blocked += len(r.Blocked)
+		merged += len(r.Merged)
+	}
+	return open, blocked, merged
+}
+
+// Subject returns the digest's email subject line.
+func (d Digest) Subject() string {
+	open, blocked, merged := d.totals()
+	return fmt.Sprintf("Dependabot %s digest: %d open, %d blocked, %d merged", d.Period, open, blocked, merged)
+}
+
+// WriteDigest writes the digest as plain text.
+func WriteDigest(w io.Writer, d Digest) error {
+	var b strings.Builder
+	open, blocked, merged := d.totals()
+	fmt.Fprintf(&b, "Dependabot %s digest, %s to %s\n\n", d.Period, d.Since.Format("2006-01-02"), d.Until.Format("2006-01-02"))
+	fmt.Fprintf(&b, "%d open, %d blocked by policy, %d merged in %d repositories\n", open, blocked, merged, len(d.Repos))
+
+	for _, r := range d.Repos {
+		fmt.Fprintf(&b, "\n%s\n", r.Name)
+		if r.Error != "" {
+			fmt.Fprintf(&b, "  Could not be read: %s\n", r.Error)
+			continue
+		}
+		if len(r.Open) == 0 && len(r.Blocked) == 0 && len(r.Merged) == 0 {
+			b.WriteString("  Nothing open or merged\n")
+			continue
+		}
+		digestSection(&b, "Open", r.Open, func(pr DigestPR) string {
+			return fmt.Sprintf("opened %s, %d days ago", pr.Time.Format("2006-01-02"), int(d.Until.Sub(pr.Time).Hours()/24))
+		})
+		digestSection(&b, "Blocked by policy", r.Blocked, func(pr DigestPR) string {
+			return pr.Reason
+		})
+		digestSection(&b, "Merged", r.Merged, func(pr DigestPR) string {
+			return "merged " + pr.Time.Format("2006-01-02")
+		})
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// digestSection writes one titled list of PRs, with a note for each.
+func digestSection(b *strings.Builder, title string, prs []DigestPR, note func(DigestPR) string) {
+	if len(prs) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "  %s (%d):\n", title, len(prs))
+	for _, pr := range prs {
+		fmt.Fprintf(b, "    #%d %s (%s)\n", pr.Number, pr.Title, note(pr))
+		if pr.URL != "" {
+			fmt.Fprintf(b, "      %s\n", pr.URL)
+		}
+	}
+}
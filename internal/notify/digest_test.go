@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testDigest() Digest {
+	until := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	return Digest{
+		Period: "weekly",
+		Since:  until.AddDate(0, 0, -7),
+		Until:  until,
+		Repos: []DigestRepo{
+			{
+				Name:    "acme/api",
+				Open:    []DigestPR{{Number: 12, Title: "Bump x from 1.0.0 to 1.1.0", URL: "https://github.com/acme/api/pull/12", Time: until.AddDate(0, 0, -30)}},
+				Blocked: []DigestPR{{Number: 13, Title: "Bump y from 1.0.0 to 2.0.0", Reason: "denied package: y"}},
+				Merged:  []DigestPR{{Number: 10, Title: "Bump z from 1.0.0 to 1.0.1", Time: until.AddDate(0, 0, -2)}},
+			},
+			{Name: "acme/web"},
+			{Name: "acme/old", Error: "timed out"},
+		},
+	}
+}
+
+func TestWriteDigest(t *testing.T) {
+	var b strings.Builder
+	if err := WriteDigest(&b, testDigest()); err != nil {
+		t.Fatal(err)
+	}
+	want := `Dependabot weekly digest, 2026-10-08 to 2026-10-15
+
+1 open, 1 blocked by policy, 1 merged in 3 repositories
+
+acme/api
+  Open (1):
+    #12 Bump x from 1.0.0 to 1.1.0 (opened 2026-09-15, 30 days ago)
+      https://github.com/acme/api/pull/12
+  Blocked by policy (1):
+    #13 Bump y from 1.0.0 to 2.0.0 (denied package: y)
+  Merged (1):
+    #10 Bump z from 1.0.0 to 1.0.1 (merged 2026-10-13)
+
+acme/web
+  Nothing open or merged
+
+acme/old
+  Could not be read: timed out
+`
+	if got := b.String(); got != want {
+		t.Errorf("WriteDigest() =\n%s\nwant\n%s", got, want)
+	}
+	if got, want := testDigest().Subject(), "Dependabot weekly digest: 1 open, 1 blocked, 1 merged"; got != want {
+		t.Errorf("Subject() = %q, want %q", got, want)
+	}
+}
+
+func TestEmailMessage(t *testing.T) {
+	e := Email{From: "bouncer@example.com", To: []string{"a@example.com", "b@example.com"}}
+	body := "Bump café " + strings.Repeat("x", 100) + "\nsecond line\n"
+	raw, err := e.message("Digest — 3 open", body, time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	if got := msg.Header.Get("To"); got != "a@example.com, b@example.com" {
+		t.Errorf("To = %q", got)
+	}
+	if subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); err != nil || subject != "Digest — 3 open" {
+		t.Errorf("Subject = %q, %v", subject, err)
+	}
+	decoded, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.ReplaceAll(string(decoded), "\r\n", "\n"); got != body {
+		t.Errorf("body = %q, want %q", got, body)
+	}
+}
+
+func TestEmailSendRequiresRecipients(t *testing.T) {
+	if err := (Email{Host: "smtp.example.com", From: "bouncer@example.com"}).Send("s", "b"); err == nil {
+		t.Error("Send() without recipients succeeded, want error")
+	}
+}
@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// discordMaxContent is the longest message Discord accepts.
+const discordMaxContent = 2000
+
+// Discord posts summaries to a Discord channel webhook.
+type Discord struct {
+	WebhookURL string
+
+	client *http.Client
+}
+
+// NewDiscord returns a notifier posting to the webhook at webhookURL.
+func NewDiscord(webhookURL string) (*Discord, error) {
+	if !validURL(webhookURL, true) {
+		return nil, fmt.Errorf("invalid Discord webhook URL: must be an https URL")
+	}
+	return &Discord{WebhookURL: webhookURL, client: newClient()}, nil
+}
+
+func (d *Discord) String() string {
+	return "Discord webhook"
+}
+
+// Notify posts the summary as a single message.
+func (d *Discord) Notify(sum Summary) error {
+	return postJSON(d.client, d.WebhookURL, nil, map[string]any{
+		"content": discordText(sum),
+		// PR titles and errors are untrusted; never let them ping anyone.
+		"allowed_mentions": map[string]any{"parse": []string{}},
+	})
+}
+
+// Post posts a Markdown document as a single message, cut to Discord's
+// message limit. The title is left out: documents start with their own.
+func (d *Discord) Post(title, markdown string) error {
+	return postJSON(d.client, d.WebhookURL, nil, map[string]any{
+		"content":          truncateLines(markdown, discordMaxContent),
+		"allowed_mentions": map[string]any{"parse": []string{}},
+	})
+}
+
+// discordText renders a summary in Discord's Markdown, dropping whole lines
+// from the end to stay within Discord's message limit.
+func discordText(sum Summary) string {
+	return truncateLines(strings.Join(sum.lines(markdownMarkup), "\n"), discordMaxContent)
+}
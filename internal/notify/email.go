@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Email sends messages through an SMTP server.
+type Email struct {
+	Host     string
+	Port     int // default 587
+	Username string
+	Password string
+	From     string
+	To       []string
+	// ImplicitTLS connects with TLS from the start (usually port 465)
+	// instead of upgrading with STARTTLS when the server offers it.
+	ImplicitTLS bool
+}
+
+func (e Email) String() string {
+	return "email to " + strings.Join(e.To, ", ")
+}
+
+// Send emails a plain-text message to every recipient.
+func (e Email) Send(subject, body string) error {
+	if e.Host == "" || e.From == "" || len(e.To) == 0 {
+		return fmt.Errorf("an SMTP host, a sender, and at least one recipient are required")
+	}
+	port := e.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(port))
+	msg, err := e.message(subject, body, time.Now())
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if e.Username != "" {
+		auth = smtp.PlainAuth("", e.Username, e.Password, e.Host)
+	}
+	if !e.ImplicitTLS {
+		return smtp.SendMail(addr, auth, e.From, e.To, msg)
+	}
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: e.Host})
+	if err != nil {
+		return err
+	}
+	c, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if auth != nil {
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(e.From); err != nil {
+		return err
+	}
+	for _, to := range e.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message builds the RFC 5322 message, with the body quoted-printable
+// encoded so long lines and non-ASCII titles survive any relay.
+func (e Email) message(subject, body string, date time.Time) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", e.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	qp := quotedprintable.NewWriter(&b)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,298 @@
+// Package notify posts a summary of each run to chat and mail systems.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Action names counted in a summary, in display order.
+var actionOrder = []string{"approved", "recreated", "rebased", "closed"}
+
+// Summary is what a run did across the repositories routed to one
+// destination.
+type Summary struct {
+	Command string `json:"command"` // approve, recreate, maintain, ...
+	Repos   []Repo `json:"repositories"`
+	Risk    *Risk  `json:"risk,omitempty"` // of the updates approved; nil when none were
+}
+
+// Risk rates the updates a run approved in the repositories of a summary.
+type Risk struct {
+	Level    string   `json:"level"` // low, medium, or high
+	Major    []string `json:"major,omitempty"`
+	Unknown  []string `json:"unknown_type,omitempty"`
+	Security []string `json:"security,omitempty"`
+	LowScore []string `json:"low_scorecard,omitempty"` // packages with their OpenSSF Scorecard score
+}
+
+// Repo is what a run did in one repository.
+type Repo struct {
+	Name     string         `json:"name"`
+	Actions  map[string]int `json:"actions,omitempty"` // PRs acted on by action: approved, recreated, rebased, closed
+	Skipped  map[string]int `json:"skipped,omitempty"` // PRs left alone by reason, e.g. DENIED_PACKAGE or failing checks
+	Failures []Failure      `json:"failures,omitempty"`
+	Error    string         `json:"error,omitempty"` // the repository could not be processed
+}
+
+// Failure is a PR on which an action failed.
+type Failure struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	URL    string `json:"url,omitempty"`
+	Error  string `json:"error"`
+}
+
+// Notifier delivers run summaries to one destination.
+type Notifier interface {
+	Notify(s Summary) error
+	String() string // names the destination in warnings
+}
+
+// Poster posts a document written in Markdown, such as a report, to one
+// destination. Every Notifier in this package is also a Poster.
+type Poster interface {
+	Post(title, markdown string) error
+	String() string // names the destination in warnings
+}
+
+// Empty reports whether the run neither acted on a PR nor failed.
+func (s Summary) Empty() bool {
+	for _, r := range s.Repos {
+		if r.Error != "" || len(r.Failures) > 0 {
+			return false
+		}
+		for _, n := range r.Actions {
+			if n > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// headline returns the run's totals, e.g. "5 approved, 3 skipped, 1 failed".
+func (s Summary) headline() string {
+	actions := make(map[string]int)
+	var skipped, failed, errored int
+	for _, r := range s.Repos {
+		for a, n := range r.Actions {
+			actions[a] += n
+		}
+		for _, n := range r.Skipped {
+			skipped += n
+		}
+		failed += len(r.Failures)
+		if r.Error != "" {
+			errored++
+		}
+	}
+
+	var parts []string
+	for _, a := range actionOrder {
+		if n := actions[a]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, a))
+		}
+	}
+	if skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", skipped))
+	}
+	if failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", failed))
+	}
+	if errored > 0 {
+		parts = append(parts, fmt.Sprintf("%d repository errors", errored))
+	}
+	if len(parts) == 0 {
+		return "nothing to do"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// counts formats counts as "2 DENIED_PACKAGE, 1 failing checks", largest
+// first.
+func counts(m map[string]int) string {
+	keys := make([]string, 0, len(m))
+	for k, n := range m {
+		if n > 0 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if m[keys[i]] != m[keys[j]] {
+			return m[keys[i]] > m[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%d %s", m[k], k)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// String formats the risk as "high: 1 major (react), 1 security fix
+// (lodash)", leaving out the empty categories.
+func (r Risk) String() string {
+	var parts []string
+	for _, c := range []struct {
+		name     string
+		packages []string
+	}{
+		{"major", r.Major},
+		{"unknown type", r.Unknown},
+		{"security fix", r.Security},
+		{"low scorecard", r.LowScore},
+	} {
+		if len(c.packages) > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s (%s)", len(c.packages), c.name, strings.Join(c.packages, ", ")))
+		}
+	}
+	if len(parts) == 0 {
+		return r.Level
+	}
+	return r.Level + ": " + strings.Join(parts, ", ")
+}
+
+// actions formats a repository's action counts in display order.
+func (r Repo) actions() string {
+	var parts []string
+	for _, a := range actionOrder {
+		if n := r.Actions[a]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, a))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// markup is how a chat system formats a summary.
+type markup struct {
+	bold          func(s string) string
+	link          func(url, text string) string
+	escape        func(s string) string
+	repoPrefix    string // starts the line of a repository
+	failurePrefix string // starts the line of a failed PR under it
+}
+
+// markdownMarkup is the Markdown understood by Discord and Teams.
+var markdownMarkup = markup{
+	bold:          func(s string) string { return "**" + s + "**" },
+	link:          func(url, text string) string { return "[" + text + "](" + url + ")" },
+	escape:        markdownEscape,
+	repoPrefix:    "- ",
+	failurePrefix: "  - ✗ ",
+}
+
+// markdownEscape escapes the characters Markdown treats as markup.
+func markdownEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "~", `\~`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`).Replace(s)
+}
+
+// lines renders a summary as a headline followed by a line per repository
+// that saw an action, a skip, or an error, each followed by a line per failed
+// PR. Quiet repositories are left out.
+func (s Summary) lines(m markup) []string {
+	lines := []string{fmt.Sprintf("%s: %s", m.bold("dependabot-bouncer "+m.escape(s.Command)), s.headline())}
+	if s.Risk != nil {
+		lines = append(lines, "Risk: "+m.escape(s.Risk.String()))
+	}
+	for _, r := range s.Repos {
+		var parts []string
+		if a := r.actions(); a != "" {
+			parts = append(parts, a)
+		}
+		if c := counts(r.Skipped); c != "" {
+			parts = append(parts, "skipped "+m.escape(c))
+		}
+		if r.Error != "" {
+			parts = append(parts, "error: "+m.escape(r.Error))
+		}
+		if len(parts) == 0 && len(r.Failures) == 0 {
+			continue
+		}
+		line := m.repoPrefix + m.bold(m.escape(r.Name))
+		if len(parts) > 0 {
+			line += ": " + strings.Join(parts, "; ")
+		}
+		lines = append(lines, line)
+		for _, f := range r.Failures {
+			pr := fmt.Sprintf("#%d", f.Number)
+			if f.URL != "" {
+				pr = m.link(f.URL, pr)
+			}
+			lines = append(lines, fmt.Sprintf("%s%s %s: %s", m.failurePrefix, pr, m.escape(f.Title), m.escape(f.Error)))
+		}
+	}
+	return lines
+}
+
+// newClient returns the HTTP client notifiers post with.
+func newClient() *http.Client {
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+// validURL reports whether u is an absolute http or https URL, or only https
+// with httpsOnly.
+func validURL(u string, httpsOnly bool) bool {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	return parsed.Scheme == "https" || (parsed.Scheme == "http" && !httpsOnly)
+}
+
+// truncateLines shortens text to at most max runes, dropping whole lines
+// from the end and marking the cut with an ellipsis.
+func truncateLines(text string, max int) string {
+	if len([]rune(text)) <= max {
+		return text
+	}
+	const more = "\n…"
+	lines := strings.Split(text, "\n")
+	for len([]rune(text)) > max && len(lines) > 1 {
+		lines = lines[:len(lines)-1]
+		text = strings.Join(lines, "\n") + more
+	}
+	if r := []rune(text); len(r) > max {
+		text = string(r[:max-1]) + "…"
+	}
+	return text
+}
+
+// postJSON posts payload as JSON to a webhook, failing on any response but
+// a 2xx.
+func postJSON(client *http.Client, webhookURL string, headers map[string]string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// The error names the URL, whose path is often the webhook's secret.
+		if uerr, ok := err.(*url.Error); ok {
+			err = uerr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func testSummary() Summary {
+	return Summary{
+		Command: "approve",
+		Repos: []Repo{
+			{
+				Name:    "acme/api",
+				Actions: map[string]int{"approved": 3, "recreated": 1},
+				Skipped: map[string]int{"DENIED_PACKAGE": 2, "failing checks": 1},
+				Failures: []Failure{
+					{Number: 12, Title: "Bump a<b", URL: "https://github.com/acme/api/pull/12", Error: "failed to approve: 403"},
+				},
+			},
+			{Name: "acme/web", Error: "gh pr list failed"},
+			{Name: "acme/quiet"},
+		},
+	}
+}
+
+func TestSlackText(t *testing.T) {
+	want := "*dependabot-bouncer approve*: 3 approved, 1 recreated, 3 skipped, 1 failed, 1 repository errors" +
+		"\n• *acme/api*: 3 approved, 1 recreated; skipped 2 DENIED_PACKAGE, 1 failing checks" +
+		"\n    ✗ <https://github.com/acme/api/pull/12|#12> Bump a&lt;b: failed to approve: 403" +
+		"\n• *acme/web*: error: gh pr list failed"
+	if got := slackText(testSummary()); got != want {
+		t.Errorf("slackText() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSlackTextRisk(t *testing.T) {
+	sum := Summary{
+		Command: "approve",
+		Repos:   []Repo{{Name: "acme/api", Actions: map[string]int{"approved": 2}}},
+		Risk:    &Risk{Level: "high", Major: []string{"react"}, Security: []string{"lodash"}},
+	}
+	want := "*dependabot-bouncer approve*: 2 approved" +
+		"\nRisk: high: 1 major (react), 1 security fix (lodash)" +
+		"\n• *acme/api*: 2 approved"
+	if got := slackText(sum); got != want {
+		t.Errorf("slackText() =\n%s\nwant\n%s", got, want)
+	}
+	if got := (Risk{Level: "low"}).String(); got != "low" {
+		t.Errorf("Risk.String() = %q, want low", got)
+	}
+}
+
+func TestSummaryEmpty(t *testing.T) {
+	if testSummary().Empty() {
+		t.Error("Empty() = true for a run with actions")
+	}
+	quiet := Summary{Command: "approve", Repos: []Repo{{Name: "acme/api", Skipped: map[string]int{"DENIED_PACKAGE": 1}}}}
+	if !quiet.Empty() {
+		t.Error("Empty() = false for a run that only skipped PRs")
+	}
+}
+
+func TestSlackNotify(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	s, err := NewSlack(srv.URL+"/services/T/B/X", "#deps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.client = srv.Client()
+	if err := s.Notify(testSummary()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if got["channel"] != "#deps" || got["text"] != slackText(testSummary()) {
+		t.Errorf("payload = %v", got)
+	}
+}
+
+func TestNewSlackInvalidURL(t *testing.T) {
+	for _, u := range []string{"", "http://hooks.slack.com/services/x", "hooks.slack.com"} {
+		if _, err := NewSlack(u, ""); err == nil {
+			t.Errorf("NewSlack(%q) succeeded, want error", u)
+		}
+	}
+}
+
+func TestDiscordText(t *testing.T) {
+	want := "**dependabot-bouncer approve**: 3 approved, 1 recreated, 3 skipped, 1 failed, 1 repository errors" +
+		"\n- **acme/api**: 3 approved, 1 recreated; skipped 2 DENIED\\_PACKAGE, 1 failing checks" +
+		"\n  - ✗ [#12](https://github.com/acme/api/pull/12) Bump a\\<b: failed to approve: 403" +
+		"\n- **acme/web**: error: gh pr list failed"
+	if got := discordText(testSummary()); got != want {
+		t.Errorf("discordText() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDiscordTextTruncated(t *testing.T) {
+	sum := Summary{Command: "approve"}
+	for i := range 100 {
+		sum.Repos = append(sum.Repos, Repo{Name: fmt.Sprintf("acme/repo-%d", i), Error: strings.Repeat("x", 50)})
+	}
+	got := discordText(sum)
+	if n := utf8.RuneCountInString(got); n > discordMaxContent {
+		t.Errorf("discordText() is %d characters, want at most %d", n, discordMaxContent)
+	}
+	if !strings.HasSuffix(got, "\n…") || !strings.Contains(got, "acme/repo-0") {
+		t.Errorf("discordText() = %q, want leading lines kept and the rest elided", got)
+	}
+}
+
+func TestTeamsCard(t *testing.T) {
+	card := teamsCard(testSummary())
+	content := card["attachments"].([]map[string]any)[0]["content"].(map[string]any)
+	body := content["body"].([]map[string]any)
+	if len(body) != 4 {
+		t.Fatalf("card has %d text blocks, want 4", len(body))
+	}
+	if got, want := body[2]["text"], "  - ✗ [#12](https://github.com/acme/api/pull/12) Bump a\\<b: failed to approve: 403"; got != want {
+		t.Errorf("failure block = %q, want %q", got, want)
+	}
+}
+
+func TestWebhookNotify(t *testing.T) {
+	var got struct {
+		Text    string `json:"text"`
+		Command string `json:"command"`
+		Repos   []Repo `json:"repositories"`
+	}
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	w, err := NewWebhook(srv.URL+"/hooks/bouncer", map[string]string{"Authorization": "Bearer t0ken"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Notify(testSummary()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if auth != "Bearer t0ken" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if got.Command != "approve" || len(got.Repos) != 3 || got.Repos[0].Failures[0].Number != 12 {
+		t.Errorf("payload = %+v", got)
+	}
+	if got.Text != "dependabot-bouncer approve: 3 approved, 1 recreated, 3 skipped, 1 failed, 1 repository errors" {
+		t.Errorf("text = %q", got.Text)
+	}
+}
+
+func TestWebhookNotifyError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bot is down", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	w, err := NewWebhook(srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Notify(testSummary()); err == nil || !strings.Contains(err.Error(), "HTTP 502: bot is down") {
+		t.Errorf("Notify() error = %v, want HTTP 502", err)
+	}
+}
+
+func TestInvalidURLs(t *testing.T) {
+	tests := []struct {
+		name string
+		new  func(string) error
+		urls []string
+	}{
+		{"webhook", func(u string) error { _, err := NewWebhook(u, nil); return err }, []string{"", "ftp://bot.internal/x", "bot.internal/x"}},
+		{"teams", func(u string) error { _, err := NewTeams(u); return err }, []string{"", "http://example.webhook.office.com/x"}},
+		{"discord", func(u string) error { _, err := NewDiscord(u); return err }, []string{"", "http://discord.com/api/webhooks/1/x"}},
+	}
+
+	for _, tt := range tests {
+		for _, u := range tt.urls {
+			if err := tt.new(u); err == nil {
+				t.Errorf("%s: %q accepted, want error", tt.name, u)
+			}
+		}
+	}
+}
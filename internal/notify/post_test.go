@@ -0,0 +1,134 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+const testReport = "# Dependency report: acme\n\n| Repository | Open |\n|---|---|\n| acme/api | 3 |\n"
+
+// postServer records the JSON body of the last post it receives.
+func postServer(t *testing.T, tls bool, got any) *httptest.Server {
+	t.Helper()
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+	})
+	newServer := httptest.NewServer
+	if tls {
+		newServer = httptest.NewTLSServer
+	}
+	srv := newServer(h)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSlackPost(t *testing.T) {
+	var got struct {
+		Text    string              `json:"text"`
+		Channel string              `json:"channel"`
+		Blocks  []map[string]string `json:"blocks"`
+	}
+	srv := postServer(t, true, &got)
+	s, err := NewSlack(srv.URL+"/services/T/B/X", "#deps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.client = srv.Client()
+
+	if err := s.Post("Dependency report: acme", testReport); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if got.Text != "Dependency report: acme" || got.Channel != "#deps" || len(got.Blocks) != 1 ||
+		got.Blocks[0]["type"] != "markdown" || got.Blocks[0]["text"] != testReport {
+		t.Errorf("payload = %+v", got)
+	}
+}
+
+func TestTeamsPost(t *testing.T) {
+	var got map[string]any
+	srv := postServer(t, true, &got)
+	tm, err := NewTeams(srv.URL + "/workflows/x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm.client = srv.Client()
+
+	if err := tm.Post("Dependency report: acme", testReport); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	content := got["attachments"].([]any)[0].(map[string]any)["content"].(map[string]any)
+	body := content["body"].([]any)
+	if len(body) != 2 {
+		t.Fatalf("card has %d blocks, want 2", len(body))
+	}
+	if title := body[0].(map[string]any)["text"]; title != "Dependency report: acme" {
+		t.Errorf("title block = %q", title)
+	}
+	if text := body[1].(map[string]any)["text"]; text != testReport {
+		t.Errorf("report block = %q", text)
+	}
+}
+
+func TestDiscordPost(t *testing.T) {
+	var got struct {
+		Content string `json:"content"`
+	}
+	srv := postServer(t, true, &got)
+	d, err := NewDiscord(srv.URL + "/api/webhooks/1/x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.client = srv.Client()
+
+	long := testReport + strings.Repeat("| acme/other | 1 |\n", 200)
+	if err := d.Post("Dependency report: acme", long); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if n := utf8.RuneCountInString(got.Content); n > discordMaxContent {
+		t.Errorf("content is %d characters, want at most %d", n, discordMaxContent)
+	}
+	if !strings.HasPrefix(got.Content, testReport) || !strings.HasSuffix(got.Content, "\n…") {
+		t.Errorf("content = %q, want the start of the report and the rest elided", got.Content)
+	}
+}
+
+func TestWebhookPost(t *testing.T) {
+	var got webhookDocument
+	srv := postServer(t, false, &got)
+	w, err := NewWebhook(srv.URL+"/hooks/bouncer", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Post("Dependency report: acme", testReport); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if got.Text != "Dependency report: acme" || got.Markdown != testReport {
+		t.Errorf("payload = %+v", got)
+	}
+}
+
+func TestTruncateLines(t *testing.T) {
+	tests := []struct {
+		text string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"one\ntwo\nthree", 9, "one\ntwo\n…"},
+		{"one\ntwo\nthree", 6, "one\n…"},
+		{"a single long line", 5, "a si…"},
+	}
+
+	for _, tt := range tests {
+		if got := truncateLines(tt.text, tt.max); got != tt.want {
+			t.Errorf("truncateLines(%q, %d) = %q, want %q", tt.text, tt.max, got, tt.want)
+		}
+	}
+}
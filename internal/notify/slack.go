@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Slack posts summaries to a Slack incoming webhook.
+type Slack struct {
+	WebhookURL string
+	// Channel overrides the webhook's default channel, e.g. "#deps". Only
+	// webhooks of legacy integrations honor it; app webhooks always post to
+	// the channel they were created for.
+	Channel string
+
+	client *http.Client
+}
+
+// NewSlack returns a notifier posting to the incoming webhook at webhookURL.
+func NewSlack(webhookURL, channel string) (*Slack, error) {
+	if !validURL(webhookURL, true) {
+		return nil, fmt.Errorf("invalid Slack webhook URL: must be an https URL")
+	}
+	return &Slack{
+		WebhookURL: webhookURL,
+		Channel:    channel,
+		client:     newClient(),
+	}, nil
+}
+
+func (s *Slack) String() string {
+	if s.Channel != "" {
+		return "Slack channel " + s.Channel
+	}
+	return "Slack webhook"
+}
+
+// Notify posts the summary as a single message.
+func (s *Slack) Notify(sum Summary) error {
+	payload := map[string]string{"text": slackText(sum)}
+	if s.Channel != "" {
+		payload["channel"] = s.Channel
+	}
+	return postJSON(s.client, s.WebhookURL, nil, payload)
+}
+
+// slackMaxMarkdown is the most text Slack accepts in markdown blocks.
+const slackMaxMarkdown = 12000
+
+// Post posts a Markdown document as a single message, in a markdown block
+// cut to Slack's limit, with title as the notification text.
+func (s *Slack) Post(title, markdown string) error {
+	payload := map[string]any{
+		"text":   title,
+		"blocks": []map[string]string{{"type": "markdown", "text": truncateLines(markdown, slackMaxMarkdown)}},
+	}
+	if s.Channel != "" {
+		payload["channel"] = s.Channel
+	}
+	return postJSON(s.client, s.WebhookURL, nil, payload)
+}
+
+// slackMarkup is Slack's mrkdwn.
+var slackMarkup = markup{
+	bold:          func(s string) string { return "*" + s + "*" },
+	link:          func(url, text string) string { return "<" + url + "|" + text + ">" },
+	escape:        slackEscape,
+	repoPrefix:    "• ",
+	failurePrefix: "    ✗ ",
+}
+
+// slackText renders a summary in Slack's mrkdwn.
+func slackText(sum Summary) string {
+	return strings.Join(sum.lines(slackMarkup), "\n")
+}
+
+// slackEscape escapes the characters Slack treats as markup.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
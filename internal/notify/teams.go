@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Teams posts summaries to a Microsoft Teams channel as an Adaptive Card,
+// through a Workflows webhook or a legacy incoming webhook.
+type Teams struct {
+	WebhookURL string
+
+	client *http.Client
+}
+
+// NewTeams returns a notifier posting to the webhook at webhookURL.
+func NewTeams(webhookURL string) (*Teams, error) {
+	if !validURL(webhookURL, true) {
+		return nil, fmt.Errorf("invalid Teams webhook URL: must be an https URL")
+	}
+	return &Teams{WebhookURL: webhookURL, client: newClient()}, nil
+}
+
+func (t *Teams) String() string {
+	return "Teams webhook"
+}
+
+// Notify posts the summary as a single card.
+func (t *Teams) Notify(sum Summary) error {
+	return postJSON(t.client, t.WebhookURL, nil, teamsCard(sum))
+}
+
+// Post posts a Markdown document as a single card, under title.
+func (t *Teams) Post(title, markdown string) error {
+	return postJSON(t.client, t.WebhookURL, nil, adaptiveCard([]map[string]any{
+		{"type": "TextBlock", "text": title, "weight": "Bolder", "size": "Medium", "wrap": true},
+		{"type": "TextBlock", "text": markdown, "wrap": true},
+	}))
+}
+
+// teamsCard returns the message carrying a summary as an Adaptive Card, with
+// one text block per line: Teams renders Markdown lists inside a single
+// block inconsistently.
+func teamsCard(sum Summary) map[string]any {
+	var body []map[string]any
+	for i, line := range sum.lines(markdownMarkup) {
+		block := map[string]any{"type": "TextBlock", "text": line, "wrap": true}
+		if i > 0 {
+			block["spacing"] = "None"
+		}
+		body = append(body, block)
+	}
+	return adaptiveCard(body)
+}
+
+// adaptiveCard returns the message carrying an Adaptive Card with the given
+// body elements.
+func adaptiveCard(body []map[string]any) map[string]any {
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]any{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+}
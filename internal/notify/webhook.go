@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Webhook posts summaries as JSON to any HTTP endpoint, for bots and
+// services that have no dedicated backend.
+type Webhook struct {
+	URL string
+	// Headers are sent with every post, e.g. for authentication.
+	Headers map[string]string
+
+	client *http.Client
+}
+
+// NewWebhook returns a notifier posting to url, which may be http for
+// endpoints on an internal network.
+func NewWebhook(url string, headers map[string]string) (*Webhook, error) {
+	if !validURL(url, false) {
+		return nil, fmt.Errorf("invalid notification webhook URL: must be an http or https URL")
+	}
+	return &Webhook{URL: url, Headers: headers, client: newClient()}, nil
+}
+
+func (w *Webhook) String() string {
+	return "notification webhook"
+}
+
+// webhookPayload is the body posted by Webhook: the summary with its
+// headline in text.
+type webhookPayload struct {
+	Text string `json:"text"`
+	Summary
+}
+
+// webhookDocument is the body posted by Webhook.Post.
+type webhookDocument struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown"`
+}
+
+// Post posts a Markdown document as JSON, with title in text.
+func (w *Webhook) Post(title, markdown string) error {
+	return postJSON(w.client, w.URL, w.Headers, webhookDocument{Text: title, Markdown: markdown})
+}
+
+// Notify posts the summary as a JSON document.
+func (w *Webhook) Notify(sum Summary) error {
+	payload := webhookPayload{Text: "dependabot-bouncer " + sum.Command + ": " + sum.headline(), Summary: sum}
+	return postJSON(w.client, w.URL, w.Headers, payload)
+}
@@ -0,0 +1,108 @@
+// Package osv looks up known vulnerabilities of package versions in the OSV
+// database.
+package osv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIURL is the base URL of the OSV API.
+var APIURL = "https://api.osv.dev"
+
+// ecosystems maps Dependabot package-ecosystem names to OSV ecosystems.
+// Versions of other ecosystems cannot be looked up.
+var ecosystems = map[string]string{
+	"gomod":          "Go",
+	"npm":            "npm",
+	"pip":            "PyPI",
+	"maven":          "Maven",
+	"gradle":         "Maven",
+	"cargo":          "crates.io",
+	"nuget":          "NuGet",
+	"bundler":        "RubyGems",
+	"composer":       "Packagist",
+	"mix":            "Hex",
+	"pub":            "Pub",
+	"github-actions": "GitHub Actions",
+}
+
+// Client looks up vulnerabilities and caches them for the life of the
+// client.
+type Client struct {
+	mu     sync.Mutex
+	client *http.Client
+	cache  map[string][]string
+}
+
+// NewClient returns a client with an empty cache.
+func NewClient() *Client {
+	return &Client{
+		client: &http.Client{Timeout: 10 * time.Second},
+		cache:  make(map[string][]string),
+	}
+}
+
+// Vulnerabilities returns the IDs of the known vulnerabilities affecting
+// version of a package of the given Dependabot ecosystem, sorted. ok is false
+// when the ecosystem is not supported or the package or version is unknown.
+// Only failed lookups are retried on the next call.
+func (c *Client) Vulnerabilities(ecosystem, pkg, version string) (ids []string, ok bool, err error) {
+	system, supported := ecosystems[ecosystem]
+	if !supported || pkg == "" || version == "" {
+		return nil, false, nil
+	}
+	if system == "Go" {
+		version = strings.TrimPrefix(version, "v")
+	}
+	key := system + " " + pkg + "@" + version
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ids, cached := c.cache[key]; cached {
+		return ids, true, nil
+	}
+
+	var query struct {
+		Version string `json:"version"`
+		Package struct {
+			Name      string `json:"name"`
+			Ecosystem string `json:"ecosystem"`
+		} `json:"package"`
+	}
+	query.Version = version
+	query.Package.Name = pkg
+	query.Package.Ecosystem = system
+	body, err := json.Marshal(query)
+	if err != nil {
+		return nil, false, err
+	}
+	resp, err := c.client.Post(APIURL+"/v1/query", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to look up vulnerabilities of %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("failed to look up vulnerabilities of %s: %s", key, resp.Status)
+	}
+	var result struct {
+		Vulns []struct {
+			ID string `json:"id"`
+		} `json:"vulns"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, false, fmt.Errorf("failed to parse vulnerabilities of %s: %w", key, err)
+	}
+	for _, v := range result.Vulns {
+		ids = append(ids, v.ID)
+	}
+	sort.Strings(ids)
+	c.cache[key] = ids
+	return ids, true, nil
+}
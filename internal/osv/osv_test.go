@@ -0,0 +1,65 @@
+package osv
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestVulnerabilities(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var q struct {
+			Version string
+			Package struct{ Name, Ecosystem string }
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/query" || json.NewDecoder(r.Body).Decode(&q) != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		switch q.Package.Ecosystem + " " + q.Package.Name + "@" + q.Version {
+		case "Go golang.org/x/net@0.17.0":
+			fmt.Fprint(w, `{"vulns": [{"id": "GO-2024-2687"}, {"id": "GHSA-4v7x-pqxf-cx7m"}]}`)
+		case "npm lodash@4.17.21":
+			fmt.Fprint(w, `{}`)
+		case "PyPI broken@1.0":
+			http.Error(w, "oops", http.StatusInternalServerError)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer srv.Close()
+
+	orig := APIURL
+	APIURL = srv.URL
+	defer func() { APIURL = orig }()
+
+	c := NewClient()
+	tests := []struct {
+		ecosystem, pkg, version string
+		want                    []string
+		wantOK, wantErr         bool
+	}{
+		{"gomod", "golang.org/x/net", "v0.17.0", []string{"GHSA-4v7x-pqxf-cx7m", "GO-2024-2687"}, true, false},
+		{"gomod", "golang.org/x/net", "0.17.0", []string{"GHSA-4v7x-pqxf-cx7m", "GO-2024-2687"}, true, false},
+		{"npm", "lodash", "4.17.21", nil, true, false},
+		{"npm", "lodash", "4.17.21", nil, true, false},
+		{"pip", "broken", "1.0", nil, false, true},
+		{"pip", "broken", "1.0", nil, false, true}, // failures are retried
+		{"docker", "nginx", "1.27", nil, false, false},
+		{"npm", "react", "", nil, false, false},
+	}
+	for _, tt := range tests {
+		got, ok, err := c.Vulnerabilities(tt.ecosystem, tt.pkg, tt.version)
+		if !slices.Equal(got, tt.want) || ok != tt.wantOK || (err != nil) != tt.wantErr {
+			t.Errorf("Vulnerabilities(%q, %q, %q) = %v, %v, %v, want %v, %v, error %v", tt.ecosystem, tt.pkg, tt.version, got, ok, err, tt.want, tt.wantOK, tt.wantErr)
+		}
+	}
+	if requests != 4 {
+		t.Errorf("requests = %d, want 4 (cached per version)", requests)
+	}
+}
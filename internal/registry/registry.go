@@ -0,0 +1,88 @@
+// Package registry looks up when package versions were published.
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DepsDevURL is the base URL of the deps.dev API, which serves the publish
+// times of several package registries.
+var DepsDevURL = "https://api.deps.dev"
+
+// systems maps Dependabot package-ecosystem names to deps.dev package
+// systems. Versions of other ecosystems cannot be looked up.
+var systems = map[string]string{
+	"gomod":   "GO",
+	"npm":     "NPM",
+	"pip":     "PYPI",
+	"maven":   "MAVEN",
+	"gradle":  "MAVEN",
+	"cargo":   "CARGO",
+	"nuget":   "NUGET",
+	"bundler": "RUBYGEMS",
+}
+
+// Client looks up publish times and caches them for the life of the client.
+type Client struct {
+	mu     sync.Mutex
+	client *http.Client
+	cache  map[string]time.Time // zero when unavailable
+}
+
+// NewClient returns a client with an empty cache.
+func NewClient() *Client {
+	return &Client{
+		client: &http.Client{Timeout: 10 * time.Second},
+		cache:  make(map[string]time.Time),
+	}
+}
+
+// PublishedAt returns when version of a package of the given Dependabot
+// ecosystem was published. ok is false when the ecosystem is not supported
+// or the registry does not know the version. Only failed lookups are
+// retried on the next call.
+func (c *Client) PublishedAt(ecosystem, pkg, version string) (published time.Time, ok bool, err error) {
+	system, supported := systems[ecosystem]
+	if !supported || pkg == "" || version == "" {
+		return time.Time{}, false, nil
+	}
+	if system == "GO" && !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	key := system + " " + pkg + "@" + version
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t, cached := c.cache[key]; cached {
+		return t, !t.IsZero(), nil
+	}
+
+	u := fmt.Sprintf("%s/v3/systems/%s/packages/%s/versions/%s", DepsDevURL, system, url.PathEscape(pkg), url.PathEscape(version))
+	resp, err := c.client.Get(u)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to look up %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		c.cache[key] = time.Time{}
+		return time.Time{}, false, nil
+	default:
+		return time.Time{}, false, fmt.Errorf("failed to look up %s: %s", key, resp.Status)
+	}
+	var body struct {
+		PublishedAt time.Time `json:"publishedAt"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to parse publish time of %s: %w", key, err)
+	}
+	c.cache[key] = body.PublishedAt
+	return body.PublishedAt, !body.PublishedAt.IsZero(), nil
+}
@@ -0,0 +1,57 @@
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPublishedAt(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.EscapedPath() {
+		case "/v3/systems/GO/packages/golang.org%2Fx%2Fnet/versions/v0.30.0":
+			fmt.Fprint(w, `{"publishedAt": "2026-10-01T12:00:00Z"}`)
+		case "/v3/systems/NPM/packages/@babel%2Fcore/versions/7.26.0":
+			fmt.Fprint(w, `{"publishedAt": "2026-09-20T08:30:00Z"}`)
+		case "/v3/systems/PYPI/packages/broken/versions/1.0":
+			http.Error(w, "oops", http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	orig := DepsDevURL
+	DepsDevURL = srv.URL
+	defer func() { DepsDevURL = orig }()
+
+	c := NewClient()
+	tests := []struct {
+		ecosystem, pkg, version string
+		want                    time.Time
+		wantOK, wantErr         bool
+	}{
+		{"gomod", "golang.org/x/net", "0.30.0", time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC), true, false},
+		{"gomod", "golang.org/x/net", "v0.30.0", time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC), true, false},
+		{"npm", "@babel/core", "7.26.0", time.Date(2026, 9, 20, 8, 30, 0, 0, time.UTC), true, false},
+		{"npm", "left-pad", "9.9.9", time.Time{}, false, false},
+		{"pip", "broken", "1.0", time.Time{}, false, true},
+		{"pip", "broken", "1.0", time.Time{}, false, true}, // failures are retried
+		{"npm", "left-pad", "9.9.9", time.Time{}, false, false},
+		{"docker", "nginx", "1.27", time.Time{}, false, false},
+		{"npm", "react", "", time.Time{}, false, false},
+	}
+	for _, tt := range tests {
+		got, ok, err := c.PublishedAt(tt.ecosystem, tt.pkg, tt.version)
+		if !got.Equal(tt.want) || ok != tt.wantOK || (err != nil) != tt.wantErr {
+			t.Errorf("PublishedAt(%q, %q, %q) = %v, %v, %v, want %v, %v, error %v", tt.ecosystem, tt.pkg, tt.version, got, ok, err, tt.want, tt.wantOK, tt.wantErr)
+		}
+	}
+	if requests != 5 {
+		t.Errorf("requests = %d, want 5 (cached per version)", requests)
+	}
+}
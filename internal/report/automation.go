@@ -0,0 +1,128 @@
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/state"
+)
+
+// handledDecisions are the decisions of the history that count as a PR
+// handled by the bouncer instead of a reviewer.
+var handledDecisions = map[string]bool{
+	"approved":  true,
+	"recreated": true,
+	"rebased":   true,
+	"closed":    true,
+	"ignored":   true,
+}
+
+// Month sums up what the bouncer did in one calendar month.
+type Month struct {
+	Start           time.Time
+	Handled         int // PRs acted on successfully
+	Denied          int // PRs denied by policy
+	MergedByBouncer int // merged PRs the bouncer approved
+	MergedManually  int // merged PRs it did not approve
+	APICalls        int
+	TimeSaved       time.Duration // Handled times the review time per PR
+}
+
+// Merged returns the PRs merged in the month.
+func (m Month) Merged() int {
+	return m.MergedByBouncer + m.MergedManually
+}
+
+// repoMerge is a merged PR together with its repository.
+type repoMerge struct {
+	repo string
+	pr   int
+	at   time.Time
+}
+
+// AddHistory records the decisions kept in the local history, and the
+// reviewer time each PR handled by the bouncer is estimated to save. The
+// automation section is only reported when history is added.
+func (r *Report) AddHistory(recs []state.Record, perPR time.Duration) {
+	r.history = append(r.history, recs...)
+	r.perPR = perPR
+}
+
+// AutomationTotal returns the automation figures summed across all months.
+func (r *Report) AutomationTotal() Month {
+	var t Month
+	for _, m := range r.Automation {
+		t.Handled += m.Handled
+		t.Denied += m.Denied
+		t.MergedByBouncer += m.MergedByBouncer
+		t.MergedManually += m.MergedManually
+		t.APICalls += m.APICalls
+		t.TimeSaved += m.TimeSaved
+	}
+	return t
+}
+
+// automationByMonth buckets the history and the merged PRs into the calendar
+// months of the days before now, oldest first. A merged PR counts as merged
+// by the bouncer when the history has it approved, at any time.
+func automationByMonth(history []state.Record, merges []repoMerge, perPR time.Duration, now time.Time, days int) []Month {
+	if len(history) == 0 || days <= 0 {
+		return nil
+	}
+	start := now.AddDate(0, 0, -days)
+	first := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, now.Location())
+	var months []Month
+	for m := first; !m.After(now); m = m.AddDate(0, 1, 0) {
+		months = append(months, Month{Start: m})
+	}
+	month := func(t time.Time) *Month {
+		if t.Before(start) || t.After(now) {
+			return nil
+		}
+		t = t.In(now.Location())
+		i := (t.Year()-first.Year())*12 + int(t.Month()-first.Month())
+		return &months[i]
+	}
+
+	type prKey struct {
+		month int
+		pr    string
+	}
+	handled := make(map[prKey]bool)
+	denied := make(map[prKey]bool)
+	approved := make(map[string]bool)
+	for _, rec := range history {
+		pr := fmt.Sprintf("%s#%d", rec.Repo, rec.Number)
+		if rec.Decision == "approved" {
+			approved[pr] = true
+		}
+		m := month(rec.Time)
+		if m == nil {
+			continue
+		}
+		key := prKey{m.Start.Year()*12 + int(m.Start.Month()), pr}
+		switch {
+		case rec.Decision == state.DecisionRun:
+			m.APICalls += rec.Calls
+		case rec.Decision == "denied" && !denied[key]:
+			denied[key] = true
+			m.Denied++
+		case handledDecisions[rec.Decision] && !handled[key]:
+			handled[key] = true
+			m.Handled++
+			m.TimeSaved += perPR
+		}
+	}
+	for _, mg := range merges {
+		m := month(mg.at)
+		if m == nil {
+			continue
+		}
+		if approved[fmt.Sprintf("%s#%d", mg.repo, mg.pr)] {
+			m.MergedByBouncer++
+		} else {
+			m.MergedManually++
+		}
+	}
+	return months
+}
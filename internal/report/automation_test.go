@@ -0,0 +1,96 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/state"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+func TestAutomation(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	r := New("acme", now, 30)
+	r.AddRepo("acme/api", nil, []bouncer.MergedPR{
+		{Number: 1, CreatedAt: now.Add(-20 * day), MergedAt: now.Add(-19 * day)},
+		{Number: 2, CreatedAt: now.Add(-3 * day), MergedAt: now.Add(-2 * day)},
+		{Number: 3, CreatedAt: now.Add(-3 * day), MergedAt: now.Add(-day)},
+	}, "enabled")
+	r.AddHistory([]state.Record{
+		{Time: now.Add(-40 * day), Repo: "acme/api", Number: 1, Decision: "approved"},
+		{Time: now.Add(-3 * day), Repo: "acme/api", Decision: state.DecisionRun, Calls: 12},
+		{Time: now.Add(-3 * day), Repo: "acme/api", Number: 2, Decision: "approved"},
+		{Time: now.Add(-2 * day), Repo: "acme/api", Number: 2, Decision: "approved"},
+		{Time: now.Add(-2 * day), Repo: "acme/api", Number: 4, Decision: "denied", Reason: "DENIED_PACKAGE"},
+		{Time: now.Add(-2 * day), Repo: "acme/api", Number: 5, Decision: "failed"},
+	}, 10*time.Minute)
+	r.Finalize()
+
+	if len(r.Automation) != 2 || r.Automation[0].Start.Month() != time.February {
+		t.Fatalf("Automation = %+v, want February and March", r.Automation)
+	}
+	feb, mar := r.Automation[0], r.Automation[1]
+	if feb.Handled != 0 || feb.MergedByBouncer != 1 || feb.MergedManually != 0 {
+		t.Errorf("February = %+v, want #1 merged by the bouncer and nothing handled", feb)
+	}
+	if mar.Handled != 1 || mar.Denied != 1 || mar.APICalls != 12 || mar.MergedByBouncer != 1 || mar.MergedManually != 1 || mar.TimeSaved != 10*time.Minute {
+		t.Errorf("March = %+v", mar)
+	}
+
+	var md bytes.Buffer
+	if err := WriteMarkdown(&md, r); err != nil {
+		t.Fatalf("WriteMarkdown() error = %v", err)
+	}
+	for _, want := range []string{
+		"## Automation (last 30 days)",
+		"2 of 3 merged PRs (66%) went through the bouncer; merged by hand: 1.",
+		"| 2026-03 | 1 | 1 | 1 | 1 | 12 | 0.2h |",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown output missing %q\n%s", want, md.String())
+		}
+	}
+}
+
+func TestAutomationWithoutHistory(t *testing.T) {
+	r := New("acme", time.Now(), 30)
+	r.AddRepo("acme/api", nil, []bouncer.MergedPR{{Number: 1, MergedAt: time.Now()}}, "enabled")
+	r.Finalize()
+	if r.Automation != nil {
+		t.Errorf("Automation = %+v, want none without history", r.Automation)
+	}
+}
+
+func TestFlakyChecks(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	r := New("acme", now, 30)
+	r.AddRepo("acme/api", nil, nil, "enabled")
+	r.AddHistory([]state.Record{
+		{Time: now.Add(-5 * time.Hour), Repo: "acme/api", Number: 1, Package: "go-redis", Decision: "recreated", Failing: []string{"integration"}},
+		{Time: now.Add(-4 * time.Hour), Repo: "acme/api", Number: 1, Package: "go-redis", Decision: "approved"},
+		{Time: now.Add(-3 * time.Hour), Repo: "acme/api", Number: 2, Package: "go-redis", Decision: "recreated", Failing: []string{"integration"}},
+		{Time: now.Add(-2 * time.Hour), Repo: "acme/api", Number: 2, Package: "go-redis", Decision: "approved"},
+	}, 10*time.Minute)
+	r.Finalize()
+
+	var md bytes.Buffer
+	if err := WriteMarkdown(&md, r); err != nil {
+		t.Fatalf("WriteMarkdown() error = %v", err)
+	}
+	for _, want := range []string{"## Flaky checks", "| acme/api | go-redis | 2 | integration |"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown output missing %q\n%s", want, md.String())
+		}
+	}
+	var html bytes.Buffer
+	if err := WriteHTML(&html, r); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+	if !strings.Contains(html.String(), "<td>go-redis</td><td>2</td><td>integration</td>") {
+		t.Errorf("HTML output missing the flaky package\n%s", html.String())
+	}
+}
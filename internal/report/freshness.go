@@ -0,0 +1,65 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+// Freshness summarizes how far a repository has fallen behind on its
+// dependency updates.
+type Freshness struct {
+	Repo       string
+	Open       int           // actionable open PRs (denied and ignored PRs excluded)
+	OldestOpen time.Duration // age of the oldest actionable PR
+	LastMerged time.Time     // most recent merged update; zero if none in the lookback window
+	Score      int
+}
+
+// ScoreFreshness scores a repository's staleness from its open PRs (as listed
+// with IncludeSkipped) and the updates merged in the last days days. The
+// score is the number of actionable PRs plus the age of the oldest one in
+// weeks plus the weeks since an update was last merged, capped at the
+// lookback window. Repositories with no actionable PRs score zero: nothing
+// is waiting on them.
+func ScoreFreshness(repo string, prs []bouncer.PRInfo, merged []bouncer.MergedPR, now time.Time, days int) Freshness {
+	f := Freshness{Repo: repo}
+	for _, pr := range prs {
+		if pr.Skipped {
+			continue
+		}
+		f.Open++
+		if age := now.Sub(pr.CreatedAt); age > f.OldestOpen {
+			f.OldestOpen = age
+		}
+	}
+	for _, m := range merged {
+		if m.MergedAt.After(f.LastMerged) {
+			f.LastMerged = m.MergedAt
+		}
+	}
+	if f.Open == 0 {
+		return f
+	}
+
+	window := time.Duration(days) * 24 * time.Hour
+	sinceMerge := window
+	if !f.LastMerged.IsZero() && now.Sub(f.LastMerged) < window {
+		sinceMerge = now.Sub(f.LastMerged)
+	}
+	week := 7 * 24 * time.Hour
+	f.Score = f.Open + int(f.OldestOpen/week) + int(sinceMerge/week)
+	return f
+}
+
+// SortFreshness orders repositories from stalest to freshest, breaking ties
+// by name.
+func SortFreshness(fs []Freshness) {
+	sort.Slice(fs, func(i, j int) bool {
+		if fs[i].Score != fs[j].Score {
+			return fs[i].Score > fs[j].Score
+		}
+		return fs[i].Repo < fs[j].Repo
+	})
+}
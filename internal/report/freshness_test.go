@@ -0,0 +1,57 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+func TestScoreFreshness(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name   string
+		prs    []bouncer.PRInfo
+		merged []bouncer.MergedPR
+		want   int
+	}{
+		{
+			name: "no actionable PRs",
+			prs:  []bouncer.PRInfo{{Skipped: true, CreatedAt: now.Add(-60 * day)}},
+			want: 0,
+		},
+		{
+			name: "recently merged",
+			prs: []bouncer.PRInfo{
+				{CreatedAt: now.Add(-15 * day)},
+				{CreatedAt: now.Add(-2 * day)},
+			},
+			merged: []bouncer.MergedPR{{MergedAt: now.Add(-30 * day)}, {MergedAt: now.Add(-8 * day)}},
+			want:   2 + 2 + 1,
+		},
+		{
+			name: "nothing merged in window",
+			prs:  []bouncer.PRInfo{{CreatedAt: now.Add(-21 * day)}},
+			want: 1 + 3 + 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ScoreFreshness("acme/api", tt.prs, tt.merged, now, 28)
+			if got.Score != tt.want {
+				t.Errorf("Score = %d, want %d (%+v)", got.Score, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSortFreshness(t *testing.T) {
+	fs := []Freshness{{Repo: "b", Score: 3}, {Repo: "c", Score: 9}, {Repo: "a", Score: 3}}
+	SortFreshness(fs)
+	if fs[0].Repo != "c" || fs[1].Repo != "a" || fs[2].Repo != "b" {
+		t.Errorf("SortFreshness() = %+v", fs)
+	}
+}
@@ -0,0 +1,247 @@
+package report
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var funcs = map[string]any{
+	"duration":      humanDuration,
+	"date":          func(r *Report) string { return r.GeneratedAt.Format("2006-01-02") },
+	"alertCoverage": alertCoverage,
+	"automation":    automationSummary,
+	"hours":         func(d time.Duration) string { return fmt.Sprintf("%.1fh", d.Hours()) },
+	"join":          func(s []string) string { return strings.Join(s, ", ") },
+}
+
+// Report formats.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// WriteMarkdown renders the report as Markdown.
+func WriteMarkdown(w io.Writer, r *Report) error {
+	return WriteTemplate(w, r, FormatMarkdown, markdownTemplate)
+}
+
+// WriteHTML renders the report as a standalone HTML page.
+func WriteHTML(w io.Writer, r *Report) error {
+	return WriteTemplate(w, r, FormatHTML, htmlTemplate)
+}
+
+// DefaultTemplate returns the built-in template of a format, a starting
+// point for a template of one's own.
+func DefaultTemplate(format string) (string, error) {
+	switch format {
+	case FormatMarkdown:
+		return markdownTemplate, nil
+	case FormatHTML:
+		return htmlTemplate, nil
+	default:
+		return "", fmt.Errorf("unsupported report format: %s (expected markdown or html)", format)
+	}
+}
+
+// WriteTemplate renders the report with text, a Go template executed on the
+// Report with the functions of the built-in templates. HTML templates are
+// parsed with html/template, which escapes what they print.
+func WriteTemplate(w io.Writer, r *Report, format, text string) error {
+	switch format {
+	case FormatMarkdown:
+		tmpl, err := template.New("report").Funcs(funcs).Parse(text)
+		if err != nil {
+			return fmt.Errorf("invalid report template: %w", err)
+		}
+		return tmpl.Execute(w, r)
+	case FormatHTML:
+		tmpl, err := htmltemplate.New("report").Funcs(funcs).Parse(text)
+		if err != nil {
+			return fmt.Errorf("invalid report template: %w", err)
+		}
+		return tmpl.Execute(w, r)
+	default:
+		return fmt.Errorf("unsupported report format: %s (expected markdown or html)", format)
+	}
+}
+
+// alertCoverage describes how many repositories have Dependabot alerts
+// enabled and names the ones that do not.
+func alertCoverage(r *Report) string {
+	enabled, total := r.AlertCoverage()
+	s := fmt.Sprintf("%d of %d repositories have Dependabot alerts enabled.", enabled, total)
+
+	var missing []string
+	for _, repo := range r.Repos {
+		if repo.Alerts != "enabled" {
+			missing = append(missing, fmt.Sprintf("%s (%s)", repo.Name, repo.Alerts))
+		}
+	}
+	if len(missing) > 0 {
+		s += " Not enabled: " + strings.Join(missing, ", ") + "."
+	}
+	return s
+}
+
+// automationSummary describes what the bouncer did over the whole report
+// window: the PRs it handled, the share of merged PRs it approved, the API
+// calls it made, and the reviewer time it is estimated to have saved.
+func automationSummary(r *Report) string {
+	t := r.AutomationTotal()
+	s := fmt.Sprintf("The bouncer handled %d PRs and denied %d by policy, using %d API calls.", t.Handled, t.Denied, t.APICalls)
+	if merged := t.Merged(); merged > 0 {
+		s += fmt.Sprintf(" %d of %d merged PRs (%d%%) went through the bouncer; merged by hand: %d.",
+			t.MergedByBouncer, merged, t.MergedByBouncer*100/merged, t.MergedManually)
+	}
+	if t.TimeSaved > 0 {
+		s += fmt.Sprintf(" Estimated reviewer time saved: %.1f hours.", t.TimeSaved.Hours())
+	}
+	return s
+}
+
+const markdownTemplate = `# Dependency health report: {{.Owner}}
+
+Generated {{date .}}.
+
+## Open PR backlog
+{{with .Totals}}
+{{.Open}} open Dependabot PRs ({{.Passing}} passing, {{.Failing}} failing, {{.Pending}} pending{{if $.StaleAfter}}, {{.Stale}} open for over {{duration $.StaleAfter}}{{end}}), {{.Denied}} denied by policy, {{.Ignored}} ignored by config. {{.Merged}} merged in the last {{$.Days}} days.
+{{end}}
+| Repository | Open | Passing | Failing | Pending | Stale | Denied | Merged | Alerts |
+|------------|-----:|--------:|--------:|--------:|------:|-------:|-------:|--------|
+{{- range .Repos}}
+| {{.Name}} | {{.Open}} | {{.Passing}} | {{.Failing}} | {{.Pending}} | {{.Stale}} | {{.Denied}} | {{.Merged}} | {{.Alerts}} |
+{{- end}}
+
+## Denial breakdown
+{{if .Denials}}
+| Rule | PRs |
+|------|----:|
+{{- range .Denials}}
+| {{.Reason}} | {{.Count}} |
+{{- end}}
+{{else}}
+No PRs denied by policy.
+{{end}}
+## Oldest open PRs
+{{if .Oldest}}
+| PR | Title | Age |
+|----|-------|----:|
+{{- range .Oldest}}
+| [{{.Repo}}#{{.Number}}]({{.URL}}) | {{.Title}} | {{duration .Age}} |
+{{- end}}
+{{else}}
+No open PRs.
+{{end}}
+## Alert coverage
+
+{{alertCoverage .}}
+
+## Time to merge (last {{.Days}} days)
+
+| Week of | Merged | Median time to merge |
+|---------|-------:|---------------------:|
+{{- range .Trends}}
+| {{.WeekStart.Format "2006-01-02"}} | {{.Merged}} | {{duration .Median}} |
+{{- end}}{{if .Automation}}
+## Automation (last {{.Days}} days)
+
+{{automation .}}
+
+| Month | Handled | Denied | Merged by bouncer | Merged by hand | API calls | Time saved |
+|-------|--------:|-------:|------------------:|---------------:|----------:|-----------:|
+{{- range .Automation}}
+| {{.Start.Format "2006-01"}} | {{.Handled}} | {{.Denied}} | {{.MergedByBouncer}} | {{.MergedManually}} | {{.APICalls}} | {{hours .TimeSaved}} |
+{{- end}}
+{{end}}{{if .Flaky}}
+## Flaky checks
+
+Packages whose PRs failed their checks and passed later, after a recreate or rebase. Their checks are likely flaky: re-run them before recreating.
+
+| Repository | Package | PRs | Failed checks |
+|------------|---------|----:|---------------|
+{{- range .Flaky}}
+| {{.Repo}} | {{.Package}} | {{.PRs}} | {{join .Checks}} |
+{{- end}}
+{{end -}}
+`
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Dependency health report: {{.Owner}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+</style>
+</head>
+<body>
+<h1>Dependency health report: {{.Owner}}</h1>
+<p>Generated {{date .}}.</p>
+
+<h2>Open PR backlog</h2>
+{{with .Totals}}<p>{{.Open}} open Dependabot PRs ({{.Passing}} passing, {{.Failing}} failing, {{.Pending}} pending{{if $.StaleAfter}}, {{.Stale}} open for over {{duration $.StaleAfter}}{{end}}), {{.Denied}} denied by policy, {{.Ignored}} ignored by config. {{.Merged}} merged in the last {{$.Days}} days.</p>{{end}}
+<table>
+<tr><th>Repository</th><th>Open</th><th>Passing</th><th>Failing</th><th>Pending</th><th>Stale</th><th>Denied</th><th>Merged</th><th>Alerts</th></tr>
+{{- range .Repos}}
+<tr><td>{{.Name}}</td><td>{{.Open}}</td><td>{{.Passing}}</td><td>{{.Failing}}</td><td>{{.Pending}}</td><td>{{.Stale}}</td><td>{{.Denied}}</td><td>{{.Merged}}</td><td>{{.Alerts}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Denial breakdown</h2>
+{{if .Denials}}<table>
+<tr><th>Rule</th><th>PRs</th></tr>
+{{- range .Denials}}
+<tr><td>{{.Reason}}</td><td>{{.Count}}</td></tr>
+{{- end}}
+</table>{{else}}<p>No PRs denied by policy.</p>{{end}}
+
+<h2>Oldest open PRs</h2>
+{{if .Oldest}}<table>
+<tr><th>PR</th><th>Title</th><th>Age</th></tr>
+{{- range .Oldest}}
+<tr><td><a href="{{.URL}}">{{.Repo}}#{{.Number}}</a></td><td>{{.Title}}</td><td>{{duration .Age}}</td></tr>
+{{- end}}
+</table>{{else}}<p>No open PRs.</p>{{end}}
+
+<h2>Alert coverage</h2>
+<p>{{alertCoverage .}}</p>
+
+<h2>Time to merge (last {{.Days}} days)</h2>
+<table>
+<tr><th>Week of</th><th>Merged</th><th>Median time to merge</th></tr>
+{{- range .Trends}}
+<tr><td>{{.WeekStart.Format "2006-01-02"}}</td><td>{{.Merged}}</td><td>{{duration .Median}}</td></tr>
+{{- end}}
+</table>
+{{- if .Automation}}
+
+<h2>Automation (last {{.Days}} days)</h2>
+<p>{{automation .}}</p>
+<table>
+<tr><th>Month</th><th>Handled</th><th>Denied</th><th>Merged by bouncer</th><th>Merged by hand</th><th>API calls</th><th>Time saved</th></tr>
+{{- range .Automation}}
+<tr><td>{{.Start.Format "2006-01"}}</td><td>{{.Handled}}</td><td>{{.Denied}}</td><td>{{.MergedByBouncer}}</td><td>{{.MergedManually}}</td><td>{{.APICalls}}</td><td>{{hours .TimeSaved}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Flaky}}
+
+<h2>Flaky checks</h2>
+<p>Packages whose PRs failed their checks and passed later, after a recreate or rebase. Their checks are likely flaky: re-run them before recreating.</p>
+<table>
+<tr><th>Repository</th><th>Package</th><th>PRs</th><th>Failed checks</th></tr>
+{{- range .Flaky}}
+<tr><td>{{.Repo}}</td><td>{{.Package}}</td><td>{{.PRs}}</td><td>{{join .Checks}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`
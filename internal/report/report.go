@@ -0,0 +1,251 @@
+// Package report aggregates Dependabot activity across repositories and
+// renders it as a Markdown or HTML dependency-health report.
+package report
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/state"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+// oldestLimit caps how many of the oldest open PRs are listed.
+const oldestLimit = 10
+
+// FlakyMinPRs is how many PRs of a package must have failed and then passed
+// before its checks are reported as flaky.
+const FlakyMinPRs = 2
+
+// Report is an owner-wide snapshot of Dependabot activity.
+type Report struct {
+	Owner       string
+	GeneratedAt time.Time
+	Days        int // time-to-merge lookback window
+	// StaleAfter is the age from which an open PR counts as stale; zero
+	// counts none.
+	StaleAfter time.Duration
+
+	Repos   []Repo
+	Denials []Denial
+	Oldest  []OpenPR
+	Trends  []Trend
+
+	// Automation is what the bouncer did, by month, when history was added.
+	Automation []Month
+	// Flaky lists the packages whose PRs failed and then passed, according
+	// to the history.
+	Flaky []state.FlakyPackage
+
+	merged  []bouncer.MergedPR
+	merges  []repoMerge
+	open    []OpenPR
+	denied  map[string]*Denial
+	history []state.Record
+	perPR   time.Duration
+}
+
+// Repo summarizes the open Dependabot backlog of a single repository.
+type Repo struct {
+	Name    string
+	Open    int
+	Passing int
+	Failing int
+	Pending int
+	Denied  int
+	Ignored int
+	Stale   int    // open PRs older than the report's StaleAfter
+	Merged  int    // PRs merged in the lookback window
+	Alerts  string // enabled, disabled, unknown
+}
+
+// Denial counts open PRs skipped by a single deny rule.
+type Denial struct {
+	Reason string
+	Count  int
+}
+
+// OpenPR is an open Dependabot PR together with its repository.
+type OpenPR struct {
+	Repo   string
+	Number int
+	Title  string
+	URL    string
+	Age    time.Duration
+}
+
+// Trend holds time-to-merge figures for one week.
+type Trend struct {
+	WeekStart time.Time
+	Merged    int
+	Median    time.Duration
+}
+
+// New returns an empty report for the owner.
+func New(owner string, now time.Time, days int) *Report {
+	return &Report{
+		Owner:       owner,
+		GeneratedAt: now,
+		Days:        days,
+		denied:      make(map[string]*Denial),
+	}
+}
+
+// AddRepo records a repository's open PRs (including skipped ones), the PRs
+// merged in the lookback window, and its alert status.
+func (r *Report) AddRepo(name string, prs []bouncer.PRInfo, merged []bouncer.MergedPR, alerts string) {
+	repo := Repo{Name: name, Alerts: alerts}
+	for _, pr := range prs {
+		if pr.SkipCode == bouncer.SkipIgnored {
+			repo.Ignored++
+			continue
+		}
+		if pr.Skipped {
+			repo.Denied++
+			d, ok := r.denied[pr.SkipReason]
+			if !ok {
+				d = &Denial{Reason: pr.SkipReason}
+				r.denied[pr.SkipReason] = d
+			}
+			d.Count++
+			continue
+		}
+
+		repo.Open++
+		if r.StaleAfter > 0 && r.GeneratedAt.Sub(pr.CreatedAt) >= r.StaleAfter {
+			repo.Stale++
+		}
+		switch pr.CIStatus {
+		case "success":
+			repo.Passing++
+		case "failure":
+			repo.Failing++
+		default:
+			repo.Pending++
+		}
+		r.open = append(r.open, OpenPR{
+			Repo:   name,
+			Number: pr.Number,
+			Title:  pr.Title,
+			URL:    pr.URL,
+			Age:    r.GeneratedAt.Sub(pr.CreatedAt),
+		})
+	}
+	repo.Merged = len(merged)
+	r.Repos = append(r.Repos, repo)
+	r.merged = append(r.merged, merged...)
+	for _, m := range merged {
+		r.merges = append(r.merges, repoMerge{repo: name, pr: m.Number, at: m.MergedAt})
+	}
+}
+
+// Finalize computes the derived sections. It must be called after all
+// repositories have been added and before rendering.
+func (r *Report) Finalize() {
+	sort.Slice(r.Repos, func(i, j int) bool { return r.Repos[i].Name < r.Repos[j].Name })
+
+	r.Denials = r.Denials[:0]
+	for _, d := range r.denied {
+		r.Denials = append(r.Denials, *d)
+	}
+	sort.Slice(r.Denials, func(i, j int) bool {
+		if r.Denials[i].Count != r.Denials[j].Count {
+			return r.Denials[i].Count > r.Denials[j].Count
+		}
+		return r.Denials[i].Reason < r.Denials[j].Reason
+	})
+
+	r.Oldest = append([]OpenPR(nil), r.open...)
+	sort.SliceStable(r.Oldest, func(i, j int) bool { return r.Oldest[i].Age > r.Oldest[j].Age })
+	if len(r.Oldest) > oldestLimit {
+		r.Oldest = r.Oldest[:oldestLimit]
+	}
+
+	r.Trends = weeklyTrends(r.merged, r.GeneratedAt, r.Days)
+	r.Automation = automationByMonth(r.history, r.merges, r.perPR, r.GeneratedAt, r.Days)
+	r.Flaky = state.FlakyPackages(r.history, FlakyMinPRs)
+}
+
+// Totals returns the backlog counts summed across all repositories.
+func (r *Report) Totals() Repo {
+	var t Repo
+	for _, repo := range r.Repos {
+		t.Open += repo.Open
+		t.Passing += repo.Passing
+		t.Failing += repo.Failing
+		t.Pending += repo.Pending
+		t.Denied += repo.Denied
+		t.Ignored += repo.Ignored
+		t.Stale += repo.Stale
+		t.Merged += repo.Merged
+	}
+	return t
+}
+
+// AlertCoverage returns how many repositories have Dependabot alerts enabled.
+func (r *Report) AlertCoverage() (enabled, total int) {
+	for _, repo := range r.Repos {
+		if repo.Alerts == "enabled" {
+			enabled++
+		}
+	}
+	return enabled, len(r.Repos)
+}
+
+// weeklyTrends buckets merged PRs into weeks ending at now, oldest first.
+func weeklyTrends(merged []bouncer.MergedPR, now time.Time, days int) []Trend {
+	weeks := (days + 6) / 7
+	if weeks <= 0 {
+		return nil
+	}
+
+	const week = 7 * 24 * time.Hour
+	start := now.Add(-time.Duration(weeks) * week)
+	buckets := make([][]time.Duration, weeks)
+	for _, m := range merged {
+		if m.MergedAt.Before(start) || m.MergedAt.After(now) {
+			continue
+		}
+		i := int(m.MergedAt.Sub(start) / week)
+		if i >= weeks {
+			i = weeks - 1
+		}
+		buckets[i] = append(buckets[i], m.MergedAt.Sub(m.CreatedAt))
+	}
+
+	trends := make([]Trend, weeks)
+	for i, b := range buckets {
+		trends[i] = Trend{
+			WeekStart: start.Add(time.Duration(i) * week),
+			Merged:    len(b),
+			Median:    median(b),
+		}
+	}
+	return trends
+}
+
+func median(ds []time.Duration) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), ds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// humanDuration formats a duration in days or hours for report tables.
+func humanDuration(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "-"
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%.1fd", d.Hours()/24)
+	default:
+		return fmt.Sprintf("%.1fh", d.Hours())
+	}
+}
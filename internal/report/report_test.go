@@ -0,0 +1,139 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+func TestReportAggregation(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	r := New("acme", now, 14)
+	r.StaleAfter = 7 * day
+	r.AddRepo("acme/api", []bouncer.PRInfo{
+		{Number: 1, Title: "Bump a", CIStatus: "success", CreatedAt: now.Add(-3 * day)},
+		{Number: 2, Title: "Bump b", CIStatus: "failure", CreatedAt: now.Add(-10 * day)},
+		{Number: 3, Title: "Bump c", Skipped: true, SkipReason: "denied org: datadog"},
+	}, []bouncer.MergedPR{
+		{Number: 10, CreatedAt: now.Add(-3 * day), MergedAt: now.Add(-2 * day)},
+		{Number: 11, CreatedAt: now.Add(-12 * day), MergedAt: now.Add(-9 * day)},
+	}, "enabled")
+	r.AddRepo("acme/web", []bouncer.PRInfo{
+		{Number: 4, Title: "Bump d", CIStatus: "pending", CreatedAt: now.Add(-1 * day)},
+		{Number: 5, Title: "Bump e", Skipped: true, SkipReason: "denied org: datadog"},
+		{Number: 6, Title: "Bump f", Skipped: true, SkipCode: bouncer.SkipIgnored, SkipReason: "listed in ignored_prs"},
+	}, nil, "disabled")
+	r.Finalize()
+
+	totals := r.Totals()
+	if totals.Open != 3 || totals.Passing != 1 || totals.Failing != 1 || totals.Pending != 1 || totals.Denied != 2 || totals.Ignored != 1 || totals.Stale != 1 || totals.Merged != 2 {
+		t.Errorf("Totals() = %+v", totals)
+	}
+
+	if len(r.Denials) != 1 || r.Denials[0].Count != 2 {
+		t.Errorf("Denials = %+v, want one rule with 2 PRs", r.Denials)
+	}
+
+	if len(r.Oldest) != 3 || r.Oldest[0].Number != 2 {
+		t.Errorf("Oldest = %+v, want PR #2 first", r.Oldest)
+	}
+
+	if enabled, total := r.AlertCoverage(); enabled != 1 || total != 2 {
+		t.Errorf("AlertCoverage() = %d/%d, want 1/2", enabled, total)
+	}
+
+	if len(r.Trends) != 2 {
+		t.Fatalf("Trends = %d weeks, want 2", len(r.Trends))
+	}
+	if r.Trends[0].Merged != 1 || r.Trends[0].Median != 3*day {
+		t.Errorf("Trends[0] = %+v, want 1 merged with 3d median", r.Trends[0])
+	}
+	if r.Trends[1].Merged != 1 || r.Trends[1].Median != day {
+		t.Errorf("Trends[1] = %+v, want 1 merged with 1d median", r.Trends[1])
+	}
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []time.Duration
+		want time.Duration
+	}{
+		{name: "empty", in: nil, want: 0},
+		{name: "odd", in: []time.Duration{3, 1, 2}, want: 2},
+		{name: "even", in: []time.Duration{4, 1, 3, 2}, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := median(tt.in); got != tt.want {
+				t.Errorf("median() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := New("acme", now, 7)
+	r.AddRepo("acme/api", []bouncer.PRInfo{
+		{Number: 1, Title: "Bump <x>", URL: "https://github.com/acme/api/pull/1", CIStatus: "success", CreatedAt: now.Add(-time.Hour)},
+	}, nil, "disabled")
+	r.Finalize()
+
+	var md bytes.Buffer
+	if err := WriteMarkdown(&md, r); err != nil {
+		t.Fatalf("WriteMarkdown() error = %v", err)
+	}
+	for _, want := range []string{
+		"# Dependency health report: acme",
+		"| acme/api | 1 | 1 | 0 | 0 | 0 | 0 | 0 | disabled |",
+		"[acme/api#1](https://github.com/acme/api/pull/1)",
+		"0 of 1 repositories have Dependabot alerts enabled. Not enabled: acme/api (disabled).",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown output missing %q\n%s", want, md.String())
+		}
+	}
+
+	var html bytes.Buffer
+	if err := WriteHTML(&html, r); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+	if !strings.Contains(html.String(), "Bump &lt;x&gt;") {
+		t.Errorf("html output does not escape titles\n%s", html.String())
+	}
+}
+
+func TestWriteTemplate(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := New("acme", now, 7)
+	r.AddRepo("acme/api", []bouncer.PRInfo{{Number: 1, Title: "Bump <x>", CIStatus: "success", CreatedAt: now}}, nil, "enabled")
+	r.Finalize()
+
+	var md bytes.Buffer
+	text := `{{.Owner}} week of {{date .}}:{{range .Repos}} {{.Name}}={{.Open}}{{end}}{{range .Oldest}} {{.Title}}{{end}}`
+	if err := WriteTemplate(&md, r, FormatMarkdown, text); err != nil {
+		t.Fatalf("WriteTemplate(markdown) error = %v", err)
+	}
+	if want := "acme week of 2026-03-01: acme/api=1 Bump <x>"; md.String() != want {
+		t.Errorf("WriteTemplate(markdown) = %q, want %q", md.String(), want)
+	}
+
+	var html bytes.Buffer
+	if err := WriteTemplate(&html, r, FormatHTML, `<p>{{range .Oldest}}{{.Title}}{{end}}</p>`); err != nil {
+		t.Fatalf("WriteTemplate(html) error = %v", err)
+	}
+	if want := "<p>Bump &lt;x&gt;</p>"; html.String() != want {
+		t.Errorf("WriteTemplate(html) = %q, want %q", html.String(), want)
+	}
+
+	if err := WriteTemplate(&md, r, FormatMarkdown, "{{.Owner"); err == nil {
+		t.Error("WriteTemplate() error = nil, want a parse error")
+	}
+}
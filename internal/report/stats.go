@@ -0,0 +1,104 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/state"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+// Stats sums up the Dependabot PRs closed over a lookback window: how many
+// were merged and how quickly, the packages updated most, and how many PRs
+// the bouncer denied by policy.
+type Stats struct {
+	Days  int // lookback window
+	Repos int
+
+	Closed            int // merged or closed without merging
+	Merged            int
+	MedianTimeToMerge time.Duration
+	TopPackages       []PackageCount // most merged first
+
+	// Bounced is the number of distinct PRs the history records as denied
+	// by policy in the window; HistoryKnown is false when no history was
+	// added.
+	Bounced      int
+	HistoryKnown bool
+
+	ttm      []time.Duration
+	packages map[string]int
+}
+
+// PackageCount is the number of merged updates of a package.
+type PackageCount struct {
+	Package string
+	Merged  int
+}
+
+// NewStats returns empty stats over the last days days.
+func NewStats(days int) *Stats {
+	return &Stats{Days: days, packages: make(map[string]int)}
+}
+
+// AddRepo records the PRs of a repository closed in the window, as listed by
+// bouncer.ListClosedDependabotPRs.
+func (s *Stats) AddRepo(closed []bouncer.MergedPR) {
+	s.Repos++
+	for _, pr := range closed {
+		s.Closed++
+		if pr.MergedAt.IsZero() {
+			continue
+		}
+		s.Merged++
+		s.ttm = append(s.ttm, pr.MergedAt.Sub(pr.CreatedAt))
+		if pr.PackageName != "" {
+			s.packages[pr.PackageName]++
+		}
+	}
+}
+
+// AddHistory counts the PRs denied by policy among the history records,
+// which should cover the window and the repositories added.
+func (s *Stats) AddHistory(recs []state.Record) {
+	s.HistoryKnown = true
+	type prKey struct {
+		repo   string
+		number int
+	}
+	denied := make(map[prKey]bool)
+	for _, rec := range recs {
+		if rec.Decision == "denied" {
+			denied[prKey{rec.Repo, rec.Number}] = true
+		}
+	}
+	s.Bounced = len(denied)
+}
+
+// MergeRate returns the share of closed PRs that were merged, from 0 to 1.
+func (s *Stats) MergeRate() float64 {
+	if s.Closed == 0 {
+		return 0
+	}
+	return float64(s.Merged) / float64(s.Closed)
+}
+
+// Finalize computes the median time-to-merge and keeps the top packages,
+// at most top of them.
+func (s *Stats) Finalize(top int) {
+	s.MedianTimeToMerge = median(s.ttm)
+	s.TopPackages = nil
+	for pkg, n := range s.packages {
+		s.TopPackages = append(s.TopPackages, PackageCount{Package: pkg, Merged: n})
+	}
+	sort.Slice(s.TopPackages, func(i, j int) bool {
+		a, b := s.TopPackages[i], s.TopPackages[j]
+		if a.Merged != b.Merged {
+			return a.Merged > b.Merged
+		}
+		return a.Package < b.Package
+	})
+	if len(s.TopPackages) > top {
+		s.TopPackages = s.TopPackages[:top]
+	}
+}
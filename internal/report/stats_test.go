@@ -0,0 +1,50 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/state"
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+func TestStats(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	hour := time.Hour
+	merged := func(pkg string, ttm time.Duration) bouncer.MergedPR {
+		return bouncer.MergedPR{PackageName: pkg, CreatedAt: now.Add(-ttm), MergedAt: now, ClosedAt: now}
+	}
+
+	s := NewStats(90)
+	s.AddRepo([]bouncer.MergedPR{
+		merged("lodash", 2*hour),
+		merged("lodash", 4*hour),
+		merged("react", 10*hour),
+		{PackageName: "left-pad", CreatedAt: now.Add(-hour), ClosedAt: now},
+	})
+	s.AddRepo([]bouncer.MergedPR{merged("axios", 30*hour)})
+	s.AddHistory([]state.Record{
+		{Repo: "acme/api", Number: 1, Decision: "denied"},
+		{Repo: "acme/api", Number: 1, Decision: "denied"},
+		{Repo: "acme/web", Number: 1, Decision: "denied"},
+		{Repo: "acme/api", Number: 2, Decision: "approved"},
+	})
+	s.Finalize(2)
+
+	if s.Repos != 2 || s.Closed != 5 || s.Merged != 4 {
+		t.Errorf("repos, closed, merged = %d, %d, %d, want 2, 5, 4", s.Repos, s.Closed, s.Merged)
+	}
+	if got := s.MergeRate(); got != 0.8 {
+		t.Errorf("MergeRate() = %v, want 0.8", got)
+	}
+	if s.MedianTimeToMerge != 7*hour {
+		t.Errorf("MedianTimeToMerge = %v, want 7h", s.MedianTimeToMerge)
+	}
+	want := []PackageCount{{"lodash", 2}, {"axios", 1}}
+	if len(s.TopPackages) != 2 || s.TopPackages[0] != want[0] || s.TopPackages[1] != want[1] {
+		t.Errorf("TopPackages = %v, want %v", s.TopPackages, want)
+	}
+	if !s.HistoryKnown || s.Bounced != 2 {
+		t.Errorf("Bounced = %d (known %v), want 2", s.Bounced, s.HistoryKnown)
+	}
+}
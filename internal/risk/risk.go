@@ -0,0 +1,185 @@
+// Package risk summarizes the risk of the updates approved in a single run.
+package risk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+// ScorecardURL is the base URL of the OpenSSF Scorecard API.
+var ScorecardURL = "https://api.securityscorecards.dev"
+
+// Risk levels reported by Summary.Level.
+const (
+	LevelLow    = "low"
+	LevelMedium = "medium"
+	LevelHigh   = "high"
+)
+
+// Summary accumulates the approved updates of a run.
+type Summary struct {
+	// MinScorecard is the OpenSSF Scorecard score below which a package counts
+	// as low-scoring. Scorecards are not fetched when it is zero.
+	MinScorecard float64
+
+	Approved int
+	Major    []string // packages approved with a major version bump
+	Unknown  []string // packages whose update type could not be determined
+	Security []string // packages approved as security fixes
+	LowScore []string // packages below MinScorecard, formatted with their score
+
+	mu         sync.Mutex // guards the fields above and approvals
+	approvals  []approval
+	client     *http.Client
+	cacheMu    sync.Mutex
+	scoreCache map[string]float64 // scorecard per GitHub repository; -1 when unavailable
+}
+
+// approval is an approved PR as recorded in a summary.
+type approval struct {
+	repo     string
+	pr       bouncer.PRInfo
+	lowScore string // the package and its score when below MinScorecard
+}
+
+// NewSummary returns an empty summary. A positive minScorecard enables
+// OpenSSF Scorecard lookups for GitHub-hosted packages.
+func NewSummary(minScorecard float64) *Summary {
+	return &Summary{
+		MinScorecard: minScorecard,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		scoreCache:   make(map[string]float64),
+	}
+}
+
+// Record adds a PR approved in the repository "owner/repo" to the summary.
+// The package's scorecard is looked up before the summary is locked, so
+// concurrent records do not wait on each other's lookups.
+func (s *Summary) Record(repo string, pr bouncer.PRInfo) {
+	a := approval{repo: repo, pr: pr}
+	if s.MinScorecard > 0 {
+		if score, ok := s.scorecard(pr.PackageName); ok && score < s.MinScorecard {
+			a.lowScore = fmt.Sprintf("%s (%.1f)", pr.PackageName, score)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.add(a)
+}
+
+// add counts an approval in the summary.
+func (s *Summary) add(a approval) {
+	s.approvals = append(s.approvals, a)
+	s.Approved++
+	switch a.pr.UpdateType {
+	case bouncer.UpdateMajor:
+		s.Major = append(s.Major, a.pr.PackageName)
+	case "":
+		s.Unknown = append(s.Unknown, a.pr.PackageName)
+	}
+	if a.pr.Security {
+		s.Security = append(s.Security, a.pr.PackageName)
+	}
+	if a.lowScore != "" {
+		s.LowScore = append(s.LowScore, a.lowScore)
+	}
+}
+
+// For returns the summary of the approvals recorded in repos only, for
+// reporting to those who follow just some of the repositories. Nothing
+// should be recorded in it.
+func (s *Summary) For(repos []string) *Summary {
+	out := &Summary{MinScorecard: s.MinScorecard}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range s.approvals {
+		if slices.Contains(repos, a.repo) {
+			out.add(a)
+		}
+	}
+	return out
+}
+
+// Level rates the run: high when majors or low-scoring packages were approved,
+// medium when some update types are unknown, low otherwise.
+func (s *Summary) Level() string {
+	switch {
+	case len(s.Major) > 0 || len(s.LowScore) > 0:
+		return LevelHigh
+	case len(s.Unknown) > 0:
+		return LevelMedium
+	default:
+		return LevelLow
+	}
+}
+
+// Lines renders the summary as human-readable lines.
+func (s *Summary) Lines() []string {
+	lines := []string{
+		fmt.Sprintf("Risk: %s (%d approved)", s.Level(), s.Approved),
+		fmt.Sprintf("  Major bumps:     %d%s", len(s.Major), list(s.Major)),
+		fmt.Sprintf("  Unknown type:    %d%s", len(s.Unknown), list(s.Unknown)),
+		fmt.Sprintf("  Security fixes:  %d%s", len(s.Security), list(s.Security)),
+	}
+	if s.MinScorecard > 0 {
+		lines = append(lines, fmt.Sprintf("  Low scorecards:  %d%s", len(s.LowScore), list(s.LowScore)))
+	}
+	return lines
+}
+
+func list(items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(items, ", ") + ")"
+}
+
+// scorecard returns the OpenSSF Scorecard score of the GitHub repository
+// hosting the package. Only github.com packages can be looked up. The cache
+// is not locked during the request, so two records of one repository may
+// both fetch it.
+func (s *Summary) scorecard(packageName string) (float64, bool) {
+	repo, ok := githubRepo(packageName)
+	if !ok {
+		return 0, false
+	}
+	s.cacheMu.Lock()
+	score, cached := s.scoreCache[repo]
+	s.cacheMu.Unlock()
+	if cached {
+		return score, score >= 0
+	}
+
+	score = -1.0
+	resp, err := s.client.Get(ScorecardURL + "/projects/" + repo)
+	if err == nil {
+		defer resp.Body.Close()
+		var body struct {
+			Score float64 `json:"score"`
+		}
+		if resp.StatusCode == http.StatusOK && json.NewDecoder(resp.Body).Decode(&body) == nil {
+			score = body.Score
+		}
+	}
+	s.cacheMu.Lock()
+	s.scoreCache[repo] = score
+	s.cacheMu.Unlock()
+	return score, score >= 0
+}
+
+// githubRepo returns "github.com/owner/repo" for packages hosted on GitHub.
+func githubRepo(packageName string) (string, bool) {
+	parts := strings.Split(packageName, "/")
+	if len(parts) < 3 || parts[0] != "github.com" {
+		return "", false
+	}
+	return strings.Join(parts[:3], "/"), true
+}
@@ -0,0 +1,124 @@
+package risk
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+func TestSummaryLevel(t *testing.T) {
+	tests := []struct {
+		name string
+		prs  []bouncer.PRInfo
+		want string
+	}{
+		{
+			name: "patch and minor only",
+			prs: []bouncer.PRInfo{
+				{PackageName: "a", UpdateType: bouncer.UpdatePatch},
+				{PackageName: "b", UpdateType: bouncer.UpdateMinor},
+			},
+			want: LevelLow,
+		},
+		{
+			name: "unknown update type",
+			prs: []bouncer.PRInfo{
+				{PackageName: "aws-sdk-go-v2"},
+			},
+			want: LevelMedium,
+		},
+		{
+			name: "major bump",
+			prs: []bouncer.PRInfo{
+				{PackageName: "a", UpdateType: bouncer.UpdatePatch},
+				{PackageName: "b", UpdateType: bouncer.UpdateMajor},
+			},
+			want: LevelHigh,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSummary(0)
+			for _, pr := range tt.prs {
+				s.Record("acme/api", pr)
+			}
+			if got := s.Level(); got != tt.want {
+				t.Errorf("Level() = %q, want %q", got, tt.want)
+			}
+			if s.Approved != len(tt.prs) {
+				t.Errorf("Approved = %d, want %d", s.Approved, len(tt.prs))
+			}
+		})
+	}
+}
+
+func TestSummarySecurity(t *testing.T) {
+	s := NewSummary(0)
+	s.Record("acme/api", bouncer.PRInfo{PackageName: "lodash", UpdateType: bouncer.UpdatePatch, Security: true})
+	s.Record("acme/api", bouncer.PRInfo{PackageName: "react", UpdateType: bouncer.UpdatePatch})
+
+	if !reflect.DeepEqual(s.Security, []string{"lodash"}) {
+		t.Errorf("Security = %v, want [lodash]", s.Security)
+	}
+}
+
+func TestSummaryScorecard(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/projects/github.com/acme/weak":
+			fmt.Fprint(w, `{"score": 3.2}`)
+		case "/projects/github.com/acme/strong":
+			fmt.Fprint(w, `{"score": 8.9}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	orig := ScorecardURL
+	ScorecardURL = srv.URL
+	defer func() { ScorecardURL = orig }()
+
+	s := NewSummary(5)
+	s.Record("acme/api", bouncer.PRInfo{PackageName: "github.com/acme/weak/v2", UpdateType: bouncer.UpdateMinor})
+	s.Record("acme/api", bouncer.PRInfo{PackageName: "github.com/acme/weak", UpdateType: bouncer.UpdatePatch})
+	s.Record("acme/api", bouncer.PRInfo{PackageName: "github.com/acme/strong", UpdateType: bouncer.UpdatePatch})
+	s.Record("acme/api", bouncer.PRInfo{PackageName: "github.com/acme/missing", UpdateType: bouncer.UpdatePatch})
+	s.Record("acme/api", bouncer.PRInfo{PackageName: "lodash", UpdateType: bouncer.UpdatePatch})
+
+	want := []string{"github.com/acme/weak/v2 (3.2)", "github.com/acme/weak (3.2)"}
+	if !reflect.DeepEqual(s.LowScore, want) {
+		t.Errorf("LowScore = %v, want %v", s.LowScore, want)
+	}
+	if s.Level() != LevelHigh {
+		t.Errorf("Level() = %q, want %q", s.Level(), LevelHigh)
+	}
+	if requests != 3 {
+		t.Errorf("scorecard requests = %d, want 3 (cached per repository)", requests)
+	}
+}
+
+func TestSummaryFor(t *testing.T) {
+	s := NewSummary(0)
+	s.Record("acme/api", bouncer.PRInfo{PackageName: "react", UpdateType: bouncer.UpdateMajor})
+	s.Record("acme/web", bouncer.PRInfo{PackageName: "lodash", UpdateType: bouncer.UpdatePatch, Security: true})
+	s.Record("acme/web", bouncer.PRInfo{PackageName: "aws-sdk-go-v2"})
+
+	web := s.For([]string{"acme/web", "acme/docs"})
+	if web.Approved != 2 || len(web.Major) != 0 || !reflect.DeepEqual(web.Security, []string{"lodash"}) || web.Level() != LevelMedium {
+		t.Errorf("For(acme/web) = %+v, level %s", web, web.Level())
+	}
+	if s.Approved != 3 || s.Level() != LevelHigh {
+		t.Errorf("summary changed to %+v", s)
+	}
+	if none := s.For(nil); none.Approved != 0 {
+		t.Errorf("For(nil).Approved = %d, want 0", none.Approved)
+	}
+}
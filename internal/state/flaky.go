@@ -0,0 +1,71 @@
+package state
+
+import (
+	"slices"
+	"sort"
+)
+
+// FlakyPackage is a package whose PRs in one repository failed their checks
+// and were approved later on, with passing checks.
+type FlakyPackage struct {
+	Repo    string   `json:"repo"`
+	Package string   `json:"package"`
+	PRs     int      `json:"prs"`    // PRs that failed, then passed
+	Checks  []string `json:"checks"` // checks that failed on those PRs
+}
+
+// FlakyPackages returns the packages with at least min PRs whose checks
+// failed, as recorded with a decision such as a recreate, and that were
+// approved after that. Such packages likely have flaky checks rather than
+// broken updates. recs must be oldest first; the result has the most PRs
+// first.
+func FlakyPackages(recs []Record, min int) []FlakyPackage {
+	type pkgKey struct{ repo, pkg string }
+	failed := make(map[prKey][]string) // checks failing on a PR not approved since
+	byPkg := make(map[pkgKey]*FlakyPackage)
+	for _, r := range recs {
+		if r.Number == 0 || r.Package == "" {
+			continue
+		}
+		key := prKey{r.Repo, r.Number}
+		switch {
+		case len(r.Failing) > 0:
+			for _, c := range r.Failing {
+				if !slices.Contains(failed[key], c) {
+					failed[key] = append(failed[key], c)
+				}
+			}
+		case r.Decision == "approved" && failed[key] != nil:
+			p := byPkg[pkgKey{r.Repo, r.Package}]
+			if p == nil {
+				p = &FlakyPackage{Repo: r.Repo, Package: r.Package}
+				byPkg[pkgKey{r.Repo, r.Package}] = p
+			}
+			p.PRs++
+			for _, c := range failed[key] {
+				if !slices.Contains(p.Checks, c) {
+					p.Checks = append(p.Checks, c)
+				}
+			}
+			delete(failed, key)
+		}
+	}
+
+	var out []FlakyPackage
+	for _, p := range byPkg {
+		if p.PRs >= min {
+			sort.Strings(p.Checks)
+			out = append(out, *p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].PRs != out[j].PRs {
+			return out[i].PRs > out[j].PRs
+		}
+		if out[i].Repo != out[j].Repo {
+			return out[i].Repo < out[j].Repo
+		}
+		return out[i].Package < out[j].Package
+	})
+	return out
+}
@@ -0,0 +1,35 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlakyPackages(t *testing.T) {
+	recs := []Record{
+		// acme/api#1 and #2: go-redis failed, was recreated, then approved.
+		{Repo: "acme/api", Number: 1, Package: "go-redis", Decision: "recreated", Failing: []string{"integration"}},
+		{Repo: "acme/api", Number: 1, Package: "go-redis", Decision: "approved"},
+		{Repo: "acme/api", Number: 2, Package: "go-redis", Decision: "recreated", Failing: []string{"e2e"}},
+		{Repo: "acme/api", Number: 2, Package: "go-redis", Decision: "failed", Failing: []string{"integration"}},
+		{Repo: "acme/api", Number: 2, Package: "go-redis", Decision: "approved"},
+		// Approved again later: counted once.
+		{Repo: "acme/api", Number: 2, Package: "go-redis", Decision: "approved"},
+		// acme/api#3: failed once, then passed; below the minimum alone.
+		{Repo: "acme/api", Number: 3, Package: "cobra", Decision: "recreated", Failing: []string{"build"}},
+		{Repo: "acme/api", Number: 3, Package: "cobra", Decision: "approved"},
+		// acme/web#4: still failing.
+		{Repo: "acme/web", Number: 4, Package: "go-redis", Decision: "recreated", Failing: []string{"build"}},
+		{Repo: "acme/web", Number: 4, Package: "go-redis", Decision: "recreated", Failing: []string{"build"}},
+		// acme/web#5: approved without ever failing.
+		{Repo: "acme/web", Number: 5, Package: "go-redis", Decision: "approved"},
+	}
+
+	want := []FlakyPackage{{Repo: "acme/api", Package: "go-redis", PRs: 2, Checks: []string{"e2e", "integration"}}}
+	if got := FlakyPackages(recs, 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("FlakyPackages(min 2) = %+v, want %+v", got, want)
+	}
+	if got := FlakyPackages(recs, 1); len(got) != 2 || got[1].Package != "cobra" {
+		t.Errorf("FlakyPackages(min 1) = %+v, want go-redis then cobra", got)
+	}
+}
@@ -0,0 +1,167 @@
+package state
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// HistoryFile is the history's name, next to the state file.
+const HistoryFile = "history.jsonl"
+
+// DecisionRun is the decision of a record that stands for a run over a
+// repository rather than a PR, with the API calls the run made.
+const DecisionRun = "run"
+
+// Record is one decision taken on a PR: an action, successful or not, or a
+// denial by policy. A record with Decision DecisionRun and no Number is a
+// run over the repository instead.
+type Record struct {
+	Time     time.Time `json:"time"`
+	Command  string    `json:"command"` // approve, recreate, maintain, ignore, close, undo, watch, serve
+	Repo     string    `json:"repo"`    // owner/repo
+	Number   int       `json:"number,omitempty"`
+	Package  string    `json:"package,omitempty"`
+	Decision string    `json:"decision"`         // approved, recreated, rebased, closed, ignored, undone, denied, skipped, failed, or run
+	Reason   string    `json:"reason,omitempty"` // skip code of a denial
+	Details  []string  `json:"details,omitempty"`
+	Errors   []string  `json:"errors,omitempty"`
+	Failing  []string  `json:"failing,omitempty"` // checks failing on the PR when it was acted on
+	Calls    int       `json:"calls,omitempty"`   // API calls of a run
+	Actor    string    `json:"actor,omitempty"`   // GitHub login the action was taken as (audit.history)
+	Rule     string    `json:"rule,omitempty"`    // allow entry or exemption that let the PR through (audit.history)
+}
+
+// History is an append-only log of records, one JSON object per line.
+// Appending never rewrites earlier records, so concurrent runs can share it.
+type History struct {
+	path string
+
+	mu   sync.Mutex
+	last map[prKey]Record // latest record per PR; nil until loaded
+}
+
+type prKey struct {
+	repo   string
+	number int
+}
+
+// HistoryPath returns the history file kept next to the state file at
+// statePath.
+func HistoryPath(statePath string) string {
+	return filepath.Join(filepath.Dir(statePath), HistoryFile)
+}
+
+// OpenHistory returns the history at path. Nothing is read until needed, and
+// the file is created on the first append.
+func OpenHistory(path string) *History {
+	return &History{path: path}
+}
+
+// Path returns the file the history is kept in.
+func (h *History) Path() string {
+	return h.path
+}
+
+// Append adds records to the end of the history.
+func (h *History) Append(recs ...Record) error {
+	if len(recs) == 0 {
+		return nil
+	}
+	var b []byte
+	for _, r := range recs {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		b = append(append(b, line...), '\n')
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+	if h.last != nil {
+		for _, r := range recs {
+			h.last[prKey{r.Repo, r.Number}] = r
+		}
+	}
+	return nil
+}
+
+// Records returns the records made at or after since, oldest first. Lines
+// that cannot be parsed, such as one cut short by a crash, are skipped.
+func (h *History) Records(since time.Time) ([]Record, error) {
+	var out []Record
+	err := h.scan(func(r Record) {
+		if !r.Time.Before(since) {
+			out = append(out, r)
+		}
+	})
+	return out, err
+}
+
+// Last returns the latest record of a PR. The whole history is read on the
+// first call; later appends through h are taken into account.
+func (h *History) Last(repo string, number int) (Record, bool, error) {
+	h.mu.Lock()
+	loaded := h.last != nil
+	h.mu.Unlock()
+	if !loaded {
+		last := make(map[prKey]Record)
+		if err := h.scan(func(r Record) { last[prKey{r.Repo, r.Number}] = r }); err != nil {
+			return Record{}, false, err
+		}
+		h.mu.Lock()
+		if h.last == nil {
+			h.last = last
+		}
+		h.mu.Unlock()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r, ok := h.last[prKey{repo, number}]
+	return r, ok, nil
+}
+
+// scan calls fn with each record in the file, oldest first.
+func (h *History) scan(fn func(Record)) error {
+	f, err := os.Open(h.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	for sc.Scan() {
+		var r Record
+		if json.Unmarshal(sc.Bytes(), &r) == nil {
+			fn(r)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+	return nil
+}
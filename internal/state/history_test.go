@@ -0,0 +1,48 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	dir := t.TempDir()
+	path := HistoryPath(filepath.Join(dir, "state.json"))
+	start := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	h := OpenHistory(path)
+	if _, ok, err := h.Last("acme/api", 7); ok || err != nil {
+		t.Fatalf("Last() on a missing file = %v, %v, want false, nil", ok, err)
+	}
+	if err := h.Append(
+		Record{Time: start, Command: "approve", Repo: "acme/api", Number: 7, Decision: "denied", Reason: "DENIED_PACKAGE"},
+		Record{Time: start.Add(time.Hour), Command: "approve", Repo: "acme/api", Number: 8, Decision: "approved"},
+	); err != nil {
+		t.Fatalf("Append(): %v", err)
+	}
+	if err := h.Append(Record{Time: start.Add(2 * time.Hour), Command: "ignore", Repo: "acme/api", Number: 7, Decision: "ignored"}); err != nil {
+		t.Fatalf("Append(): %v", err)
+	}
+
+	// A torn last line, as left by a crash, is skipped.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"time":"2026-06-01T15:00:00Z","repo":"acme`)
+	f.Close()
+
+	reopened := OpenHistory(path)
+	got, err := reopened.Records(start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Records(): %v", err)
+	}
+	if len(got) != 2 || got[0].Number != 8 || got[1].Decision != "ignored" {
+		t.Errorf("Records(since an hour in) = %+v, want #8 approved then #7 ignored", got)
+	}
+	if r, ok, err := reopened.Last("acme/api", 7); !ok || err != nil || r.Decision != "ignored" {
+		t.Errorf("Last(acme/api#7) = %+v, %v, %v, want the ignored record", r, ok, err)
+	}
+}
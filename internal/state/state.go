@@ -0,0 +1,145 @@
+// Package state keeps what the tool remembers between runs on this machine:
+// PRs snoozed until a date, in a JSON file, and the history of decisions
+// taken on PRs, in an append-only JSON-lines file next to it.
+//
+// Every change to the state file rewrites it through a temporary file and a
+// rename, so a crash never leaves it half written. Runs that only read the
+// state open it again each time, which picks up changes made by other
+// processes.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultFile is the state file's name under the user's home directory.
+const DefaultFile = ".dependabot-bouncer/state.json"
+
+// Snooze defers a pull request until a date.
+type Snooze struct {
+	Repo      string    `json:"repo"` // owner/repo
+	Number    int       `json:"number"`
+	Until     time.Time `json:"until"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Store is the state file, loaded into memory.
+type Store struct {
+	path string
+
+	mu   sync.Mutex
+	data document
+}
+
+// document is the file's layout.
+type document struct {
+	Snoozes []Snooze `json:"snoozes,omitempty"`
+}
+
+// DefaultPath returns DefaultFile under the user's home directory.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the state file: %w", err)
+	}
+	return filepath.Join(home, DefaultFile), nil
+}
+
+// Open loads the state file at path. A missing file is empty state; it is
+// created on the first change.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+	if err := json.Unmarshal(b, &s.data); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Path returns the file the store is kept in.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Snooze records sn, replacing an earlier snooze of the same PR. Snoozes
+// that have expired by now are dropped.
+func (s *Store) Snooze(sn Snooze, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Snoozes = slices.DeleteFunc(s.data.Snoozes, func(x Snooze) bool {
+		return (x.Repo == sn.Repo && x.Number == sn.Number) || !x.Until.After(now)
+	})
+	s.data.Snoozes = append(s.data.Snoozes, sn)
+	return s.save()
+}
+
+// Unsnooze removes the snooze of a PR and reports whether it had one.
+func (s *Store) Unsnooze(repo string, number int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.data.Snoozes)
+	s.data.Snoozes = slices.DeleteFunc(s.data.Snoozes, func(x Snooze) bool {
+		return x.Repo == repo && x.Number == number
+	})
+	if len(s.data.Snoozes) == n {
+		return false, nil
+	}
+	return true, s.save()
+}
+
+// Snoozes returns the snoozes still in effect at now, soonest to end first.
+// When repo is not empty, only that repository's are returned.
+func (s *Store) Snoozes(repo string, now time.Time) []Snooze {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Snooze
+	for _, x := range s.data.Snoozes {
+		if x.Until.After(now) && (repo == "" || x.Repo == repo) {
+			out = append(out, x)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b Snooze) int { return a.Until.Compare(b.Until) })
+	return out
+}
+
+// save writes the state file; s.mu must be held.
+func (s *Store) save() error {
+	b, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	return nil
+}
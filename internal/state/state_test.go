@@ -0,0 +1,46 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnoozes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() on a missing file: %v", err)
+	}
+	for _, sn := range []Snooze{
+		{Repo: "acme/api", Number: 7, Until: now.Add(14 * 24 * time.Hour), Reason: "next sprint"},
+		{Repo: "acme/api", Number: 8, Until: now.Add(24 * time.Hour)},
+		{Repo: "acme/web", Number: 1, Until: now.Add(48 * time.Hour)},
+		{Repo: "acme/api", Number: 7, Until: now.Add(7 * 24 * time.Hour)}, // replaces the first
+	} {
+		if err := s.Snooze(sn, now); err != nil {
+			t.Fatalf("Snooze(%s#%d): %v", sn.Repo, sn.Number, err)
+		}
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() after saving: %v", err)
+	}
+	got := reopened.Snoozes("acme/api", now)
+	if len(got) != 2 || got[0].Number != 8 || got[1].Number != 7 || !got[1].Until.Equal(now.Add(7*24*time.Hour)) {
+		t.Errorf("Snoozes(acme/api) = %+v, want #8 then #7 until a week from now", got)
+	}
+	if got := reopened.Snoozes("", now.Add(36*time.Hour)); len(got) != 2 {
+		t.Errorf("Snoozes() a day and a half later returned %d, want 2 (the one-day snooze expired)", len(got))
+	}
+
+	if ok, err := reopened.Unsnooze("acme/web", 1); !ok || err != nil {
+		t.Errorf("Unsnooze(acme/web#1) = %v, %v, want true, nil", ok, err)
+	}
+	if ok, err := reopened.Unsnooze("acme/web", 1); ok || err != nil {
+		t.Errorf("second Unsnooze(acme/web#1) = %v, %v, want false, nil", ok, err)
+	}
+}
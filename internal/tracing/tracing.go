@@ -0,0 +1,338 @@
+// Package tracing records spans of a run and exports them to an
+// OpenTelemetry collector with OTLP over HTTP, using the JSON encoding.
+//
+// A nil *Tracer and the nil *Span it returns are valid and do nothing, so
+// callers never need to check whether tracing is enabled.
+package tracing
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxBuffered is how many ended spans are kept before they are exported
+// without waiting for Flush.
+const maxBuffered = 2048
+
+// Span kinds, as numbered by OTLP.
+const (
+	kindInternal = 1
+	kindClient   = 3
+)
+
+// Attr is a span attribute.
+type Attr struct {
+	Key   string
+	Value any // string or int
+}
+
+// String returns a string attribute.
+func String(key, value string) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Tracer creates spans and exports them to an OTLP endpoint.
+type Tracer struct {
+	url     string
+	headers map[string]string
+	service string
+	client  *http.Client
+
+	mu     sync.Mutex
+	scopes []*Span // open scope spans, innermost last
+	ended  []*Span
+}
+
+// New returns a tracer exporting to tracesURL, the full URL of the
+// collector's traces endpoint (usually ending in /v1/traces). headers are
+// sent with every export, e.g. for authentication.
+func New(tracesURL string, headers map[string]string, service string) (*Tracer, error) {
+	u, err := url.Parse(tracesURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q", tracesURL)
+	}
+	return &Tracer{
+		url:     tracesURL,
+		headers: headers,
+		service: service,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// TracesURL returns the traces endpoint of the collector at base, following
+// the OTEL_EXPORTER_OTLP_ENDPOINT convention of appending /v1/traces.
+func TracesURL(base string) string {
+	return strings.TrimSuffix(base, "/") + "/v1/traces"
+}
+
+// ParseHeaders parses headers in the OTEL_EXPORTER_OTLP_HEADERS format:
+// comma-separated key=value pairs with URL-encoded values.
+func ParseHeaders(s string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("invalid header %q, want key=value", pair)
+		}
+		value, err := url.QueryUnescape(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("invalid header %q: %w", pair, err)
+		}
+		headers[strings.TrimSpace(k)] = value
+	}
+	return headers, nil
+}
+
+// StartScope begins a span that the spans started after it nest under until
+// it ends. A scope started while no other scope is open begins a new trace.
+func (t *Tracer) StartScope(name string, attrs ...Attr) *Span {
+	if t == nil {
+		return nil
+	}
+	s := t.start(name, kindInternal, attrs)
+	s.scope = true
+	t.mu.Lock()
+	t.scopes = append(t.scopes, s)
+	t.mu.Unlock()
+	return s
+}
+
+// Start begins a span for work done by the tool itself under the innermost
+// open scope.
+func (t *Tracer) Start(name string, attrs ...Attr) *Span {
+	if t == nil {
+		return nil
+	}
+	return t.start(name, kindInternal, attrs)
+}
+
+// StartCall begins a span for a call to a remote API under the innermost
+// open scope.
+func (t *Tracer) StartCall(name string, attrs ...Attr) *Span {
+	if t == nil {
+		return nil
+	}
+	return t.start(name, kindClient, attrs)
+}
+
+func (t *Tracer) start(name string, kind int, attrs []Attr) *Span {
+	s := &Span{
+		t:     t,
+		name:  name,
+		kind:  kind,
+		start: time.Now(),
+		attrs: attrs,
+		id:    newID(8),
+	}
+	t.mu.Lock()
+	if n := len(t.scopes); n > 0 {
+		parent := t.scopes[n-1]
+		s.traceID, s.parentID = parent.traceID, parent.id
+	}
+	t.mu.Unlock()
+	if s.traceID == "" {
+		s.traceID = newID(16)
+	}
+	return s
+}
+
+// Flush exports every ended span.
+func (t *Tracer) Flush() error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	spans := t.ended
+	t.ended = nil
+	t.mu.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+	return t.export(spans)
+}
+
+func (t *Tracer) export(spans []*Span) error {
+	body, err := json.Marshal(t.request(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export %d spans: %w", len(spans), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to export %d spans: HTTP %d: %s", len(spans), resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// Span is one timed operation.
+type Span struct {
+	t        *Tracer
+	traceID  string
+	id       string
+	parentID string
+	name     string
+	kind     int
+	start    time.Time
+	end      time.Time
+	attrs    []Attr
+	errMsg   string
+	scope    bool
+}
+
+// SetAttr adds attributes to the span.
+func (s *Span) SetAttr(attrs ...Attr) {
+	if s == nil {
+		return
+	}
+	s.t.mu.Lock()
+	s.attrs = append(s.attrs, attrs...)
+	s.t.mu.Unlock()
+}
+
+// End ends the span, marking it failed when err is not nil. Ending a scope
+// closes it; spans it was the parent of are unaffected.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	t := s.t
+	t.mu.Lock()
+	s.end = time.Now()
+	if err != nil {
+		s.errMsg = err.Error()
+	}
+	if s.scope {
+		if i := slices.Index(t.scopes, s); i >= 0 {
+			t.scopes = slices.Delete(t.scopes, i, i+1)
+		}
+	}
+	t.ended = append(t.ended, s)
+	var full []*Span
+	if len(t.ended) >= maxBuffered {
+		full, t.ended = t.ended, nil
+	}
+	t.mu.Unlock()
+	if full != nil {
+		t.export(full) // best effort: a failed export drops these spans
+	}
+}
+
+func newID(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// OTLP JSON encoding of an ExportTraceServiceRequest.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttr `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string     `json:"traceId"`
+		SpanID            string     `json:"spanId"`
+		ParentSpanID      string     `json:"parentSpanId,omitempty"`
+		Name              string     `json:"name"`
+		Kind              int        `json:"kind"`
+		StartTimeUnixNano string     `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+		Attributes        []otlpAttr `json:"attributes,omitempty"`
+		Status            otlpStatus `json:"status"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code,omitempty"` // 2 is error
+		Message string `json:"message,omitempty"`
+	}
+	otlpAttr struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string `json:"stringValue,omitempty"`
+		IntValue    string  `json:"intValue,omitempty"`
+	}
+)
+
+func (t *Tracer) request(spans []*Span) otlpRequest {
+	out := make([]otlpSpan, len(spans))
+	for i, s := range spans {
+		out[i] = otlpSpan{
+			TraceID:           s.traceID,
+			SpanID:            s.id,
+			ParentSpanID:      s.parentID,
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        otlpAttrs(s.attrs),
+		}
+		if s.errMsg != "" {
+			out[i].Status = otlpStatus{Code: 2, Message: s.errMsg}
+		}
+	}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: otlpAttrs([]Attr{String("service.name", t.service)})},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: t.service}, Spans: out}},
+	}}}
+}
+
+func otlpAttrs(attrs []Attr) []otlpAttr {
+	out := make([]otlpAttr, 0, len(attrs))
+	for _, a := range attrs {
+		var v otlpValue
+		switch x := a.Value.(type) {
+		case int:
+			v.IntValue = strconv.Itoa(x)
+		default:
+			s := fmt.Sprint(x)
+			v.StringValue = &s
+		}
+		out = append(out, otlpAttr{Key: a.Key, Value: v})
+	}
+	return out
+}
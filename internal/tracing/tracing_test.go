@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestTracerExport(t *testing.T) {
+	var got otlpRequest
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	tr, err := New(TracesURL(srv.URL), map[string]string{"Authorization": "Bearer x"}, "bouncer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := tr.StartScope("approve")
+	repo := tr.StartScope("repository", String("repository", "acme/api"))
+	call := tr.StartCall("gh pr list", String("repository", "acme/api"))
+	call.End(errors.New("boom"))
+	repo.End(nil)
+	after := tr.Start("report")
+	after.End(nil)
+	run.End(nil)
+	if err := tr.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if auth != "Bearer x" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	spans := got.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 4 {
+		t.Fatalf("exported %d spans, want 4", len(spans))
+	}
+	byName := map[string]otlpSpan{}
+	for _, s := range spans {
+		byName[s.Name] = s
+		if s.TraceID != spans[0].TraceID {
+			t.Errorf("span %s in trace %s, want %s", s.Name, s.TraceID, spans[0].TraceID)
+		}
+	}
+	if p := byName["gh pr list"].ParentSpanID; p != byName["repository"].SpanID {
+		t.Errorf("call parent = %s, want the repository span", p)
+	}
+	if p := byName["report"].ParentSpanID; p != byName["approve"].SpanID {
+		t.Errorf("report parent = %s, want the run span", p)
+	}
+	if p := byName["approve"].ParentSpanID; p != "" {
+		t.Errorf("run parent = %s, want none", p)
+	}
+	if s := byName["gh pr list"]; s.Kind != kindClient || s.Status.Code != 2 || s.Status.Message != "boom" {
+		t.Errorf("call span = %+v, want a failed client span", s)
+	}
+	if len(byName["approve"].TraceID) != 32 || len(byName["approve"].SpanID) != 16 {
+		t.Errorf("IDs = %s/%s, want 16 and 8 hex bytes", byName["approve"].TraceID, byName["approve"].SpanID)
+	}
+
+	if err := tr.Flush(); err != nil {
+		t.Errorf("empty Flush() error = %v", err)
+	}
+}
+
+func TestNilTracer(t *testing.T) {
+	var tr *Tracer
+	s := tr.StartScope("run")
+	s.SetAttr(Int("prs", 1))
+	s.End(nil)
+	if err := tr.Flush(); err != nil {
+		t.Errorf("Flush() error = %v", err)
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	got, err := ParseHeaders("api-key=abc%3D, x-team = infra,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"api-key": "abc=", "x-team": "infra"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseHeaders() = %v, want %v", got, want)
+	}
+	if _, err := ParseHeaders("novalue"); err == nil {
+		t.Error("ParseHeaders(novalue) succeeded, want error")
+	}
+}
+
+func TestNewInvalidURL(t *testing.T) {
+	if _, err := New("localhost:4318", nil, "bouncer"); err == nil {
+		t.Error("New() accepted a URL without scheme")
+	}
+}
@@ -0,0 +1,122 @@
+// Package webhook decodes the GitHub webhook deliveries that should trigger
+// the approve policy for a pull request.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Headers GitHub sends with each delivery.
+const (
+	EventHeader     = "X-GitHub-Event"
+	DeliveryHeader  = "X-GitHub-Delivery"
+	SignatureHeader = "X-Hub-Signature-256"
+)
+
+// ErrBadSignature is returned by Verify when a delivery was not signed with
+// the shared secret.
+var ErrBadSignature = errors.New("invalid webhook signature")
+
+// Verify checks the X-Hub-Signature-256 header of a delivery ("sha256=<hex>")
+// against the HMAC-SHA256 of its body under secret.
+func Verify(secret, body []byte, signature string) error {
+	sum, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return ErrBadSignature
+	}
+	got, err := hex.DecodeString(sum)
+	if err != nil {
+		return ErrBadSignature
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// Target is a pull request a delivery asks to act on.
+type Target struct {
+	Repo   string // owner/repo
+	Number int
+	// Author and AuthorID identify the PR author when the event carries
+	// them; check_suite events do not.
+	Author   string
+	AuthorID string
+}
+
+// pullRequestActions are the pull_request actions after which a PR may have
+// become approvable.
+var pullRequestActions = map[string]bool{
+	"opened":           true,
+	"reopened":         true,
+	"synchronize":      true,
+	"ready_for_review": true,
+}
+
+type repository struct {
+	FullName string `json:"full_name"`
+}
+
+// Targets returns the pull requests a delivery of the given event type is
+// about. Events and actions that cannot make a PR approvable, such as a
+// closed PR or a failed check suite, return no targets.
+func Targets(event string, body []byte) ([]Target, error) {
+	switch event {
+	case "pull_request":
+		var p struct {
+			Action      string     `json:"action"`
+			Repository  repository `json:"repository"`
+			PullRequest struct {
+				Number int `json:"number"`
+				User   struct {
+					Login  string `json:"login"`
+					NodeID string `json:"node_id"`
+				} `json:"user"`
+			} `json:"pull_request"`
+		}
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, fmt.Errorf("failed to parse pull_request event: %w", err)
+		}
+		if !pullRequestActions[p.Action] {
+			return nil, nil
+		}
+		return []Target{{
+			Repo:     p.Repository.FullName,
+			Number:   p.PullRequest.Number,
+			Author:   p.PullRequest.User.Login,
+			AuthorID: p.PullRequest.User.NodeID,
+		}}, nil
+
+	case "check_suite":
+		var p struct {
+			Action     string     `json:"action"`
+			Repository repository `json:"repository"`
+			CheckSuite struct {
+				Conclusion   string `json:"conclusion"`
+				PullRequests []struct {
+					Number int `json:"number"`
+				} `json:"pull_requests"`
+			} `json:"check_suite"`
+		}
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, fmt.Errorf("failed to parse check_suite event: %w", err)
+		}
+		if p.Action != "completed" || p.CheckSuite.Conclusion != "success" {
+			return nil, nil
+		}
+		var targets []Target
+		for _, pr := range p.CheckSuite.PullRequests {
+			targets = append(targets, Target{Repo: p.Repository.FullName, Number: pr.Number})
+		}
+		return targets, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerify(t *testing.T) {
+	body := `{"action":"opened"}`
+	tests := []struct {
+		name      string
+		signature string
+		wantErr   bool
+	}{
+		{"valid", sign("s3cret", body), false},
+		{"wrong secret", sign("other", body), true},
+		{"sha1 header", "sha1=" + sign("s3cret", body)[7:], true},
+		{"not hex", "sha256=zz", true},
+		{"missing", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify([]byte("s3cret"), []byte(body), tt.signature); (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTargets(t *testing.T) {
+	tests := []struct {
+		name  string
+		event string
+		body  string
+		want  []Target
+	}{
+		{
+			name:  "opened PR",
+			event: "pull_request",
+			body:  `{"action":"opened","repository":{"full_name":"acme/api"},"pull_request":{"number":7,"user":{"login":"dependabot[bot]","node_id":"MDM6Qm90NDk2OTkzMzM="}}}`,
+			want:  []Target{{Repo: "acme/api", Number: 7, Author: "dependabot[bot]", AuthorID: "MDM6Qm90NDk2OTkzMzM="}},
+		},
+		{
+			name:  "closed PR",
+			event: "pull_request",
+			body:  `{"action":"closed","repository":{"full_name":"acme/api"},"pull_request":{"number":7}}`,
+		},
+		{
+			name:  "successful check suite",
+			event: "check_suite",
+			body:  `{"action":"completed","repository":{"full_name":"acme/api"},"check_suite":{"conclusion":"success","pull_requests":[{"number":7},{"number":9}]}}`,
+			want:  []Target{{Repo: "acme/api", Number: 7}, {Repo: "acme/api", Number: 9}},
+		},
+		{
+			name:  "failed check suite",
+			event: "check_suite",
+			body:  `{"action":"completed","repository":{"full_name":"acme/api"},"check_suite":{"conclusion":"failure","pull_requests":[{"number":7}]}}`,
+		},
+		{
+			name:  "other event",
+			event: "push",
+			body:  `{}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Targets(tt.event, []byte(tt.body))
+			if err != nil {
+				t.Fatalf("Targets() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Targets() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetsInvalidJSON(t *testing.T) {
+	if _, err := Targets("pull_request", []byte("{")); err == nil {
+		t.Error("Targets() succeeded on invalid JSON, want error")
+	}
+}
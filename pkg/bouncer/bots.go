@@ -0,0 +1,44 @@
+package bouncer
+
+import "strings"
+
+// DefaultBots are the PR authors processed when no bots are configured.
+var DefaultBots = []string{"dependabot[bot]"}
+
+// bots holds the normalized logins and node IDs of the configured bots.
+var bots = normalizeBots(DefaultBots)
+
+// SetBots configures which PR authors are processed on GitHub. Entries are
+// logins ("renovate[bot]", "app/renovate", or a plain user name for bots
+// running as a user account) or GraphQL node IDs. An empty list restores
+// DefaultBots.
+func SetBots(entries []string) {
+	if len(entries) == 0 {
+		entries = DefaultBots
+	}
+	bots = normalizeBots(entries)
+}
+
+func normalizeBots(entries []string) map[string]bool {
+	m := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		m[normalizeLogin(e)] = true
+	}
+	return m
+}
+
+// normalizeLogin maps the "app/<name>" form gh uses for GitHub App authors to
+// the "<name>[bot]" form shown in the web UI, lower-cased.
+func normalizeLogin(login string) string {
+	login = strings.ToLower(strings.TrimSpace(login))
+	if name, ok := strings.CutPrefix(login, "app/"); ok {
+		return name + "[bot]"
+	}
+	return login
+}
+
+// IsBot reports whether a PR author, given by login and node ID, is one of the
+// configured bots.
+func IsBot(login, id string) bool {
+	return bots[normalizeLogin(login)] || (id != "" && bots[strings.ToLower(id)])
+}
@@ -0,0 +1,33 @@
+package bouncer
+
+import "testing"
+
+func TestIsBot(t *testing.T) {
+	defer SetBots(nil)
+
+	if !IsBot("app/dependabot", "") {
+		t.Error("default bots should include app/dependabot")
+	}
+	if IsBot("app/renovate", "") {
+		t.Error("default bots should not include renovate")
+	}
+
+	SetBots([]string{"Renovate[bot]", "app/dependabot", "mybot", "BOT_kgDOABCDEF"})
+	tests := []struct {
+		login string
+		id    string
+		want  bool
+	}{
+		{login: "app/renovate", want: true},
+		{login: "app/dependabot", want: true},
+		{login: "mybot", want: true},
+		{login: "app/mybot", want: false},
+		{login: "someone", id: "BOT_kgDOABCDEF", want: true},
+		{login: "alice", id: "U_123", want: false},
+	}
+	for _, tt := range tests {
+		if got := IsBot(tt.login, tt.id); got != tt.want {
+			t.Errorf("IsBot(%q, %q) = %v, want %v", tt.login, tt.id, got, tt.want)
+		}
+	}
+}
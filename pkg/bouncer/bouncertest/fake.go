@@ -0,0 +1,225 @@
+// Package bouncertest provides an in-memory bouncer.Client for tests of code
+// built on package bouncer.
+package bouncertest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+// Call is one Client method call recorded by a Fake.
+type Call struct {
+	Method string // the Client method, e.g. "Approve"
+	Repo   string // owner/repo
+	Number int    // the PR number; 0 for List and BaseCIStatus
+	Arg    string // the comment body, ignore update type, or merge method
+}
+
+// Fake is a bouncer.Client that keeps the open PRs of each repository in
+// memory and records every call. List applies the query's policy with
+// bouncer.FilterPRs; Close and Merge take a PR out of the open list. The zero
+// value is not usable; use NewFake.
+type Fake struct {
+	mu     sync.Mutex
+	open   map[string][]bouncer.PRInfo
+	closed map[string][]bouncer.ClosedPR
+	base   map[string]string
+	errs   map[string]error
+	calls  []Call
+}
+
+var _ bouncer.Client = (*Fake)(nil)
+
+// NewFake returns a Fake with no PRs.
+func NewFake() *Fake {
+	return &Fake{
+		open:   make(map[string][]bouncer.PRInfo),
+		closed: make(map[string][]bouncer.ClosedPR),
+		base:   make(map[string]string),
+		errs:   make(map[string]error),
+	}
+}
+
+// AddPR adds an open PR to owner/repo. A PR without a package name has its
+// dependency details parsed from its title and the head branch.
+func (f *Fake) AddPR(owner, repo, branch string, pr bouncer.PRInfo) {
+	if pr.PackageName == "" {
+		pr = bouncer.ParsePR(pr, branch)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := owner + "/" + repo
+	f.open[key] = append(f.open[key], pr)
+}
+
+// SetBaseCIStatus sets what BaseCIStatus returns for owner/repo; it returns
+// "success" by default.
+func (f *Fake) SetBaseCIStatus(owner, repo, status string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.base[owner+"/"+repo] = status
+}
+
+// FailOn makes every later call of method (e.g. "Approve") fail with err, or
+// succeed again when err is nil. Failed calls are recorded too.
+func (f *Fake) FailOn(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.errs, method)
+		return
+	}
+	f.errs[method] = err
+}
+
+// Calls returns the calls made so far, in order.
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.calls)
+}
+
+// Open returns the open PRs of owner/repo.
+func (f *Fake) Open(owner, repo string) []bouncer.PRInfo {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.open[owner+"/"+repo])
+}
+
+// record records a call and returns the error set with FailOn for its method.
+// f.mu must be held.
+func (f *Fake) record(method, owner, repo string, number int, arg string) error {
+	f.calls = append(f.calls, Call{Method: method, Repo: owner + "/" + repo, Number: number, Arg: arg})
+	return f.errs[method]
+}
+
+// find returns the index of open PR number in owner/repo. f.mu must be held.
+func (f *Fake) find(owner, repo string, number int) (int, error) {
+	i := slices.IndexFunc(f.open[owner+"/"+repo], func(pr bouncer.PRInfo) bool { return pr.Number == number })
+	if i < 0 {
+		return -1, fmt.Errorf("no open PR #%d in %s/%s", number, owner, repo)
+	}
+	return i, nil
+}
+
+// act records a call on an open PR and returns its error.
+func (f *Fake) act(method, owner, repo string, number int, arg string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record(method, owner, repo, number, arg); err != nil {
+		return err
+	}
+	_, err := f.find(owner, repo, number)
+	return err
+}
+
+// remove records a call that takes an open PR out of the open list, leaving
+// it in state.
+func (f *Fake) remove(method, owner, repo string, number int, arg, state string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record(method, owner, repo, number, arg); err != nil {
+		return err
+	}
+	i, err := f.find(owner, repo, number)
+	if err != nil {
+		return err
+	}
+	key := owner + "/" + repo
+	f.open[key] = slices.Delete(f.open[key], i, i+1)
+	f.closed[key] = append(f.closed[key], bouncer.ClosedPR{Number: number, State: state})
+	return nil
+}
+
+func (f *Fake) List(_ context.Context, q bouncer.DependencyUpdateQuery, skipFailing bool) ([]bouncer.PRInfo, error) {
+	f.mu.Lock()
+	if err := f.record("List", q.Owner, q.Repo, 0, ""); err != nil {
+		f.mu.Unlock()
+		return nil, err
+	}
+	var candidates []bouncer.PRInfo
+	for _, pr := range f.open[q.Owner+"/"+q.Repo] {
+		if len(q.Numbers) == 0 || slices.Contains(q.Numbers, pr.Number) {
+			candidates = append(candidates, pr)
+		}
+	}
+	f.mu.Unlock()
+	return bouncer.FilterPRs(candidates, q, skipFailing), nil
+}
+
+func (f *Fake) Approve(_ context.Context, owner, repo string, number int) error {
+	return f.act("Approve", owner, repo, number, "")
+}
+
+func (f *Fake) Comment(_ context.Context, owner, repo string, number int, body string) error {
+	return f.act("Comment", owner, repo, number, body)
+}
+
+func (f *Fake) Rebase(_ context.Context, owner, repo string, number int) error {
+	return f.act("Rebase", owner, repo, number, "")
+}
+
+func (f *Fake) Recreate(_ context.Context, owner, repo string, number int) error {
+	return f.act("Recreate", owner, repo, number, "")
+}
+
+func (f *Fake) Close(_ context.Context, owner, repo string, number int) error {
+	return f.remove("Close", owner, repo, number, "", "CLOSED")
+}
+
+func (f *Fake) Ignore(_ context.Context, owner, repo string, number int, updateType string) error {
+	return f.act("Ignore", owner, repo, number, updateType)
+}
+
+func (f *Fake) EnableAutoMerge(_ context.Context, owner, repo string, number int, method string) error {
+	return f.act("EnableAutoMerge", owner, repo, number, method)
+}
+
+func (f *Fake) Merge(_ context.Context, owner, repo string, number int, method string) error {
+	return f.remove("Merge", owner, repo, number, method, "MERGED")
+}
+
+func (f *Fake) MarkReady(_ context.Context, owner, repo string, number int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("MarkReady", owner, repo, number, ""); err != nil {
+		return err
+	}
+	i, err := f.find(owner, repo, number)
+	if err != nil {
+		return err
+	}
+	f.open[owner+"/"+repo][i].Draft = false
+	return nil
+}
+
+func (f *Fake) FindClosed(_ context.Context, owner, repo string, numbers []int) ([]bouncer.ClosedPR, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("FindClosed", owner, repo, 0, ""); err != nil {
+		return nil, err
+	}
+	var closed []bouncer.ClosedPR
+	for _, c := range f.closed[owner+"/"+repo] {
+		if slices.Contains(numbers, c.Number) {
+			closed = append(closed, c)
+		}
+	}
+	return closed, nil
+}
+
+func (f *Fake) BaseCIStatus(_ context.Context, owner, repo string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("BaseCIStatus", owner, repo, 0, ""); err != nil {
+		return "", err
+	}
+	if status, ok := f.base[owner+"/"+repo]; ok {
+		return status, nil
+	}
+	return "success", nil
+}
@@ -0,0 +1,52 @@
+package bouncertest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/promiseofcake/dependabot-bouncer/pkg/bouncer"
+)
+
+func TestFake(t *testing.T) {
+	f := NewFake()
+	f.AddPR("acme", "api", "dependabot/go_modules/github.com/spf13/cobra-1.8.0", bouncer.PRInfo{Number: 1, Title: "Bump github.com/spf13/cobra from 1.7.0 to 1.8.0", CIStatus: "success"})
+	f.AddPR("acme", "api", "", bouncer.PRInfo{Number: 2, Title: "Bump left-pad from 1.1.0 to 1.3.0", CIStatus: "success"})
+
+	prs, err := f.List(context.Background(), bouncer.DependencyUpdateQuery{Owner: "acme", Repo: "api", DeniedPackages: []string{"left-pad"}}, true)
+	if err != nil || len(prs) != 1 || prs[0].PackageName != "github.com/spf13/cobra" || prs[0].Ecosystem != "gomod" || prs[0].UpdateType != bouncer.UpdateMinor {
+		t.Fatalf("List() = %+v, %v, want the cobra minor update", prs, err)
+	}
+
+	if err := f.Merge(context.Background(), "acme", "api", 1, bouncer.MergeSquash); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if err := f.Approve(context.Background(), "acme", "api", 1); err == nil {
+		t.Error("Approve() of a merged PR succeeded")
+	}
+	closed, err := f.FindClosed(context.Background(), "acme", "api", []int{1, 2})
+	if err != nil || !reflect.DeepEqual(closed, []bouncer.ClosedPR{{Number: 1, State: "MERGED"}}) {
+		t.Errorf("FindClosed() = %+v, %v, want #1 merged", closed, err)
+	}
+
+	boom := errors.New("boom")
+	f.FailOn("Close", boom)
+	if err := f.Close(context.Background(), "acme", "api", 2); !errors.Is(err, boom) {
+		t.Errorf("Close() error = %v, want %v", err, boom)
+	}
+	if open := f.Open("acme", "api"); len(open) != 1 || open[0].Number != 2 {
+		t.Errorf("Open() = %+v, want #2", open)
+	}
+
+	want := []Call{
+		{Method: "List", Repo: "acme/api"},
+		{Method: "Merge", Repo: "acme/api", Number: 1, Arg: bouncer.MergeSquash},
+		{Method: "Approve", Repo: "acme/api", Number: 1},
+		{Method: "FindClosed", Repo: "acme/api"},
+		{Method: "Close", Repo: "acme/api", Number: 2},
+	}
+	if got := f.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Calls() = %+v, want %+v", got, want)
+	}
+}
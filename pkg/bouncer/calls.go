@@ -0,0 +1,133 @@
+package bouncer
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// API call kinds, matching GitHub's rate-limit buckets.
+const (
+	CallCore    = "core"
+	CallGraphQL = "graphql"
+	CallSearch  = "search"
+)
+
+// CallCounts is the number of API calls made, by rate-limit bucket, and how
+// many of them failed.
+type CallCounts struct {
+	Core    int `json:"core"`
+	GraphQL int `json:"graphql"`
+	Search  int `json:"search"`
+	Errors  int `json:"errors"`
+}
+
+// Total returns the number of calls across all buckets.
+func (c CallCounts) Total() int {
+	return c.Core + c.GraphQL + c.Search
+}
+
+var (
+	callsMu sync.Mutex
+	calls   = make(map[string]*CallCounts) // lower-cased "owner/repo" ("" for calls not tied to a repository)
+)
+
+// repoCounts returns the counts of a repository; callsMu must be held.
+func repoCounts(repo string) *CallCounts {
+	key := strings.ToLower(repo)
+	c, ok := calls[key]
+	if !ok {
+		c = &CallCounts{}
+		calls[key] = c
+	}
+	return c
+}
+
+// recordCall counts one API call of the given kind against a repository.
+func recordCall(repo, kind string) {
+	callsMu.Lock()
+	defer callsMu.Unlock()
+
+	c := repoCounts(repo)
+	switch kind {
+	case CallGraphQL:
+		c.GraphQL++
+	case CallSearch:
+		c.Search++
+	default:
+		c.Core++
+	}
+}
+
+// recordCallError counts a failed API call against a repository. Calls
+// refused before they were sent, e.g. in read-only mode, are not counted.
+func recordCallError(repo string) {
+	callsMu.Lock()
+	defer callsMu.Unlock()
+	repoCounts(repo).Errors++
+}
+
+// AllCalls returns the API calls made so far, keyed by lower-cased
+// "owner/repo" ("" for calls not tied to a repository).
+func AllCalls() map[string]CallCounts {
+	callsMu.Lock()
+	defer callsMu.Unlock()
+
+	all := make(map[string]CallCounts, len(calls))
+	for repo, c := range calls {
+		all[repo] = *c
+	}
+	return all
+}
+
+// RepoCalls returns the API calls made so far for "owner/repo". Paginated
+// requests count once.
+func RepoCalls(repo string) CallCounts {
+	callsMu.Lock()
+	defer callsMu.Unlock()
+
+	if c, ok := calls[strings.ToLower(repo)]; ok {
+		return *c
+	}
+	return CallCounts{}
+}
+
+// callKind classifies a gh or glab invocation by the GitHub rate-limit bucket
+// it draws from. The pr and repo subcommands of gh use GraphQL; glab calls
+// draw from no GitHub bucket and count as core.
+func callKind(args []string) string {
+	if len(args) < 2 || args[0] != "gh" {
+		return CallCore
+	}
+	if args[1] != "api" {
+		return CallGraphQL
+	}
+	for _, a := range args[2:] {
+		switch {
+		case a == "graphql":
+			return CallGraphQL
+		case strings.HasPrefix(a, "search/"):
+			return CallSearch
+		}
+	}
+	return CallCore
+}
+
+// repoFromPath returns the "owner/repo" of a REST API path such as
+// "repos/owner/repo/pulls" (GitHub, Gitea) or "projects/owner%2Frepo/..."
+// (GitLab), or "" if the path is not repository-specific.
+func repoFromPath(path string) string {
+	if rest, ok := strings.CutPrefix(path, "repos/"); ok {
+		parts := strings.SplitN(rest, "/", 3)
+		if len(parts) >= 2 {
+			return parts[0] + "/" + strings.SplitN(parts[1], "?", 2)[0]
+		}
+	}
+	if rest, ok := strings.CutPrefix(path, "projects/"); ok {
+		project := strings.SplitN(strings.SplitN(rest, "/", 2)[0], "?", 2)[0]
+		if p, err := url.PathUnescape(project); err == nil && strings.Count(p, "/") == 1 {
+			return p
+		}
+	}
+	return ""
+}
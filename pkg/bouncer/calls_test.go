@@ -0,0 +1,44 @@
+package bouncer
+
+import "testing"
+
+func TestCallKind(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"gh", "pr", "list", "--repo", "acme/api"}, CallGraphQL},
+		{[]string{"gh", "api", "graphql", "-f", "query=..."}, CallGraphQL},
+		{[]string{"gh", "api", "--paginate", "repos/acme/api/dependabot/alerts"}, CallCore},
+		{[]string{"gh", "api", "search/issues?q=repo:acme/api"}, CallSearch},
+		{[]string{"glab", "api", "projects/acme%2Fapi/merge_requests"}, CallCore},
+	}
+
+	for _, tt := range tests {
+		if got := callKind(tt.args); got != tt.want {
+			t.Errorf("callKind(%v) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestRepoCalls(t *testing.T) {
+	recordCall("Acme/Counted", CallGraphQL)
+	recordCall("acme/counted", CallGraphQL)
+	recordCall("acme/counted", CallCore)
+	recordCall("acme/counted", CallSearch)
+	recordCallError("acme/counted")
+
+	got := RepoCalls("acme/counted")
+	if want := (CallCounts{Core: 1, GraphQL: 2, Search: 1, Errors: 1}); got != want {
+		t.Errorf("RepoCalls() = %+v, want %+v", got, want)
+	}
+	if got.Total() != 4 {
+		t.Errorf("Total() = %d, want 4", got.Total())
+	}
+	if got := RepoCalls("acme/other"); got.Total() != 0 {
+		t.Errorf("RepoCalls(unused) = %+v, want zero", got)
+	}
+	if got := AllCalls()["acme/counted"]; got.Errors != 1 {
+		t.Errorf("AllCalls()[acme/counted] = %+v, want 1 error", got)
+	}
+}
@@ -0,0 +1,73 @@
+package bouncer
+
+import "strings"
+
+// Check is the outcome of a single CI check or commit status on a PR's head
+// commit.
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // success, skipped, failure, pending
+}
+
+// combineChecks rolls checks up into an overall CI status and the names of
+// the failing checks: "failure" if any check failed, "pending" if there are
+// no checks or any is still running, "success" otherwise. Skipped checks
+// count as passing.
+func combineChecks(checks []Check) (string, []string) {
+	if len(checks) == 0 {
+		return "pending", nil
+	}
+
+	var failures []string
+	pending := false
+	for _, c := range checks {
+		switch c.Status {
+		case "success", "skipped":
+		case "pending":
+			pending = true
+		default:
+			failures = append(failures, c.Name)
+		}
+	}
+
+	if len(failures) > 0 {
+		return "failure", failures
+	}
+	if pending {
+		return "pending", nil
+	}
+	return "success", nil
+}
+
+// requiredChecksStatus is combineChecks restricted to the named checks, so
+// optional checks cannot block approval. A required check that has not
+// reported yet counts as pending. Names match case-insensitively; when
+// several checks share a required name, all of them must pass.
+func requiredChecksStatus(checks []Check, required []string) (string, []string) {
+	var selected []Check
+	for _, name := range required {
+		found := false
+		for _, c := range checks {
+			if strings.EqualFold(c.Name, name) {
+				selected = append(selected, c)
+				found = true
+			}
+		}
+		if !found {
+			selected = append(selected, Check{Name: name, Status: "pending"})
+		}
+	}
+	return combineChecks(selected)
+}
+
+// successfulChecks counts the checks that ran and succeeded, leaving out
+// skipped ones.
+func successfulChecks(checks []Check) int {
+	n := 0
+	for _, c := range checks {
+		if c.Status == "success" {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,69 @@
+package bouncer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRequiredChecksStatus(t *testing.T) {
+	checks := []Check{
+		{Name: "build", Status: "success"},
+		{Name: "unit-tests", Status: "success"},
+		{Name: "codecov/patch", Status: "failure"},
+		{Name: "lint", Status: "pending"},
+	}
+
+	tests := []struct {
+		name         string
+		required     []string
+		wantStatus   string
+		wantFailures []string
+	}{
+		{"optional failure ignored", []string{"build", "Unit-Tests"}, "success", nil},
+		{"required still running", []string{"build", "lint"}, "pending", nil},
+		{"required not reported", []string{"build", "e2e"}, "pending", nil},
+		{"required failure", []string{"build", "codecov/patch"}, "failure", []string{"codecov/patch"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, failures := requiredChecksStatus(checks, tt.required)
+			if status != tt.wantStatus || !reflect.DeepEqual(failures, tt.wantFailures) {
+				t.Errorf("requiredChecksStatus() = %q, %v, want %q, %v", status, failures, tt.wantStatus, tt.wantFailures)
+			}
+		})
+	}
+}
+
+func TestFilterPRsRequiredChecks(t *testing.T) {
+	candidates := []PRInfo{{
+		Number:     1,
+		CIStatus:   "failure",
+		CIFailures: []string{"codecov/patch"},
+		Checks:     []Check{{Name: "build", Status: "success"}, {Name: "codecov/patch", Status: "failure"}},
+	}}
+
+	got := filterPRs(candidates, DependencyUpdateQuery{RequiredChecks: []string{"build"}}, true)
+	if len(got) != 1 || got[0].CIStatus != "success" || got[0].CIFailures != nil {
+		t.Errorf("filterPRs(RequiredChecks) = %+v, want PR #1 passing", got)
+	}
+}
+
+func TestFilterPRsMinSuccessfulChecks(t *testing.T) {
+	candidates := []PRInfo{
+		{Number: 1, CIStatus: "success", Checks: []Check{{Name: "build", Status: "success"}, {Name: "test", Status: "success"}}},
+		{Number: 2, CIStatus: "success", Checks: []Check{{Name: "build", Status: "success"}, {Name: "test", Status: "skipped"}}},
+		{Number: 3, CIStatus: "success"},
+	}
+
+	got := filterPRs(candidates, DependencyUpdateQuery{MinSuccessfulChecks: 2, CIFilter: CIAny}, false)
+	want := []string{"success", "pending", "pending"}
+	if len(got) != len(want) {
+		t.Fatalf("filterPRs(MinSuccessfulChecks) returned %d PRs, want %d", len(got), len(want))
+	}
+	for i, pr := range got {
+		if pr.CIStatus != want[i] {
+			t.Errorf("PR #%d CIStatus = %q, want %q", pr.Number, pr.CIStatus, want[i])
+		}
+	}
+}
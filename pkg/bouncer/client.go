@@ -0,0 +1,172 @@
+package bouncer
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Provider names accepted in Options.
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+	ProviderGitea  = "gitea"
+)
+
+// Client lists the dependency update pull (or merge) requests of a
+// source-control host and acts on them. Methods return errors rather than
+// exiting or panicking; they are safe for concurrent use.
+type Client interface {
+	// List returns open dependency update PRs, filtered as described by
+	// ListDependabotPRs.
+	List(ctx context.Context, q DependencyUpdateQuery, skipFailing bool) ([]PRInfo, error)
+	Approve(ctx context.Context, owner, repo string, number int) error
+	// Comment leaves a non-approving review or note with the given body.
+	Comment(ctx context.Context, owner, repo string, number int, body string) error
+	Rebase(ctx context.Context, owner, repo string, number int) error
+	Recreate(ctx context.Context, owner, repo string, number int) error
+	Close(ctx context.Context, owner, repo string, number int) error
+	// Ignore tells the bot to stop proposing the PR's dependency, or only its
+	// updates of updateType (UpdateMajor, UpdateMinor, or UpdatePatch) when
+	// updateType is not empty. It does not close the PR.
+	Ignore(ctx context.Context, owner, repo string, number int, updateType string) error
+	// EnableAutoMerge sets a PR to merge with method (as for Merge) once its
+	// checks pass.
+	EnableAutoMerge(ctx context.Context, owner, repo string, number int, method string) error
+	// Merge merges a PR now with method (MergeSquash, MergeMerge, or
+	// MergeRebase), or the provider's default method when method is empty.
+	Merge(ctx context.Context, owner, repo string, number int, method string) error
+	// MarkReady takes a draft PR out of draft so it can be merged.
+	MarkReady(ctx context.Context, owner, repo string, number int) error
+	// FindClosed returns the PRs among numbers that are closed or merged.
+	FindClosed(ctx context.Context, owner, repo string, numbers []int) ([]ClosedPR, error)
+	// BaseCIStatus returns the CI status ("success", "failure", or "pending")
+	// of the latest commit on the repository's default branch.
+	BaseCIStatus(ctx context.Context, owner, repo string) (string, error)
+}
+
+// Options select and configure the Client returned by NewClient.
+type Options struct {
+	Provider string // ProviderGitHub (default), ProviderGitLab, or ProviderGitea
+	// BotAuthor overrides the username whose PRs are listed; when empty, the
+	// provider's default bot account is used. Ignored for GitHub.
+	BotAuthor string
+	BaseURL   string // Gitea server URL, e.g. https://git.example.com
+	// Host is the GitHub Enterprise Server host, e.g. github.example.com, or
+	// its URL; when empty, gh's default host is used. Ignored for GitLab and
+	// Gitea.
+	Host string
+	// Token is the Gitea API token, or the token gh calls authenticate with
+	// on GitHub; when empty there, the token pool (see SetTokenPool) or gh's
+	// own authentication is used.
+	Token string
+	// Retry sets how GitHub calls that fail transiently or hit a rate limit
+	// are retried; the zero value uses DefaultRetryPolicy.
+	Retry RetryPolicy
+	// ReplyWait is how long to watch for Dependabot's reply after posting
+	// one of its commands on GitHub: DefaultReplyWait when zero; when
+	// negative, commands are assumed accepted without waiting.
+	ReplyWait time.Duration
+	// ETagCache, when set, makes GitHub PR listings revalidate the cached
+	// ones with conditional requests.
+	ETagCache *ETagCache
+}
+
+// NewClient returns the client for the provider described by opts.
+func NewClient(opts Options) (Client, error) {
+	switch opts.Provider {
+	case "", ProviderGitHub:
+		return NewGitHub(opts), nil
+	case ProviderGitLab:
+		if opts.BotAuthor == "" {
+			opts.BotAuthor = defaultGitLabAuthor
+		}
+		return GitLab{Author: opts.BotAuthor}, nil
+	case ProviderGitea:
+		if opts.BaseURL == "" {
+			return nil, fmt.Errorf("the gitea provider requires a server URL")
+		}
+		if opts.BotAuthor == "" {
+			opts.BotAuthor = defaultGiteaAuthor
+		}
+		return NewGitea(opts.BaseURL, opts.Token, opts.BotAuthor), nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q (expected %q, %q, or %q)", opts.Provider, ProviderGitHub, ProviderGitLab, ProviderGitea)
+	}
+}
+
+// GitHub is the Client backed by the gh CLI. Its zero value uses gh's default
+// host and authentication and the default retries.
+type GitHub struct {
+	Host      string        // as for Options.Host
+	Token     string        // as for Options.Token
+	Retry     RetryPolicy   // as for Options.Retry
+	ReplyWait time.Duration // as for Options.ReplyWait
+	ETags     *ETagCache    // as for Options.ETagCache
+}
+
+// NewGitHub returns the GitHub client configured by the GitHub settings of
+// opts; its provider is ignored.
+func NewGitHub(opts Options) GitHub {
+	return GitHub{
+		Host:      opts.Host,
+		Token:     opts.Token,
+		Retry:     opts.Retry,
+		ReplyWait: opts.ReplyWait,
+		ETags:     opts.ETagCache,
+	}
+}
+
+func (g GitHub) List(ctx context.Context, q DependencyUpdateQuery, skipFailing bool) ([]PRInfo, error) {
+	return g.ListDependabotPRs(ctx, q, skipFailing)
+}
+
+func (g GitHub) Approve(ctx context.Context, owner, repo string, number int) error {
+	return g.ApprovePR(ctx, owner, repo, number)
+}
+
+func (g GitHub) Comment(ctx context.Context, owner, repo string, number int, body string) error {
+	return g.CommentPR(ctx, owner, repo, number, body)
+}
+
+func (g GitHub) Rebase(ctx context.Context, owner, repo string, number int) error {
+	return g.RebasePR(ctx, owner, repo, number)
+}
+
+func (g GitHub) Recreate(ctx context.Context, owner, repo string, number int) error {
+	return g.RecreatePR(ctx, owner, repo, number)
+}
+
+func (g GitHub) Close(ctx context.Context, owner, repo string, number int) error {
+	return g.ClosePR(ctx, owner, repo, number)
+}
+
+func (g GitHub) Ignore(ctx context.Context, owner, repo string, number int, updateType string) error {
+	return g.IgnorePR(ctx, owner, repo, number, updateType)
+}
+
+func (g GitHub) EnableAutoMerge(ctx context.Context, owner, repo string, number int, method string) error {
+	return g.AutoMergePR(ctx, owner, repo, number, method)
+}
+
+func (g GitHub) Merge(ctx context.Context, owner, repo string, number int, method string) error {
+	return g.MergePR(ctx, owner, repo, number, method)
+}
+
+func (g GitHub) MarkReady(ctx context.Context, owner, repo string, number int) error {
+	return g.MarkPRReady(ctx, owner, repo, number)
+}
+
+func (g GitHub) FindClosed(ctx context.Context, owner, repo string, numbers []int) ([]ClosedPR, error) {
+	return g.FindClosedPRs(ctx, owner, repo, numbers)
+}
+
+func (g GitHub) BaseCIStatus(ctx context.Context, owner, repo string) (string, error) {
+	return g.DefaultBranchCIStatus(ctx, owner, repo)
+}
+
+var (
+	_ Client = GitHub{}
+	_ Client = GitLab{}
+	_ Client = Gitea{}
+)
@@ -0,0 +1,158 @@
+package bouncer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// codeOwnersQuery reads the CODEOWNERS file from each location GitHub looks
+// in, on the default branch, in one call.
+const codeOwnersQuery = `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    github: object(expression: "HEAD:.github/CODEOWNERS") { ... on Blob { text } }
+    root: object(expression: "HEAD:CODEOWNERS") { ... on Blob { text } }
+    docs: object(expression: "HEAD:docs/CODEOWNERS") { ... on Blob { text } }
+  }
+}`
+
+// CodeOwners is a parsed CODEOWNERS file.
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// FetchCodeOwners returns the CODEOWNERS file of a GitHub repository's
+// default branch, taken from .github/, the root, or docs/ like GitHub does.
+// A repository without one has no owners.
+func (g GitHub) FetchCodeOwners(ctx context.Context, owner, repo string) (*CodeOwners, error) {
+	out, err := g.output(ctx, "read CODEOWNERS", "gh", "api", "graphql",
+		"-f", "query="+codeOwnersQuery,
+		"-f", "owner="+owner,
+		"-f", "name="+repo,
+	)
+	if err != nil {
+		return nil, err
+	}
+	type blob struct {
+		Text string `json:"text"`
+	}
+	var resp struct {
+		Data struct {
+			Repository struct {
+				GitHub *blob `json:"github"`
+				Root   *blob `json:"root"`
+				Docs   *blob `json:"docs"`
+			} `json:"repository"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse CODEOWNERS response: %w", err)
+	}
+	r := resp.Data.Repository
+	for _, b := range []*blob{r.GitHub, r.Root, r.Docs} {
+		if b != nil {
+			return ParseCodeOwners(b.Text), nil
+		}
+	}
+	return ParseCodeOwners(""), nil
+}
+
+// ChangedFiles returns the paths of the files a GitHub PR changes.
+func (g GitHub) ChangedFiles(ctx context.Context, owner, repo string, number int) ([]string, error) {
+	out, err := g.output(ctx, "list changed files", "gh", "api", "--paginate",
+		fmt.Sprintf("repos/%s/%s/pulls/%d/files", owner, repo, number),
+		"--jq", ".[].filename",
+	)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// ParseCodeOwners parses the contents of a CODEOWNERS file. Lines that
+// cannot be parsed are skipped, as GitHub does.
+func ParseCodeOwners(content string) *CodeOwners {
+	c := &CodeOwners{}
+	sc := bufio.NewScanner(strings.NewReader(content))
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		re, err := regexp.Compile(codeOwnersPattern(fields[0]))
+		if err != nil {
+			continue
+		}
+		c.rules = append(c.rules, codeOwnersRule{pattern: re, owners: fields[1:]})
+	}
+	return c
+}
+
+// Owners returns the owners of a path: those of the last matching rule, as
+// in GitHub. A path matched by no rule, or by a rule with no owners, has
+// none.
+func (c *CodeOwners) Owners(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(path) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// codeOwnersPattern translates a CODEOWNERS pattern, which follows
+// .gitignore rules, to a regular expression over repository paths. A
+// pattern with a leading or inner slash is anchored to the root; otherwise
+// it matches at any depth. A pattern matching a directory matches
+// everything under it, and one with a trailing slash matches only that.
+func codeOwnersPattern(pattern string) string {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "/**") && i+3 == len(p):
+			b.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	if dirOnly {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+	return b.String()
+}
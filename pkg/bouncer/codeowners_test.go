@@ -0,0 +1,43 @@
+package bouncer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCodeOwners(t *testing.T) {
+	c := ParseCodeOwners(`# Default owners
+*                   @acme/platform
+
+/frontend/          @acme/web
+package.json        @acme/web @acme/platform
+docs/*.md           @acme/docs
+**/migrations       @acme/data
+/vendor/**          # no owners
+go.sum              @acme/platform # trailing comment
+`)
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"go.mod", []string{"@acme/platform"}},
+		{"go.sum", []string{"@acme/platform"}},
+		{"frontend/yarn.lock", []string{"@acme/web"}},
+		{"frontend", []string{"@acme/platform"}},
+		{"services/api/package.json", []string{"@acme/web", "@acme/platform"}},
+		{"docs/guide.md", []string{"@acme/docs"}},
+		{"docs/api/guide.md", []string{"@acme/platform"}},
+		{"db/migrations/001.sql", []string{"@acme/data"}},
+		{"vendor/github.com/x/y.go", nil},
+	}
+	for _, tt := range tests {
+		if got := c.Owners(tt.path); !slices.Equal(got, tt.want) {
+			t.Errorf("Owners(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	if got := ParseCodeOwners("").Owners("go.mod"); got != nil {
+		t.Errorf("Owners() without rules = %v, want none", got)
+	}
+}
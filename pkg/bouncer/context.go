@@ -0,0 +1,72 @@
+package bouncer
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ctxMu guards repoCtxs, which is read by calls running concurrently.
+var ctxMu sync.Mutex
+
+// repoCtxs bound the API calls on single repositories, by lower-cased
+// "owner/repo", on top of the context each call is made with. The last
+// bound set on a repository applies.
+var repoCtxs = make(map[string][]*repoBound)
+
+// repoBound is one bound set with SetRepoContext.
+type repoBound struct {
+	ctx context.Context
+}
+
+// SetRepoContext makes subsequent API calls on repo ("owner/repo") run under
+// ctx as well as the context they are made with: calls still running when
+// ctx is done are killed and fail with its error. It bounds the calls made
+// deep inside code that does not pass a context of its own for the
+// repository, so that repositories processed at the same time each get
+// their own bound. Calling the returned
+// function removes the bound. When a repository is bound again before that,
+// as when two runs on it overlap, the newer bound applies until it is
+// removed, and then the older one again.
+func SetRepoContext(repo string, ctx context.Context) (remove func()) {
+	key := strings.ToLower(repo)
+	b := &repoBound{ctx: ctx}
+	ctxMu.Lock()
+	defer ctxMu.Unlock()
+	repoCtxs[key] = append(repoCtxs[key], b)
+	return func() {
+		ctxMu.Lock()
+		defer ctxMu.Unlock()
+		bounds := slices.DeleteFunc(repoCtxs[key], func(o *repoBound) bool { return o == b })
+		if len(bounds) == 0 {
+			delete(repoCtxs, key)
+		} else {
+			repoCtxs[key] = bounds
+		}
+	}
+}
+
+// contextFor returns the bound set on API calls on repo with SetRepoContext,
+// or context.Background() when there is none or repo is empty.
+func contextFor(repo string) context.Context {
+	ctxMu.Lock()
+	defer ctxMu.Unlock()
+	if bounds := repoCtxs[strings.ToLower(repo)]; repo != "" && len(bounds) > 0 {
+		return bounds[len(bounds)-1].ctx
+	}
+	return context.Background()
+}
+
+// callContext returns a context that is done once ctx or the bound on API
+// calls on repo (see contextFor) is, with the cause of whichever is done
+// first. Calling cancel releases it.
+func callContext(ctx context.Context, repo string) (_ context.Context, cancel context.CancelFunc) {
+	bound := contextFor(repo)
+	ctx, cancelCause := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(bound, func() { cancelCause(context.Cause(bound)) })
+	return ctx, func() {
+		stop()
+		cancelCause(context.Canceled)
+	}
+}
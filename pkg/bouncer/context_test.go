@@ -0,0 +1,108 @@
+package bouncer
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestContextFor(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	remove := SetRepoContext("acme/API", ctx)
+	defer remove()
+
+	if got := contextFor("Acme/api"); got != ctx {
+		t.Error("contextFor(Acme/api) is not the repository's context")
+	}
+	if got := contextFor("acme/web"); got != context.Background() {
+		t.Error("contextFor(acme/web) is bound")
+	}
+	if got := contextFor(""); got != context.Background() {
+		t.Error("contextFor(\"\") is bound")
+	}
+
+	remove()
+	if got := contextFor("acme/api"); got != context.Background() {
+		t.Error("contextFor(acme/api) after removing it is bound")
+	}
+}
+
+func TestSetRepoContextOverlapping(t *testing.T) {
+	first, cancelFirst := context.WithCancel(context.Background())
+	defer cancelFirst()
+	second, cancelSecond := context.WithCancel(context.Background())
+	defer cancelSecond()
+
+	removeFirst := SetRepoContext("acme/api", first)
+	removeSecond := SetRepoContext("acme/api", second)
+	if got := contextFor("acme/api"); got != second {
+		t.Error("contextFor(acme/api) is not the newer context")
+	}
+
+	removeFirst()
+	if got := contextFor("acme/api"); got != second {
+		t.Error("removing the older context removed the newer one")
+	}
+	removeSecond()
+	if got := contextFor("acme/api"); got != context.Background() {
+		t.Error("contextFor(acme/api) after removing both is bound")
+	}
+	if _, ok := repoCtxs["acme/api"]; ok {
+		t.Error("removed contexts left an entry behind")
+	}
+
+	removeFirst = SetRepoContext("acme/api", first)
+	removeSecond = SetRepoContext("acme/api", second)
+	removeSecond()
+	if got := contextFor("acme/api"); got != first {
+		t.Error("removing the newer context did not restore the older one")
+	}
+	removeFirst()
+}
+
+func TestCallContext(t *testing.T) {
+	bound, cancelBound := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancelBound()
+	defer SetRepoContext("acme/api", bound)()
+
+	ctx, cancel := callContext(context.Background(), "acme/api")
+	defer cancel()
+	<-ctx.Done()
+	if err := context.Cause(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("callContext() cause after the bound's deadline = %v, want context.DeadlineExceeded", err)
+	}
+
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel = callContext(parent, "acme/web")
+	defer cancel()
+	if ctx.Err() != nil {
+		t.Fatal("callContext() is done before its parent")
+	}
+	cancelParent()
+	if err := ctx.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("callContext() error after canceling its parent = %v, want context.Canceled", err)
+	}
+}
+
+func TestRunCallWaitsForSlot(t *testing.T) {
+	defer SetMaxConcurrentCalls(DefaultMaxConcurrentCalls)
+	SetMaxConcurrentCalls(1)
+	callSlots <- struct{}{} // another call is running
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := runCall(ctx, exec.Command("true"), false); !errors.Is(err, context.Canceled) {
+		t.Errorf("runCall() with no free slot error = %v, want context.Canceled", err)
+	}
+
+	<-callSlots
+	if _, err := runCall(context.Background(), exec.Command("true"), false); err != nil {
+		t.Errorf("runCall() error = %v", err)
+	}
+	if len(callSlots) != 0 {
+		t.Errorf("runCall() left %d slots taken, want 0", len(callSlots))
+	}
+}
@@ -0,0 +1,149 @@
+package bouncer
+
+import "time"
+
+// DependencyUpdateQuery holds parameters for listing and filtering Dependabot PRs.
+type DependencyUpdateQuery struct {
+	Owner string
+	Repo  string
+	// Numbers, when non-empty, limits the query to these PRs, which are
+	// fetched one by one instead of listing the repository's open PRs. They
+	// must still be open, by a configured bot, and pass the filters below.
+	Numbers        []int
+	IgnoredPRs     []int
+	DeniedPackages []string
+	DeniedOrgs     []string
+	// Snoozed maps PRs to the time they are snoozed until; they are skipped
+	// with SkipSnoozed. Expired snoozes should be left out.
+	Snoozed map[int]time.Time
+	// AllowedPackages and AllowedOrgs, when either is non-empty, restrict
+	// processing to matching packages; everything else is skipped.
+	AllowedPackages []string
+	AllowedOrgs     []string
+	Precedence      string // PrecedenceDeny (default) or PrecedenceAllow
+	// TrustedPackages and TrustedOrgs mark matching packages as Trusted, using
+	// deny-list matching rules. Trust does not exempt them from the allow and
+	// deny lists.
+	TrustedPackages []string
+	TrustedOrgs     []string
+	// DeniedUpdateTypes skips updates of these types (UpdateMajor, UpdateMinor,
+	// UpdatePatch) for every package. DeniedUpdateTypesByPackage does the same
+	// for packages matching a key, using deny-list matching rules.
+	DeniedUpdateTypes          []string
+	DeniedUpdateTypesByPackage map[string][]string
+	// InternalPackages marks matching packages (deny-list matching rules) as
+	// internal, e.g. modules from the organization's private registry.
+	// DeniedUpdateTypesInternal applies to internal packages only, and
+	// DeniedUpdateTypesExternal to every other package.
+	InternalPackages          []string
+	DeniedUpdateTypesInternal []string
+	DeniedUpdateTypesExternal []string
+	// EcosystemDeniedPackages and EcosystemDeniedOrgs hold extra deny entries
+	// keyed by Dependabot package-ecosystem name (gomod, npm, pip, ...). They
+	// only apply to PRs detected as belonging to that ecosystem.
+	EcosystemDeniedPackages map[string][]string
+	EcosystemDeniedOrgs     map[string][]string
+	ExemptSecurity          bool // security updates bypass the allow and deny lists
+	IncludeSkipped          bool // return skipped PRs marked as Skipped instead of dropping them
+	IncludeDrafts           bool // process draft PRs instead of skipping them
+	// UnparsedTitles is what happens to PRs whose title names no package:
+	// UnparsedWarn (when empty), UnparsedProcess, or UnparsedSkip.
+	UnparsedTitles string
+	// CreatedAfter, CreatedBefore, and UpdatedSince, when non-zero, limit the
+	// run to PRs opened or last updated within the window; PRs outside it are
+	// dropped, even when IncludeSkipped is set.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedSince  time.Time
+	// CIFilter selects PRs by CI status: CIPassing, CIPassingOrPending,
+	// CIAny, or CIFailing. When empty, the skipFailing argument of List
+	// decides between CIPassing and CIAny.
+	CIFilter string
+	// RequiredChecks, when non-empty, limits the CI status of each PR to
+	// these named checks; other checks are ignored.
+	RequiredChecks []string
+	// MinSuccessfulChecks, when positive, keeps a passing PR pending until at
+	// least this many of its checks ran and succeeded. Skipped checks do not
+	// count.
+	MinSuccessfulChecks int
+	// Stats, when non-nil, is filled with counts of the open PRs that were
+	// not returned and why.
+	Stats *ListStats
+}
+
+// ListStats explains how List narrowed a repository's open PRs down to the
+// ones it returned.
+type ListStats struct {
+	Open        int            `json:"open"`              // open PRs seen before any filtering
+	OutOfWindow int            `json:"out_of_window"`     // created or updated outside the selection window
+	NotByBot    int            `json:"not_by_bot"`        // opened by someone other than the configured bots
+	WrongBase   int            `json:"wrong_base"`        // targeting a branch other than main
+	Skipped     map[string]int `json:"skipped,omitempty"` // skipped by policy, keyed by skip code
+	Passing     int            `json:"passing,omitempty"` // CI passing (only counted when passing PRs are dropped)
+	Failing     int            `json:"failing"`           // CI failing (only counted when failing PRs are dropped)
+	Pending     int            `json:"pending"`           // CI still running (only counted when pending PRs are dropped)
+}
+
+// inWindow reports whether a PR falls within the query's selection window.
+func (q DependencyUpdateQuery) inWindow(pr PRInfo) bool {
+	switch {
+	case !q.CreatedAfter.IsZero() && pr.CreatedAt.Before(q.CreatedAfter):
+		return false
+	case !q.CreatedBefore.IsZero() && !pr.CreatedAt.Before(q.CreatedBefore):
+		return false
+	case !q.UpdatedSince.IsZero() && pr.UpdatedAt.Before(q.UpdatedSince):
+		return false
+	}
+	return true
+}
+
+// skip counts a PR skipped with the given code.
+func (s *ListStats) skip(code string) {
+	if s == nil {
+		return
+	}
+	if s.Skipped == nil {
+		s.Skipped = make(map[string]int)
+	}
+	s.Skipped[code]++
+}
+
+// PRInfo contains information about a Dependabot pull request.
+type PRInfo struct {
+	Number           int       `json:"number"`
+	Title            string    `json:"title"`
+	URL              string    `json:"url"`
+	State            string    `json:"state,omitempty"`              // OPEN, CLOSED, MERGED (populated by GetPR)
+	HeadSHA          string    `json:"head_sha,omitempty"`           // head commit; changes when Dependabot rebases or recreates (populated by GetPR)
+	MergeStateStatus string    `json:"merge_state_status,omitempty"` // BEHIND, BLOCKED, CLEAN, DIRTY, DRAFT, HAS_HOOKS, UNKNOWN, UNSTABLE
+	ReviewDecision   string    `json:"review_decision,omitempty"`    // APPROVED, REVIEW_REQUIRED, CHANGES_REQUESTED
+	Draft            bool      `json:"draft,omitempty"`              // draft PR (GitLab and Gitea: draft title prefix)
+	CIStatus         string    `json:"ci_status,omitempty"`          // success, failure, pending
+	CIFailures       []string  `json:"ci_failures,omitempty"`        // names of failing checks (populated when CIStatus is "failure")
+	Checks           []Check   `json:"checks,omitempty"`             // individual checks behind CIStatus
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	PackageName      string    `json:"package"`
+	FromVersion      string    `json:"from_version,omitempty"`
+	ToVersion        string    `json:"to_version,omitempty"`
+	UpdateType       string    `json:"update_type,omitempty"` // major, minor, patch, or empty when unknown
+	Security         bool      `json:"security"`              // security update: labeled "security", fixing an open Dependabot alert, or fixing a known vulnerability
+	Updates          []Update  `json:"updates,omitempty"`     // the updates of a grouped update, whose PackageName is the group name
+	OrgName          string    `json:"org,omitempty"`
+	Ecosystem        string    `json:"ecosystem,omitempty"` // Dependabot package-ecosystem name, e.g. gomod, npm
+	Trusted          bool      `json:"trusted,omitempty"`   // matches trusted_packages or trusted_orgs
+	Internal         bool      `json:"internal,omitempty"`  // matches internal_packages
+	Skipped          bool      `json:"skipped"`
+	SkipCode         string    `json:"skip_code,omitempty"` // one of the Skip* constants
+	SkipReason       string    `json:"skip_reason,omitempty"`
+	SkipRule         string    `json:"skip_rule,omitempty"`  // deny entry, "allow list", or update type behind a policy skip
+	AllowRule        string    `json:"allow_rule,omitempty"` // allow entry or "security exemption" that let the PR through an allow list or deny list
+	Reviews          []Review  `json:"-"`                    // reviews left on the PR (GitHub only)
+}
+
+// Review is a review left on a PR.
+type Review struct {
+	Author string // login of the reviewer
+	State  string // APPROVED, COMMENTED, CHANGES_REQUESTED, DISMISSED
+	Body   string
+}
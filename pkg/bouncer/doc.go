@@ -0,0 +1,49 @@
+// Package bouncer is the approve/deny engine behind the dependabot-bouncer
+// command, for tools that want to list and act on dependency update PRs
+// without shelling out to the CLI.
+//
+// A Client, returned by NewClient, lists the open dependency update PRs of a
+// repository and acts on them. List applies the policy in a
+// DependencyUpdateQuery: PRs denied by it are dropped, or returned with
+// Skipped set and a SkipCode when IncludeSkipped is set, and the rest are
+// returned with their package, versions, update type, and CI status parsed:
+//
+//	client, err := bouncer.NewClient(bouncer.Options{Provider: bouncer.ProviderGitHub})
+//	if err != nil {
+//		return err
+//	}
+//	prs, err := client.List(ctx, bouncer.DependencyUpdateQuery{
+//		Owner:             "myorg",
+//		Repo:              "api",
+//		DeniedPackages:    []string{"left-pad", "react@>=19"},
+//		DeniedUpdateTypes: []string{bouncer.UpdateMajor},
+//	}, true)
+//	if err != nil {
+//		return err
+//	}
+//	for _, pr := range prs {
+//		if err := client.Approve(ctx, "myorg", "api", pr.Number); err != nil {
+//			return err
+//		}
+//	}
+//
+// The GitHub and GitLab clients run the gh and glab CLIs, which must be
+// installed and authenticated; the Gitea client calls the server's API.
+// Functions report failures as errors and never exit or panic. Progress and
+// retries are logged with the standard log package.
+//
+// Client methods take a context: calls still running when it is done are
+// killed and fail with its error. A client's settings, such as the GitHub
+// host and token, retries, and the ETag cache, are set in Options. The Set
+// functions configure what every client in the process shares: the token
+// pool and the pacing of calls (SetMaxConcurrentCalls, SetWriteInterval),
+// read-only mode, the bot identities, tracing, and SetRepoContext, which
+// bounds the calls on a repository made by code that does not pass a
+// context for it. Call them before the first Client call; their defaults
+// suit a single interactive run.
+//
+// The package applies the policy in a DependencyUpdateQuery but does not
+// read the dependabot-bouncer config file: building queries and review
+// policies from it, with policy groups and per-repository overrides, is
+// left to the command.
+package bouncer
@@ -0,0 +1,151 @@
+package bouncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// branchEcosystems maps the directory Dependabot uses in its branch names
+// (dependabot/<dir>/...) to the package-ecosystem name used in dependabot.yml.
+var branchEcosystems = map[string]string{
+	"go_modules":     "gomod",
+	"npm_and_yarn":   "npm",
+	"pip":            "pip",
+	"docker":         "docker",
+	"github_actions": "github-actions",
+	"bundler":        "bundler",
+	"maven":          "maven",
+	"gradle":         "gradle",
+	"cargo":          "cargo",
+	"composer":       "composer",
+	"nuget":          "nuget",
+	"terraform":      "terraform",
+	"hex":            "mix",
+	"pub":            "pub",
+	"elm":            "elm",
+	"submodules":     "gitsubmodule",
+}
+
+// titleEcosystems map wording in PR titles to the ecosystem it implies, for
+// PRs whose branch does not name one (Renovate, or Dependabot PRs from forks).
+var titleEcosystems = []struct {
+	re        *regexp.Regexp
+	ecosystem string
+}{
+	{regexp.MustCompile(`(?i)\bdocker\s+(?:tag|digest)\b`), "docker"},
+	{regexp.MustCompile("(?i)\\bfrom\\s+`[0-9a-f]{7,}`\\s+to\\b"), "docker"}, // Dependabot image digest updates
+	{regexp.MustCompile(`(?i)\bupdate\s+\S+\s+action\s+to\b`), "github-actions"},
+	{regexp.MustCompile(`(?i)\bupdate\s+(?:rust\s+)?crate\b`), "cargo"},
+	{regexp.MustCompile(`(?i)\bupdate\s+gem\b`), "bundler"},
+	{regexp.MustCompile(`(?i)\bupdate\s+module\b`), "gomod"},
+}
+
+// mavenCoordinateRe matches Maven and Gradle coordinates such as
+// com.fasterxml.jackson.core:jackson-databind.
+var mavenCoordinateRe = regexp.MustCompile(`^[\w-]+(?:\.[\w-]+)+:[\w.-]+$`)
+
+// detectEcosystem determines the Dependabot package ecosystem of a PR. The head
+// branch (dependabot/<ecosystem>/...) is authoritative; when it is unavailable
+// the title and package name are used for the unambiguous cases (Renovate's
+// "Docker tag", "action", "crate", "gem", and "module" wording, image digests,
+// Go module paths, scoped npm packages, and Maven coordinates). Returns ""
+// when the ecosystem cannot be determined.
+func detectEcosystem(branch, packageName, title string) string {
+	if rest, ok := strings.CutPrefix(branch, "dependabot/"); ok {
+		dir, _, _ := strings.Cut(rest, "/")
+		if eco, ok := branchEcosystems[dir]; ok {
+			return eco
+		}
+	}
+
+	for _, t := range titleEcosystems {
+		if t.re.MatchString(title) {
+			return t.ecosystem
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(packageName, "github.com/"),
+		strings.HasPrefix(packageName, "golang.org/"),
+		strings.HasPrefix(packageName, "google.golang.org/"),
+		strings.HasPrefix(packageName, "gopkg.in/"):
+		return "gomod"
+	case strings.HasPrefix(packageName, "@") && strings.Contains(packageName, "/"):
+		return "npm"
+	case mavenCoordinateRe.MatchString(packageName):
+		return "maven"
+	}
+	return ""
+}
+
+// dependabotConfigQuery reads the Dependabot configuration of the default
+// branch under both of the names GitHub accepts, in one call.
+const dependabotConfigQuery = `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    yml: object(expression: "HEAD:.github/dependabot.yml") { ... on Blob { text } }
+    yaml: object(expression: "HEAD:.github/dependabot.yaml") { ... on Blob { text } }
+  }
+}`
+
+// DependabotEcosystems returns the package ecosystems a GitHub repository's
+// .github/dependabot.yml configures updates for, sorted. ok is false when the
+// repository has no Dependabot configuration.
+func (g GitHub) DependabotEcosystems(ctx context.Context, owner, repo string) (ecosystems []string, ok bool, err error) {
+	out, err := g.output(ctx, "read dependabot.yml", "gh", "api", "graphql",
+		"-f", "query="+dependabotConfigQuery,
+		"-f", "owner="+owner,
+		"-f", "name="+repo,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	type blob struct {
+		Text string `json:"text"`
+	}
+	var resp struct {
+		Data struct {
+			Repository struct {
+				YML  *blob `json:"yml"`
+				YAML *blob `json:"yaml"`
+			} `json:"repository"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, false, fmt.Errorf("failed to parse dependabot.yml response: %w", err)
+	}
+	for _, b := range []*blob{resp.Data.Repository.YML, resp.Data.Repository.YAML} {
+		if b != nil {
+			ecosystems, err := ParseDependabotEcosystems([]byte(b.Text))
+			return ecosystems, err == nil, err
+		}
+	}
+	return nil, false, nil
+}
+
+// ParseDependabotEcosystems returns the distinct package-ecosystem values of
+// the updates in a dependabot.yml file, sorted.
+func ParseDependabotEcosystems(data []byte) ([]string, error) {
+	var cfg struct {
+		Updates []struct {
+			PackageEcosystem string `yaml:"package-ecosystem"`
+		} `yaml:"updates"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse dependabot.yml: %w", err)
+	}
+	var ecosystems []string
+	for _, u := range cfg.Updates {
+		if u.PackageEcosystem != "" && !slices.Contains(ecosystems, u.PackageEcosystem) {
+			ecosystems = append(ecosystems, u.PackageEcosystem)
+		}
+	}
+	sort.Strings(ecosystems)
+	return ecosystems, nil
+}
@@ -0,0 +1,71 @@
+package bouncer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDetectEcosystem(t *testing.T) {
+	tests := []struct {
+		name        string
+		branch      string
+		packageName string
+		title       string
+		want        string
+	}{
+		{name: "go modules branch", branch: "dependabot/go_modules/github.com/spf13/cobra-1.7.0", packageName: "github.com/spf13/cobra", want: "gomod"},
+		{name: "npm branch", branch: "dependabot/npm_and_yarn/left-pad-1.3.0", packageName: "left-pad", want: "npm"},
+		{name: "npm subdirectory branch", branch: "dependabot/npm_and_yarn/frontend/lodash-4.17.21", packageName: "lodash", want: "npm"},
+		{name: "pip branch", branch: "dependabot/pip/requests-2.31.0", packageName: "requests", want: "pip"},
+		{name: "docker branch", branch: "dependabot/docker/golang-1.22", packageName: "golang", want: "docker"},
+		{name: "actions branch", branch: "dependabot/github_actions/actions/checkout-4", packageName: "actions/checkout", want: "github-actions"},
+		{name: "branch wins over package name", branch: "dependabot/github_actions/github.com/foo/bar-1", packageName: "github.com/foo/bar", want: "github-actions"},
+		{name: "go module without branch", packageName: "golang.org/x/net", want: "gomod"},
+		{name: "scoped npm without branch", packageName: "@datadog/browser-rum", want: "npm"},
+		{name: "maven coordinate without branch", packageName: "com.fasterxml.jackson.core:jackson-databind", want: "maven"},
+		{name: "renovate docker tag", branch: "renovate/golang-1.x", packageName: "golang", title: "Update golang Docker tag to v1.22", want: "docker"},
+		{name: "renovate action", branch: "renovate/actions-checkout-4.x", packageName: "actions/checkout", title: "chore(deps): update actions/checkout action to v4", want: "github-actions"},
+		{name: "renovate crate", branch: "renovate/serde-monorepo", packageName: "serde", title: "Update Rust crate serde to 1.0.200", want: "cargo"},
+		{name: "renovate module", branch: "renovate/github.com-foo-bar-1.x", packageName: "github.com/foo/bar", title: "fix(deps): update module github.com/foo/bar to v1.2.3", want: "gomod"},
+		{name: "image digest", packageName: "node", title: "Bump node from `1a2b3c4` to `5d6e7f8` in /docker", want: "docker"},
+		{name: "unknown", branch: "feature/foo", packageName: "left-pad", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectEcosystem(tt.branch, tt.packageName, tt.title); got != tt.want {
+				t.Errorf("detectEcosystem(%q, %q, %q) = %q, want %q", tt.branch, tt.packageName, tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDependabotEcosystems(t *testing.T) {
+	data := []byte(`version: 2
+updates:
+  - package-ecosystem: gomod
+    directory: /
+    schedule:
+      interval: weekly
+  - package-ecosystem: docker
+    directory: /
+  - package-ecosystem: gomod
+    directory: /tools
+  - package-ecosystem: github-actions
+    directory: /
+`)
+	got, err := ParseDependabotEcosystems(data)
+	if err != nil {
+		t.Fatalf("ParseDependabotEcosystems() error = %v", err)
+	}
+	if want := []string{"docker", "github-actions", "gomod"}; !slices.Equal(got, want) {
+		t.Errorf("ParseDependabotEcosystems() = %q, want %q", got, want)
+	}
+
+	if got, err := ParseDependabotEcosystems([]byte("version: 2\n")); err != nil || got != nil {
+		t.Errorf("ParseDependabotEcosystems(no updates) = %q, %v, want nil", got, err)
+	}
+	if _, err := ParseDependabotEcosystems([]byte("updates: [")); err == nil {
+		t.Error("ParseDependabotEcosystems(malformed) error = nil, want error")
+	}
+}
@@ -0,0 +1,260 @@
+package bouncer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// etagMaxPRs is the most open PRs a repository may have for its listing to be
+// cached: the ETag of the first page of open PRs only covers that page.
+const etagMaxPRs = 100
+
+// ETagCache keeps the PR listing of each repository together with the ETags
+// of the REST resources it depends on: the open PRs, the head of main, and
+// the statuses and check suites of each PR's head commit. While GitHub
+// answers every conditional request for them with 304 Not Modified, which
+// does not count against the rate limit, the listing is reused instead of
+// fetched again. The cache is saved to a file after every change, so it
+// carries over between runs.
+type ETagCache struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]etagEntry // by lower-cased "owner/repo"
+}
+
+type etagEntry struct {
+	ETags   map[string]string `json:"etags"`   // REST path -> ETag
+	Listing json.RawMessage   `json:"listing"` // gh pr list output
+}
+
+// OpenETagCache reads the cache saved at path. A missing file is an empty
+// cache.
+func OpenETagCache(path string) (*ETagCache, error) {
+	c := &ETagCache{path: path, entries: make(map[string]etagEntry)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ETag cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse ETag cache %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// conditionalGet requests a REST path from g's host, under ctx, with
+// If-None-Match set to etag, or unconditionally when etag is empty, and returns the response's
+// status code and ETag; replaced in tests.
+var conditionalGet = GitHub.conditionalRequest
+
+// listing returns the cached PR listing of owner/repo when g's host reports
+// none of the resources it depends on modified. Any other answer, including
+// an error, is a miss. The ETags of the repository-wide resources, the open
+// PRs and the head of main, are fetched before anything else and returned
+// for store: taken before the PRs are listed again, they can only be older
+// than the listing, never newer.
+func (c *ETagCache) listing(ctx context.Context, g GitHub, owner, repo string) (listing []byte, ok bool, repoETags map[string]string) {
+	c.mu.Lock()
+	e, cached := c.entries[strings.ToLower(owner+"/"+repo)]
+	c.mu.Unlock()
+
+	unchanged := cached
+	repoETags = make(map[string]string)
+	for _, path := range repoPaths(owner, repo) {
+		status, etag, err := conditionalGet(g, ctx, path, e.ETags[path])
+		switch {
+		case err != nil:
+			return nil, false, nil
+		case status == 304:
+			repoETags[path] = e.ETags[path]
+		default:
+			unchanged = false
+			repoETags[path] = etag
+		}
+	}
+	if !unchanged {
+		return nil, false, repoETags
+	}
+	for path, etag := range e.ETags {
+		if _, ok := repoETags[path]; ok {
+			continue
+		}
+		if status, _, err := conditionalGet(g, ctx, path, etag); err != nil || status != 304 {
+			return nil, false, repoETags
+		}
+	}
+	return e.Listing, true, nil
+}
+
+// store caches the PR listing of owner/repo with repoETags, from listing, and
+// the ETags of the statuses and check suites of each PR's head commit, and
+// saves the cache. A listing is dropped instead when a PR's checks are still
+// running, since they will change soon anyway and may have finished before
+// their ETags are fetched; when it has more than etagMaxPRs PRs; and when an
+// ETag is missing.
+func (c *ETagCache) store(ctx context.Context, g GitHub, owner, repo string, listing []byte, prs []ghPR, repoETags map[string]string) error {
+	key := strings.ToLower(owner + "/" + repo)
+	var e etagEntry
+	if len(repoETags) > 0 && len(prs) <= etagMaxPRs && !anyPending(prs) {
+		e = etagEntry{ETags: make(map[string]string), Listing: listing}
+		for path, etag := range repoETags {
+			e.ETags[path] = etag
+		}
+		for _, path := range prPaths(owner, repo, prs) {
+			status, etag, err := conditionalGet(g, ctx, path, "")
+			if err != nil || status != 200 {
+				e = etagEntry{}
+				break
+			}
+			e.ETags[path] = etag
+		}
+		for _, etag := range e.ETags {
+			if etag == "" {
+				e = etagEntry{}
+				break
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e.ETags == nil {
+		if _, ok := c.entries[key]; !ok {
+			return nil
+		}
+		delete(c.entries, key)
+	} else {
+		c.entries[key] = e
+	}
+	return c.save()
+}
+
+// anyPending reports whether any PR has checks still running.
+func anyPending(prs []ghPR) bool {
+	for _, p := range prs {
+		if status, _ := combineChecks(githubChecks(p.StatusCheckRollup)); status == "pending" {
+			return true
+		}
+	}
+	return false
+}
+
+// repoPaths returns the REST paths of the repository-wide resources a PR
+// listing depends on.
+func repoPaths(owner, repo string) []string {
+	base := "repos/" + owner + "/" + repo
+	return []string{
+		base + "/pulls?state=open&sort=updated&direction=desc&per_page=" + strconv.Itoa(etagMaxPRs),
+		base + "/git/ref/heads/main",
+	}
+}
+
+// prPaths returns the REST paths of the statuses and check suites of each
+// PR's head commit.
+func prPaths(owner, repo string, prs []ghPR) []string {
+	base := "repos/" + owner + "/" + repo
+	var paths []string
+	for _, p := range prs {
+		if p.HeadRefOid != "" {
+			paths = append(paths,
+				base+"/commits/"+p.HeadRefOid+"/status",
+				base+"/commits/"+p.HeadRefOid+"/check-suites")
+		}
+	}
+	return paths
+}
+
+// save writes the cache file; c.mu must be held.
+func (c *ETagCache) save() error {
+	b, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("failed to save ETag cache: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save ETag cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save ETag cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save ETag cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to save ETag cache: %w", err)
+	}
+	return nil
+}
+
+// conditionalRequest makes a conditional GET with gh api. gh exits non-zero on
+// a 304, but still prints the response headers.
+func (g GitHub) conditionalRequest(ctx context.Context, path, etag string) (int, string, error) {
+	args := []string{"gh", "api", "--include", "--method", "GET", path}
+	if etag != "" {
+		args = append(args, "-H", "If-None-Match: "+etag)
+	}
+	ctx, cancel := callContext(ctx, repoFromPath(path))
+	defer cancel()
+	cmd, _, err := g.exec(ctx, args...)
+	if err != nil {
+		return 0, "", err
+	}
+	out, err := runCall(ctx, cmd, false)
+	status, newETag := parseResponseHeaders(out)
+	if status == 0 {
+		if err == nil {
+			err = fmt.Errorf("no status line in gh output")
+		}
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			err = fmt.Errorf("%s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return 0, "", fmt.Errorf("conditional request for %s failed: %w", path, err)
+	}
+	return status, newETag, nil
+}
+
+// parseResponseHeaders extracts the status code and ETag from the response
+// headers gh prints with --include. The status is 0 when there are none.
+func parseResponseHeaders(out []byte) (status int, etag string) {
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	if !sc.Scan() {
+		return 0, ""
+	}
+	// The status line, e.g. "HTTP/2.0 304 Not Modified".
+	fields := strings.Fields(sc.Text())
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "HTTP/") {
+		return 0, ""
+	}
+	status, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, ""
+	}
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			break // end of the headers
+		}
+		name, value, found := strings.Cut(line, ":")
+		if found && strings.EqualFold(name, "ETag") {
+			etag = strings.TrimSpace(value)
+		}
+	}
+	return status, etag
+}
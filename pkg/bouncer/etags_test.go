@@ -0,0 +1,106 @@
+package bouncer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseResponseHeaders(t *testing.T) {
+	tests := []struct {
+		name       string
+		out        string
+		wantStatus int
+		wantETag   string
+	}{
+		{"not modified", "HTTP/2.0 304 Not Modified\r\nEtag: \"abc\"\r\n\r\n", 304, `"abc"`},
+		{"ok with body", "HTTP/2.0 200 OK\r\nContent-Type: application/json\r\nETag: W/\"def\"\r\n\r\n{\"etag\": \"no\"}", 200, `W/"def"`},
+		{"no etag", "HTTP/2.0 404 Not Found\r\n\r\n{}", 404, ""},
+		{"no headers", "[]", 0, ""},
+		{"empty", "", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, etag := parseResponseHeaders([]byte(tt.out))
+			if status != tt.wantStatus || etag != tt.wantETag {
+				t.Errorf("parseResponseHeaders() = %d, %q, want %d, %q", status, etag, tt.wantStatus, tt.wantETag)
+			}
+		})
+	}
+}
+
+func TestETagCache(t *testing.T) {
+	// current is the ETag GitHub would send for each path; missing paths
+	// are 404s.
+	current := map[string]string{
+		"repos/acme/api/pulls?state=open&sort=updated&direction=desc&per_page=100": `"pulls-1"`,
+		"repos/acme/api/git/ref/heads/main":                                        `"main-1"`,
+		"repos/acme/api/commits/abc/status":                                        `"status-1"`,
+		"repos/acme/api/commits/abc/check-suites":                                  `"suites-1"`,
+	}
+	var requests int
+	orig := conditionalGet
+	defer func() { conditionalGet = orig }()
+	conditionalGet = func(_ GitHub, _ context.Context, path, etag string) (int, string, error) {
+		requests++
+		cur, ok := current[path]
+		switch {
+		case !ok:
+			return 404, "", nil
+		case etag == cur:
+			return 304, cur, nil
+		}
+		return 200, cur, nil
+	}
+
+	path := filepath.Join(t.TempDir(), "etags.json")
+	c, err := OpenETagCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := c.listing(context.Background(), GitHub{}, "acme", "api"); ok {
+		t.Fatal("listing() hit on an empty cache")
+	}
+
+	// A listing with a pending PR is not cached.
+	_, _, repoETags := c.listing(context.Background(), GitHub{}, "acme", "api")
+	pending := []ghPR{{Number: 1, HeadRefOid: "abc"}}
+	if err := c.store(context.Background(), GitHub{}, "acme", "api", []byte(`[1]`), pending, repoETags); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := c.listing(context.Background(), GitHub{}, "acme", "api"); ok {
+		t.Error("listing() hit after storing a listing with pending checks")
+	}
+
+	_, _, repoETags = c.listing(context.Background(), GitHub{}, "acme", "api")
+	passing := []ghPR{{Number: 1, HeadRefOid: "abc", StatusCheckRollup: []statusCheck{{TypeName: "CheckRun", Name: "build", Status: "COMPLETED", Conclusion: "SUCCESS"}}}}
+	if err := c.store(context.Background(), GitHub{}, "acme", "api", []byte(`[1]`), passing, repoETags); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cache carries over to the next run.
+	c, err = OpenETagCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests = 0
+	if out, ok, _ := c.listing(context.Background(), GitHub{}, "acme", "api"); !ok || string(out) != `[1]` {
+		t.Errorf("listing() = %s, %v, want [1], true", out, ok)
+	}
+	if requests != 4 {
+		t.Errorf("listing() made %d requests, want 4", requests)
+	}
+
+	// A check suite changing invalidates the listing.
+	current["repos/acme/api/commits/abc/check-suites"] = `"suites-2"`
+	if _, ok, _ := c.listing(context.Background(), GitHub{}, "acme", "api"); ok {
+		t.Error("listing() hit after a check suite changed")
+	}
+
+	// So does main moving, and the new ETag is returned for the next store.
+	current["repos/acme/api/git/ref/heads/main"] = `"main-2"`
+	_, ok, repoETags := c.listing(context.Background(), GitHub{}, "acme", "api")
+	if ok || repoETags["repos/acme/api/git/ref/heads/main"] != `"main-2"` {
+		t.Errorf("listing() after main moved = %v, %q, want a miss with the new ETag", ok, repoETags)
+	}
+}
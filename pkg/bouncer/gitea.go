@@ -0,0 +1,414 @@
+package bouncer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/tracing"
+)
+
+// defaultGiteaAuthor is the username Renovate opens pull requests as when
+// none is configured.
+const defaultGiteaAuthor = "renovate"
+
+// renovateRebaseBox is the unticked checkbox Renovate puts in PR bodies;
+// ticking it asks Renovate to rebase or recreate the branch.
+const renovateRebaseBox = "- [ ] <!-- rebase-check -->"
+
+// Gitea is the Client for Gitea and Forgejo servers. Unlike the GitHub and
+// GitLab providers there is no CLI to shell out to, so it calls the REST API
+// directly with a token.
+type Gitea struct {
+	BaseURL string // server URL without the /api/v1 suffix
+	Token   string
+	Author  string // username of the bot that opens the pull requests
+
+	client *http.Client
+}
+
+// NewGitea returns a Gitea provider for the server at baseURL.
+func NewGitea(baseURL, token, author string) Gitea {
+	return Gitea{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Token:   token,
+		Author:  author,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// giteaPR represents a pull request as returned by the Gitea API.
+type giteaPR struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	HTMLURL   string    `json:"html_url"`
+	State     string    `json:"state"` // open or closed
+	Merged    bool      `json:"merged"`
+	Mergeable bool      `json:"mergeable"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Labels    []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	User struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Head struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+// giteaDraftPrefixes are Gitea's default work-in-progress title prefixes,
+// which mark a pull request as a draft.
+var giteaDraftPrefixes = []string{"WIP:", "[WIP]"}
+
+// giteaStatus is the combined commit status of a pull request's head.
+type giteaStatus struct {
+	State    string `json:"state"` // success, pending, failure, error, warning
+	Statuses []struct {
+		Context string `json:"context"`
+		Status  string `json:"status"`
+	} `json:"statuses"`
+}
+
+// List lists open pull requests by the bot targeting main, or only the ones
+// among q.Numbers. Commit status and reviews are fetched per candidate.
+func (g Gitea) List(ctx context.Context, q DependencyUpdateQuery, skipFailing bool) ([]PRInfo, error) {
+	var candidates []PRInfo
+	for page := 1; ; page++ {
+		pulls, more, err := g.openPulls(ctx, q, page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range pulls {
+			if q.Stats != nil {
+				q.Stats.Open++
+			}
+			if p.User.Login != g.Author {
+				if q.Stats != nil {
+					q.Stats.NotByBot++
+				}
+				continue
+			}
+			if p.Base.Ref != "main" {
+				if q.Stats != nil {
+					q.Stats.WrongBase++
+				}
+				continue
+			}
+
+			var status giteaStatus
+			if err := g.do(ctx, "get commit status", http.MethodGet, fmt.Sprintf("%s/commits/%s/status", repoPath(q.Owner, q.Repo), p.Head.SHA), nil, &status); err != nil {
+				return nil, err
+			}
+			approved, err := g.approved(ctx, q.Owner, q.Repo, p.Number)
+			if err != nil {
+				return nil, err
+			}
+
+			d := parseDependency(p.Title, p.Head.Ref, "", "")
+			for _, l := range p.Labels {
+				d.Security = d.Security || strings.EqualFold(l.Name, "security")
+			}
+			checks := giteaChecks(status)
+			ci, ciFailures := combineChecks(checks)
+			merge, review := "CLEAN", "REVIEW_REQUIRED"
+			draft := stripDraftPrefix(p.Title, giteaDraftPrefixes) != p.Title
+			switch {
+			case draft:
+				merge = "DRAFT"
+			case !p.Mergeable:
+				merge = "DIRTY"
+			}
+			if approved {
+				review = "APPROVED"
+			}
+
+			candidates = append(candidates, d.withPR(PRInfo{
+				Number:           p.Number,
+				Title:            p.Title,
+				URL:              p.HTMLURL,
+				MergeStateStatus: merge,
+				ReviewDecision:   review,
+				Draft:            draft,
+				CIStatus:         ci,
+				CIFailures:       ciFailures,
+				Checks:           checks,
+				CreatedAt:        p.CreatedAt,
+				UpdatedAt:        p.UpdatedAt,
+			}))
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return filterPRs(candidates, q, skipFailing), nil
+}
+
+// openPulls returns a page of the repository's open pull requests and whether
+// more may follow. When q.Numbers is set, the first page holds the open ones
+// among them.
+func (g Gitea) openPulls(ctx context.Context, q DependencyUpdateQuery, page int) ([]giteaPR, bool, error) {
+	var pulls []giteaPR
+	if len(q.Numbers) == 0 {
+		err := g.do(ctx, "list PRs", http.MethodGet, fmt.Sprintf("%s/pulls?state=open&limit=50&page=%d", repoPath(q.Owner, q.Repo), page), nil, &pulls)
+		return pulls, len(pulls) == 50, err
+	}
+	for _, n := range q.Numbers {
+		var p giteaPR
+		if err := g.do(ctx, "get PR", http.MethodGet, fmt.Sprintf("%s/pulls/%d", repoPath(q.Owner, q.Repo), n), nil, &p); err != nil {
+			return nil, false, err
+		}
+		if p.State != "open" {
+			log.Printf("Skipping PR #%d: %s\n", n, p.State)
+			continue
+		}
+		pulls = append(pulls, p)
+	}
+	return pulls, false, nil
+}
+
+func (g Gitea) approved(ctx context.Context, owner, repo string, number int) (bool, error) {
+	var reviews []struct {
+		State     string `json:"state"`
+		Dismissed bool   `json:"dismissed"`
+		Stale     bool   `json:"stale"`
+	}
+	if err := g.do(ctx, "list reviews", http.MethodGet, fmt.Sprintf("%s/pulls/%d/reviews", repoPath(owner, repo), number), nil, &reviews); err != nil {
+		return false, err
+	}
+	for _, r := range reviews {
+		if r.State == "APPROVED" && !r.Dismissed && !r.Stale {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (g Gitea) Approve(ctx context.Context, owner, repo string, number int) error {
+	return g.review(ctx, "approve PR", owner, repo, number, "APPROVED", "")
+}
+
+func (g Gitea) Comment(ctx context.Context, owner, repo string, number int, body string) error {
+	return g.review(ctx, "comment on PR", owner, repo, number, "COMMENT", body)
+}
+
+func (g Gitea) review(ctx context.Context, desc, owner, repo string, number int, event, body string) error {
+	payload := map[string]string{"event": event, "body": body}
+	return g.do(ctx, desc, http.MethodPost, fmt.Sprintf("%s/pulls/%d/reviews", repoPath(owner, repo), number), payload, nil)
+}
+
+// Rebase rebases the PR branch onto its base with Gitea's update endpoint.
+func (g Gitea) Rebase(ctx context.Context, owner, repo string, number int) error {
+	return g.do(ctx, "rebase PR", http.MethodPost, fmt.Sprintf("%s/pulls/%d/update?style=rebase", repoPath(owner, repo), number), nil, nil)
+}
+
+// Recreate ticks the rebase/retry checkbox Renovate adds to its PR bodies.
+func (g Gitea) Recreate(ctx context.Context, owner, repo string, number int) error {
+	var p giteaPR
+	path := fmt.Sprintf("%s/pulls/%d", repoPath(owner, repo), number)
+	if err := g.do(ctx, "get PR", http.MethodGet, path, nil, &p); err != nil {
+		return err
+	}
+	if !strings.Contains(p.Body, renovateRebaseBox) {
+		return fmt.Errorf("recreate PR failed: PR #%d has no Renovate rebase checkbox", number)
+	}
+	body := strings.Replace(p.Body, renovateRebaseBox, "- [x] <!-- rebase-check -->", 1)
+	return g.do(ctx, "recreate PR", http.MethodPatch, path, map[string]string{"body": body}, nil)
+}
+
+// Ignore does nothing: Renovate ignores an update whose PR was closed without
+// merging, so closing it is what ignores it.
+func (g Gitea) Ignore(ctx context.Context, owner, repo string, number int, updateType string) error {
+	return nil
+}
+
+func (g Gitea) Close(ctx context.Context, owner, repo string, number int) error {
+	return g.do(ctx, "close PR", http.MethodPatch, fmt.Sprintf("%s/pulls/%d", repoPath(owner, repo), number), map[string]string{"state": "closed"}, nil)
+}
+
+// MarkReady removes the work-in-progress prefix from the PR's title, which is
+// how Gitea tracks draft status.
+func (g Gitea) MarkReady(ctx context.Context, owner, repo string, number int) error {
+	path := fmt.Sprintf("%s/pulls/%d", repoPath(owner, repo), number)
+	var pr giteaPR
+	if err := g.do(ctx, "get PR", http.MethodGet, path, nil, &pr); err != nil {
+		return err
+	}
+	return g.do(ctx, "mark PR ready", http.MethodPatch, path, map[string]string{"title": stripDraftPrefix(pr.Title, giteaDraftPrefixes)}, nil)
+}
+
+// EnableAutoMerge schedules a merge for when the checks succeed, squashing
+// the PR when method is empty.
+func (g Gitea) EnableAutoMerge(ctx context.Context, owner, repo string, number int, method string) error {
+	if method == "" {
+		method = MergeSquash
+	}
+	payload := map[string]any{"Do": method, "merge_when_checks_succeed": true}
+	return g.do(ctx, "auto-merge PR", http.MethodPost, fmt.Sprintf("%s/pulls/%d/merge", repoPath(owner, repo), number), payload, nil)
+}
+
+// Merge merges the PR now, squashing it when method is empty.
+func (g Gitea) Merge(ctx context.Context, owner, repo string, number int, method string) error {
+	if method == "" {
+		method = MergeSquash
+	}
+	payload := map[string]any{"Do": method}
+	return g.do(ctx, "merge PR", http.MethodPost, fmt.Sprintf("%s/pulls/%d/merge", repoPath(owner, repo), number), payload, nil)
+}
+
+// FindClosed fetches each PR in turn: the Gitea API cannot look several up
+// by number at once.
+func (g Gitea) FindClosed(ctx context.Context, owner, repo string, numbers []int) ([]ClosedPR, error) {
+	var closed []ClosedPR
+	for _, n := range numbers {
+		var p giteaPR
+		if err := g.do(ctx, "get PR", http.MethodGet, fmt.Sprintf("%s/pulls/%d", repoPath(owner, repo), n), nil, &p); err != nil {
+			return nil, err
+		}
+		switch {
+		case p.Merged:
+			closed = append(closed, ClosedPR{Number: n, State: "MERGED"})
+		case p.State == "closed":
+			closed = append(closed, ClosedPR{Number: n, State: "CLOSED"})
+		}
+	}
+	return closed, nil
+}
+
+// BaseCIStatus reads the combined status of the default branch's head commit.
+func (g Gitea) BaseCIStatus(ctx context.Context, owner, repo string) (string, error) {
+	var r struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := g.do(ctx, "get repository", http.MethodGet, repoPath(owner, repo), nil, &r); err != nil {
+		return "", err
+	}
+	var status giteaStatus
+	if err := g.do(ctx, "get commit status", http.MethodGet, fmt.Sprintf("%s/commits/%s/status", repoPath(owner, repo), url.PathEscape(r.DefaultBranch)), nil, &status); err != nil {
+		return "", err
+	}
+	if len(status.Statuses) == 0 {
+		return "success", nil
+	}
+	ci, _ := giteaCIStatus(status)
+	return ci, nil
+}
+
+// do sends an API request and decodes the JSON response into out when it is
+// non-nil. Non-2xx responses are returned as errors including the server's
+// message. Requests other than GET are paced by the write interval and
+// refused in read-only mode.
+func (g Gitea) do(ctx context.Context, desc, method, path string, payload, out any) (err error) {
+	span := tracer.StartCall(desc,
+		tracing.String("repository", repoFromPath(path)),
+		tracing.String("http.method", method),
+		tracing.String("path", strings.SplitN(path, "?", 2)[0]),
+	)
+	defer func() { span.End(err) }()
+
+	ctx, cancel := callContext(ctx, repoFromPath(path))
+	defer cancel()
+	if method != http.MethodGet {
+		if readOnly {
+			return fmt.Errorf("%s failed: %w", desc, ErrReadOnly)
+		}
+		if err := writePacer.wait(ctx); err != nil {
+			return fmt.Errorf("%s failed: %w", desc, err)
+		}
+	}
+
+	var body io.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("%s failed: %w", desc, err)
+		}
+		body = bytes.NewReader(b)
+	}
+
+	recordCall(repoFromPath(path), CallCore)
+	req, err := http.NewRequestWithContext(ctx, method, g.BaseURL+"/api/v1/"+path, body)
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", desc, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if g.Token != "" {
+		req.Header.Set("Authorization", "token "+g.Token)
+	}
+
+	client := g.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		recordCallError(repoFromPath(path))
+		return fmt.Errorf("%s failed: %w", desc, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		recordCallError(repoFromPath(path))
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = resp.Status
+		}
+		return fmt.Errorf("%s failed: %s", desc, apiErr.Message)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to parse Gitea response: %w", err)
+		}
+	}
+	return nil
+}
+
+// repoPath returns the API route of a repository.
+func repoPath(owner, repo string) string {
+	return "repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+}
+
+// giteaCIStatus maps a combined commit status onto the CI statuses used for
+// GitHub. Like GitHub PRs without checks, a commit without statuses is pending.
+func giteaCIStatus(s giteaStatus) (string, []string) {
+	return combineChecks(giteaChecks(s))
+}
+
+// giteaChecks maps the individual commit statuses onto checks. Warnings do
+// not fail a PR.
+func giteaChecks(s giteaStatus) []Check {
+	checks := make([]Check, 0, len(s.Statuses))
+	for _, st := range s.Statuses {
+		switch st.Status {
+		case "success", "warning":
+			checks = append(checks, Check{Name: st.Context, Status: "success"})
+		case "pending":
+			checks = append(checks, Check{Name: st.Context, Status: "pending"})
+		default:
+			checks = append(checks, Check{Name: st.Context, Status: "failure"})
+		}
+	}
+	return checks
+}
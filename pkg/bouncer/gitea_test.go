@@ -0,0 +1,150 @@
+package bouncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGiteaList(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "token secret" {
+			t.Errorf("Authorization = %q, want %q", got, "token secret")
+		}
+		switch r.URL.Path {
+		case "/api/v1/repos/acme/api/pulls":
+			fmt.Fprint(w, `[
+				{"number": 1, "title": "Bump lodash from 4.17.20 to 4.17.21", "html_url": "https://git/acme/api/pulls/1",
+				 "mergeable": true, "user": {"login": "renovate"},
+				 "head": {"ref": "renovate/lodash-4.x", "sha": "aaa"}, "base": {"ref": "main"}},
+				{"number": 2, "title": "Bump left-pad from 1.0.0 to 2.0.0", "mergeable": false, "user": {"login": "renovate"},
+				 "head": {"ref": "renovate/left-pad-2.x", "sha": "bbb"}, "base": {"ref": "main"}},
+				{"number": 3, "title": "Add feature", "user": {"login": "alice"},
+				 "head": {"ref": "feature", "sha": "ccc"}, "base": {"ref": "main"}}
+			]`)
+		case "/api/v1/repos/acme/api/commits/aaa/status":
+			fmt.Fprint(w, `{"state": "success", "statuses": [{"context": "ci/build", "status": "success"}]}`)
+		case "/api/v1/repos/acme/api/commits/bbb/status":
+			fmt.Fprint(w, `{"state": "failure", "statuses": [{"context": "ci/build", "status": "failure"}]}`)
+		case "/api/v1/repos/acme/api/pulls/1/reviews":
+			fmt.Fprint(w, `[{"state": "APPROVED"}]`)
+		case "/api/v1/repos/acme/api/pulls/2/reviews":
+			fmt.Fprint(w, `[{"state": "APPROVED", "stale": true}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	g := NewGitea(srv.URL+"/", "secret", "renovate")
+	prs, err := g.List(context.Background(), DependencyUpdateQuery{Owner: "acme", Repo: "api"}, false)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(prs) != 2 {
+		t.Fatalf("List() returned %d PRs, want 2", len(prs))
+	}
+
+	if got := prs[0]; got.PackageName != "lodash" || got.CIStatus != "success" || got.ReviewDecision != "APPROVED" || got.MergeStateStatus != "CLEAN" || got.UpdateType != UpdatePatch {
+		t.Errorf("PR #1 = %+v", got)
+	}
+	if got := prs[1]; got.CIStatus != "failure" || !reflect.DeepEqual(got.CIFailures, []string{"ci/build"}) || got.ReviewDecision != "REVIEW_REQUIRED" || got.MergeStateStatus != "DIRTY" {
+		t.Errorf("PR #2 = %+v", got)
+	}
+}
+
+func TestGiteaListNumbers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/repos/acme/api/pulls":
+			t.Error("List() listed pull requests despite q.Numbers")
+		case "/api/v1/repos/acme/api/pulls/1":
+			fmt.Fprint(w, `{"number": 1, "title": "Bump lodash from 4.17.20 to 4.17.21", "state": "open", "mergeable": true,
+				"user": {"login": "renovate"}, "head": {"ref": "renovate/lodash-4.x", "sha": "aaa"}, "base": {"ref": "main"}}`)
+		case "/api/v1/repos/acme/api/pulls/2":
+			fmt.Fprint(w, `{"number": 2, "title": "Bump left-pad from 1.0.0 to 2.0.0", "state": "closed",
+				"user": {"login": "renovate"}, "head": {"ref": "renovate/left-pad-2.x", "sha": "bbb"}, "base": {"ref": "main"}}`)
+		case "/api/v1/repos/acme/api/commits/aaa/status":
+			fmt.Fprint(w, `{"state": "success", "statuses": []}`)
+		case "/api/v1/repos/acme/api/pulls/1/reviews":
+			fmt.Fprint(w, `[]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	g := NewGitea(srv.URL, "secret", "renovate")
+	prs, err := g.List(context.Background(), DependencyUpdateQuery{Owner: "acme", Repo: "api", Numbers: []int{1, 2}}, false)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(prs) != 1 || prs[0].Number != 1 || prs[0].PackageName != "lodash" {
+		t.Errorf("List() = %+v, want only the open PR #1", prs)
+	}
+}
+
+func TestGiteaReview(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/repos/acme/api/pulls/7/reviews" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		fmt.Fprint(w, `{}`)
+	}))
+	defer srv.Close()
+
+	g := NewGitea(srv.URL, "", "renovate")
+	if err := g.Comment(context.Background(), "acme", "api", 7, "LGTM"); err != nil {
+		t.Fatalf("Comment() error = %v", err)
+	}
+	if want := map[string]string{"event": "COMMENT", "body": "LGTM"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("review payload = %v, want %v", got, want)
+	}
+}
+
+func TestGiteaMerge(t *testing.T) {
+	tests := []struct {
+		method string
+		want   string
+	}{
+		{"", "squash"},
+		{MergeRebase, "rebase"},
+	}
+
+	for _, tt := range tests {
+		var got map[string]any
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || r.URL.Path != "/api/v1/repos/acme/api/pulls/7/merge" {
+				t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			}
+			json.NewDecoder(r.Body).Decode(&got)
+		}))
+
+		if err := NewGitea(srv.URL, "", "renovate").Merge(context.Background(), "acme", "api", 7, tt.method); err != nil {
+			t.Errorf("Merge(%q) error = %v", tt.method, err)
+		}
+		if want := map[string]any{"Do": tt.want}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Merge(%q) payload = %v, want %v", tt.method, got, want)
+		}
+		srv.Close()
+	}
+}
+
+func TestGiteaError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "token does not have required scope"}`)
+	}))
+	defer srv.Close()
+
+	err := NewGitea(srv.URL, "", "renovate").Approve(context.Background(), "acme", "api", 7)
+	if err == nil || err.Error() != "approve PR failed: token does not have required scope" {
+		t.Errorf("Approve() error = %v", err)
+	}
+}
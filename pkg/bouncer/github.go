@@ -0,0 +1,948 @@
+package bouncer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// statusCheck represents a single entry in statusCheckRollup.
+// gh returns two types: CheckRun (has status/conclusion) and StatusContext (has state).
+type statusCheck struct {
+	TypeName   string `json:"__typename"`
+	Name       string `json:"name"`       // CheckRun: check name (e.g. "build", "lint")
+	Status     string `json:"status"`     // CheckRun: COMPLETED, IN_PROGRESS, etc.
+	Conclusion string `json:"conclusion"` // CheckRun: SUCCESS, FAILURE, etc.
+	State      string `json:"state"`      // StatusContext: SUCCESS, FAILURE, PENDING, ERROR, EXPECTED
+	Context    string `json:"context"`    // StatusContext: context name
+}
+
+// ghPR represents a pull request as returned by `gh pr list --json`.
+type ghPR struct {
+	Number           int       `json:"number"`
+	Title            string    `json:"title"`
+	URL              string    `json:"url"`
+	MergeStateStatus string    `json:"mergeStateStatus"`
+	ReviewDecision   string    `json:"reviewDecision"`
+	IsDraft          bool      `json:"isDraft"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+	HeadRefName      string    `json:"headRefName"`
+	HeadRefOid       string    `json:"headRefOid"`
+	BaseRefName      string    `json:"baseRefName"`
+	Body             string    `json:"body"`
+	Labels           []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Author struct {
+		ID    string `json:"id"`
+		Login string `json:"login"`
+	} `json:"author"`
+	StatusCheckRollup []statusCheck `json:"statusCheckRollup"`
+	Commits           []struct {
+		MessageBody string `json:"messageBody"`
+	} `json:"commits"`
+	Reviews []struct {
+		Author struct {
+			Login string `json:"login"`
+		} `json:"author"`
+		State string `json:"state"`
+		Body  string `json:"body"`
+	} `json:"reviews"`
+}
+
+// commitMessage returns the body of the first commit message of the PR that
+// carries Dependabot's updated-dependencies metadata, or "" when none does.
+func (p ghPR) commitMessage() string {
+	for _, c := range p.Commits {
+		if metadataBlockRe.MatchString(c.MessageBody) {
+			return c.MessageBody
+		}
+	}
+	return ""
+}
+
+// reviews returns the reviews left on the PR.
+func (p ghPR) reviews() []Review {
+	var out []Review
+	for _, r := range p.Reviews {
+		out = append(out, Review{Author: r.Author.Login, State: r.State, Body: r.Body})
+	}
+	return out
+}
+
+// isSecurityUpdate reports whether the PR is labeled as a security update.
+// ListDependabotPRs additionally matches PRs against the open Dependabot
+// alerts they fix.
+func (p ghPR) isSecurityUpdate() bool {
+	for _, l := range p.Labels {
+		if strings.EqualFold(l.Name, "security") {
+			return true
+		}
+	}
+	return false
+}
+
+// maxListedPRs caps how many open PRs are fetched per repository. gh pages
+// through the results itself; the cap only guards against runaway listings.
+const maxListedPRs = 5000
+
+// ListDependabotPRs lists open PRs targeting main that were authored by the
+// configured bots (see SetBots) for the given repository, applying the
+// filters described in the query. When skipFailing is true,
+// only PRs whose CI status is "success" are returned. PRs rejected by the
+// allow or deny lists are dropped unless q.IncludeSkipped is set, in which
+// case they are returned with Skipped and SkipReason populated.
+func (g GitHub) ListDependabotPRs(ctx context.Context, q DependencyUpdateQuery, skipFailing bool) ([]PRInfo, error) {
+	ghPRs, err := g.listOpenPRs(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts map[string][]Alert
+	if len(ghPRs) > 0 {
+		if alerts, err = g.OpenAlerts(ctx, q.Owner, q.Repo); err != nil {
+			alertsWarning.Do(func() {
+				log.Printf("Warning: cannot read Dependabot alerts (%s/%s: %v); detecting security updates by label only\n", q.Owner, q.Repo, err)
+			})
+		}
+	}
+
+	var candidates []PRInfo
+	for _, p := range ghPRs {
+		if q.Stats != nil {
+			q.Stats.Open++
+		}
+		if !IsBot(p.Author.Login, p.Author.ID) {
+			if q.Stats != nil {
+				q.Stats.NotByBot++
+			}
+			continue
+		}
+		if p.BaseRefName != "main" {
+			if q.Stats != nil {
+				q.Stats.WrongBase++
+			}
+			continue
+		}
+
+		d := parseDependency(p.Title, p.HeadRefName, p.Body, p.commitMessage())
+		d.Security = d.Security || p.isSecurityUpdate()
+		for _, u := range d.packages() {
+			d.Security = d.Security || fixesAlert(alerts, u)
+		}
+		checks := githubChecks(p.StatusCheckRollup)
+		status, ciFailures := combineChecks(checks)
+
+		candidates = append(candidates, d.withPR(PRInfo{
+			Number:           p.Number,
+			Title:            p.Title,
+			URL:              p.URL,
+			MergeStateStatus: p.MergeStateStatus,
+			ReviewDecision:   p.ReviewDecision,
+			Draft:            p.IsDraft,
+			CIStatus:         status,
+			CIFailures:       ciFailures,
+			Checks:           checks,
+			CreatedAt:        p.CreatedAt,
+			UpdatedAt:        p.UpdatedAt,
+			Reviews:          p.reviews(),
+		}))
+	}
+
+	return filterPRs(candidates, q, skipFailing), nil
+}
+
+// alertsWarning logs the first failure to read Dependabot alerts only, as
+// a token without alert access fails the same way on every repository.
+var alertsWarning sync.Once
+
+// fixesAlert reports whether the update u fixes one of the open alerts on
+// its package.
+func fixesAlert(alerts map[string][]Alert, u dependency) bool {
+	for _, a := range alerts[strings.ToLower(u.Package)] {
+		if a.Fixes(u.From, u.To) {
+			return true
+		}
+	}
+	return false
+}
+
+// ghPRFields are the fields of each PR that ListDependabotPRs requests.
+const ghPRFields = "number,title,url,author,body,commits,baseRefName,headRefName,headRefOid,labels,isDraft,mergeStateStatus,reviewDecision,reviews,statusCheckRollup,createdAt,updatedAt"
+
+// listOpenPRs returns the repository's open PRs, or only the open ones among
+// q.Numbers when it is set. The full listing comes from the ETag cache when
+// one is set and GitHub reports nothing changed since it was cached.
+func (g GitHub) listOpenPRs(ctx context.Context, q DependencyUpdateQuery) ([]ghPR, error) {
+	if len(q.Numbers) > 0 {
+		var ghPRs []ghPR
+		for _, n := range q.Numbers {
+			out, err := g.output(ctx, "gh pr view", "gh", "pr", "view", strconv.Itoa(n),
+				"--repo", q.Owner+"/"+q.Repo,
+				"--json", ghPRFields+",state",
+			)
+			if err != nil {
+				return nil, err
+			}
+			var p struct {
+				ghPR
+				State string `json:"state"`
+			}
+			if err := json.Unmarshal(out, &p); err != nil {
+				return nil, fmt.Errorf("failed to parse gh output: %w", err)
+			}
+			if p.State != "OPEN" {
+				log.Printf("Skipping PR #%d: %s\n", n, strings.ToLower(p.State))
+				continue
+			}
+			ghPRs = append(ghPRs, p.ghPR)
+		}
+		return ghPRs, nil
+	}
+
+	var repoETags map[string]string
+	if g.ETags != nil {
+		var out []byte
+		var ok bool
+		if out, ok, repoETags = g.ETags.listing(ctx, g, q.Owner, q.Repo); ok {
+			var ghPRs []ghPR
+			if err := json.Unmarshal(out, &ghPRs); err == nil {
+				log.Printf("%s/%s: no changes since the last run, reusing its PR list\n", q.Owner, q.Repo)
+				return ghPRs, nil
+			}
+		}
+	}
+
+	out, err := g.output(ctx, "gh pr list", "gh", "pr", "list",
+		"--repo", q.Owner+"/"+q.Repo,
+		"--json", ghPRFields,
+		"--limit", strconv.Itoa(maxListedPRs),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var ghPRs []ghPR
+	if err := json.Unmarshal(out, &ghPRs); err != nil {
+		return nil, fmt.Errorf("failed to parse gh output: %w", err)
+	}
+	if len(ghPRs) == maxListedPRs {
+		log.Printf("Warning: %s/%s has more than %d open PRs; only the first %d were listed\n", q.Owner, q.Repo, maxListedPRs, maxListedPRs)
+	}
+	if g.ETags != nil {
+		if err := g.ETags.store(ctx, g, q.Owner, q.Repo, out, ghPRs, repoETags); err != nil {
+			log.Printf("Warning: %v\n", err)
+		}
+	}
+	return ghPRs, nil
+}
+
+// GetPR fetches a single pull request by number, regardless of its author or
+// state. Deny lists are not applied.
+func (g GitHub) GetPR(ctx context.Context, owner, repo string, number int) (PRInfo, error) {
+	out, err := g.output(ctx, "gh pr view", "gh", "pr", "view", fmt.Sprintf("%d", number),
+		"--repo", owner+"/"+repo,
+		"--json", "number,title,url,body,commits,state,headRefName,headRefOid,labels,isDraft,mergeStateStatus,reviewDecision,reviews,statusCheckRollup,createdAt,updatedAt",
+	)
+	if err != nil {
+		return PRInfo{}, err
+	}
+
+	var p struct {
+		ghPR
+		State      string `json:"state"`
+		HeadRefOid string `json:"headRefOid"`
+	}
+	if err := json.Unmarshal(out, &p); err != nil {
+		return PRInfo{}, fmt.Errorf("failed to parse gh output: %w", err)
+	}
+
+	d := parseDependency(p.Title, p.HeadRefName, p.Body, p.commitMessage())
+	d.Security = d.Security || p.isSecurityUpdate()
+	checks := githubChecks(p.StatusCheckRollup)
+	status, ciFailures := combineChecks(checks)
+	return d.withPR(PRInfo{
+		Number:           p.Number,
+		Title:            p.Title,
+		URL:              p.URL,
+		State:            p.State,
+		HeadSHA:          p.HeadRefOid,
+		MergeStateStatus: p.MergeStateStatus,
+		ReviewDecision:   p.ReviewDecision,
+		Draft:            p.IsDraft,
+		CIStatus:         status,
+		CIFailures:       ciFailures,
+		Checks:           checks,
+		CreatedAt:        p.CreatedAt,
+		UpdatedAt:        p.UpdatedAt,
+	}), nil
+}
+
+// ClosedPR identifies a pull request that is no longer open.
+type ClosedPR struct {
+	Number int    `json:"number"`
+	State  string `json:"state"` // CLOSED or MERGED
+}
+
+// prStatesBatch is how many pull requests one FindClosedPRs query looks up.
+const prStatesBatch = 100
+
+// FindClosedPRs returns the pull requests among numbers that have been closed
+// or merged. It looks them up with one GraphQL query per 100 numbers.
+func (g GitHub) FindClosedPRs(ctx context.Context, owner, repo string, numbers []int) ([]ClosedPR, error) {
+	var closed []ClosedPR
+	for batch := range slices.Chunk(numbers, prStatesBatch) {
+		out, err := g.output(ctx, "check PR states", "gh", "api", "graphql",
+			"-f", "query="+prStatesQuery(batch),
+			"-f", "owner="+owner,
+			"-f", "name="+repo,
+		)
+		if err != nil {
+			return nil, err
+		}
+		states, err := parsePRStates(out)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range batch {
+			if state, ok := states[n]; ok && state != "OPEN" {
+				closed = append(closed, ClosedPR{Number: n, State: state})
+			}
+		}
+	}
+	return closed, nil
+}
+
+// prStatesQuery returns a GraphQL query for the state of each pull request
+// among numbers, aliased pr<number>.
+func prStatesQuery(numbers []int) string {
+	var b strings.Builder
+	b.WriteString("query($owner: String!, $name: String!) {\n  repository(owner: $owner, name: $name) {\n")
+	for _, n := range numbers {
+		fmt.Fprintf(&b, "    pr%d: pullRequest(number: %d) { state }\n", n, n)
+	}
+	b.WriteString("  }\n}")
+	return b.String()
+}
+
+// parsePRStates returns the states in a response to prStatesQuery, by PR
+// number.
+func parsePRStates(out []byte) (map[int]string, error) {
+	var resp struct {
+		Data struct {
+			Repository map[string]*struct {
+				State string `json:"state"`
+			} `json:"repository"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse PR states: %w", err)
+	}
+	states := make(map[int]string, len(resp.Data.Repository))
+	for alias, pr := range resp.Data.Repository {
+		n, err := strconv.Atoi(strings.TrimPrefix(alias, "pr"))
+		if err != nil || pr == nil {
+			continue
+		}
+		states[n] = pr.State
+	}
+	return states, nil
+}
+
+// ciStatus determines the overall CI status from a statusCheckRollup.
+// Returns "pending" if there are no checks or any check is still running,
+// "failure" if any check failed, "success" otherwise.
+func ciStatus(checks []statusCheck) (string, []string) {
+	return combineChecks(githubChecks(checks))
+}
+
+// githubChecks maps a statusCheckRollup onto checks.
+//
+// The rollup contains two types: CheckRun (status/conclusion) and
+// StatusContext (state). Skipped and neutral check runs are reported as
+// skipped, which counts as passing.
+func githubChecks(rollup []statusCheck) []Check {
+	checks := make([]Check, 0, len(rollup))
+	for _, c := range rollup {
+		if c.TypeName == "StatusContext" {
+			name := c.Context
+			if name == "" {
+				name = "status check"
+			}
+			switch c.State {
+			case "SUCCESS":
+				checks = append(checks, Check{Name: name, Status: "success"})
+			case "PENDING", "EXPECTED":
+				checks = append(checks, Check{Name: name, Status: "pending"})
+			default:
+				checks = append(checks, Check{Name: name, Status: "failure"})
+			}
+			continue
+		}
+
+		// CheckRun
+		name := c.Name
+		if name == "" {
+			name = "check run"
+		}
+		switch {
+		case c.Status != "COMPLETED":
+			checks = append(checks, Check{Name: name, Status: "pending"})
+		case c.Conclusion == "SUCCESS":
+			checks = append(checks, Check{Name: name, Status: "success"})
+		case c.Conclusion == "SKIPPED", c.Conclusion == "NEUTRAL":
+			checks = append(checks, Check{Name: name, Status: "skipped"})
+		default:
+			checks = append(checks, Check{Name: name, Status: "failure"})
+		}
+	}
+	return checks
+}
+
+// Review events accepted by ReviewPR.
+const (
+	ReviewApprove = "approve"
+	ReviewComment = "comment"
+)
+
+// ApprovePR approves a pull request.
+func (g GitHub) ApprovePR(ctx context.Context, owner, repo string, number int) error {
+	return g.ReviewPR(ctx, owner, repo, number, ReviewApprove, "")
+}
+
+// ReviewPR submits a review on a pull request with the given event
+// (ReviewApprove or ReviewComment) and optional body. A comment review
+// requires a body.
+func (g GitHub) ReviewPR(ctx context.Context, owner, repo string, number int, event, body string) error {
+	args := []string{"gh", "pr", "review", "--" + event,
+		"--repo", owner + "/" + repo, fmt.Sprintf("%d", number)}
+	if body != "" {
+		args = append(args, "--body", body)
+	}
+	if !isDependabotCommand(body) {
+		return g.command(ctx, event+" PR", args...)
+	}
+	// Dependabot answers in a comment of its own, so the newest comment
+	// before the review marks where its reply would start.
+	after, err := g.latestCommentID(ctx, owner, repo, number)
+	if err != nil {
+		log.Printf("Warning: cannot watch for Dependabot's reply on %s/%s#%d: %v\n", owner, repo, number, err)
+		return g.command(ctx, event+" PR", args...)
+	}
+	if err := g.command(ctx, event+" PR", args...); err != nil {
+		return err
+	}
+	return g.awaitDependabotReply(ctx, event+" PR", owner, repo, number, after)
+}
+
+// Merge methods, in order of preference.
+const (
+	MergeSquash = "squash"
+	MergeMerge  = "merge"
+	MergeRebase = "rebase"
+)
+
+var (
+	mergeMethodsMu sync.Mutex
+	mergeMethods   = make(map[string][]string) // "host/owner/repo" -> allowed merge methods
+)
+
+// AllowedMergeMethods returns the merge methods the repository allows, in
+// order of preference. Results are cached for the lifetime of the process.
+func (g GitHub) AllowedMergeMethods(ctx context.Context, owner, repo string) ([]string, error) {
+	key := githubHost(g.Host) + "/" + owner + "/" + repo
+	mergeMethodsMu.Lock()
+	defer mergeMethodsMu.Unlock()
+	if methods, ok := mergeMethods[key]; ok {
+		return methods, nil
+	}
+
+	out, err := g.output(ctx, "gh repo view", "gh", "repo", "view", owner+"/"+repo,
+		"--json", "squashMergeAllowed,mergeCommitAllowed,rebaseMergeAllowed")
+	if err != nil {
+		return nil, err
+	}
+	var r struct {
+		Squash bool `json:"squashMergeAllowed"`
+		Merge  bool `json:"mergeCommitAllowed"`
+		Rebase bool `json:"rebaseMergeAllowed"`
+	}
+	if err := json.Unmarshal(out, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse gh output: %w", err)
+	}
+
+	var methods []string
+	if r.Squash {
+		methods = append(methods, MergeSquash)
+	}
+	if r.Merge {
+		methods = append(methods, MergeMerge)
+	}
+	if r.Rebase {
+		methods = append(methods, MergeRebase)
+	}
+	mergeMethods[key] = methods
+	return methods, nil
+}
+
+// pickMergeMethod returns want when the repository allows it, or the most
+// preferred allowed merge method when want is empty.
+func pickMergeMethod(allowed []string, want string) (string, error) {
+	if len(allowed) == 0 {
+		return "", fmt.Errorf("the repository allows no merge method")
+	}
+	if want == "" {
+		return allowed[0], nil
+	}
+	if !slices.Contains(allowed, want) {
+		return "", fmt.Errorf("the repository does not allow %s merges (allowed: %s)", want, strings.Join(allowed, ", "))
+	}
+	return want, nil
+}
+
+// AutoMergePR enables auto-merge on a pull request with the given merge
+// method, or when method is empty with squash if the repository allows it
+// and otherwise a merge commit or rebase. A method the repository does not
+// allow is refused before calling GitHub.
+func (g GitHub) AutoMergePR(ctx context.Context, owner, repo string, number int, method string) error {
+	allowed, err := g.AllowedMergeMethods(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+	method, err = pickMergeMethod(allowed, method)
+	if err != nil {
+		return fmt.Errorf("cannot auto-merge PR #%d: %w", number, err)
+	}
+	err = g.command(ctx, "auto-merge PR", "gh", "pr", "merge", "--auto", "--"+method,
+		"--repo", owner+"/"+repo, fmt.Sprintf("%d", number))
+	if err != nil && isAutoMergeDisabled(err.Error()) {
+		return fmt.Errorf("failed to auto-merge PR: %w", ErrAutoMergeDisabled)
+	}
+	return err
+}
+
+// ErrAutoMergeDisabled is returned by AutoMergePR when the repository does
+// not allow auto-merge.
+var ErrAutoMergeDisabled = errors.New("auto-merge is disabled for the repository")
+
+// isAutoMergeDisabled reports whether a gh error says the repository does
+// not allow auto-merge.
+func isAutoMergeDisabled(msg string) bool {
+	return strings.Contains(strings.ToLower(msg), "auto merge is not allowed")
+}
+
+// MergePR merges a pull request right away with the given merge method, or
+// with the most preferred method the repository allows when method is empty.
+// A method the repository does not allow is refused before calling GitHub.
+func (g GitHub) MergePR(ctx context.Context, owner, repo string, number int, method string) error {
+	allowed, err := g.AllowedMergeMethods(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+	if method, err = pickMergeMethod(allowed, method); err != nil {
+		return fmt.Errorf("cannot merge PR #%d: %w", number, err)
+	}
+	return g.command(ctx, "merge PR", "gh", "pr", "merge", "--"+method,
+		"--repo", owner+"/"+repo, fmt.Sprintf("%d", number))
+}
+
+// CommentPR submits a comment review on a pull request.
+func (g GitHub) CommentPR(ctx context.Context, owner, repo string, number int, body string) error {
+	return g.ReviewPR(ctx, owner, repo, number, ReviewComment, body)
+}
+
+// ClosePR closes a pull request without merging it.
+func (g GitHub) ClosePR(ctx context.Context, owner, repo string, number int) error {
+	return g.command(ctx, "close PR", "gh", "pr", "close",
+		"--repo", owner+"/"+repo, fmt.Sprintf("%d", number))
+}
+
+// MarkPRReady marks a draft pull request as ready for review.
+func (g GitHub) MarkPRReady(ctx context.Context, owner, repo string, number int) error {
+	return g.command(ctx, "mark PR ready", "gh", "pr", "ready",
+		"--repo", owner+"/"+repo, fmt.Sprintf("%d", number))
+}
+
+// RebasePR tells Dependabot to rebase a pull request. It fails with
+// ErrCommandRejected when Dependabot replies that it will not.
+func (g GitHub) RebasePR(ctx context.Context, owner, repo string, number int) error {
+	return g.postDependabotCommand(ctx, "rebase PR", owner, repo, number, "rebase")
+}
+
+// RecreatePR tells Dependabot to recreate a pull request. It fails with
+// ErrCommandRejected when Dependabot replies that it will not.
+func (g GitHub) RecreatePR(ctx context.Context, owner, repo string, number int) error {
+	return g.postDependabotCommand(ctx, "recreate PR", owner, repo, number, "recreate")
+}
+
+// IgnorePR tells Dependabot to stop proposing the dependency a PR updates, or
+// only its typ (major, minor, or patch) updates when typ is not empty. It
+// fails with ErrCommandRejected when Dependabot replies that it will not.
+func (g GitHub) IgnorePR(ctx context.Context, owner, repo string, number int, typ string) error {
+	command := "ignore this dependency"
+	if typ != "" {
+		command = fmt.Sprintf("ignore this %s version", typ)
+	}
+	return g.postDependabotCommand(ctx, "ignore PR", owner, repo, number, command)
+}
+
+// output runs a gh CLI command (or glab, for the GitLab provider) and
+// returns its stdout. On failure the error includes the command's stderr so
+// the cause is visible to the user. Calls that fail transiently or are
+// refused by a GitHub rate limit are retried (see retryCall).
+func (g GitHub) output(ctx context.Context, desc string, args ...string) (_ []byte, err error) {
+	span := startCallSpan(desc, args)
+	defer func() { span.End(err) }()
+
+	ctx, cancel := callContext(ctx, repoFromArgs(args))
+	defer cancel()
+	for attempt := 0; ; attempt++ {
+		cmd, token, err := g.exec(ctx, args...)
+		if err != nil {
+			return nil, fmt.Errorf("%s failed: %w", desc, err)
+		}
+		out, err := runCall(ctx, cmd, false)
+		if err == nil {
+			return out, nil
+		}
+		var msg string
+		exitErr, isExit := err.(*exec.ExitError)
+		if isExit {
+			msg = strings.TrimSpace(string(exitErr.Stderr))
+			if g.retryCall(ctx, args, token, msg, attempt) {
+				continue
+			}
+		}
+		recordCallError(repoFromArgs(args))
+		if ctxErr := context.Cause(ctx); ctxErr != nil {
+			return nil, fmt.Errorf("%s failed: %w", desc, ctxErr)
+		}
+		if isExit {
+			return nil, fmt.Errorf("%s failed: %s%s", desc, msg, permissionHint(args, msg))
+		}
+		return nil, fmt.Errorf("%s failed: %w", desc, err)
+	}
+}
+
+// command runs a gh CLI command and returns a descriptive error on failure.
+// Calls that fail transiently or are refused by a GitHub rate limit are
+// retried (see retryCall).
+func (g GitHub) command(ctx context.Context, desc string, args ...string) (err error) {
+	span := startCallSpan(desc, args)
+	defer func() { span.End(err) }()
+
+	ctx, cancel := callContext(ctx, repoFromArgs(args))
+	defer cancel()
+	for attempt := 0; ; attempt++ {
+		cmd, token, err := g.exec(ctx, args...)
+		if err != nil {
+			return fmt.Errorf("failed to %s: %w", desc, err)
+		}
+		out, err := runCall(ctx, cmd, true)
+		if err == nil {
+			return nil
+		}
+		msg := strings.TrimSpace(string(out))
+		if g.retryCall(ctx, args, token, msg, attempt) {
+			continue
+		}
+		recordCallError(repoFromArgs(args))
+		if ctxErr := context.Cause(ctx); ctxErr != nil {
+			return fmt.Errorf("failed to %s: %w", desc, ctxErr)
+		}
+		return fmt.Errorf("failed to %s: %s%s", desc, msg, permissionHint(args, msg))
+	}
+}
+
+// titlePrefix matches what tools and repositories put before the verb of a
+// dependency update title: an emoji, a "[deps]" tag, and a conventional
+// commit type such as "build(deps-dev):" or "⬆️ (deps):", in any combination.
+const titlePrefix = `^(?:[^\w\s(\[]+\s*)?(?:\[[^\]]*\]\s*)?(?:\w*(?:\([^)]*\))?!?:\s*)?`
+
+// titlePatterns match the package name of dependency update titles, most
+// specific first.
+var titlePatterns = []*regexp.Regexp{
+	// "Bump the aws-sdk-go-v2 group with N updates"
+	regexp.MustCompile(`(?i)` + titlePrefix + `bump\s+the\s+(\S+)\s+group\b`),
+	// "Bump package from x to y", "Bump package to y", and requirement updates:
+	// "Update requests requirement from <2.29,>=2.28 to >=2.28,<2.32"
+	regexp.MustCompile(`(?i)` + titlePrefix + `(?:bump|update)\s+(\S+)\s+(?:requirement\s+)?(?:from|to)\s`),
+	// "chore(deps): bump package from x to y" with other text before the verb
+	regexp.MustCompile(`(?i)^chore.*\bbump\s+(\S+)\s+(?:from|to)\s`),
+	// Renovate: "Update dependency lodash to v4.17.21", "fix(deps): update module github.com/foo/bar to v1.2.3",
+	// "Update rust crate serde to 1.0.200"
+	regexp.MustCompile(`(?i)` + titlePrefix + `update\s+(?:dependency|module|(?:rust\s+)?crate|gem|package)\s+(\S+)\s+to\s`),
+	// Renovate: "Update golang Docker tag to v1.22", "Update actions/checkout action to v4"
+	regexp.MustCompile(`(?i)` + titlePrefix + `update\s+(\S+)\s+(?:docker\s+tag|docker\s+digest|action|digest)\b`),
+}
+
+// extractPackageInfo extracts package name and organization from a Dependabot PR title
+// Examples:
+// "Bump github.com/datadog/datadog-go from 1.0.0 to 2.0.0" -> "github.com/datadog/datadog-go", "datadog"
+// "build(deps-dev): bump @datadog/browser-rum from 4.0.0 to 5.0.0 in /web" -> "@datadog/browser-rum", "datadog"
+// "Bump uvicorn[standard] from 0.23.0 to 0.24.0" -> "uvicorn", ""
+// "Update rails to 7.0.0" -> "rails", ""
+func extractPackageInfo(title string) (packageName string, orgName string) {
+	title = strings.TrimSpace(title)
+	for _, re := range titlePatterns {
+		if matches := re.FindStringSubmatch(title); matches != nil {
+			packageName = matches[1]
+			break
+		}
+	}
+
+	if packageName == "" {
+		// Fallback: try to extract any package-like string
+		if parts := strings.Fields(title); len(parts) > 1 {
+			for _, part := range parts[1:] {
+				if strings.Contains(part, "/") || strings.Contains(part, "@") {
+					packageName = part
+					break
+				}
+			}
+		}
+	}
+
+	packageName = strings.Trim(packageName, "`'\"")
+	// Python extras: "uvicorn[standard]" is the uvicorn package
+	if i := strings.Index(packageName, "["); i > 0 && strings.HasSuffix(packageName, "]") {
+		packageName = packageName[:i]
+	}
+	return packageName, packageOrg(packageName)
+}
+
+// packageOrg returns the organization of a package, or "" when it has
+// none, e.g. "datadog" for "@datadog/browser-rum" and
+// "github.com/datadog/datadog-go".
+func packageOrg(packageName string) (orgName string) {
+	// Handle scoped npm packages like @datadog/browser-rum
+	if strings.HasPrefix(packageName, "@") && strings.Contains(packageName, "/") {
+		parts := strings.Split(packageName, "/")
+		orgName = strings.TrimPrefix(parts[0], "@")
+	} else if group, _, ok := strings.Cut(packageName, ":"); ok && !strings.Contains(packageName, "/") {
+		// Maven and Gradle coordinates like com.fasterxml.jackson.core:jackson-databind;
+		// the group ID is the org
+		orgName = group
+	} else if strings.Contains(packageName, "/") {
+		// Special case for golang.org/x and google.golang.org packages - they don't have an org
+		if strings.HasPrefix(packageName, "golang.org/x/") || strings.HasPrefix(packageName, "google.golang.org/") {
+			orgName = ""
+		} else if strings.HasPrefix(packageName, "gopkg.in/") {
+			// gopkg.in packages can have orgs like gopkg.in/DataDog/dd-trace-go.v1
+			// Extract the org from the second part if it exists
+			parts := strings.Split(packageName, "/")
+			if len(parts) > 2 {
+				// gopkg.in/DataDog/dd-trace-go.v1 -> DataDog
+				orgName = strings.ToLower(parts[1])
+			} else {
+				orgName = ""
+			}
+		} else {
+			// Handle GitHub-style packages like github.com/datadog/datadog-go
+			parts := strings.Split(packageName, "/")
+			// For github.com/owner/repo or github.com/owner/repo/v2
+			// We want the owner (second part)
+			if len(parts) >= 3 && strings.HasPrefix(packageName, "github.com/") {
+				orgName = parts[1]
+			} else if len(parts) == 2 && !strings.Contains(parts[0], ".") {
+				// Composer packages like laravel/framework; the vendor is the org
+				orgName = parts[0]
+			} else {
+				// Fallback for other patterns
+				for i, part := range parts {
+					// Skip domain parts and version indicators
+					if i > 0 && !strings.Contains(part, ".") && !strings.HasPrefix(part, "v") {
+						orgName = part
+						break
+					}
+				}
+			}
+		}
+	}
+	return orgName
+}
+
+// regexPrefix marks a package entry that is a regular expression rather
+// than a name, version, or wildcard pattern.
+const regexPrefix = "re:"
+
+// entryRegexps caches the compiled regular expressions of "re:" entries by
+// entry, so each is compiled once.
+var entryRegexps sync.Map
+
+// entryRegexp compiles the regular expression of a "re:" entry, or returns
+// it from the cache. It is matched case-insensitively, like other entries.
+func entryRegexp(entry string) (*regexp.Regexp, error) {
+	if re, ok := entryRegexps.Load(entry); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile("(?i)" + strings.TrimPrefix(entry, regexPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression in deny entry %q: %w", entry, err)
+	}
+	entryRegexps.Store(entry, re)
+	return re, nil
+}
+
+// ValidateDenyEntry returns an error when a package entry's regular
+// expression or version constraint cannot be parsed. Valid regular
+// expressions are compiled and cached for matching.
+func ValidateDenyEntry(entry string) error {
+	if strings.HasPrefix(entry, regexPrefix) {
+		_, err := entryRegexp(entry)
+		return err
+	}
+	_, _, _, err := parseVersionConstraint(entry)
+	return err
+}
+
+// isDenied checks if a package or organization is in the deny list
+func isDenied(packageName, orgName string, deniedPackages, deniedOrgs []string) bool {
+	return deniedEntry(packageName, orgName, deniedPackages, deniedOrgs) != ""
+}
+
+// deniedEntry returns the first deny list entry matching a package or
+// organization, or "" when none does.
+func deniedEntry(packageName, orgName string, deniedPackages, deniedOrgs []string) string {
+	// Check if package is denied
+	for _, denied := range deniedPackages {
+		// Regular expressions (re:...) match anywhere in the name unless
+		// anchored; invalid ones never match
+		if strings.HasPrefix(denied, regexPrefix) {
+			if re, err := entryRegexp(denied); err == nil && packageName != "" && re.MatchString(packageName) {
+				return denied
+			}
+			continue
+		}
+
+		// Handle wildcard patterns (leading and/or trailing * only)
+		if strings.Contains(denied, "*") {
+			pattern := strings.ToLower(denied)
+			pkg := strings.ToLower(packageName)
+
+			hasPrefix := strings.HasPrefix(pattern, "*")
+			hasSuffix := strings.HasSuffix(pattern, "*")
+			inner := strings.Trim(pattern, "*")
+
+			switch {
+			case hasPrefix && hasSuffix:
+				// *foo* → contains
+				if strings.Contains(pkg, inner) {
+					return denied
+				}
+			case hasPrefix:
+				// *foo → suffix match
+				if strings.HasSuffix(pkg, inner) {
+					return denied
+				}
+			case hasSuffix:
+				// foo* → prefix match
+				if strings.HasPrefix(pkg, inner) {
+					return denied
+				}
+			}
+			continue
+		}
+
+		// Exact match (case insensitive)
+		if strings.EqualFold(packageName, denied) {
+			return denied
+		}
+
+		// Check if it's a partial match (for versioned denials like github.com/gin-gonic/gin@v1)
+		// But don't match if the denied package is a substring of a different package
+		// e.g., don't match aws-sdk-go-v2 when aws-sdk-go is denied
+		if strings.Contains(denied, "@") {
+			// Version-specific denial
+			if strings.Contains(strings.ToLower(packageName), strings.ToLower(denied)) {
+				return denied
+			}
+		} else {
+			// For non-versioned denials, check for exact package name match
+			// This prevents aws-sdk-go from matching aws-sdk-go-v2
+			deniedLower := strings.ToLower(denied)
+			pkgLower := strings.ToLower(packageName)
+
+			// Check if they're the same package (not just a substring)
+			if pkgLower == deniedLower {
+				return denied
+			}
+
+			// Also check with common version suffixes removed for comparison
+			// This allows "github.com/gin-gonic/gin@v1.7.0" to match "github.com/gin-gonic/gin@v1"
+			if idx := strings.Index(pkgLower, "@"); idx > 0 {
+				pkgBase := pkgLower[:idx]
+				if pkgBase == deniedLower {
+					return denied
+				}
+			}
+		}
+	}
+
+	// Check if organization is denied
+	for _, denied := range deniedOrgs {
+		if orgMatches(packageName, orgName, denied) {
+			return denied
+		}
+	}
+
+	return ""
+}
+
+// orgMatches reports whether an organization entry matches a package of
+// orgName. The entry is an organization name or glob, such as "*-labs" or
+// "internal-*", matched case-insensitively; in the combined form
+// "org/package", such as "datadog/*-beta", the package part must also match
+// the rest of the package name after the organization, by the rules of
+// package entries. Packages without an organization match no entry.
+func orgMatches(packageName, orgName, entry string) bool {
+	if orgName == "" {
+		return false
+	}
+	orgPattern, pkgPattern, combined := strings.Cut(entry, "/")
+	if ok, err := path.Match(strings.ToLower(orgPattern), strings.ToLower(orgName)); err != nil || !ok {
+		return false
+	}
+	if !combined {
+		return true
+	}
+	return isDenied(orgRelativeName(packageName, orgName), "", []string{pkgPattern}, nil)
+}
+
+// orgRelativeName returns the part of a package name after its
+// organization: "browser-rum" for "@datadog/browser-rum", "dd-trace-go" for
+// "github.com/datadog/dd-trace-go", and "jackson-databind" for
+// "com.fasterxml.jackson.core:jackson-databind".
+func orgRelativeName(packageName, orgName string) string {
+	lower, org := strings.ToLower(packageName), strings.ToLower(orgName)
+	for _, sep := range []string{"/", ":"} {
+		if i := strings.Index(lower, org+sep); i >= 0 && (i == 0 || strings.ContainsRune("@/", rune(lower[i-1]))) {
+			return packageName[i+len(org)+len(sep):]
+		}
+	}
+	return packageName
+}
+
+// ValidateOrgEntry returns an error when an organization entry's glob is
+// malformed.
+func ValidateOrgEntry(entry string) error {
+	orgPattern, pkgPattern, combined := strings.Cut(entry, "/")
+	if _, err := path.Match(orgPattern, ""); err != nil {
+		return fmt.Errorf("invalid organization pattern in deny entry %q: %w", entry, err)
+	}
+	if combined {
+		return ValidateDenyEntry(pkgPattern)
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
-package scm
+package bouncer
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -157,6 +159,223 @@ func TestExtractPackageInfo(t *testing.T) {
 			expectedPackage: "github.com/vektra/mockery/v2",
 			expectedOrg:     "vektra",
 		},
+		// Renovate formats
+		{
+			name:            "Renovate dependency",
+			title:           "Update dependency lodash to v4.17.21",
+			expectedPackage: "lodash",
+			expectedOrg:     "",
+		},
+		{
+			name:            "Renovate scoped npm package with semantic prefix",
+			title:           "chore(deps): update dependency @datadog/browser-rum to v5 [SECURITY]",
+			expectedPackage: "@datadog/browser-rum",
+			expectedOrg:     "datadog",
+		},
+		{
+			name:            "Renovate Go module",
+			title:           "fix(deps): update module github.com/hashicorp/consul to v1.11.0",
+			expectedPackage: "github.com/hashicorp/consul",
+			expectedOrg:     "hashicorp",
+		},
+		{
+			name:            "Renovate Docker tag",
+			title:           "Update golang Docker tag to v1.22",
+			expectedPackage: "golang",
+			expectedOrg:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg, org := extractPackageInfo(tt.title)
+			if pkg != tt.expectedPackage {
+				t.Errorf("extractPackageInfo() package = %v, want %v", pkg, tt.expectedPackage)
+			}
+			if org != tt.expectedOrg {
+				t.Errorf("extractPackageInfo() org = %v, want %v", org, tt.expectedOrg)
+			}
+		})
+	}
+}
+
+func TestExtractPackageInfoMaven(t *testing.T) {
+	tests := []struct {
+		name            string
+		title           string
+		expectedPackage string
+		expectedOrg     string
+	}{
+		{
+			name:            "Maven coordinate",
+			title:           "Bump com.fasterxml.jackson.core:jackson-databind from 2.15.0 to 2.15.1",
+			expectedPackage: "com.fasterxml.jackson.core:jackson-databind",
+			expectedOrg:     "com.fasterxml.jackson.core",
+		},
+		{
+			name:            "Maven coordinate in subproject",
+			title:           "Bump org.springframework.boot:spring-boot-starter-web from 3.1.0 to 3.1.5 in /backend",
+			expectedPackage: "org.springframework.boot:spring-boot-starter-web",
+			expectedOrg:     "org.springframework.boot",
+		},
+		{
+			name:            "Gradle coordinate in nested subproject",
+			title:           "Bump io.netty:netty-codec-http from 4.1.94.Final to 4.1.100.Final in /services/gateway",
+			expectedPackage: "io.netty:netty-codec-http",
+			expectedOrg:     "io.netty",
+		},
+		{
+			name:            "Conventional commit prefix",
+			title:           "chore(deps): bump com.google.guava:guava from 32.0.0-jre to 32.1.3-jre in /app",
+			expectedPackage: "com.google.guava:guava",
+			expectedOrg:     "com.google.guava",
+		},
+		{
+			name:            "Renovate Maven dependency",
+			title:           "Update dependency org.apache.commons:commons-lang3 to v3.14.0",
+			expectedPackage: "org.apache.commons:commons-lang3",
+			expectedOrg:     "org.apache.commons",
+		},
+		{
+			name:            "Gradle plugin ID without group",
+			title:           "Bump org.jetbrains.kotlin.jvm from 1.9.0 to 1.9.10",
+			expectedPackage: "org.jetbrains.kotlin.jvm",
+			expectedOrg:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg, org := extractPackageInfo(tt.title)
+			if pkg != tt.expectedPackage {
+				t.Errorf("extractPackageInfo() package = %v, want %v", pkg, tt.expectedPackage)
+			}
+			if org != tt.expectedOrg {
+				t.Errorf("extractPackageInfo() org = %v, want %v", org, tt.expectedOrg)
+			}
+		})
+	}
+}
+
+func TestExtractPackageInfoCargoComposer(t *testing.T) {
+	tests := []struct {
+		name            string
+		title           string
+		expectedPackage string
+		expectedOrg     string
+	}{
+		{
+			name:            "Cargo crate",
+			title:           "Bump serde from 1.0.190 to 1.0.193",
+			expectedPackage: "serde",
+			expectedOrg:     "",
+		},
+		{
+			name:            "Cargo crate in workspace member",
+			title:           "Bump tokio from 1.32.0 to 1.33.0 in /crates/api",
+			expectedPackage: "tokio",
+			expectedOrg:     "",
+		},
+		{
+			name:            "Cargo crate with hyphen",
+			title:           "chore(deps): bump wasm-bindgen from 0.2.87 to 0.2.88",
+			expectedPackage: "wasm-bindgen",
+			expectedOrg:     "",
+		},
+		{
+			name:            "Composer package",
+			title:           "Bump laravel/framework from 10.28.0 to 10.30.1",
+			expectedPackage: "laravel/framework",
+			expectedOrg:     "laravel",
+		},
+		{
+			name:            "Composer package in subdirectory",
+			title:           "Bump symfony/http-kernel from 6.3.5 to 6.3.8 in /app",
+			expectedPackage: "symfony/http-kernel",
+			expectedOrg:     "symfony",
+		},
+		{
+			name:            "Renovate Composer dependency",
+			title:           "Update dependency guzzlehttp/guzzle to v7.8.1",
+			expectedPackage: "guzzlehttp/guzzle",
+			expectedOrg:     "guzzlehttp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg, org := extractPackageInfo(tt.title)
+			if pkg != tt.expectedPackage {
+				t.Errorf("extractPackageInfo() package = %v, want %v", pkg, tt.expectedPackage)
+			}
+			if org != tt.expectedOrg {
+				t.Errorf("extractPackageInfo() org = %v, want %v", org, tt.expectedOrg)
+			}
+		})
+	}
+}
+
+func TestExtractPackageInfoEcosystems(t *testing.T) {
+	tests := []struct {
+		name            string
+		title           string
+		expectedPackage string
+		expectedOrg     string
+	}{
+		{
+			name:            "Scoped npm package with dev prefix and directory",
+			title:           "build(deps-dev): bump @typescript-eslint/parser from 6.7.0 to 6.8.0 in /web",
+			expectedPackage: "@typescript-eslint/parser",
+			expectedOrg:     "typescript-eslint",
+		},
+		{
+			name:            "npm requirement update",
+			title:           "Update @types/node requirement from ^18.0.0 to ^20.0.0",
+			expectedPackage: "@types/node",
+			expectedOrg:     "types",
+		},
+		{
+			name:            "GitHub Action",
+			title:           "deps: bump actions/checkout from 3 to 4",
+			expectedPackage: "actions/checkout",
+			expectedOrg:     "actions",
+		},
+		{
+			name:            "GitHub Action in workflow directory",
+			title:           "⬆️ Bump github/codeql-action from 2 to 3 in /.github/workflows",
+			expectedPackage: "github/codeql-action",
+			expectedOrg:     "github",
+		},
+		{
+			name:            "Docker image digest",
+			title:           "Bump node from `1a2b3c4` to `5d6e7f8` in /docker",
+			expectedPackage: "node",
+			expectedOrg:     "",
+		},
+		{
+			name:            "Docker image tag",
+			title:           "[docker] Bump golang from 1.21-alpine to 1.22-alpine",
+			expectedPackage: "golang",
+			expectedOrg:     "",
+		},
+		{
+			name:            "Python requirement update",
+			title:           "Update requests requirement from <2.29,>=2.28 to >=2.28,<2.32",
+			expectedPackage: "requests",
+			expectedOrg:     "",
+		},
+		{
+			name:            "Python extras",
+			title:           "build(deps): bump uvicorn[standard] from 0.23.0 to 0.24.0 in /api",
+			expectedPackage: "uvicorn",
+			expectedOrg:     "",
+		},
+		{
+			name:            "Maven with fix prefix",
+			title:           "fix(deps): bump org.postgresql:postgresql from 42.6.0 to 42.7.2",
+			expectedPackage: "org.postgresql:postgresql",
+			expectedOrg:     "org.postgresql",
+		},
 	}
 
 	for _, tt := range tests {
@@ -363,9 +582,11 @@ func TestRealWorldDenials(t *testing.T) {
 	// Test with real-world PR titles and deny lists
 	deniedPackages := []string{
 		"github.com/aws/aws-sdk-go", // Deny v1, but not v2
+		"serde",
 	}
 	deniedOrgs := []string{
 		"datadog",
+		"laravel",
 	}
 
 	tests := []struct {
@@ -393,6 +614,21 @@ func TestRealWorldDenials(t *testing.T) {
 			shouldDeny: false,
 			reason:     "Redis client should be allowed",
 		},
+		{
+			prTitle:    "Bump laravel/framework from 10.28.0 to 10.30.1 in /app",
+			shouldDeny: true,
+			reason:     "Composer packages of the laravel vendor should be denied",
+		},
+		{
+			prTitle:    "Bump serde from 1.0.190 to 1.0.193",
+			shouldDeny: true,
+			reason:     "serde crate should be denied",
+		},
+		{
+			prTitle:    "Bump serde_json from 1.0.107 to 1.0.108",
+			shouldDeny: false,
+			reason:     "serde_json crate should be allowed",
+		},
 	}
 
 	for _, tt := range tests {
@@ -557,6 +793,191 @@ func TestWildcardPatterns(t *testing.T) {
 	}
 }
 
+func TestRegexPatterns(t *testing.T) {
+	tests := []struct {
+		name        string
+		pattern     string
+		packageName string
+		shouldMatch bool
+	}{
+		{
+			name:        "Anchored prefix",
+			pattern:     `re:^github\.com/internal-.*`,
+			packageName: "github.com/internal-tools/cli",
+			shouldMatch: true,
+		},
+		{
+			name:        "Anchored prefix no match",
+			pattern:     `re:^github\.com/internal-.*`,
+			packageName: "github.com/acme/internal-tools",
+			shouldMatch: false,
+		},
+		{
+			name:        "Release candidate suffix",
+			pattern:     `re:-rc\.?[0-9]*$`,
+			packageName: "github.com/example/app-rc1",
+			shouldMatch: true,
+		},
+		{
+			name:        "Release candidate does not match search",
+			pattern:     `re:-rc\.?[0-9]*$`,
+			packageName: "github.com/example/search",
+			shouldMatch: false,
+		},
+		{
+			name:        "Unanchored matches anywhere",
+			pattern:     `re:jwt`,
+			packageName: "github.com/dgrijalva/jwt-go",
+			shouldMatch: true,
+		},
+		{
+			name:        "Case insensitive",
+			pattern:     `re:^@DataDog/`,
+			packageName: "@datadog/browser-rum",
+			shouldMatch: true,
+		},
+		{
+			name:        "Invalid expression never matches",
+			pattern:     `re:(`,
+			packageName: "(",
+			shouldMatch: false,
+		},
+		{
+			name:        "Unknown package",
+			pattern:     `re:.*`,
+			packageName: "",
+			shouldMatch: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := isDenied(tt.packageName, "", []string{tt.pattern}, nil)
+			if result != tt.shouldMatch {
+				t.Errorf("Pattern %s match for %s = %v, want %v",
+					tt.pattern, tt.packageName, result, tt.shouldMatch)
+			}
+		})
+	}
+}
+
+func TestOrgPatterns(t *testing.T) {
+	tests := []struct {
+		name        string
+		pattern     string
+		packageName string
+		shouldMatch bool
+	}{
+		{
+			name:        "Exact org",
+			pattern:     "DataDog",
+			packageName: "github.com/datadog/datadog-go",
+			shouldMatch: true,
+		},
+		{
+			name:        "Suffix glob",
+			pattern:     "*-labs",
+			packageName: "github.com/acme-labs/toolkit",
+			shouldMatch: true,
+		},
+		{
+			name:        "Suffix glob no match",
+			pattern:     "*-labs",
+			packageName: "github.com/labs/toolkit",
+			shouldMatch: false,
+		},
+		{
+			name:        "Prefix glob on npm scope",
+			pattern:     "internal-*",
+			packageName: "@internal-ui/buttons",
+			shouldMatch: true,
+		},
+		{
+			name:        "Combined org and package",
+			pattern:     "datadog/*-beta",
+			packageName: "@datadog/browser-rum-beta",
+			shouldMatch: true,
+		},
+		{
+			name:        "Combined org and package no match",
+			pattern:     "datadog/*-beta",
+			packageName: "@datadog/browser-rum",
+			shouldMatch: false,
+		},
+		{
+			name:        "Combined form needs the org",
+			pattern:     "datadog/*-beta",
+			packageName: "github.com/elastic/apm-beta",
+			shouldMatch: false,
+		},
+		{
+			name:        "Combined exact package",
+			pattern:     "DataDog/dd-trace-go.v1",
+			packageName: "gopkg.in/DataDog/dd-trace-go.v1",
+			shouldMatch: true,
+		},
+		{
+			name:        "Combined glob org with package prefix",
+			pattern:     "hashi*/terraform-*",
+			packageName: "github.com/hashicorp/terraform-plugin-sdk/v2",
+			shouldMatch: true,
+		},
+		{
+			name:        "Combined Maven group",
+			pattern:     "com.fasterxml.*/jackson-data*",
+			packageName: "com.fasterxml.jackson.core:jackson-databind",
+			shouldMatch: true,
+		},
+		{
+			name:        "Glob never matches a package without an org",
+			pattern:     "*",
+			packageName: "golang.org/x/net",
+			shouldMatch: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, org := extractPackageInfo("Bump " + tt.packageName + " from 1.0.0 to 1.1.0")
+			result := isDenied(tt.packageName, org, nil, []string{tt.pattern})
+			if result != tt.shouldMatch {
+				t.Errorf("Org pattern %s match for %s (org %q) = %v, want %v",
+					tt.pattern, tt.packageName, org, result, tt.shouldMatch)
+			}
+		})
+	}
+}
+
+func TestValidateOrgEntry(t *testing.T) {
+	for entry, wantErr := range map[string]bool{
+		"datadog":        false,
+		"*-labs":         false,
+		"datadog/*-beta": false,
+		"[a-":            true,
+		"datadog/re:[a-": true,
+	} {
+		if err := ValidateOrgEntry(entry); (err != nil) != wantErr {
+			t.Errorf("ValidateOrgEntry(%q) = %v, want error %v", entry, err, wantErr)
+		}
+	}
+}
+
+func TestValidateDenyEntry(t *testing.T) {
+	for entry, wantErr := range map[string]bool{
+		"github.com/pkg/errors":     false,
+		"*rc*":                      false,
+		`re:^github\.com/internal-`: false,
+		"re:[a-":                    true,
+		"re:(?<name>x)":             false,
+		"react@>=19":                false,
+		"react@>=latest":            true,
+	} {
+		if err := ValidateDenyEntry(entry); (err != nil) != wantErr {
+			t.Errorf("ValidateDenyEntry(%q) = %v, want error %v", entry, err, wantErr)
+		}
+	}
+}
+
 func TestCIStatus(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -646,6 +1067,22 @@ func TestCIStatus(t *testing.T) {
 			},
 			want: "success",
 		},
+		{
+			name: "mixed with CheckRun still running",
+			checks: []statusCheck{
+				{TypeName: "CheckRun", Status: "QUEUED"},
+				{TypeName: "StatusContext", State: "SUCCESS"},
+			},
+			want: "pending",
+		},
+		{
+			name: "mixed with CheckRun failure and StatusContext pending",
+			checks: []statusCheck{
+				{TypeName: "CheckRun", Status: "COMPLETED", Conclusion: "TIMED_OUT"},
+				{TypeName: "StatusContext", State: "PENDING"},
+			},
+			want: "failure",
+		},
 		{
 			name: "mixed with StatusContext failure",
 			checks: []statusCheck{
@@ -665,3 +1102,71 @@ func TestCIStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestIsAutoMergeDisabled(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want bool
+	}{
+		{"failed to auto-merge PR: GraphQL: Pull request Auto merge is not allowed for this repository (enablePullRequestAutoMerge)", true},
+		{"failed to auto-merge PR: GraphQL: Pull request is in clean status (enablePullRequestAutoMerge)", false},
+		{"failed to auto-merge PR: HTTP 403", false},
+	}
+
+	for _, tt := range tests {
+		if got := isAutoMergeDisabled(tt.msg); got != tt.want {
+			t.Errorf("isAutoMergeDisabled(%q) = %v, want %v", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestPickMergeMethod(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		want    string
+		wantGot string
+		wantErr bool
+	}{
+		{"preferred allowed", []string{MergeMerge, MergeRebase}, "", MergeMerge, false},
+		{"configured and allowed", []string{MergeSquash, MergeRebase}, MergeRebase, MergeRebase, false},
+		{"configured but not allowed", []string{MergeSquash}, MergeRebase, "", true},
+		{"nothing allowed", nil, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pickMergeMethod(tt.allowed, tt.want)
+			if got != tt.wantGot || (err != nil) != tt.wantErr {
+				t.Errorf("pickMergeMethod(%q, %q) = %q, %v, want %q (error: %v)", tt.allowed, tt.want, got, err, tt.wantGot, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPRStatesQuery(t *testing.T) {
+	q := prStatesQuery([]int{7, 12})
+	for _, want := range []string{
+		"repository(owner: $owner, name: $name)",
+		"pr7: pullRequest(number: 7) { state }",
+		"pr12: pullRequest(number: 12) { state }",
+	} {
+		if !strings.Contains(q, want) {
+			t.Errorf("prStatesQuery() = %q, want it to contain %q", q, want)
+		}
+	}
+}
+
+func TestParsePRStates(t *testing.T) {
+	out := []byte(`{"data": {"repository": {"pr7": {"state": "MERGED"}, "pr12": {"state": "OPEN"}, "pr30": null}}}`)
+	got, err := parsePRStates(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[int]string{7: "MERGED", 12: "OPEN"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePRStates() = %v, want %v", got, want)
+	}
+	if _, err := parsePRStates([]byte("not json")); err == nil {
+		t.Error("parsePRStates() error = nil, want an error for bad output")
+	}
+}
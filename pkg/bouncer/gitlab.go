@@ -0,0 +1,547 @@
+package bouncer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultGitLabAuthor is the username dependabot-gitlab opens merge requests
+// as when none is configured.
+const defaultGitLabAuthor = "dependabot-bot"
+
+// GitLab is the Client backed by the glab CLI. It works with merge requests
+// opened by Dependabot-style bots such as dependabot-gitlab; authentication
+// and host selection are handled by glab (glab auth login, GITLAB_HOST).
+type GitLab struct {
+	Author string // username of the bot that opens the merge requests
+}
+
+// glMR represents a merge request as returned by the GitLab API.
+type glMR struct {
+	IID          int    `json:"iid"`
+	Title        string `json:"title"`
+	WebURL       string `json:"web_url"`
+	State        string `json:"state"` // opened, closed, merged, locked
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	Author       struct {
+		Username string `json:"username"`
+	} `json:"author"`
+	Labels              []string  `json:"labels"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+	Draft               bool      `json:"draft"`
+	HasConflicts        bool      `json:"has_conflicts"`
+	DetailedMergeStatus string    `json:"detailed_merge_status"`
+	HeadPipeline        *struct {
+		Status string `json:"status"`
+	} `json:"head_pipeline"`
+}
+
+// projectPath returns the URL-encoded project path used in API routes.
+func projectPath(owner, repo string) string {
+	return "projects/" + url.PathEscape(owner+"/"+repo)
+}
+
+// glOutput and glCommand run glab calls through the runner gh calls use; the
+// GitHub host and token of a GitHub client only apply to gh.
+func glOutput(ctx context.Context, desc string, args ...string) ([]byte, error) {
+	return GitHub{}.output(ctx, desc, args...)
+}
+
+func glCommand(ctx context.Context, desc string, args ...string) error {
+	return GitHub{}.command(ctx, desc, args...)
+}
+
+// mrPath returns the API route of a merge request.
+func mrPath(owner, repo string, number int) string {
+	return fmt.Sprintf("%s/merge_requests/%d", projectPath(owner, repo), number)
+}
+
+// List lists open merge requests by the bot targeting main, or only the ones
+// among q.Numbers. The REST listing omits pipeline status and approvals, so
+// merge requests are read through GraphQL instead, 100 per call.
+func (g GitLab) List(ctx context.Context, q DependencyUpdateQuery, skipFailing bool) ([]PRInfo, error) {
+	var nodes []glNode
+	if len(q.Numbers) == 0 {
+		filter := fmt.Sprintf(`state: opened, targetBranches: ["main"], authorUsername: %q`, g.Author)
+		page, err := g.queryMRs(ctx, q.Owner, q.Repo, filter)
+		if err != nil {
+			return nil, err
+		}
+		nodes = page
+	}
+	for batch := range slices.Chunk(q.Numbers, 100) {
+		iids := make([]string, len(batch))
+		for i, n := range batch {
+			iids[i] = strconv.Quote(strconv.Itoa(n))
+		}
+		page, err := g.queryMRs(ctx, q.Owner, q.Repo, "iids: ["+strings.Join(iids, ", ")+"]")
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, page...)
+	}
+
+	// When listing, author and target branch are filtered by the API, so
+	// only the merge requests that pass them are counted in q.Stats.
+	var candidates []PRInfo
+	for _, n := range nodes {
+		mr, err := n.mr()
+		if err != nil {
+			return nil, err
+		}
+		if mr.State != "opened" {
+			log.Printf("Skipping MR !%d: %s\n", mr.IID, mr.State)
+			continue
+		}
+		if q.Stats != nil {
+			q.Stats.Open++
+		}
+		if mr.Author.Username != g.Author {
+			if q.Stats != nil {
+				q.Stats.NotByBot++
+			}
+			continue
+		}
+		if mr.TargetBranch != "main" {
+			if q.Stats != nil {
+				q.Stats.WrongBase++
+			}
+			continue
+		}
+
+		d := parseDependency(mr.Title, mr.SourceBranch, "", "")
+		d.Security = d.Security || hasLabel(mr.Labels, "security")
+		status, ciFailures := glCIStatus(mr)
+		review := "REVIEW_REQUIRED"
+		if n.Approved {
+			review = "APPROVED"
+		}
+
+		candidates = append(candidates, d.withPR(PRInfo{
+			Number:           mr.IID,
+			Title:            mr.Title,
+			URL:              mr.WebURL,
+			MergeStateStatus: glMergeState(mr),
+			ReviewDecision:   review,
+			Draft:            mr.Draft,
+			CIStatus:         status,
+			CIFailures:       ciFailures,
+			Checks:           glChecks(mr),
+			CreatedAt:        mr.CreatedAt,
+			UpdatedAt:        mr.UpdatedAt,
+		}))
+	}
+
+	return filterPRs(candidates, q, skipFailing), nil
+}
+
+// glMRsQuery reads a page of a project's merge requests matching a filter
+// (the arguments of the mergeRequests field) after a cursor, or null for the
+// first page.
+const glMRsQuery = `query {
+  project(fullPath: %q) {
+    mergeRequests(%s, first: 100, after: %s) {
+      nodes {
+        iid title webUrl state sourceBranch targetBranch
+        author { username }
+        labels { nodes { title } }
+        createdAt updatedAt draft conflicts detailedMergeStatus
+        headPipeline { status }
+        approved
+      }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+// glNode is a merge request as returned by glMRsQuery.
+type glNode struct {
+	IID          string `json:"iid"`
+	Title        string `json:"title"`
+	WebURL       string `json:"webUrl"`
+	State        string `json:"state"`
+	SourceBranch string `json:"sourceBranch"`
+	TargetBranch string `json:"targetBranch"`
+	Author       struct {
+		Username string `json:"username"`
+	} `json:"author"`
+	Labels struct {
+		Nodes []struct {
+			Title string `json:"title"`
+		} `json:"nodes"`
+	} `json:"labels"`
+	CreatedAt           time.Time `json:"createdAt"`
+	UpdatedAt           time.Time `json:"updatedAt"`
+	Draft               bool      `json:"draft"`
+	Conflicts           bool      `json:"conflicts"`
+	DetailedMergeStatus string    `json:"detailedMergeStatus"`
+	HeadPipeline        *struct {
+		Status string `json:"status"`
+	} `json:"headPipeline"`
+	Approved bool `json:"approved"`
+}
+
+// mr converts the node to its REST form. GraphQL spells the merge and
+// pipeline statuses in upper case.
+func (n glNode) mr() (glMR, error) {
+	iid, err := strconv.Atoi(n.IID)
+	if err != nil {
+		return glMR{}, fmt.Errorf("failed to parse glab output: bad iid %q", n.IID)
+	}
+	mr := glMR{
+		IID:                 iid,
+		Title:               n.Title,
+		WebURL:              n.WebURL,
+		State:               n.State,
+		SourceBranch:        n.SourceBranch,
+		TargetBranch:        n.TargetBranch,
+		CreatedAt:           n.CreatedAt,
+		UpdatedAt:           n.UpdatedAt,
+		Draft:               n.Draft,
+		HasConflicts:        n.Conflicts,
+		DetailedMergeStatus: strings.ToLower(n.DetailedMergeStatus),
+	}
+	mr.Author.Username = n.Author.Username
+	for _, l := range n.Labels.Nodes {
+		mr.Labels = append(mr.Labels, l.Title)
+	}
+	if n.HeadPipeline != nil {
+		mr.HeadPipeline = &struct {
+			Status string `json:"status"`
+		}{Status: strings.ToLower(n.HeadPipeline.Status)}
+	}
+	return mr, nil
+}
+
+// queryMRs returns every merge request of a project matching filter, one
+// GraphQL call per 100.
+func (g GitLab) queryMRs(ctx context.Context, owner, repo, filter string) ([]glNode, error) {
+	var all []glNode
+	after := "null"
+	for {
+		out, err := glOutput(ctx, "glab api", "glab", "api", "graphql",
+			"-f", "query="+fmt.Sprintf(glMRsQuery, owner+"/"+repo, filter, after))
+		if err != nil {
+			return nil, err
+		}
+		nodes, next, err := parseMRsPage(out)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, nodes...)
+		if next == "" {
+			return all, nil
+		}
+		after = strconv.Quote(next)
+	}
+}
+
+// parseMRsPage returns the merge requests in a response to glMRsQuery and the
+// cursor of the next page, or "" on the last one.
+func parseMRsPage(out []byte) ([]glNode, string, error) {
+	var resp struct {
+		Data struct {
+			Project *struct {
+				MergeRequests struct {
+					Nodes    []glNode `json:"nodes"`
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+				} `json:"mergeRequests"`
+			} `json:"project"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, "", fmt.Errorf("failed to parse glab output: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		return nil, "", fmt.Errorf("glab api graphql: %s", resp.Errors[0].Message)
+	}
+	if resp.Data.Project == nil {
+		return nil, "", errors.New("glab api graphql: project not found")
+	}
+	mrs := resp.Data.Project.MergeRequests
+	if !mrs.PageInfo.HasNextPage {
+		return mrs.Nodes, "", nil
+	}
+	return mrs.Nodes, mrs.PageInfo.EndCursor, nil
+}
+
+func (g GitLab) get(ctx context.Context, owner, repo string, number int) (glMR, error) {
+	out, err := glOutput(ctx, "glab api", "glab", "api", mrPath(owner, repo, number))
+	if err != nil {
+		return glMR{}, err
+	}
+	var mr glMR
+	if err := json.Unmarshal(out, &mr); err != nil {
+		return glMR{}, fmt.Errorf("failed to parse glab output: %w", err)
+	}
+	return mr, nil
+}
+
+func (g GitLab) Approve(ctx context.Context, owner, repo string, number int) error {
+	return glCommand(ctx, "approve MR", "glab", "api", "-X", "POST", mrPath(owner, repo, number)+"/approve")
+}
+
+func (g GitLab) Comment(ctx context.Context, owner, repo string, number int, body string) error {
+	return glCommand(ctx, "comment on MR", "glab", "api", "-X", "POST", mrPath(owner, repo, number)+"/notes",
+		"-f", "body="+body)
+}
+
+// Rebase uses GitLab's rebase endpoint rather than a bot command.
+func (g GitLab) Rebase(ctx context.Context, owner, repo string, number int) error {
+	return glCommand(ctx, "rebase MR", "glab", "api", "-X", "PUT", mrPath(owner, repo, number)+"/rebase")
+}
+
+// Recreate asks dependabot-gitlab to recreate the merge request.
+func (g GitLab) Recreate(ctx context.Context, owner, repo string, number int) error {
+	return g.Comment(ctx, owner, repo, number, "$dependabot recreate")
+}
+
+// Ignore does nothing: dependabot-gitlab does not reopen an update whose
+// merge request was closed, so closing it is what ignores it.
+func (g GitLab) Ignore(ctx context.Context, owner, repo string, number int, updateType string) error {
+	return nil
+}
+
+func (g GitLab) Close(ctx context.Context, owner, repo string, number int) error {
+	return glCommand(ctx, "close MR", "glab", "api", "-X", "PUT", mrPath(owner, repo, number),
+		"-f", "state_event=close")
+}
+
+// EnableAutoMerge sets the merge request to merge when its pipeline
+// succeeds, squashing its commits unless method is MergeMerge. As with
+// Merge, MergeRebase is refused. GitLab merges right away when no pipeline
+// is running, so, as GitHub does for a PR in clean status, that is refused
+// too.
+func (g GitLab) EnableAutoMerge(ctx context.Context, owner, repo string, number int, method string) error {
+	if method == MergeRebase {
+		return fmt.Errorf("cannot auto-merge MR !%d: GitLab merge requests cannot be rebase-merged through the API", number)
+	}
+	mr, err := g.get(ctx, owner, repo, number)
+	if err != nil {
+		return err
+	}
+	if err := glAutoMergeable(mr); err != nil {
+		return fmt.Errorf("cannot auto-merge MR !%d: %w", number, err)
+	}
+	return glCommand(ctx, "auto-merge MR", "glab", "api", "-X", "PUT", mrPath(owner, repo, number)+"/merge",
+		"-F", "merge_when_pipeline_succeeds=true",
+		"-F", fmt.Sprintf("squash=%t", method != MergeMerge))
+}
+
+// Merge merges the merge request now, squashing its commits unless method is
+// MergeMerge. GitLab sets fast-forward and rebase merges per project, so
+// MergeRebase is refused.
+func (g GitLab) Merge(ctx context.Context, owner, repo string, number int, method string) error {
+	if method == MergeRebase {
+		return fmt.Errorf("cannot merge MR !%d: GitLab merge requests cannot be rebase-merged through the API", number)
+	}
+	return glCommand(ctx, "merge MR", "glab", "api", "-X", "PUT", mrPath(owner, repo, number)+"/merge",
+		"-F", fmt.Sprintf("squash=%t", method != MergeMerge))
+}
+
+// MarkReady removes the draft marker from the merge request's title, which is
+// how GitLab tracks draft status.
+func (g GitLab) MarkReady(ctx context.Context, owner, repo string, number int) error {
+	mr, err := g.get(ctx, owner, repo, number)
+	if err != nil {
+		return err
+	}
+	return glCommand(ctx, "mark MR ready", "glab", "api", "-X", "PUT", mrPath(owner, repo, number),
+		"-f", "title="+stripDraftPrefix(mr.Title, glDraftPrefixes))
+}
+
+// FindClosed looks the merge requests up with one call per 100 iids.
+func (g GitLab) FindClosed(ctx context.Context, owner, repo string, numbers []int) ([]ClosedPR, error) {
+	mrs, err := g.listByIID(ctx, owner, repo, numbers)
+	if err != nil {
+		return nil, err
+	}
+	return glClosed(mrs), nil
+}
+
+// listByIID returns the merge requests among iids, in any state, with one
+// call per 100 iids. The listing omits head_pipeline.
+func (g GitLab) listByIID(ctx context.Context, owner, repo string, iids []int) ([]glMR, error) {
+	var all []glMR
+	for batch := range slices.Chunk(iids, 100) {
+		route := projectPath(owner, repo) + "/merge_requests?state=all&per_page=100"
+		for _, iid := range batch {
+			route += fmt.Sprintf("&iids%%5B%%5D=%d", iid)
+		}
+		out, err := glOutput(ctx, "glab api", "glab", "api", "--paginate", route)
+		if err != nil {
+			return nil, err
+		}
+		mrs, err := decodePages[glMR](out)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse glab output: %w", err)
+		}
+		all = append(all, mrs...)
+	}
+	return all, nil
+}
+
+// glClosed returns the merged, closed and locked merge requests among mrs,
+// ordered by iid.
+func glClosed(mrs []glMR) []ClosedPR {
+	var closed []ClosedPR
+	for _, mr := range mrs {
+		switch mr.State {
+		case "merged":
+			closed = append(closed, ClosedPR{Number: mr.IID, State: "MERGED"})
+		case "closed", "locked":
+			closed = append(closed, ClosedPR{Number: mr.IID, State: "CLOSED"})
+		}
+	}
+	slices.SortFunc(closed, func(a, b ClosedPR) int { return a.Number - b.Number })
+	return closed
+}
+
+// BaseCIStatus reads the latest pipeline on the project's default branch.
+func (g GitLab) BaseCIStatus(ctx context.Context, owner, repo string) (string, error) {
+	out, err := glOutput(ctx, "glab api", "glab", "api", projectPath(owner, repo))
+	if err != nil {
+		return "", err
+	}
+	var project struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := json.Unmarshal(out, &project); err != nil {
+		return "", fmt.Errorf("failed to parse glab output: %w", err)
+	}
+
+	out, err = glOutput(ctx, "glab api", "glab", "api",
+		projectPath(owner, repo)+"/pipelines?per_page=1&ref="+url.QueryEscape(project.DefaultBranch))
+	if err != nil {
+		return "", err
+	}
+	var pipelines []struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(out, &pipelines); err != nil {
+		return "", fmt.Errorf("failed to parse glab output: %w", err)
+	}
+	if len(pipelines) == 0 {
+		return "success", nil
+	}
+	mr := glMR{}
+	mr.HeadPipeline = &pipelines[0]
+	status, _ := glCIStatus(mr)
+	return status, nil
+}
+
+// glCIStatus maps a merge request's head pipeline onto the CI statuses used
+// for GitHub. Like GitHub PRs without checks, a missing pipeline is pending.
+func glCIStatus(mr glMR) (string, []string) {
+	if mr.HeadPipeline == nil {
+		return "pending", nil
+	}
+	switch mr.HeadPipeline.Status {
+	case "success", "skipped":
+		return "success", nil
+	case "failed", "canceled":
+		return "failure", []string{"pipeline " + mr.HeadPipeline.Status}
+	default:
+		return "pending", nil
+	}
+}
+
+// glAutoMergeable returns an error unless the merge request's head pipeline
+// is still running, the only state in which merge_when_pipeline_succeeds
+// waits for it rather than merging now.
+func glAutoMergeable(mr glMR) error {
+	if mr.HeadPipeline == nil {
+		return errors.New("no pipeline to wait for")
+	}
+	if status, _ := glCIStatus(mr); status != "pending" {
+		return fmt.Errorf("pipeline already finished (%s)", mr.HeadPipeline.Status)
+	}
+	return nil
+}
+
+// glChecks reports the head pipeline as a single check named "pipeline";
+// the merge request API does not expose individual jobs.
+func glChecks(mr glMR) []Check {
+	if mr.HeadPipeline == nil {
+		return nil
+	}
+	status, _ := glCIStatus(mr)
+	return []Check{{Name: "pipeline", Status: status}}
+}
+
+// glMergeState maps a merge request's merge status onto GitHub's
+// mergeStateStatus values, so approve handles conflicts and stale branches
+// the same way on both providers.
+func glMergeState(mr glMR) string {
+	switch {
+	case mr.Draft:
+		return "DRAFT"
+	case mr.HasConflicts:
+		return "DIRTY"
+	}
+	switch mr.DetailedMergeStatus {
+	case "mergeable":
+		return "CLEAN"
+	case "need_rebase":
+		return "BEHIND"
+	case "ci_must_pass", "ci_still_running":
+		return "UNSTABLE"
+	default:
+		return "BLOCKED"
+	}
+}
+
+// glDraftPrefixes are the title prefixes GitLab treats as marking a draft.
+var glDraftPrefixes = []string{"Draft:", "[Draft]", "(Draft)", "Draft -", "WIP:", "[WIP]"}
+
+// stripDraftPrefix removes a leading draft marker (case-insensitively) from
+// a title.
+func stripDraftPrefix(title string, prefixes []string) string {
+	for _, p := range prefixes {
+		if len(title) >= len(p) && strings.EqualFold(title[:len(p)], p) {
+			return strings.TrimSpace(title[len(p):])
+		}
+	}
+	return title
+}
+
+// decodePages decodes paginated API output, which is a sequence of JSON arrays
+// (one per page) rather than a single array.
+func decodePages[T any](out []byte) ([]T, error) {
+	var all []T
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for dec.More() {
+		var page []T
+		if err := dec.Decode(&page); err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+	}
+	return all, nil
+}
+
+func hasLabel(labels []string, name string) bool {
+	for _, l := range labels {
+		if strings.EqualFold(l, name) {
+			return true
+		}
+	}
+	return false
+}
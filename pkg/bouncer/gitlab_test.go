@@ -0,0 +1,182 @@
+package bouncer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGLMergeState(t *testing.T) {
+	tests := []struct {
+		name string
+		mr   glMR
+		want string
+	}{
+		{name: "mergeable", mr: glMR{DetailedMergeStatus: "mergeable"}, want: "CLEAN"},
+		{name: "conflicts", mr: glMR{HasConflicts: true, DetailedMergeStatus: "broken_status"}, want: "DIRTY"},
+		{name: "needs rebase", mr: glMR{DetailedMergeStatus: "need_rebase"}, want: "BEHIND"},
+		{name: "pipeline running", mr: glMR{DetailedMergeStatus: "ci_still_running"}, want: "UNSTABLE"},
+		{name: "draft wins", mr: glMR{Draft: true, HasConflicts: true}, want: "DRAFT"},
+		{name: "not approved", mr: glMR{DetailedMergeStatus: "not_approved"}, want: "BLOCKED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := glMergeState(tt.mr); got != tt.want {
+				t.Errorf("glMergeState() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGLCIStatus(t *testing.T) {
+	pipeline := func(status string) glMR {
+		mr := glMR{}
+		mr.HeadPipeline = &struct {
+			Status string `json:"status"`
+		}{Status: status}
+		return mr
+	}
+
+	tests := []struct {
+		name         string
+		mr           glMR
+		wantStatus   string
+		wantFailures []string
+	}{
+		{name: "no pipeline", mr: glMR{}, wantStatus: "pending"},
+		{name: "success", mr: pipeline("success"), wantStatus: "success"},
+		{name: "skipped", mr: pipeline("skipped"), wantStatus: "success"},
+		{name: "failed", mr: pipeline("failed"), wantStatus: "failure", wantFailures: []string{"pipeline failed"}},
+		{name: "running", mr: pipeline("running"), wantStatus: "pending"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, failures := glCIStatus(tt.mr)
+			if status != tt.wantStatus || !reflect.DeepEqual(failures, tt.wantFailures) {
+				t.Errorf("glCIStatus() = %q, %v, want %q, %v", status, failures, tt.wantStatus, tt.wantFailures)
+			}
+		})
+	}
+}
+
+func TestProjectPath(t *testing.T) {
+	if got, want := mrPath("group", "api", 7), "projects/group%2Fapi/merge_requests/7"; got != want {
+		t.Errorf("mrPath() = %q, want %q", got, want)
+	}
+}
+
+func TestDecodePages(t *testing.T) {
+	got, err := decodePages[glMR]([]byte(`[{"iid": 1}, {"iid": 2}]
+[{"iid": 3}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[2].IID != 3 {
+		t.Errorf("decodePages() = %+v, want 3 merge requests", got)
+	}
+}
+
+func TestStripDraftPrefix(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Draft: Bump lodash from 4.17.20 to 4.17.21", "Bump lodash from 4.17.20 to 4.17.21"},
+		{"[draft] Bump lodash", "Bump lodash"},
+		{"WIP: Bump lodash", "Bump lodash"},
+		{"Bump drafts from 1.0.0 to 1.1.0", "Bump drafts from 1.0.0 to 1.1.0"},
+	}
+
+	for _, tt := range tests {
+		if got := stripDraftPrefix(tt.title, glDraftPrefixes); got != tt.want {
+			t.Errorf("stripDraftPrefix(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestGLClosed(t *testing.T) {
+	mrs := []glMR{
+		{IID: 9, State: "closed"},
+		{IID: 3, State: "merged"},
+		{IID: 4, State: "opened"},
+		{IID: 5, State: "locked"},
+	}
+	want := []ClosedPR{{Number: 3, State: "MERGED"}, {Number: 5, State: "CLOSED"}, {Number: 9, State: "CLOSED"}}
+	if got := glClosed(mrs); !reflect.DeepEqual(got, want) {
+		t.Errorf("glClosed() = %+v, want %+v", got, want)
+	}
+}
+
+func TestGLAutoMergeable(t *testing.T) {
+	pipeline := func(status string) glMR {
+		mr := glMR{}
+		mr.HeadPipeline = &struct {
+			Status string `json:"status"`
+		}{Status: status}
+		return mr
+	}
+
+	tests := []struct {
+		name    string
+		mr      glMR
+		wantErr bool
+	}{
+		{name: "running", mr: pipeline("running")},
+		{name: "pending", mr: pipeline("pending")},
+		{name: "no pipeline", mr: glMR{}, wantErr: true},
+		{name: "succeeded", mr: pipeline("success"), wantErr: true},
+		{name: "failed", mr: pipeline("failed"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := glAutoMergeable(tt.mr); (err != nil) != tt.wantErr {
+				t.Errorf("glAutoMergeable() error = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseMRsPage(t *testing.T) {
+	out := []byte(`{"data": {"project": {"mergeRequests": {
+		"nodes": [{
+			"iid": "7", "title": "Bump lodash from 4.17.20 to 4.17.21", "state": "opened",
+			"targetBranch": "main", "author": {"username": "dependabot-bot"},
+			"labels": {"nodes": [{"title": "security"}]},
+			"conflicts": true, "detailedMergeStatus": "NEED_REBASE",
+			"headPipeline": {"status": "SUCCESS"}, "approved": true
+		}],
+		"pageInfo": {"hasNextPage": true, "endCursor": "abc"}
+	}}}}`)
+	nodes, next, err := parseMRsPage(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != "abc" || len(nodes) != 1 || !nodes[0].Approved {
+		t.Fatalf("parseMRsPage() = %+v, %q", nodes, next)
+	}
+	mr, err := nodes[0].mr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mr.IID != 7 || mr.Author.Username != "dependabot-bot" || !reflect.DeepEqual(mr.Labels, []string{"security"}) {
+		t.Errorf("mr() = %+v", mr)
+	}
+	if got := glMergeState(mr); got != "DIRTY" {
+		t.Errorf("glMergeState() = %q, want DIRTY", got)
+	}
+	if status, _ := glCIStatus(mr); status != "success" {
+		t.Errorf("glCIStatus() = %q, want success", status)
+	}
+
+	last := []byte(`{"data": {"project": {"mergeRequests": {"nodes": [], "pageInfo": {"hasNextPage": false, "endCursor": "abc"}}}}}`)
+	if _, next, err := parseMRsPage(last); err != nil || next != "" {
+		t.Errorf("parseMRsPage() on the last page = %q, %v, want no cursor", next, err)
+	}
+	for _, bad := range []string{`{"data": {"project": null}}`, `{"errors": [{"message": "boom"}]}`, `not json`} {
+		if _, _, err := parseMRsPage([]byte(bad)); err == nil {
+			t.Errorf("parseMRsPage(%s) error = nil, want an error", bad)
+		}
+	}
+}
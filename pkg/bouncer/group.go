@@ -0,0 +1,93 @@
+package bouncer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Update is one dependency updated by a grouped update PR.
+type Update struct {
+	Package    string `json:"package"`
+	Org        string `json:"org,omitempty"`
+	From       string `json:"from_version,omitempty"`
+	To         string `json:"to_version,omitempty"`
+	UpdateType string `json:"update_type,omitempty"`
+}
+
+// groupTitleRe matches the titles of Dependabot grouped updates, such as
+// "Bump the aws-sdk-go-v2 group with 4 updates" or "chore(deps): bump the
+// npm_and_yarn group across 2 directories with 3 updates", and captures the
+// group name.
+var groupTitleRe = regexp.MustCompile(`(?i)\bbump\s+the\s+(\S+)\s+group\b`)
+
+// groupUpdateRes match the updates listed in the body of a grouped update
+// PR: one "Updates `pkg` from 1.0 to 1.1" line per update, or, for larger
+// groups, a table with a "| [pkg](url) | `1.0` | `1.1` |" row per update.
+var groupUpdateRes = []*regexp.Regexp{
+	regexp.MustCompile("(?m)^Updates `([^`]+)` from (\\S+) to (\\S+)"),
+	regexp.MustCompile("(?m)^\\|\\s*\\[([^\\]]+)\\]\\([^)]*\\)\\s*\\|\\s*`([^`]+)`\\s*\\|\\s*`([^`]+)`\\s*\\|"),
+}
+
+// parseGroup returns the group name and updates of a grouped update PR, or
+// ok false when the title is not that of a grouped update or its body lists
+// no updates. An update listed several times, e.g. once per directory, is
+// returned once.
+func parseGroup(title, body string) (group string, updates []dependency, ok bool) {
+	m := groupTitleRe.FindStringSubmatch(title)
+	if m == nil {
+		return "", nil, false
+	}
+	seen := make(map[string]bool)
+	for _, re := range groupUpdateRes {
+		for _, u := range re.FindAllStringSubmatch(body, -1) {
+			d := dependency{
+				Package: u[1],
+				Org:     packageOrg(u[1]),
+				From:    strings.TrimRight(u[2], ".,;:"),
+				To:      strings.TrimRight(u[3], ".,;:"),
+			}
+			key := strings.ToLower(d.Package + "@" + d.To)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			d.UpdateType = updateType(d.From, d.To)
+			updates = append(updates, d)
+		}
+	}
+	if len(updates) == 0 {
+		return "", nil, false
+	}
+	return m[1], updates, true
+}
+
+// largestUpdateType returns the largest update type among updates, or ""
+// when none is known.
+func largestUpdateType(updates []dependency) string {
+	largest := ""
+	for _, u := range updates {
+		switch {
+		case u.UpdateType == UpdateMajor:
+			return UpdateMajor
+		case u.UpdateType == UpdateMinor:
+			largest = UpdateMinor
+		case u.UpdateType == UpdatePatch && largest == "":
+			largest = UpdatePatch
+		}
+	}
+	return largest
+}
+
+// packages returns the updates of a grouped update, or the dependency itself
+// for any other PR.
+func (d dependency) packages() []dependency {
+	if len(d.Members) == 0 {
+		return []dependency{d}
+	}
+	members := make([]dependency, len(d.Members))
+	for i, m := range d.Members {
+		m.Ecosystem, m.Security = d.Ecosystem, d.Security
+		members[i] = m
+	}
+	return members
+}
@@ -0,0 +1,140 @@
+package bouncer
+
+import (
+	"reflect"
+	"testing"
+)
+
+const groupBodyLines = "Bumps the aws-sdk-go-v2 group with 3 updates: [github.com/aws/aws-sdk-go-v2](https://github.com/aws/aws-sdk-go-v2), [github.com/aws/aws-sdk-go-v2/config](https://github.com/aws/aws-sdk-go-v2) and [github.com/aws/aws-sdk-go-v2/service/s3](https://github.com/aws/aws-sdk-go-v2).\n" +
+	"\n" +
+	"Updates `github.com/aws/aws-sdk-go-v2` from 1.21.0 to 1.21.2\n" +
+	"<details>\n<summary>Commits</summary>\n</details>\n" +
+	"\n" +
+	"Updates `github.com/aws/aws-sdk-go-v2/config` from 1.18.42 to 1.19.0\n" +
+	"\n" +
+	"Updates `github.com/aws/aws-sdk-go-v2/service/s3` from 1.40.0 to 2.0.0\n" +
+	"\n" +
+	"Updates `github.com/aws/aws-sdk-go-v2` from 1.21.0 to 1.21.2\n"
+
+const groupBodyTable = "Bumps the npm_and_yarn group with 2 updates in the / directory:\n" +
+	"\n" +
+	"| Package | From | To |\n" +
+	"| --- | --- | --- |\n" +
+	"| [@datadog/browser-rum](https://github.com/DataDog/browser-sdk) | `4.50.0` | `4.50.1` |\n" +
+	"| [lodash](https://github.com/lodash/lodash) | `4.17.20` | `4.17.21` |\n"
+
+func TestParseGroup(t *testing.T) {
+	tests := []struct {
+		name      string
+		title     string
+		body      string
+		wantGroup string
+		want      []dependency
+		wantOK    bool
+	}{
+		{
+			name:      "update lines",
+			title:     "Bump the aws-sdk-go-v2 group with 3 updates",
+			body:      groupBodyLines,
+			wantGroup: "aws-sdk-go-v2",
+			want: []dependency{
+				{Package: "github.com/aws/aws-sdk-go-v2", Org: "aws", From: "1.21.0", To: "1.21.2", UpdateType: UpdatePatch},
+				{Package: "github.com/aws/aws-sdk-go-v2/config", Org: "aws", From: "1.18.42", To: "1.19.0", UpdateType: UpdateMinor},
+				{Package: "github.com/aws/aws-sdk-go-v2/service/s3", Org: "aws", From: "1.40.0", To: "2.0.0", UpdateType: UpdateMajor},
+			},
+			wantOK: true,
+		},
+		{
+			name:      "table",
+			title:     "chore(deps): bump the npm_and_yarn group across 1 directory with 2 updates",
+			body:      groupBodyTable,
+			wantGroup: "npm_and_yarn",
+			want: []dependency{
+				{Package: "@datadog/browser-rum", Org: "datadog", From: "4.50.0", To: "4.50.1", UpdateType: UpdatePatch},
+				{Package: "lodash", From: "4.17.20", To: "4.17.21", UpdateType: UpdatePatch},
+			},
+			wantOK: true,
+		},
+		{
+			name:  "group without a body",
+			title: "Bump the aws-sdk-go-v2 group with 3 updates",
+		},
+		{
+			name:  "single update",
+			title: "Bump lodash from 4.17.20 to 4.17.21",
+			body:  "Bumps [lodash](https://github.com/lodash/lodash) from 4.17.20 to 4.17.21.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, got, ok := parseGroup(tt.title, tt.body)
+			if group != tt.wantGroup || !reflect.DeepEqual(got, tt.want) || ok != tt.wantOK {
+				t.Errorf("parseGroup() = %q, %+v, %v, want %q, %+v, %v", group, got, ok, tt.wantGroup, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSkipReasonGroup(t *testing.T) {
+	d := parseDependency("Bump the aws-sdk-go-v2 group with 3 updates", "dependabot/go_modules/aws-sdk-go-v2-abc123", groupBodyLines, "")
+	if d.Package != "aws-sdk-go-v2" || d.Ecosystem != "gomod" || d.UpdateType != UpdateMajor || len(d.Members) != 3 {
+		t.Fatalf("parseDependency() = %+v, want the aws-sdk-go-v2 group with a major update", d)
+	}
+
+	tests := []struct {
+		name     string
+		query    DependencyUpdateQuery
+		wantCode string
+		want     string
+	}{
+		{
+			name: "no member denied",
+			query: DependencyUpdateQuery{
+				DeniedPackages: []string{"aws-sdk-go-v2"},
+			},
+		},
+		{
+			name:     "one member denied",
+			query:    DependencyUpdateQuery{DeniedPackages: []string{"github.com/aws/aws-sdk-go-v2/config"}},
+			wantCode: SkipDeniedPackage,
+			want:     "denied package: github.com/aws/aws-sdk-go-v2/config (in the aws-sdk-go-v2 group)",
+		},
+		{
+			name:     "member version range",
+			query:    DependencyUpdateQuery{DeniedPackages: []string{"github.com/aws/aws-sdk-go-v2/service/s3@>=2"}},
+			wantCode: SkipDeniedPackage,
+			want:     "denied version: github.com/aws/aws-sdk-go-v2/service/s3 2.0.0 (github.com/aws/aws-sdk-go-v2/service/s3@>=2) (in the aws-sdk-go-v2 group)",
+		},
+		{
+			name:     "member update type",
+			query:    DependencyUpdateQuery{DeniedUpdateTypes: []string{UpdateMajor}},
+			wantCode: SkipUpdateType,
+			want:     "denied major update: github.com/aws/aws-sdk-go-v2/service/s3 (in the aws-sdk-go-v2 group)",
+		},
+		{
+			name:     "member not allowed",
+			query:    DependencyUpdateQuery{AllowedPackages: []string{"github.com/aws/aws-sdk-go-v2", "github.com/aws/aws-sdk-go-v2/config"}},
+			wantCode: SkipNotAllowed,
+			want:     "not in allow list: github.com/aws/aws-sdk-go-v2/service/s3 (in the aws-sdk-go-v2 group)",
+		},
+		{
+			name:  "all members allowed",
+			query: DependencyUpdateQuery{AllowedPackages: []string{"github.com/aws/*"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, reason := skipReason(d, tt.query)
+			if code != tt.wantCode || reason != tt.want {
+				t.Errorf("skipReason() = (%q, %q), want (%q, %q)", code, reason, tt.wantCode, tt.want)
+			}
+		})
+	}
+
+	pr := d.withPR(PRInfo{Number: 1})
+	if got := dependencyOf(pr); !reflect.DeepEqual(got, d) {
+		t.Errorf("dependencyOf(withPR()) = %+v, want %+v", got, d)
+	}
+}
@@ -0,0 +1,39 @@
+package bouncer
+
+import (
+	"os"
+	"strings"
+)
+
+// githubHost returns the host of a GitHub Enterprise Server host or URL such
+// as "github.example.com" or "https://github.example.com/", or "" for gh's
+// default host, github.com unless GH_HOST is set.
+func githubHost(host string) string {
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+	host, _, _ = strings.Cut(host, "/")
+	return host
+}
+
+// env returns the environment of a gh invocation authenticated with token,
+// or with gh's own authentication when token is empty, on the client's
+// host. It returns nil, inheriting the environment, when neither is set.
+// gh reads the token of an enterprise host from GH_ENTERPRISE_TOKEN instead
+// of GH_TOKEN.
+func (g GitHub) env(token string) []string {
+	host := githubHost(g.Host)
+	if token == "" && host == "" {
+		return nil
+	}
+	env := os.Environ()
+	if host != "" {
+		env = append(env, "GH_HOST="+host)
+	}
+	if token != "" {
+		name := "GH_TOKEN"
+		if host != "" && host != "github.com" {
+			name = "GH_ENTERPRISE_TOKEN"
+		}
+		env = append(env, name+"="+token)
+	}
+	return env
+}
@@ -0,0 +1,35 @@
+package bouncer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGitHubEnv(t *testing.T) {
+	tests := []struct {
+		name  string
+		host  string
+		token string
+		want  []string // variables the environment must end with; nil for none
+	}{
+		{"defaults", "", "", nil},
+		{"token", "", "t1", []string{"GH_TOKEN=t1"}},
+		{"enterprise host", "github.example.com", "", []string{"GH_HOST=github.example.com"}},
+		{"enterprise token", "https://github.example.com/", "t1", []string{"GH_HOST=github.example.com", "GH_ENTERPRISE_TOKEN=t1"}},
+		{"github.com host", "github.com", "t1", []string{"GH_HOST=github.com", "GH_TOKEN=t1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := GitHub{Host: tt.host}.env(tt.token)
+			if tt.want == nil {
+				if env != nil {
+					t.Errorf("env(%q) = %d variables, want nil", tt.token, len(env))
+				}
+				return
+			}
+			if len(env) < len(tt.want) || !slices.Equal(env[len(env)-len(tt.want):], tt.want) {
+				t.Errorf("env(%q) ends with %q, want %q", tt.token, env[max(0, len(env)-len(tt.want)):], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package bouncer
+
+import (
+	"regexp"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// dependabotMetadata is one entry of the updated-dependencies block that
+// Dependabot appends to its commit messages:
+//
+//	---
+//	updated-dependencies:
+//	- dependency-name: lodash
+//	  dependency-version: 4.17.21
+//	  dependency-type: direct:production
+//	  update-type: version-update:semver-patch
+//	...
+type dependabotMetadata struct {
+	Name       string `yaml:"dependency-name"`
+	Version    string `yaml:"dependency-version"` // the new version; only in recent commits
+	UpdateType string `yaml:"update-type"`        // e.g. version-update:semver-major
+}
+
+// metadataBlockRe matches the updated-dependencies block of a Dependabot
+// commit message.
+var metadataBlockRe = regexp.MustCompile(`(?ms)^---\s*\n(updated-dependencies:.*?)^\.\.\.\s*$`)
+
+// parseCommitMetadata returns the dependencies listed in the metadata block
+// of a Dependabot commit message, or nil when it has none.
+func parseCommitMetadata(message string) []dependabotMetadata {
+	m := metadataBlockRe.FindStringSubmatch(strings.ReplaceAll(message, "\r\n", "\n"))
+	if m == nil {
+		return nil
+	}
+	var block struct {
+		Updated []dependabotMetadata `yaml:"updated-dependencies"`
+	}
+	if err := yaml.Unmarshal([]byte(m[1]), &block); err != nil {
+		return nil
+	}
+	var deps []dependabotMetadata
+	for _, d := range block.Updated {
+		if d.Name != "" {
+			deps = append(deps, d)
+		}
+	}
+	return deps
+}
+
+// updateType returns the update type the metadata gives, or "" when it gives
+// none.
+func (m dependabotMetadata) updateType() string {
+	switch strings.TrimPrefix(m.UpdateType, "version-update:") {
+	case "semver-major":
+		return UpdateMajor
+	case "semver-minor":
+		return UpdateMinor
+	case "semver-patch":
+		return UpdatePatch
+	}
+	return ""
+}
+
+// bodyUpdateRe matches the first line of the body of a Dependabot PR for a
+// single dependency, "Bumps [lodash](https://...) from 4.17.20 to 4.17.21."
+// or, without a link, "Bumps lodash from 4.17.20 to 4.17.21.", optionally
+// followed by the directory.
+var bodyUpdateRe = regexp.MustCompile(`(?m)^Bumps \[?([^\s\]]+)\]?(?:\([^)]*\))? from (\S+) to (\S+?)\.?(?:\s|$)`)
+
+// parseBodyUpdate returns the package and versions named in the body of a
+// Dependabot PR for a single dependency.
+func parseBodyUpdate(body string) (pkg, from, to string, ok bool) {
+	m := bodyUpdateRe.FindStringSubmatch(body)
+	if m == nil {
+		return "", "", "", false
+	}
+	return m[1], m[2], strings.TrimRight(m[3], ".,;:"), true
+}
+
+// applyMetadata fills in a dependency parsed from a PR title or body with
+// what the commit metadata says: the package name and new version of a
+// single-dependency update, and the update type of each package.
+func (d *dependency) applyMetadata(meta []dependabotMetadata) {
+	if len(meta) == 0 {
+		return
+	}
+	if len(d.Members) == 0 && len(meta) == 1 {
+		m := meta[0]
+		if !strings.EqualFold(d.Package, m.Name) {
+			d.Package, d.Org = m.Name, packageOrg(m.Name)
+		}
+		if m.Version != "" {
+			d.To = m.Version
+		}
+		if t := m.updateType(); t != "" {
+			d.UpdateType = t
+		} else {
+			d.UpdateType = updateType(d.From, d.To)
+		}
+		return
+	}
+	for i, member := range d.Members {
+		for _, m := range meta {
+			if strings.EqualFold(member.Package, m.Name) {
+				if t := m.updateType(); t != "" {
+					d.Members[i].UpdateType = t
+				}
+			}
+		}
+	}
+	d.UpdateType = largestUpdateType(d.Members)
+}
@@ -0,0 +1,115 @@
+package bouncer
+
+import (
+	"reflect"
+	"testing"
+)
+
+const lodashCommit = "Bumps [lodash](https://github.com/lodash/lodash) from 4.17.20 to 4.17.21.\n" +
+	"- [Release notes](https://github.com/lodash/lodash/releases)\n" +
+	"\n" +
+	"---\n" +
+	"updated-dependencies:\n" +
+	"- dependency-name: lodash\n" +
+	"  dependency-version: 4.17.21\n" +
+	"  dependency-type: direct:production\n" +
+	"  update-type: version-update:semver-patch\n" +
+	"...\n" +
+	"\n" +
+	"Signed-off-by: dependabot[bot] <support@github.com>\n"
+
+func TestParseCommitMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []dependabotMetadata
+	}{
+		{
+			name:    "single update",
+			message: lodashCommit,
+			want:    []dependabotMetadata{{Name: "lodash", Version: "4.17.21", UpdateType: "version-update:semver-patch"}},
+		},
+		{
+			name: "grouped update",
+			message: "---\r\nupdated-dependencies:\r\n" +
+				"- dependency-name: github.com/aws/aws-sdk-go-v2\r\n  dependency-type: direct:production\r\n  update-type: version-update:semver-patch\r\n  dependency-group: aws-sdk-go-v2\r\n" +
+				"- dependency-name: github.com/aws/aws-sdk-go-v2/service/s3\r\n  dependency-type: direct:production\r\n  update-type: version-update:semver-minor\r\n  dependency-group: aws-sdk-go-v2\r\n" +
+				"...\r\n",
+			want: []dependabotMetadata{
+				{Name: "github.com/aws/aws-sdk-go-v2", UpdateType: "version-update:semver-patch"},
+				{Name: "github.com/aws/aws-sdk-go-v2/service/s3", UpdateType: "version-update:semver-minor"},
+			},
+		},
+		{
+			name:    "no metadata",
+			message: "Bump lodash\n\nSigned-off-by: someone\n",
+		},
+		{
+			name:    "malformed metadata",
+			message: "---\nupdated-dependencies: [\n...\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseCommitMetadata(tt.message); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCommitMetadata() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDependencyBody(t *testing.T) {
+	tests := []struct {
+		name   string
+		title  string
+		branch string
+		body   string
+		commit string
+		want   dependency
+	}{
+		{
+			name:   "custom title prefix",
+			title:  "⬆️ [deps] lodash: 4.17.20 → 4.17.21",
+			branch: "dependabot/npm_and_yarn/lodash-4.17.21",
+			body:   "Bumps [lodash](https://github.com/lodash/lodash) from 4.17.20 to 4.17.21.\n- [Release notes](https://github.com/lodash/lodash/releases)\n",
+			want:   dependency{Package: "lodash", Ecosystem: "npm", From: "4.17.20", To: "4.17.21", UpdateType: UpdatePatch},
+		},
+		{
+			name:   "body without a link",
+			title:  "build(deps): bump golang.org/x/net",
+			branch: "dependabot/go_modules/golang.org/x/net-0.23.0",
+			body:   "Bumps golang.org/x/net from 0.17.0 to 0.23.0.\n",
+			want:   dependency{Package: "golang.org/x/net", Ecosystem: "gomod", From: "0.17.0", To: "0.23.0", UpdateType: UpdateMinor},
+		},
+		{
+			name:   "metadata update type",
+			title:  "Bump lodash from 4.17.20 to 4.17.21",
+			branch: "dependabot/npm_and_yarn/lodash-4.17.21",
+			body:   "Bumps [lodash](https://github.com/lodash/lodash) from 4.17.20 to 4.17.21.\n",
+			commit: "---\nupdated-dependencies:\n- dependency-name: lodash\n  update-type: version-update:semver-minor\n...\n",
+			want:   dependency{Package: "lodash", Ecosystem: "npm", From: "4.17.20", To: "4.17.21", UpdateType: UpdateMinor},
+		},
+		{
+			name:   "metadata only",
+			title:  "🔧 update deps",
+			branch: "dependabot/npm_and_yarn/lodash-4.17.21",
+			commit: lodashCommit,
+			want:   dependency{Package: "lodash", Ecosystem: "npm", To: "4.17.21", UpdateType: UpdatePatch},
+		},
+		{
+			name:   "title fallback",
+			title:  "Bump lodash from 4.17.20 to 4.17.21",
+			branch: "dependabot/npm_and_yarn/lodash-4.17.21",
+			want:   dependency{Package: "lodash", Ecosystem: "npm", From: "4.17.20", To: "4.17.21", UpdateType: UpdatePatch},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseDependency(tt.title, tt.branch, tt.body, tt.commit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDependency() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
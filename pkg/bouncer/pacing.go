@@ -0,0 +1,91 @@
+package bouncer
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// DefaultWriteInterval spaces out mutating calls to stay within GitHub's
+// secondary rate limit of about 80 content-creating requests per minute.
+const DefaultWriteInterval = 750 * time.Millisecond
+
+// pacer hands out start times at least interval apart, so concurrent callers
+// are released one by one.
+type pacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// writePacer paces mutating gh, glab, and Gitea calls.
+var writePacer = &pacer{interval: DefaultWriteInterval}
+
+// SetWriteInterval sets the minimum time between the starts of two mutating
+// API calls. Zero disables pacing.
+func SetWriteInterval(d time.Duration) {
+	writePacer.mu.Lock()
+	defer writePacer.mu.Unlock()
+	writePacer.interval = d
+}
+
+// wait blocks until the caller's turn, or until ctx is done, in which case
+// it returns the context's error.
+func (p *pacer) wait(ctx context.Context) error {
+	p.mu.Lock()
+	if p.interval <= 0 {
+		p.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	start := p.next
+	if start.Before(now) {
+		start = now
+	}
+	p.next = start.Add(p.interval)
+	p.mu.Unlock()
+
+	delay := time.Until(start)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// DefaultMaxConcurrentCalls is how many gh and glab calls may run at once
+// unless set otherwise.
+const DefaultMaxConcurrentCalls = 8
+
+// callSlots holds a slot for every gh or glab call running.
+var callSlots = make(chan struct{}, DefaultMaxConcurrentCalls)
+
+// SetMaxConcurrentCalls sets how many gh and glab calls may run at once, in
+// all the repositories and PRs processed concurrently; n is at least 1. It
+// must be called before any call is made.
+func SetMaxConcurrentCalls(n int) {
+	callSlots = make(chan struct{}, max(n, 1))
+}
+
+// runCall runs cmd once a call slot is free, or fails with ctx's error when
+// ctx is done first, and returns its stdout, or its combined output when
+// combined is set.
+func runCall(ctx context.Context, cmd *exec.Cmd, combined bool) ([]byte, error) {
+	select {
+	case callSlots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-callSlots }()
+	if combined {
+		return cmd.CombinedOutput()
+	}
+	return cmd.Output()
+}
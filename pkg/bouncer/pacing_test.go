@@ -0,0 +1,40 @@
+package bouncer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPacerSpacesCalls(t *testing.T) {
+	p := &pacer{interval: 20 * time.Millisecond}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := p.wait(context.Background()); err != nil {
+			t.Fatalf("wait() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("3 calls took %s, want at least 40ms", elapsed)
+	}
+
+	p.interval = 0
+	if err := p.wait(context.Background()); err != nil {
+		t.Errorf("wait() without interval error = %v", err)
+	}
+}
+
+func TestPacerCanceled(t *testing.T) {
+	p := &pacer{interval: time.Hour}
+	if err := p.wait(context.Background()); err != nil {
+		t.Fatalf("first wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("wait() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,52 @@
+package bouncer
+
+import "strings"
+
+// permissionRule maps gh invocations to the fine-grained personal access
+// token permission they need. A rule matches when the call's arguments start
+// with cmd (e.g. "pr review") and, if set, contain fragment anywhere (an API
+// path segment or a GraphQL field).
+type permissionRule struct {
+	cmd        string
+	fragment   string
+	permission string
+}
+
+// permissionRules are checked in order; the first match wins, so specific
+// rules come before general ones.
+var permissionRules = []permissionRule{
+	{"pr review", "", `"Pull requests: Read and write"`},
+	{"pr comment", "", `"Pull requests: Read and write"`},
+	{"pr close", "", `"Pull requests: Read and write"`},
+	{"pr ready", "", `"Pull requests: Read and write"`},
+	{"pr merge", "", `"Pull requests: Read and write" and "Contents: Read and write"`},
+	{"pr list", "", `"Pull requests: Read-only", "Commit statuses: Read-only", and "Checks: Read-only"`},
+	{"pr view", "", `"Pull requests: Read-only", "Commit statuses: Read-only", and "Checks: Read-only"`},
+	{"repo", "", `"Metadata: Read-only"`},
+	{"api", "revertPullRequest", `"Pull requests: Read and write" and "Contents: Read and write"`},
+	{"api", "statusCheckRollup", `"Commit statuses: Read-only" and "Checks: Read-only"`},
+	{"api", "/dependabot/alerts", `"Dependabot alerts: Read-only"`},
+	{"api", "/vulnerability-alerts", `"Administration: Read-only"`},
+}
+
+// isPermissionError reports whether gh output describes a request rejected
+// for lack of permission.
+func isPermissionError(output string) bool {
+	return strings.Contains(output, "HTTP 403") || strings.Contains(output, "Resource not accessible by")
+}
+
+// permissionHint returns advice naming the fine-grained token permission a
+// failed gh call most likely lacks, or "" when the failure is not a
+// permission error or the call is not recognized.
+func permissionHint(args []string, output string) string {
+	if len(args) < 2 || args[0] != "gh" || !isPermissionError(output) {
+		return ""
+	}
+	call := strings.Join(args[1:], " ")
+	for _, r := range permissionRules {
+		if strings.HasPrefix(call, r.cmd+" ") && strings.Contains(call, r.fragment) {
+			return " (if you use a fine-grained personal access token, grant it " + r.permission + " on this repository)"
+		}
+	}
+	return ""
+}
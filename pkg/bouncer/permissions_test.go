@@ -0,0 +1,40 @@
+package bouncer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPermissionHint(t *testing.T) {
+	const forbidden = "GraphQL: Resource not accessible by personal access token (addPullRequestReview)"
+
+	tests := []struct {
+		name   string
+		args   []string
+		output string
+		want   string // substring of the hint; empty means no hint
+	}{
+		{"review", []string{"gh", "pr", "review", "--repo", "acme/api", "7", "--approve"}, forbidden, `"Pull requests: Read and write"`},
+		{"auto-merge", []string{"gh", "pr", "merge", "--auto", "--squash", "--repo", "acme/api", "7"}, forbidden, `"Contents: Read and write"`},
+		{"alerts", []string{"gh", "api", "--paginate", "repos/acme/api/dependabot/alerts?state=open"}, "gh: Resource not accessible by personal access token (HTTP 403)", `"Dependabot alerts: Read-only"`},
+		{"rollup", []string{"gh", "api", "graphql", "-f", "query=" + defaultBranchStatusQuery}, forbidden, `"Checks: Read-only"`},
+		{"not a permission error", []string{"gh", "pr", "review", "--repo", "acme/api", "7"}, "HTTP 404: Not Found", ""},
+		{"unknown call", []string{"gh", "api", "user"}, "HTTP 403: Forbidden", ""},
+		{"glab", []string{"glab", "api", "projects/acme%2Fapi"}, "403 Forbidden", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := permissionHint(tt.args, tt.output)
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("permissionHint() = %q, want none", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("permissionHint() = %q, want it to mention %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package bouncer
+
+import (
+	"log"
+	"strings"
+)
+
+// Precedence values decide what happens to a package that matches both the
+// allow list and the deny list.
+const (
+	PrecedenceDeny  = "deny"  // stricter wins: denied packages are skipped even when allowed (default)
+	PrecedenceAllow = "allow" // allowed packages are processed even when denied
+)
+
+// CI filters select PRs by the status of their checks.
+const (
+	CIPassing          = "passing"            // only PRs whose checks passed
+	CIPassingOrPending = "passing_or_pending" // also PRs whose checks are still running
+	CIAny              = "any"                // every PR, whatever its checks
+	CIFailing          = "failing"            // only PRs with failing checks
+)
+
+// ValidCIFilter reports whether f is one of the CI filter constants.
+func ValidCIFilter(f string) bool {
+	switch f {
+	case CIPassing, CIPassingOrPending, CIAny, CIFailing:
+		return true
+	}
+	return false
+}
+
+// ciSelected reports whether a PR with the given CI status passes filter.
+func ciSelected(status, filter string) bool {
+	switch filter {
+	case CIPassing:
+		return status == "success"
+	case CIPassingOrPending:
+		return status != "failure"
+	case CIFailing:
+		return status == "failure"
+	default:
+		return true
+	}
+}
+
+// Skip codes identify why a PR was skipped. They are stable identifiers meant
+// for machine-readable output; SkipReason carries the human-readable detail.
+const (
+	SkipDeniedPackage = "DENIED_PACKAGE"
+	SkipDeniedOrg     = "DENIED_ORG"
+	SkipNotAllowed    = "NOT_ALLOWED"
+	SkipIgnored       = "IGNORED_BY_CONFIG"
+	SkipUpdateType    = "DENIED_UPDATE_TYPE"
+	SkipDraft         = "DRAFT"
+	SkipUnparsed      = "UNPARSED_TITLE"
+	SkipSnoozed       = "SNOOZED"
+	SkipCodeOwners    = "CODEOWNERS" // changes files owned by others; see FetchCodeOwners
+	SkipTooNew        = "TOO_NEW"    // version younger than min_age
+	SkipVulnerable    = "VULNERABLE" // version with known vulnerabilities; see vulnerability_check
+)
+
+// Unparsed-title policies decide what happens to a PR whose title names no
+// package the allow and deny lists could be applied to.
+const (
+	UnparsedWarn    = "warn"    // process it and log a warning (default)
+	UnparsedProcess = "process" // process it silently
+	UnparsedSkip    = "skip"    // skip it with SkipUnparsed, for a human to review
+)
+
+// dependency describes the update proposed by a Dependabot PR, as far as it
+// can be determined from the PR's title and branch.
+type dependency struct {
+	Package    string
+	Org        string
+	Ecosystem  string // Dependabot package-ecosystem name, e.g. "gomod", "npm"
+	From       string
+	To         string
+	UpdateType string // UpdateMajor, UpdateMinor, UpdatePatch, or "" when unknown
+	Security   bool   // the PR fixes a vulnerability (security update)
+
+	// Members are the updates of a grouped update PR, whose Package is then
+	// the group name and UpdateType the largest of theirs.
+	Members []dependency
+}
+
+// parseDependency extracts the dependency details from a PR title, head
+// branch, body, and commit message. The structured parts of Dependabot's PR
+// body and commit message are read first; the title, in whatever format and
+// with whatever prefix, is the fallback. body and commit may be empty.
+func parseDependency(title, branch, body, commit string) dependency {
+	var d dependency
+	if group, members, ok := parseGroup(title, body); ok {
+		d.Package, d.Members = group, members
+		d.UpdateType = largestUpdateType(members)
+	} else if pkg, from, to, ok := parseBodyUpdate(body); ok {
+		d.Package, d.Org = pkg, packageOrg(pkg)
+		d.From, d.To = from, to
+		d.UpdateType = updateType(d.From, d.To)
+	} else {
+		d.Package, d.Org = extractPackageInfo(title)
+		d.From, d.To = parseVersions(title)
+		if d.From == "" && d.To != "" {
+			// Renovate titles name only the new version, often shortened
+			// to "v5"; its body lists the current one and the full new one.
+			if from, to, ok := parseRenovateChange(body); ok {
+				d.From = from
+				if strings.HasPrefix(strings.TrimPrefix(to, "v"), strings.TrimPrefix(d.To, "v")) {
+					d.To = to
+				}
+			}
+		}
+		d.UpdateType = updateType(d.From, d.To)
+		if d.UpdateType == "" && strings.HasPrefix(branch, "renovate/major-") {
+			d.UpdateType = UpdateMajor
+		}
+	}
+	d.applyMetadata(parseCommitMetadata(commit))
+	d.Ecosystem = detectEcosystem(branch, d.packages()[0].Package, title)
+	return d
+}
+
+// withPR copies the dependency details into pr and returns it.
+func (d dependency) withPR(pr PRInfo) PRInfo {
+	pr.PackageName = d.Package
+	pr.OrgName = d.Org
+	pr.Ecosystem = d.Ecosystem
+	pr.FromVersion = d.From
+	pr.ToVersion = d.To
+	pr.UpdateType = d.UpdateType
+	pr.Security = d.Security
+	pr.Updates = nil
+	for _, m := range d.Members {
+		pr.Updates = append(pr.Updates, Update{Package: m.Package, Org: m.Org, From: m.From, To: m.To, UpdateType: m.UpdateType})
+	}
+	return pr
+}
+
+// dependencyOf is the inverse of withPR.
+func dependencyOf(pr PRInfo) dependency {
+	var members []dependency
+	for _, u := range pr.Updates {
+		members = append(members, dependency{Package: u.Package, Org: u.Org, From: u.From, To: u.To, UpdateType: u.UpdateType})
+	}
+	return dependency{
+		Package:    pr.PackageName,
+		Org:        pr.OrgName,
+		Ecosystem:  pr.Ecosystem,
+		From:       pr.FromVersion,
+		To:         pr.ToVersion,
+		UpdateType: pr.UpdateType,
+		Security:   pr.Security,
+		Members:    members,
+	}
+}
+
+// filterPRs drops candidate PRs outside the query's selection window, applies
+// q.IgnoredPRs, q.Snoozed, and the allow and deny lists to the rest, then
+// drops PRs whose CI status does not pass q.CIFilter (CIPassing when it is
+// empty and skipFailing is set).
+// Skipped PRs are dropped unless q.IncludeSkipped is set, in which case they
+// are returned with Skipped, SkipCode, and SkipReason populated and their
+// CI and merge details cleared. PRs that are not skipped are marked Trusted
+// when their package matches q.TrustedPackages or q.TrustedOrgs, and
+// Internal when it matches q.InternalPackages; grouped updates when all
+// their updates' packages do.
+func filterPRs(candidates []PRInfo, q DependencyUpdateQuery, skipFailing bool) []PRInfo {
+	ciFilter := q.CIFilter
+	if ciFilter == "" && skipFailing {
+		ciFilter = CIPassing
+	}
+	excluded := make(map[int]bool, len(q.IgnoredPRs))
+	for _, n := range q.IgnoredPRs {
+		excluded[n] = true
+	}
+
+	var prs []PRInfo
+	for _, pr := range candidates {
+		if !q.inWindow(pr) {
+			if q.Stats != nil {
+				q.Stats.OutOfWindow++
+			}
+			continue
+		}
+
+		code, reason, rule := SkipIgnored, "listed in ignored_prs", ""
+		if until, ok := q.Snoozed[pr.Number]; ok && !excluded[pr.Number] {
+			code, reason = SkipSnoozed, "snoozed until "+until.Format("2006-01-02")
+		} else if !excluded[pr.Number] {
+			code, reason, rule = skipDecision(dependencyOf(pr), q)
+			if code == "" && pr.Draft && !q.IncludeDrafts {
+				code, reason, rule = SkipDraft, "draft PR", ""
+			}
+			if code == "" && pr.PackageName == "" && (q.UnparsedTitles == "" || q.UnparsedTitles == UnparsedWarn) {
+				log.Printf("Warning: cannot determine the package of PR #%d, deny lists do not apply to it: %s\n", pr.Number, pr.Title)
+			}
+			if code != "" {
+				log.Printf("Skipping package: %s (org: %s, %s) - PR #%d: %s\n", pr.PackageName, pr.OrgName, reason, pr.Number, pr.Title)
+			}
+		}
+		if code != "" {
+			q.Stats.skip(code)
+			if q.IncludeSkipped {
+				prs = append(prs, dependencyOf(pr).withPR(PRInfo{
+					Number:     pr.Number,
+					Title:      pr.Title,
+					URL:        pr.URL,
+					CreatedAt:  pr.CreatedAt,
+					UpdatedAt:  pr.UpdatedAt,
+					Skipped:    true,
+					SkipCode:   code,
+					SkipReason: reason,
+					SkipRule:   rule,
+				}))
+			}
+			continue
+		}
+
+		pr.AllowRule = rule
+		pr.Trusted, pr.Internal = true, true
+		for _, d := range dependencyOf(pr).packages() {
+			pr.Trusted = pr.Trusted && d.Package != "" && isAllowed(d.Package, d.Org, q.TrustedPackages, q.TrustedOrgs)
+			pr.Internal = pr.Internal && isInternal(d.Package, q)
+		}
+		if len(q.RequiredChecks) > 0 {
+			pr.CIStatus, pr.CIFailures = requiredChecksStatus(pr.Checks, q.RequiredChecks)
+		}
+		if pr.CIStatus == "success" && successfulChecks(pr.Checks) < q.MinSuccessfulChecks {
+			pr.CIStatus = "pending"
+		}
+		if !ciSelected(pr.CIStatus, ciFilter) {
+			if q.Stats != nil {
+				switch pr.CIStatus {
+				case "success":
+					q.Stats.Passing++
+				case "failure":
+					q.Stats.Failing++
+				default:
+					q.Stats.Pending++
+				}
+			}
+			continue
+		}
+		prs = append(prs, pr)
+	}
+	return prs
+}
+
+// FilterPRs applies q to listed PRs the way List does for the built-in
+// clients: ignored and snoozed PRs, the allow and deny lists, drafts, and the
+// CI filter. It is for Client implementations outside this package, such
+// as fakes in tests.
+func FilterPRs(candidates []PRInfo, q DependencyUpdateQuery, skipFailing bool) []PRInfo {
+	return filterPRs(candidates, q, skipFailing)
+}
+
+// ParsePR fills in the package, org, ecosystem, versions, and update type of
+// pr from its title and head branch, as List does for the PRs it lists. A
+// Security flag already set on pr is kept.
+func ParsePR(pr PRInfo, branch string) PRInfo {
+	d := parseDependency(pr.Title, branch, "", "")
+	d.Security = d.Security || pr.Security
+	return d.withPR(pr)
+}
+
+// PolicyDecision applies the query's allow and deny lists to a listed PR
+// the way filterPRs does, and returns the skip code and reason, or empty
+// strings when the policy lets it through. ignored_prs, snoozes, and drafts
+// are not considered.
+func PolicyDecision(pr PRInfo, q DependencyUpdateQuery) (code, reason string) {
+	return skipReason(dependencyOf(pr), q)
+}
+
+// skipReason applies the query's allow and deny lists to a dependency and
+// returns the skip code and reason, or empty strings when it may be processed.
+//
+// Deny lists for the dependency's ecosystem are merged with the query-wide
+// ones. Deny entries with a version constraint, such as "react@>=19", only
+// deny updates to a version in their range. When an allow list is configured, packages that match neither
+// AllowedPackages nor AllowedOrgs are skipped. Packages matching both lists are
+// resolved by q.Precedence. Update-type denials (e.g. major bumps) apply to
+// every package, including allowed ones.
+//
+// Security updates bypass the allow and deny lists when q.ExemptSecurity is
+// set; update-type denials still apply to them.
+//
+// A grouped update is skipped when any of its updates would be, with that
+// update's code and reason.
+//
+// A dependency whose package could not be determined from the PR title is
+// skipped with SkipUnparsed when q.UnparsedTitles is UnparsedSkip; otherwise
+// the lists are applied to it as to any other, which lets it through unless
+// an allow list is configured.
+func skipReason(d dependency, q DependencyUpdateQuery) (code, reason string) {
+	code, reason, _ = skipDecision(d, q)
+	return code, reason
+}
+
+// skipDecision is skipReason that also returns the rule behind the
+// decision. For a policy skip, it is the deny list entry that matched,
+// "allow list", or the denied update type such as "major updates"; it is ""
+// for unparsed titles. For a dependency let through, it is the allow list
+// entry that matched, "security exemption", or "" when no allow list
+// applies.
+func skipDecision(d dependency, q DependencyUpdateQuery) (code, reason, rule string) {
+	if d.Package == "" && q.UnparsedTitles == UnparsedSkip {
+		return SkipUnparsed, "cannot determine the package from the title", ""
+	}
+	if len(d.Members) > 0 {
+		for _, m := range d.packages() {
+			if code, reason, rule := skipDecision(m, q); code != "" {
+				return code, reason + " (in the " + d.Package + " group)", rule
+			}
+		}
+		return "", "", ""
+	}
+	if d.Security && q.ExemptSecurity {
+		if isUpdateTypeDenied(d.Package, d.UpdateType, q) {
+			reason, rule := updateTypeDenial(d)
+			return SkipUpdateType, reason, rule
+		}
+		return "", "", "security exemption"
+	}
+
+	deniedPackages := append(append([]string(nil), q.DeniedPackages...), q.EcosystemDeniedPackages[d.Ecosystem]...)
+	deniedOrgs := append(append([]string(nil), q.DeniedOrgs...), q.EcosystemDeniedOrgs[d.Ecosystem]...)
+
+	hasAllowList := len(q.AllowedPackages) > 0 || len(q.AllowedOrgs) > 0
+	allowed := hasAllowList && isAllowed(d.Package, d.Org, q.AllowedPackages, q.AllowedOrgs)
+
+	versionEntry := deniedVersion(d.Package, d.To, deniedPackages)
+	if versionEntry != "" || isDenied(d.Package, d.Org, deniedPackages, deniedOrgs) {
+		if !allowed || q.Precedence != PrecedenceAllow {
+			if versionEntry != "" {
+				return SkipDeniedPackage, "denied version: " + d.Package + " " + d.To + " (" + versionEntry + ")", versionEntry
+			}
+			if entry := deniedEntry(d.Package, "", deniedPackages, nil); entry != "" {
+				return SkipDeniedPackage, "denied package: " + d.Package, entry
+			}
+			return SkipDeniedOrg, "denied org: " + d.Org, deniedEntry(d.Package, d.Org, nil, deniedOrgs)
+		}
+	}
+
+	if hasAllowList && !allowed {
+		return SkipNotAllowed, "not in allow list: " + d.Package, "allow list"
+	}
+
+	if isUpdateTypeDenied(d.Package, d.UpdateType, q) {
+		reason, rule := updateTypeDenial(d)
+		return SkipUpdateType, reason, rule
+	}
+
+	if allowed {
+		return "", "", deniedEntry(d.Package, d.Org, q.AllowedPackages, q.AllowedOrgs)
+	}
+	return "", "", ""
+}
+
+// isAllowed checks if a package or organization is in the allow list. Allow
+// entries support the same exact, versioned, and wildcard forms as deny entries.
+func isAllowed(packageName, orgName string, allowedPackages, allowedOrgs []string) bool {
+	return isDenied(packageName, orgName, allowedPackages, allowedOrgs)
+}
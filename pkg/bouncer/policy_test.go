@@ -0,0 +1,593 @@
+package bouncer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSkipReason(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       DependencyUpdateQuery
+		packageName string
+		orgName     string
+		ecosystem   string
+		toVersion   string
+		updateType  string
+		security    bool
+		wantCode    string
+		want        string
+	}{
+		{
+			name:        "no lists",
+			packageName: "github.com/spf13/cobra",
+			orgName:     "spf13",
+			want:        "",
+		},
+		{
+			name:        "denied package",
+			query:       DependencyUpdateQuery{DeniedPackages: []string{"github.com/pkg/errors"}},
+			packageName: "github.com/pkg/errors",
+			orgName:     "pkg",
+			wantCode:    SkipDeniedPackage,
+			want:        "denied package: github.com/pkg/errors",
+		},
+		{
+			name:        "denied org",
+			query:       DependencyUpdateQuery{DeniedOrgs: []string{"datadog"}},
+			packageName: "github.com/datadog/datadog-go",
+			orgName:     "datadog",
+			wantCode:    SkipDeniedOrg,
+			want:        "denied org: datadog",
+		},
+		{
+			name:     "unparsed title skipped",
+			query:    DependencyUpdateQuery{UnparsedTitles: UnparsedSkip},
+			wantCode: SkipUnparsed,
+			want:     "cannot determine the package from the title",
+		},
+		{
+			name:  "unparsed title processed",
+			query: DependencyUpdateQuery{UnparsedTitles: UnparsedProcess, DeniedPackages: []string{"lodash"}},
+			want:  "",
+		},
+		{
+			name:        "allow list match",
+			query:       DependencyUpdateQuery{AllowedPackages: []string{"golang.org/x/*"}},
+			packageName: "golang.org/x/net",
+			want:        "",
+		},
+		{
+			name:        "allow list by org",
+			query:       DependencyUpdateQuery{AllowedOrgs: []string{"aws"}},
+			packageName: "github.com/aws/aws-sdk-go-v2",
+			orgName:     "aws",
+			want:        "",
+		},
+		{
+			name:        "not in allow list",
+			query:       DependencyUpdateQuery{AllowedOrgs: []string{"aws"}},
+			packageName: "github.com/spf13/cobra",
+			orgName:     "spf13",
+			wantCode:    SkipNotAllowed,
+			want:        "not in allow list: github.com/spf13/cobra",
+		},
+		{
+			name: "allowed and denied, deny precedence by default",
+			query: DependencyUpdateQuery{
+				AllowedOrgs:    []string{"aws"},
+				DeniedPackages: []string{"github.com/aws/aws-sdk-go"},
+			},
+			packageName: "github.com/aws/aws-sdk-go",
+			orgName:     "aws",
+			wantCode:    SkipDeniedPackage,
+			want:        "denied package: github.com/aws/aws-sdk-go",
+		},
+		{
+			name: "allowed and denied, allow precedence",
+			query: DependencyUpdateQuery{
+				AllowedPackages: []string{"github.com/datadog/datadog-go"},
+				DeniedOrgs:      []string{"datadog"},
+				Precedence:      PrecedenceAllow,
+			},
+			packageName: "github.com/datadog/datadog-go",
+			orgName:     "datadog",
+			want:        "",
+		},
+		{
+			name: "allow precedence does not rescue unlisted packages",
+			query: DependencyUpdateQuery{
+				AllowedPackages: []string{"github.com/datadog/datadog-go"},
+				DeniedOrgs:      []string{"datadog"},
+				Precedence:      PrecedenceAllow,
+			},
+			packageName: "github.com/datadog/dd-trace-go",
+			orgName:     "datadog",
+			wantCode:    SkipDeniedOrg,
+			want:        "denied org: datadog",
+		},
+		{
+			name:        "denied update type",
+			query:       DependencyUpdateQuery{DeniedUpdateTypes: []string{"major"}},
+			packageName: "github.com/spf13/cobra",
+			updateType:  UpdateMajor,
+			wantCode:    SkipUpdateType,
+			want:        "denied major update: github.com/spf13/cobra",
+		},
+		{
+			name:        "allowed update type",
+			query:       DependencyUpdateQuery{DeniedUpdateTypes: []string{"major"}},
+			packageName: "github.com/spf13/cobra",
+			updateType:  UpdateMinor,
+			want:        "",
+		},
+		{
+			name: "update type denial applies to allowed packages",
+			query: DependencyUpdateQuery{
+				AllowedPackages:   []string{"github.com/spf13/cobra"},
+				DeniedUpdateTypes: []string{"major"},
+				Precedence:        PrecedenceAllow,
+			},
+			packageName: "github.com/spf13/cobra",
+			updateType:  UpdateMajor,
+			wantCode:    SkipUpdateType,
+			want:        "denied major update: github.com/spf13/cobra",
+		},
+		{
+			name: "per-package update type",
+			query: DependencyUpdateQuery{
+				DeniedUpdateTypesByPackage: map[string][]string{"github.com/aws/*": {"major", "minor"}},
+			},
+			packageName: "github.com/aws/aws-sdk-go-v2",
+			updateType:  UpdateMinor,
+			wantCode:    SkipUpdateType,
+			want:        "denied minor update: github.com/aws/aws-sdk-go-v2",
+		},
+		{
+			name: "external major denied",
+			query: DependencyUpdateQuery{
+				InternalPackages:          []string{"github.com/acme/*"},
+				DeniedUpdateTypesExternal: []string{"major"},
+			},
+			packageName: "github.com/spf13/cobra",
+			updateType:  UpdateMajor,
+			wantCode:    SkipUpdateType,
+			want:        "denied major update: github.com/spf13/cobra",
+		},
+		{
+			name: "internal major not denied by the external list",
+			query: DependencyUpdateQuery{
+				InternalPackages:          []string{"github.com/acme/*"},
+				DeniedUpdateTypesExternal: []string{"major"},
+			},
+			packageName: "github.com/acme/log",
+			updateType:  UpdateMajor,
+			want:        "",
+		},
+		{
+			name: "internal update type",
+			query: DependencyUpdateQuery{
+				InternalPackages:          []string{"github.com/acme/*"},
+				DeniedUpdateTypesInternal: []string{"major"},
+			},
+			packageName: "github.com/acme/log",
+			updateType:  UpdateMajor,
+			wantCode:    SkipUpdateType,
+			want:        "denied major update: github.com/acme/log",
+		},
+		{
+			name: "per-package update type does not affect other packages",
+			query: DependencyUpdateQuery{
+				DeniedUpdateTypesByPackage: map[string][]string{"github.com/aws/*": {"major"}},
+			},
+			packageName: "github.com/spf13/cobra",
+			updateType:  UpdateMajor,
+			want:        "",
+		},
+		{
+			name: "ecosystem deny list applies to its ecosystem",
+			query: DependencyUpdateQuery{
+				EcosystemDeniedPackages: map[string][]string{"npm": {"left-pad"}},
+			},
+			packageName: "left-pad",
+			ecosystem:   "npm",
+			wantCode:    SkipDeniedPackage,
+			want:        "denied package: left-pad",
+		},
+		{
+			name: "ecosystem deny list ignores other ecosystems",
+			query: DependencyUpdateQuery{
+				EcosystemDeniedPackages: map[string][]string{"npm": {"left-pad"}},
+			},
+			packageName: "left-pad",
+			ecosystem:   "pip",
+			want:        "",
+		},
+		{
+			name: "ecosystem org deny list",
+			query: DependencyUpdateQuery{
+				EcosystemDeniedOrgs: map[string][]string{"npm": {"datadog"}},
+			},
+			packageName: "@datadog/browser-rum",
+			orgName:     "datadog",
+			ecosystem:   "npm",
+			wantCode:    SkipDeniedOrg,
+			want:        "denied org: datadog",
+		},
+		{
+			name:        "security update exempt from deny list",
+			query:       DependencyUpdateQuery{DeniedOrgs: []string{"datadog"}, ExemptSecurity: true},
+			packageName: "github.com/datadog/datadog-go",
+			orgName:     "datadog",
+			security:    true,
+			want:        "",
+		},
+		{
+			name:        "security update exempt from allow list",
+			query:       DependencyUpdateQuery{AllowedOrgs: []string{"aws"}, ExemptSecurity: true},
+			packageName: "lodash",
+			security:    true,
+			want:        "",
+		},
+		{
+			name:        "security update denied when not exempt",
+			query:       DependencyUpdateQuery{DeniedOrgs: []string{"datadog"}},
+			packageName: "github.com/datadog/datadog-go",
+			orgName:     "datadog",
+			security:    true,
+			wantCode:    SkipDeniedOrg,
+			want:        "denied org: datadog",
+		},
+		{
+			name:        "security update still subject to update-type denial",
+			query:       DependencyUpdateQuery{DeniedUpdateTypes: []string{"major"}, ExemptSecurity: true},
+			packageName: "lodash",
+			updateType:  UpdateMajor,
+			security:    true,
+			wantCode:    SkipUpdateType,
+			want:        "denied major update: lodash",
+		},
+		{
+			name:        "version in denied range",
+			query:       DependencyUpdateQuery{DeniedPackages: []string{"react@>=19"}},
+			packageName: "react",
+			toVersion:   "19.0.0",
+			wantCode:    SkipDeniedPackage,
+			want:        "denied version: react 19.0.0 (react@>=19)",
+		},
+		{
+			name:        "version outside denied range",
+			query:       DependencyUpdateQuery{DeniedPackages: []string{"react@>=19"}},
+			packageName: "react",
+			toVersion:   "18.3.1",
+			want:        "",
+		},
+		{
+			name:        "version range with space and wildcard",
+			query:       DependencyUpdateQuery{DeniedPackages: []string{"github.com/aws/* < 1.20"}},
+			packageName: "github.com/aws/aws-sdk-go-v2",
+			toVersion:   "v1.19.5",
+			wantCode:    SkipDeniedPackage,
+			want:        "denied version: github.com/aws/aws-sdk-go-v2 v1.19.5 (github.com/aws/* < 1.20)",
+		},
+		{
+			name:        "version range of another package",
+			query:       DependencyUpdateQuery{DeniedPackages: []string{"github.com/aws/aws-sdk-go < 1.20"}},
+			packageName: "github.com/aws/aws-sdk-go-v2",
+			toVersion:   "1.19.0",
+			want:        "",
+		},
+		{
+			name:        "version range without a version",
+			query:       DependencyUpdateQuery{DeniedPackages: []string{"react@>=19"}},
+			packageName: "react",
+			want:        "",
+		},
+		{
+			name:        "version range overridden by allow precedence",
+			query:       DependencyUpdateQuery{DeniedPackages: []string{"@babel/core@>=8"}, AllowedPackages: []string{"@babel/*"}, Precedence: PrecedenceAllow},
+			packageName: "@babel/core",
+			toVersion:   "8.0.0",
+			want:        "",
+		},
+		{
+			name:        "unknown update type is denied when update types are",
+			query:       DependencyUpdateQuery{DeniedUpdateTypes: []string{"major"}},
+			packageName: "aws-sdk-go-v2",
+			wantCode:    SkipUpdateType,
+			want:        "denied update of unknown type: aws-sdk-go-v2",
+		},
+		{
+			name:        "unknown update type without denied update types",
+			query:       DependencyUpdateQuery{DeniedPackages: []string{"lodash"}},
+			packageName: "aws-sdk-go-v2",
+			want:        "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, reason := skipReason(dependency{
+				Package:    tt.packageName,
+				Org:        tt.orgName,
+				Ecosystem:  tt.ecosystem,
+				To:         tt.toVersion,
+				UpdateType: tt.updateType,
+				Security:   tt.security,
+			}, tt.query)
+			if code != tt.wantCode || reason != tt.want {
+				t.Errorf("skipReason() = (%q, %q), want (%q, %q)", code, reason, tt.wantCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterPRs(t *testing.T) {
+	candidates := []PRInfo{
+		{Number: 1, PackageName: "lodash", CIStatus: "success"},
+		{Number: 2, PackageName: "left-pad", CIStatus: "success"},
+		{Number: 3, PackageName: "react", CIStatus: "failure", CIFailures: []string{"build"}},
+		{Number: 4, PackageName: "express", CIStatus: "success"},
+	}
+	q := DependencyUpdateQuery{DeniedPackages: []string{"left-pad"}, IgnoredPRs: []int{4}}
+
+	numbers := func(prs []PRInfo) []int {
+		var n []int
+		for _, pr := range prs {
+			n = append(n, pr.Number)
+		}
+		return n
+	}
+
+	if got := numbers(filterPRs(candidates, q, false)); !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Errorf("filterPRs() = %v, want [1 3]", got)
+	}
+	if got := numbers(filterPRs(candidates, q, true)); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("filterPRs(skipFailing) = %v, want [1]", got)
+	}
+
+	q.IncludeSkipped = true
+	got := filterPRs(candidates, q, false)
+	if len(got) != 4 {
+		t.Fatalf("filterPRs(IncludeSkipped) returned %d PRs, want 4", len(got))
+	}
+	if got[1].SkipCode != SkipDeniedPackage || got[1].CIStatus != "" {
+		t.Errorf("denied PR = %+v, want SkipCode %q and no CI status", got[1], SkipDeniedPackage)
+	}
+	if got[3].SkipCode != SkipIgnored {
+		t.Errorf("ignored PR SkipCode = %q, want %q", got[3].SkipCode, SkipIgnored)
+	}
+}
+
+func TestFilterPRsDrafts(t *testing.T) {
+	candidates := []PRInfo{
+		{Number: 1, PackageName: "lodash", CIStatus: "success"},
+		{Number: 2, PackageName: "react", CIStatus: "success", Draft: true},
+	}
+
+	got := filterPRs(candidates, DependencyUpdateQuery{IncludeSkipped: true}, false)
+	if len(got) != 2 || got[0].Skipped || got[1].SkipCode != SkipDraft {
+		t.Errorf("filterPRs() = %+v, want draft PR #2 skipped with %q", got, SkipDraft)
+	}
+	if got := filterPRs(candidates, DependencyUpdateQuery{IncludeDrafts: true}, true); len(got) != 2 {
+		t.Errorf("filterPRs(IncludeDrafts) returned %d PRs, want 2", len(got))
+	}
+}
+
+func TestFilterPRsTrusted(t *testing.T) {
+	candidates := []PRInfo{
+		{Number: 1, PackageName: "github.com/acme/log", OrgName: "acme", CIStatus: "success"},
+		{Number: 2, PackageName: "golang.org/x/net", OrgName: "golang.org", CIStatus: "success"},
+		{Number: 3, PackageName: "github.com/acme/denied", OrgName: "acme", CIStatus: "success"},
+		{Number: 4, Title: "Update everything", CIStatus: "success"},
+	}
+	q := DependencyUpdateQuery{
+		TrustedOrgs:     []string{"acme"},
+		TrustedPackages: []string{"golang.org/x/*"},
+		DeniedPackages:  []string{"github.com/acme/denied"},
+		UnparsedTitles:  UnparsedProcess,
+	}
+
+	got := filterPRs(candidates, q, true)
+	if len(got) != 3 {
+		t.Fatalf("filterPRs() returned %d PRs, want 3 (denied packages are not trusted past the deny list)", len(got))
+	}
+	for i, want := range []bool{true, true, false} {
+		if got[i].Trusted != want {
+			t.Errorf("PR #%d Trusted = %v, want %v", got[i].Number, got[i].Trusted, want)
+		}
+	}
+}
+
+func TestParseDependencyRenovate(t *testing.T) {
+	tests := []struct {
+		name, title, branch, body  string
+		wantFrom, wantTo, wantType string
+	}{
+		{
+			name:     "version change from the body",
+			title:    "Update dependency lodash to v5",
+			branch:   "renovate/lodash-5.x",
+			body:     "| lodash | `4.17.20` -> `5.0.0` |",
+			wantFrom: "4.17.20", wantTo: "5.0.0", wantType: UpdateMajor,
+		},
+		{
+			name:     "pinned range in the body",
+			title:    "Update dependency lodash to v4.17.21",
+			branch:   "renovate/lodash-4.x",
+			body:     "| lodash | `^4.17.20` → `^4.17.21` |",
+			wantFrom: "4.17.20", wantTo: "4.17.21", wantType: UpdatePatch,
+		},
+		{
+			name:   "major branch without a body",
+			title:  "Update dependency lodash to v5",
+			branch: "renovate/major-lodash",
+			wantTo: "v5", wantType: UpdateMajor,
+		},
+		{
+			name:   "no current version",
+			title:  "Update dependency lodash to v4.17.21",
+			branch: "renovate/lodash-4.x",
+			wantTo: "v4.17.21",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := parseDependency(tt.title, tt.branch, tt.body, "")
+			if d.Package != "lodash" || d.From != tt.wantFrom || d.To != tt.wantTo || d.UpdateType != tt.wantType {
+				t.Errorf("parseDependency() = %+v, want lodash %q -> %q (%q)", d, tt.wantFrom, tt.wantTo, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestParseDependencyRenovateSecurity(t *testing.T) {
+	// The [SECURITY] marker is only a title; open Dependabot alerts decide
+	// whether an update is a security fix.
+	if d := parseDependency("Update dependency lodash to v4.17.21 [SECURITY]", "renovate/npm-lodash-vulnerability", "", ""); d.Security || d.Package != "lodash" {
+		t.Errorf("parseDependency() = %+v, want non-security update of lodash", d)
+	}
+}
+
+func TestFilterPRsStats(t *testing.T) {
+	candidates := []PRInfo{
+		{Number: 1, PackageName: "lodash", CIStatus: "success"},
+		{Number: 2, PackageName: "left-pad", CIStatus: "success"},
+		{Number: 3, PackageName: "react", CIStatus: "failure"},
+		{Number: 4, PackageName: "express", CIStatus: "pending"},
+		{Number: 5, PackageName: "vue", CIStatus: "success", Draft: true},
+	}
+	var stats ListStats
+	q := DependencyUpdateQuery{DeniedPackages: []string{"left-pad"}, Stats: &stats}
+
+	filterPRs(candidates, q, true)
+	want := ListStats{Skipped: map[string]int{SkipDeniedPackage: 1, SkipDraft: 1}, Failing: 1, Pending: 1}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
+
+func TestFilterPRsSkipRule(t *testing.T) {
+	candidates := []PRInfo{
+		{Number: 1, PackageName: "@aws-sdk/client-s3", OrgName: "aws-sdk", CIStatus: "success"},
+		{Number: 2, PackageName: "github.com/acme/log", OrgName: "acme", CIStatus: "success"},
+		{Number: 3, PackageName: "react", ToVersion: "19.0.0", CIStatus: "success"},
+		{Number: 4, PackageName: "lodash", UpdateType: "major", CIStatus: "success"},
+		{Number: 5, PackageName: "vue", CIStatus: "success", Draft: true},
+	}
+	q := DependencyUpdateQuery{
+		DeniedPackages:    []string{"@aws-sdk/*", "react@>=19"},
+		DeniedOrgs:        []string{"acme"},
+		DeniedUpdateTypes: []string{"major"},
+		IncludeSkipped:    true,
+	}
+
+	var got []string
+	for _, pr := range filterPRs(candidates, q, false) {
+		got = append(got, pr.SkipRule)
+	}
+	want := []string{"@aws-sdk/*", "acme", "react@>=19", "major updates", "unknown update type"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SkipRule = %q, want %q", got, want)
+	}
+}
+
+func TestFilterPRsAllowRule(t *testing.T) {
+	candidates := []PRInfo{
+		{Number: 1, PackageName: "github.com/acme/log", OrgName: "acme", CIStatus: "success"},
+		{Number: 2, PackageName: "lodash", CIStatus: "success", Security: true},
+		{Number: 3, PackageName: "golang.org/x/net", CIStatus: "success", Draft: true},
+	}
+	q := DependencyUpdateQuery{
+		AllowedOrgs:     []string{"acme"},
+		AllowedPackages: []string{"golang.org/x/*"},
+		ExemptSecurity:  true,
+		IncludeSkipped:  true,
+	}
+
+	got := filterPRs(candidates, q, false)
+	if got[0].AllowRule != "acme" || got[1].AllowRule != "security exemption" {
+		t.Errorf("AllowRule = %q, %q, want acme and security exemption", got[0].AllowRule, got[1].AllowRule)
+	}
+	if got[2].SkipCode != SkipDraft || got[2].SkipRule != "" {
+		t.Errorf("draft PR = %+v, want a draft skip without a rule", got[2])
+	}
+}
+
+func TestFilterPRsCIFilter(t *testing.T) {
+	candidates := []PRInfo{
+		{Number: 1, PackageName: "lodash", CIStatus: "success"},
+		{Number: 2, PackageName: "react", CIStatus: "failure"},
+		{Number: 3, PackageName: "express", CIStatus: "pending"},
+	}
+
+	tests := []struct {
+		filter    string
+		want      []int
+		wantStats ListStats
+	}{
+		{CIPassing, []int{1}, ListStats{Failing: 1, Pending: 1}},
+		{CIPassingOrPending, []int{1, 3}, ListStats{Failing: 1}},
+		{CIAny, []int{1, 2, 3}, ListStats{}},
+		{CIFailing, []int{2}, ListStats{Passing: 1, Pending: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			var stats ListStats
+			var got []int
+			// CIFilter takes precedence over skipFailing.
+			for _, pr := range filterPRs(candidates, DependencyUpdateQuery{CIFilter: tt.filter, Stats: &stats}, true) {
+				got = append(got, pr.Number)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterPRs() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(stats, tt.wantStats) {
+				t.Errorf("stats = %+v, want %+v", stats, tt.wantStats)
+			}
+		})
+	}
+}
+
+func TestFilterPRsWindow(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	candidates := []PRInfo{
+		{Number: 1, PackageName: "lodash", CIStatus: "success", CreatedAt: day(1), UpdatedAt: day(9)},
+		{Number: 2, PackageName: "react", CIStatus: "success", CreatedAt: day(5), UpdatedAt: day(6)},
+		{Number: 3, PackageName: "vue", CIStatus: "success", CreatedAt: day(8), UpdatedAt: day(8)},
+		{Number: 4, PackageName: "express", CIStatus: "success", CreatedAt: day(10), UpdatedAt: day(10)},
+	}
+
+	tests := []struct {
+		name string
+		q    DependencyUpdateQuery
+		want []int
+	}{
+		{"no window", DependencyUpdateQuery{}, []int{1, 2, 3, 4}},
+		{"created after", DependencyUpdateQuery{CreatedAfter: day(5)}, []int{2, 3, 4}},
+		{"created before", DependencyUpdateQuery{CreatedBefore: day(8)}, []int{1, 2}},
+		{"updated since", DependencyUpdateQuery{UpdatedSince: day(8)}, []int{1, 3, 4}},
+		{"combined", DependencyUpdateQuery{CreatedAfter: day(2), CreatedBefore: day(10), UpdatedSince: day(7)}, []int{3}},
+		{"skipped PRs are dropped too", DependencyUpdateQuery{CreatedAfter: day(5), IncludeSkipped: true, DeniedPackages: []string{"vue"}}, []int{2, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stats ListStats
+			tt.q.Stats = &stats
+			var got []int
+			for _, pr := range filterPRs(candidates, tt.q, true) {
+				got = append(got, pr.Number)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterPRs() = %v, want %v", got, tt.want)
+			}
+			if stats.OutOfWindow != len(candidates)-len(tt.want) {
+				t.Errorf("OutOfWindow = %d, want %d", stats.OutOfWindow, len(candidates)-len(tt.want))
+			}
+		})
+	}
+}
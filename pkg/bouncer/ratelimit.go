@@ -0,0 +1,80 @@
+package bouncer
+
+import (
+	"strings"
+	"time"
+)
+
+// rateLimitRetries is how many times a gh call GitHub refuses with a rate
+// limit is retried before its error is returned.
+const rateLimitRetries = 5
+
+// secondaryRateLimitWait is the wait before retrying a call refused by a
+// secondary rate limit; it doubles with every retry. GitHub asks clients to
+// wait at least a minute when it sends no Retry-After header, and gh does not
+// show the header.
+const secondaryRateLimitWait = time.Minute
+
+// rateLimitReset looks up when a token's rate limit on the client's host
+// resets; replaced in tests.
+var rateLimitReset = func(g GitHub, token string) (time.Time, error) {
+	_, reset, err := g.tokenRateLimit(token)
+	return reset, err
+}
+
+// rateLimitKind classifies the error message of a refused gh call.
+type rateLimitKind int
+
+const (
+	notRateLimited rateLimitKind = iota
+	primaryRateLimit
+	secondaryRateLimit
+)
+
+// classifyRateLimit tells whether gh's error message reports the primary
+// rate limit (the hourly request budget) or a secondary one (too many
+// requests at once or too quickly).
+func classifyRateLimit(msg string) rateLimitKind {
+	msg = strings.ToLower(msg)
+	switch {
+	case strings.Contains(msg, "secondary rate limit"),
+		strings.Contains(msg, "abuse detection"),
+		strings.Contains(msg, "submitted too quickly"),
+		strings.Contains(msg, "http 429"):
+		return secondaryRateLimit
+	case strings.Contains(msg, "api rate limit exceeded"),
+		strings.Contains(msg, "rate_limited"):
+		return primaryRateLimit
+	}
+	return notRateLimited
+}
+
+// rateLimitDelay returns how long to wait before retrying a gh call made with
+// token that failed with msg, after attempt retries. ok is false when the
+// call should not be retried: it did not hit a rate limit, it was retried
+// too often, or the limit lifts only after the retry policy's
+// MaxRateLimitWait. A primary limit
+// lifts at its reset, or right away when another pooled token has budget.
+func (g GitHub) rateLimitDelay(msg, token string, attempt int, now time.Time) (delay time.Duration, ok bool) {
+	kind := classifyRateLimit(msg)
+	if kind == notRateLimited || attempt >= rateLimitRetries {
+		return 0, false
+	}
+	if kind == secondaryRateLimit {
+		delay = secondaryRateLimitWait << attempt
+	} else {
+		reset, err := rateLimitReset(g, token)
+		if pool := g.pool(); pool != nil && pool.exhaust(token, reset) {
+			return 0, true
+		}
+		if err != nil {
+			delay = secondaryRateLimitWait
+		} else {
+			delay = max(reset.Sub(now)+time.Second, 0)
+		}
+	}
+	if delay > g.retry().MaxRateLimitWait {
+		return 0, false
+	}
+	return delay, true
+}
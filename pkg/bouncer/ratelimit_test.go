@@ -0,0 +1,102 @@
+package bouncer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClassifyRateLimit(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want rateLimitKind
+	}{
+		{"gh: API rate limit exceeded for user ID 1. (HTTP 403)", primaryRateLimit},
+		{"GraphQL: API rate limit exceeded for user ID 1.", primaryRateLimit},
+		{`{"errors":[{"type":"RATE_LIMITED"}]}`, primaryRateLimit},
+		{"gh: You have exceeded a secondary rate limit. Please wait a few minutes before you try again. (HTTP 403)", secondaryRateLimit},
+		{"You have triggered an abuse detection mechanism.", secondaryRateLimit},
+		{"GraphQL: was submitted too quickly (addComment)", secondaryRateLimit},
+		{"gh: Too Many Requests (HTTP 429)", secondaryRateLimit},
+		{"gh: Resource not accessible by integration (HTTP 403)", notRateLimited},
+		{"", notRateLimited},
+	}
+	for _, tt := range tests {
+		if got := classifyRateLimit(tt.msg); got != tt.want {
+			t.Errorf("classifyRateLimit(%q) = %d, want %d", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimitDelay(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	resets := map[string]time.Time{
+		"soon":  now.Add(10 * time.Minute),
+		"later": now.Add(2 * time.Hour),
+		"past":  now.Add(-time.Minute),
+	}
+	origReset := rateLimitReset
+	defer func() { rateLimitReset = origReset }()
+	rateLimitReset = func(_ GitHub, token string) (time.Time, error) {
+		if reset, ok := resets[token]; ok {
+			return reset, nil
+		}
+		return time.Time{}, errors.New("lookup failed")
+	}
+
+	const primary = "API rate limit exceeded"
+	const secondary = "exceeded a secondary rate limit"
+	tests := []struct {
+		name      string
+		msg       string
+		token     string
+		attempt   int
+		maxWait   time.Duration
+		wantDelay time.Duration
+		wantOK    bool
+	}{
+		{"not rate limited", "HTTP 404", "soon", 0, time.Hour, 0, false},
+		{"primary waits for reset", primary, "soon", 0, time.Hour, 10*time.Minute + time.Second, true},
+		{"primary reset passed", primary, "past", 0, time.Hour, 0, true},
+		{"primary reset too far", primary, "later", 0, time.Hour, 0, false},
+		{"primary reset unknown", primary, "unknown", 0, time.Hour, time.Minute, true},
+		{"secondary first retry", secondary, "", 0, time.Hour, time.Minute, true},
+		{"secondary doubles", secondary, "", 2, time.Hour, 4 * time.Minute, true},
+		{"retries exhausted", secondary, "", rateLimitRetries, time.Hour, 0, false},
+		{"waiting disabled", secondary, "", 0, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := GitHub{Retry: DefaultRetryPolicy}
+			g.Retry.MaxRateLimitWait = tt.maxWait
+			delay, ok := g.rateLimitDelay(tt.msg, tt.token, tt.attempt, now)
+			if delay != tt.wantDelay || ok != tt.wantOK {
+				t.Errorf("rateLimitDelay() = %v, %v, want %v, %v", delay, ok, tt.wantDelay, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRateLimitDelaySwitchesPooledToken(t *testing.T) {
+	origReset := rateLimitReset
+	defer func() { rateLimitReset = origReset; SetTokenPool(nil) }()
+	reset := time.Now().Add(30 * time.Minute)
+	rateLimitReset = func(GitHub, string) (time.Time, error) { return reset, nil }
+
+	pool, err := NewTokenPool("", []Token{{Name: "A", Value: "a"}, {Name: "B", Value: "b"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.rateLimit = func(value string) (int, time.Time, error) { return 5000, reset, nil }
+	SetTokenPool(pool)
+
+	if delay, ok := (GitHub{}).rateLimitDelay("API rate limit exceeded", "a", 0, time.Now()); delay != 0 || !ok {
+		t.Errorf("rateLimitDelay() = %v, %v, want an immediate retry", delay, ok)
+	}
+	if got := pool.pick(""); got.Name != "B" {
+		t.Errorf("pick() after exhausting A = %s, want B", got.Name)
+	}
+	if delay, ok := (GitHub{}).rateLimitDelay("API rate limit exceeded", "b", 0, time.Now()); delay <= 0 || !ok {
+		t.Errorf("rateLimitDelay() with every token exhausted = %v, %v, want a wait until reset", delay, ok)
+	}
+}
@@ -0,0 +1,81 @@
+package bouncer
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrReadOnly is returned for any API call that would change something while
+// read-only mode is on.
+var ErrReadOnly = errors.New("refused in read-only mode")
+
+// readOnly blocks every mutating gh, glab, and Gitea call when set.
+var readOnly bool
+
+// SetReadOnly turns read-only mode on or off. While it is on, calls that
+// could modify a repository, PR, or comment fail with ErrReadOnly before
+// anything is sent.
+func SetReadOnly(on bool) {
+	readOnly = on
+}
+
+// readVerbs are the gh and glab subcommands (e.g. "pr list") that only read.
+// Any other subcommand is treated as a mutation, so new call sites are
+// blocked in read-only mode until they are known to be safe.
+var readVerbs = map[string]bool{
+	"list":   true,
+	"view":   true,
+	"status": true,
+	"checks": true,
+	"diff":   true,
+}
+
+// isMutation reports whether a gh or glab invocation could modify state.
+func isMutation(args []string) bool {
+	if len(args) < 2 {
+		return true
+	}
+	if args[1] == "api" {
+		return apiMutation(args[2:])
+	}
+	return len(args) < 3 || !readVerbs[args[2]]
+}
+
+// apiMutation reports whether the arguments of a gh or glab api call make it
+// a mutation: an explicit non-GET method, request fields without a method
+// (both CLIs then send a POST), or a GraphQL mutation.
+func apiMutation(args []string) bool {
+	var method, query string
+	graphql, fields := false, false
+	for i := 0; i < len(args); i++ {
+		switch a := args[i]; a {
+		case "-X", "--method":
+			if i+1 < len(args) {
+				method = args[i+1]
+				i++
+			}
+		case "-f", "-F", "--field", "--raw-field":
+			fields = true
+			if i+1 < len(args) {
+				if v, ok := strings.CutPrefix(args[i+1], "query="); ok {
+					query = v
+				}
+				i++
+			}
+		case "--input":
+			fields = true
+			i++
+		case "graphql":
+			graphql = true
+		}
+	}
+
+	if graphql {
+		query = strings.TrimSpace(query)
+		return !strings.HasPrefix(query, "query") && !strings.HasPrefix(query, "{")
+	}
+	if method != "" {
+		return !strings.EqualFold(method, "GET")
+	}
+	return fields
+}
@@ -0,0 +1,71 @@
+package bouncer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsMutation(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{"pr list", []string{"gh", "pr", "list", "--repo", "acme/api"}, false},
+		{"pr view", []string{"gh", "pr", "view", "7", "--repo", "acme/api"}, false},
+		{"repo view", []string{"gh", "repo", "view", "acme/api"}, false},
+		{"pr review", []string{"gh", "pr", "review", "--approve", "--repo", "acme/api", "7"}, true},
+		{"pr merge", []string{"gh", "pr", "merge", "--auto", "--squash", "--repo", "acme/api", "7"}, true},
+		{"pr comment", []string{"gh", "pr", "comment", "7", "--repo", "acme/api", "--body", "@dependabot rebase"}, true},
+		{"unknown subcommand", []string{"gh", "pr", "unknown"}, true},
+		{"api get", []string{"gh", "api", "--paginate", "repos/acme/api/dependabot/alerts?state=open"}, false},
+		{"api explicit get", []string{"gh", "api", "-X", "GET", "search/issues", "-f", "q=is:pr"}, false},
+		{"api fields imply post", []string{"gh", "api", "repos/acme/api/issues/7/comments", "-f", "body=hi"}, true},
+		{"api delete", []string{"gh", "api", "--method", "DELETE", "repos/acme/api/git/refs/heads/x"}, true},
+		{"graphql query", []string{"gh", "api", "graphql", "-f", "query=" + defaultBranchStatusQuery, "-f", "owner=acme"}, false},
+		{"graphql mutation", []string{"gh", "api", "graphql", "-f", "query=" + revertMutation, "-f", "id=1"}, true},
+		{"glab get", []string{"glab", "api", "projects/acme%2Fapi/merge_requests/7"}, false},
+		{"glab put", []string{"glab", "api", "-X", "PUT", "projects/acme%2Fapi/merge_requests/7/rebase"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isMutation(tt.args); got != tt.want {
+				t.Errorf("isMutation(%q) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadOnlyRefusesMutations(t *testing.T) {
+	SetReadOnly(true)
+	defer SetReadOnly(false)
+
+	if _, _, err := (GitHub{}).exec(context.Background(), "gh", "pr", "review", "--approve", "--repo", "acme/api", "7"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("exec(pr review) error = %v, want ErrReadOnly", err)
+	}
+	if err := (GitHub{}).Approve(context.Background(), "acme", "api", 7); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("GitHub.Approve() error = %v, want ErrReadOnly", err)
+	}
+
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	g := Gitea{BaseURL: srv.URL}
+	if err := g.Close(context.Background(), "acme", "api", 7); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Gitea.Close() error = %v, want ErrReadOnly", err)
+	}
+	if requests != 0 {
+		t.Errorf("read-only mode sent %d mutating requests", requests)
+	}
+	if _, err := g.BaseCIStatus(context.Background(), "acme", "api"); err != nil {
+		t.Errorf("Gitea.BaseCIStatus() error = %v, want reads to succeed", err)
+	}
+}
@@ -0,0 +1,146 @@
+package bouncer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultReplyWait is how long to watch for Dependabot's reply after posting
+// one of its commands.
+const DefaultReplyWait = 10 * time.Second
+
+// replyPoll is how often the PR's comments are read while waiting.
+const replyPoll = 2 * time.Second
+
+// dependabotLogin is the account Dependabot comments as.
+const dependabotLogin = "dependabot[bot]"
+
+// replyWait returns how long the client watches for Dependabot's reply:
+// DefaultReplyWait when ReplyWait is zero, none when it is negative.
+func (g GitHub) replyWait() time.Duration {
+	if g.ReplyWait == 0 {
+		return DefaultReplyWait
+	}
+	return max(g.ReplyWait, 0)
+}
+
+// ErrCommandRejected is returned when Dependabot answers a command with a
+// reply, which it only does when it will not carry it out.
+var ErrCommandRejected = errors.New("Dependabot rejected the command")
+
+// issueComment is the part of a GitHub issue comment that replies are
+// matched on.
+type issueComment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+	User struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// commentURLID matches the comment ID at the end of a comment URL, as
+// printed by gh pr comment.
+var commentURLID = regexp.MustCompile(`#issuecomment-(\d+)\s*$`)
+
+// isDependabotCommand reports whether a comment body is addressed to
+// Dependabot.
+func isDependabotCommand(body string) bool {
+	return strings.HasPrefix(strings.TrimSpace(body), "@dependabot ")
+}
+
+// postDependabotCommand comments "@dependabot <command>" on a PR and waits
+// for Dependabot's reply.
+func (g GitHub) postDependabotCommand(ctx context.Context, desc, owner, repo string, number int, command string) error {
+	out, err := g.output(ctx, desc, "gh", "pr", "comment",
+		"--repo", owner+"/"+repo, fmt.Sprintf("%d", number),
+		"--body", "@dependabot "+command)
+	if err != nil {
+		return err
+	}
+	m := commentURLID.FindStringSubmatch(strings.TrimSpace(string(out)))
+	if m == nil {
+		log.Printf("Warning: cannot watch for Dependabot's reply on %s/%s#%d: unexpected gh output %q\n", owner, repo, number, out)
+		return nil
+	}
+	id, _ := strconv.ParseInt(m[1], 10, 64)
+	return g.awaitDependabotReply(ctx, desc, owner, repo, number, id)
+}
+
+// latestCommentID returns the ID of the newest comment on a PR, or 0 when it
+// has none.
+func (g GitHub) latestCommentID(ctx context.Context, owner, repo string, number int) (int64, error) {
+	comments, err := g.issueComments(ctx, owner, repo, number)
+	if err != nil {
+		return 0, err
+	}
+	var latest int64
+	for _, c := range comments {
+		latest = max(latest, c.ID)
+	}
+	return latest, nil
+}
+
+// awaitDependabotReply watches a PR for up to the reply wait for a
+// Dependabot comment newer than the comment with ID after. A reply means the
+// command was rejected and is returned as ErrCommandRejected; no reply means
+// it was accepted. Failures to read the comments are logged, and the command
+// is then assumed accepted.
+func (g GitHub) awaitDependabotReply(ctx context.Context, desc, owner, repo string, number int, after int64) error {
+	wait := g.replyWait()
+	if wait <= 0 {
+		return nil
+	}
+	ctx, cancel := callContext(ctx, owner+"/"+repo)
+	defer cancel()
+	deadline := time.Now().Add(wait)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(min(replyPoll, time.Until(deadline))):
+		}
+		comments, err := g.issueComments(ctx, owner, repo, number)
+		if err != nil {
+			log.Printf("Warning: cannot read Dependabot's reply on %s/%s#%d: %v\n", owner, repo, number, err)
+			return nil
+		}
+		if reply, ok := dependabotReply(comments, after); ok {
+			return fmt.Errorf("failed to %s: %w: %s", desc, ErrCommandRejected, reply)
+		}
+		if !time.Now().Before(deadline) {
+			return nil
+		}
+	}
+}
+
+// dependabotReply returns the first line of the first Dependabot comment
+// newer than the comment with ID after.
+func dependabotReply(comments []issueComment, after int64) (string, bool) {
+	for _, c := range comments {
+		if c.ID > after && strings.EqualFold(c.User.Login, dependabotLogin) {
+			line, _, _ := strings.Cut(strings.TrimSpace(c.Body), "\n")
+			return strings.TrimSpace(line), true
+		}
+	}
+	return "", false
+}
+
+// issueComments lists the comments on a PR, oldest first.
+func (g GitHub) issueComments(ctx context.Context, owner, repo string, number int) ([]issueComment, error) {
+	out, err := g.output(ctx, "gh api comments", "gh", "api", "--paginate",
+		fmt.Sprintf("repos/%s/%s/issues/%d/comments?per_page=100", owner, repo, number))
+	if err != nil {
+		return nil, err
+	}
+	comments, err := decodePages[issueComment](out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse gh output: %w", err)
+	}
+	return comments, nil
+}
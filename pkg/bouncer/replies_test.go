@@ -0,0 +1,77 @@
+package bouncer
+
+import "testing"
+
+func TestDependabotReply(t *testing.T) {
+	comment := func(id int64, login, body string) issueComment {
+		c := issueComment{ID: id, Body: body}
+		c.User.Login = login
+		return c
+	}
+	comments := []issueComment{
+		comment(10, "dependabot[bot]", "Bumps lodash from 4.17.20 to 4.17.21."),
+		comment(20, "octocat", "@dependabot recreate"),
+		comment(30, "renovate[bot]", "Not me"),
+		comment(40, "dependabot[bot]", "Sorry, only users with push access can use that command.\n\nMore text"),
+	}
+
+	tests := []struct {
+		name   string
+		after  int64
+		want   string
+		wantOK bool
+	}{
+		{"reply after the command", 20, "Sorry, only users with push access can use that command.", true},
+		{"older comments ignored", 40, "", false},
+		{"first reply wins", 0, "Bumps lodash from 4.17.20 to 4.17.21.", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := dependabotReply(comments, tt.after)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("dependabotReply(after %d) = %q, %v, want %q, %v", tt.after, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCommentURLID(t *testing.T) {
+	tests := []struct {
+		out  string
+		want string
+	}{
+		{"https://github.com/acme/api/pull/7#issuecomment-123456\n", "123456"},
+		{"https://github.com/acme/api/pull/7\n", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		var got string
+		if m := commentURLID.FindStringSubmatch(tt.out); m != nil {
+			got = m[1]
+		}
+		if got != tt.want {
+			t.Errorf("comment ID in %q = %q, want %q", tt.out, got, tt.want)
+		}
+	}
+}
+
+func TestIsDependabotCommand(t *testing.T) {
+	tests := []struct {
+		body string
+		want bool
+	}{
+		{"@dependabot squash and merge", true},
+		{"  @dependabot merge", true},
+		{"LGTM", false},
+		{"", false},
+		{"@dependabotx merge", false},
+	}
+
+	for _, tt := range tests {
+		if got := isDependabotCommand(tt.body); got != tt.want {
+			t.Errorf("isDependabotCommand(%q) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package bouncer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RepoInfo describes a repository returned by `gh repo list`.
+type RepoInfo struct {
+	NameWithOwner string `json:"nameWithOwner"`
+	IsArchived    bool   `json:"isArchived"`
+}
+
+// RepoFilter selects repositories discovered in an organization. Include and
+// Exclude are glob patterns (path.Match syntax) matched case-insensitively
+// against the repository name without its owner.
+type RepoFilter struct {
+	Include         []string // when non-empty, only matching repositories are kept
+	Exclude         []string // matching repositories are dropped, even if included
+	IncludeArchived bool
+}
+
+// MergedPR describes a merged Dependabot pull request, or a closed one as
+// listed by ListClosedDependabotPRs.
+type MergedPR struct {
+	Number      int
+	Title       string
+	URL         string
+	PackageName string
+	ToVersion   string
+	UpdateType  string // major, minor, patch, or empty when unknown
+	CreatedAt   time.Time
+	MergedAt    time.Time // zero for a PR closed without merging
+	ClosedAt    time.Time
+}
+
+// ListOwnerRepos lists the repositories belonging to a user or organization.
+func (g GitHub) ListOwnerRepos(ctx context.Context, owner string) ([]RepoInfo, error) {
+	out, err := g.output(ctx, "gh repo list", "gh", "repo", "list", owner,
+		"--json", "nameWithOwner,isArchived",
+		"--limit", "1000",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []RepoInfo
+	if err := json.Unmarshal(out, &repos); err != nil {
+		return nil, fmt.Errorf("failed to parse gh output: %w", err)
+	}
+	return repos, nil
+}
+
+// ListOrgRepos lists the repositories of an organization (or user) that pass
+// the filter, as "owner/repo" strings.
+func (g GitHub) ListOrgRepos(ctx context.Context, org string, f RepoFilter) ([]string, error) {
+	repos, err := g.ListOwnerRepos(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	return filterRepos(repos, f), nil
+}
+
+// ListTeamRepos lists the repositories an organization's team has access to
+// that pass the filter, as "owner/repo" strings. Listing a team needs a token
+// that can read the organization (read:org for classic tokens).
+func (g GitHub) ListTeamRepos(ctx context.Context, org, team string, f RepoFilter) ([]string, error) {
+	out, err := g.output(ctx, "list team repositories", "gh", "api", "--paginate",
+		"orgs/"+org+"/teams/"+team+"/repos?per_page=100",
+		"--jq", ".[] | {nameWithOwner: .full_name, isArchived: .archived}",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []RepoInfo
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for dec.More() {
+		var r RepoInfo
+		if err := dec.Decode(&r); err != nil {
+			return nil, fmt.Errorf("failed to parse gh output: %w", err)
+		}
+		repos = append(repos, r)
+	}
+	return filterRepos(repos, f), nil
+}
+
+// filterRepos applies a RepoFilter to a list of repositories.
+func filterRepos(repos []RepoInfo, f RepoFilter) []string {
+	var names []string
+	for _, r := range repos {
+		if r.IsArchived && !f.IncludeArchived {
+			continue
+		}
+		name := strings.ToLower(r.NameWithOwner)
+		if i := strings.Index(name, "/"); i >= 0 {
+			name = name[i+1:]
+		}
+		if len(f.Include) > 0 && !matchesAnyGlob(name, f.Include) {
+			continue
+		}
+		if matchesAnyGlob(name, f.Exclude) {
+			continue
+		}
+		names = append(names, r.NameWithOwner)
+	}
+	return names
+}
+
+// matchesAnyGlob reports whether name matches any of the glob patterns.
+// Malformed patterns never match.
+func matchesAnyGlob(name string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, err := path.Match(strings.ToLower(p), name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ListMergedDependabotPRs lists Dependabot PRs merged into the repository
+// since the given time.
+func (g GitHub) ListMergedDependabotPRs(ctx context.Context, owner, repo string, since time.Time) ([]MergedPR, error) {
+	return g.listDonePRs(ctx, owner, repo, "merged", since)
+}
+
+// ListClosedDependabotPRs lists Dependabot PRs closed since the given time,
+// merged or not. MergedAt is zero for the PRs closed without merging.
+func (g GitHub) ListClosedDependabotPRs(ctx context.Context, owner, repo string, since time.Time) ([]MergedPR, error) {
+	return g.listDonePRs(ctx, owner, repo, "closed", since)
+}
+
+// listDonePRs lists the Dependabot PRs of the repository in the given
+// state, merged or closed, since the given time.
+func (g GitHub) listDonePRs(ctx context.Context, owner, repo, state string, since time.Time) ([]MergedPR, error) {
+	out, err := g.output(ctx, "gh pr list", "gh", "pr", "list",
+		"--repo", owner+"/"+repo,
+		"--state", state,
+		"--search", state+":>="+since.Format("2006-01-02"),
+		"--json", "number,title,url,author,createdAt,mergedAt,closedAt",
+		"--limit", "1000",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var pulls []struct {
+		Number    int       `json:"number"`
+		Title     string    `json:"title"`
+		URL       string    `json:"url"`
+		CreatedAt time.Time `json:"createdAt"`
+		MergedAt  time.Time `json:"mergedAt"`
+		ClosedAt  time.Time `json:"closedAt"`
+		Author    struct {
+			ID    string `json:"id"`
+			Login string `json:"login"`
+		} `json:"author"`
+	}
+	if err := json.Unmarshal(out, &pulls); err != nil {
+		return nil, fmt.Errorf("failed to parse gh output: %w", err)
+	}
+
+	var merged []MergedPR
+	for _, p := range pulls {
+		if !IsBot(p.Author.Login, p.Author.ID) {
+			continue
+		}
+		packageName, _ := extractPackageInfo(p.Title)
+		from, to := parseVersions(p.Title)
+		merged = append(merged, MergedPR{
+			Number:      p.Number,
+			Title:       p.Title,
+			URL:         p.URL,
+			PackageName: packageName,
+			ToVersion:   to,
+			UpdateType:  updateType(from, to),
+			CreatedAt:   p.CreatedAt,
+			MergedAt:    p.MergedAt,
+			ClosedAt:    p.ClosedAt,
+		})
+	}
+	return merged, nil
+}
+
+// DependabotAlertsEnabled reports whether Dependabot vulnerability alerts are
+// enabled for the repository. GitHub answers 204 when enabled and 404 when not.
+func (g GitHub) DependabotAlertsEnabled(ctx context.Context, owner, repo string) (bool, error) {
+	args := []string{"gh", "api", "repos/" + owner + "/" + repo + "/vulnerability-alerts", "--silent"}
+	ctx, cancel := callContext(ctx, owner+"/"+repo)
+	defer cancel()
+	for attempt := 0; ; attempt++ {
+		cmd, token, err := g.exec(ctx, args...)
+		if err != nil {
+			return false, fmt.Errorf("failed to check vulnerability alerts: %w", err)
+		}
+		out, err := runCall(ctx, cmd, true)
+		if err == nil {
+			return true, nil
+		}
+		if ctxErr := context.Cause(ctx); ctxErr != nil {
+			return false, fmt.Errorf("failed to check vulnerability alerts: %w", ctxErr)
+		}
+		msg := strings.TrimSpace(string(out))
+		if strings.Contains(msg, "HTTP 404") {
+			return false, nil
+		}
+		if g.retryCall(ctx, args, token, msg, attempt) {
+			continue
+		}
+		recordCallError(owner + "/" + repo)
+		return false, fmt.Errorf("failed to check vulnerability alerts: %s%s", msg, permissionHint(args, msg))
+	}
+}
+
+// defaultBranchStatusQuery reads the combined check and status rollup of the
+// default branch's head commit.
+const defaultBranchStatusQuery = `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    defaultBranchRef {
+      target {
+        ... on Commit { statusCheckRollup { state } }
+      }
+    }
+  }
+}`
+
+// DefaultBranchCIStatus returns "success", "failure", or "pending" for the
+// latest commit on the repository's default branch. A commit without any
+// checks counts as success, since there is nothing failing to merge into.
+func (g GitHub) DefaultBranchCIStatus(ctx context.Context, owner, repo string) (string, error) {
+	out, err := g.output(ctx, "get default branch status", "gh", "api", "graphql",
+		"-f", "query="+defaultBranchStatusQuery,
+		"-f", "owner="+owner,
+		"-f", "name="+repo,
+		"--jq", ".data.repository.defaultBranchRef.target.statusCheckRollup.state // \"\"",
+	)
+	if err != nil {
+		return "", err
+	}
+	return rollupStatus(strings.TrimSpace(string(out))), nil
+}
+
+// rollupStatus maps a GraphQL StatusState onto the CI statuses used for PRs.
+func rollupStatus(state string) string {
+	switch state {
+	case "", "SUCCESS":
+		return "success"
+	case "PENDING", "EXPECTED":
+		return "pending"
+	default:
+		return "failure"
+	}
+}
+
+// Alert is an open Dependabot alert on a package.
+type Alert struct {
+	Package         string `json:"package"`
+	VulnerableRange string `json:"vulnerable_version_range"` // e.g. ">= 4.0.0, < 4.17.21"
+	FirstPatched    string `json:"first_patched_version"`    // empty when no fix is released
+}
+
+// OpenAlerts returns the open Dependabot alerts of the repository, keyed by
+// lower-cased package name.
+func (g GitHub) OpenAlerts(ctx context.Context, owner, repo string) (map[string][]Alert, error) {
+	out, err := g.output(ctx, "list Dependabot alerts", "gh", "api", "--paginate",
+		"repos/"+owner+"/"+repo+"/dependabot/alerts?state=open&per_page=100",
+		"--jq", `.[] | {package: .dependency.package.name, vulnerable_version_range: .security_vulnerability.vulnerable_version_range, first_patched_version: (.security_vulnerability.first_patched_version.identifier // "")}`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := make(map[string][]Alert)
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for dec.More() {
+		var a Alert
+		if err := dec.Decode(&a); err != nil {
+			return nil, fmt.Errorf("failed to parse gh output: %w", err)
+		}
+		key := strings.ToLower(a.Package)
+		alerts[key] = append(alerts[key], a)
+	}
+	return alerts, nil
+}
+
+// OpenAlertPackages returns the lower-cased names of packages with open
+// Dependabot alerts in the repository.
+func (g GitHub) OpenAlertPackages(ctx context.Context, owner, repo string) (map[string]bool, error) {
+	alerts, err := g.OpenAlerts(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	packages := make(map[string]bool, len(alerts))
+	for name := range alerts {
+		packages[name] = true
+	}
+	return packages, nil
+}
+
+// Fixes reports whether updating the alert's package from one version to
+// another fixes it: from is in the vulnerable range and the first patched
+// version is at or below to. An alert without a patched version, or a
+// version or range that cannot be parsed, is never fixed.
+func (a Alert) Fixes(from, to string) bool {
+	patched, ok := versionParts(a.FirstPatched)
+	if !ok {
+		return false
+	}
+	t, ok := versionParts(to)
+	if !ok || slices.Compare(patched[:], t[:]) > 0 {
+		return false
+	}
+	return inVersionRange(from, a.VulnerableRange)
+}
+
+// inVersionRange reports whether version satisfies every comma-separated
+// constraint of an advisory range such as ">= 4.0.0, < 4.17.21" or "= 1.2.3".
+func inVersionRange(version, rng string) bool {
+	if _, ok := versionParts(version); !ok || strings.TrimSpace(rng) == "" {
+		return false
+	}
+	for _, part := range strings.Split(rng, ",") {
+		part = strings.TrimSpace(part)
+		var c versionConstraint
+		for _, op := range constraintOps {
+			if strings.HasPrefix(part, op) {
+				c.op = op
+				break
+			}
+		}
+		parts, ok := versionParts(strings.TrimSpace(strings.TrimPrefix(part, c.op)))
+		if c.op == "" || !ok {
+			return false
+		}
+		c.version = parts
+		if !c.matches(version) {
+			return false
+		}
+	}
+	return true
+}
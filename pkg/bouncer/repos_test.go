@@ -0,0 +1,123 @@
+package bouncer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterRepos(t *testing.T) {
+	repos := []RepoInfo{
+		{NameWithOwner: "acme/api-gateway"},
+		{NameWithOwner: "acme/api-users"},
+		{NameWithOwner: "acme/API-Legacy", IsArchived: true},
+		{NameWithOwner: "acme/web"},
+		{NameWithOwner: "acme/web-deprecated"},
+	}
+
+	tests := []struct {
+		name   string
+		filter RepoFilter
+		want   []string
+	}{
+		{
+			name:   "no filter skips archived",
+			filter: RepoFilter{},
+			want:   []string{"acme/api-gateway", "acme/api-users", "acme/web", "acme/web-deprecated"},
+		},
+		{
+			name:   "include archived",
+			filter: RepoFilter{IncludeArchived: true},
+			want:   []string{"acme/api-gateway", "acme/api-users", "acme/API-Legacy", "acme/web", "acme/web-deprecated"},
+		},
+		{
+			name:   "include glob",
+			filter: RepoFilter{Include: []string{"api-*"}},
+			want:   []string{"acme/api-gateway", "acme/api-users"},
+		},
+		{
+			name:   "include glob is case-insensitive",
+			filter: RepoFilter{Include: []string{"API-*"}, IncludeArchived: true},
+			want:   []string{"acme/api-gateway", "acme/api-users", "acme/API-Legacy"},
+		},
+		{
+			name:   "exclude glob",
+			filter: RepoFilter{Exclude: []string{"*-deprecated", "api-users"}},
+			want:   []string{"acme/api-gateway", "acme/web"},
+		},
+		{
+			name:   "exclude wins over include",
+			filter: RepoFilter{Include: []string{"web*"}, Exclude: []string{"*-deprecated"}},
+			want:   []string{"acme/web"},
+		},
+		{
+			name:   "malformed pattern never matches",
+			filter: RepoFilter{Include: []string{"[api"}},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterRepos(repos, tt.filter)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterRepos() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRollupStatus(t *testing.T) {
+	tests := map[string]string{
+		"":         "success",
+		"SUCCESS":  "success",
+		"PENDING":  "pending",
+		"EXPECTED": "pending",
+		"FAILURE":  "failure",
+		"ERROR":    "failure",
+	}
+	for state, want := range tests {
+		if got := rollupStatus(state); got != want {
+			t.Errorf("rollupStatus(%q) = %q, want %q", state, got, want)
+		}
+	}
+}
+
+func TestAlertFixes(t *testing.T) {
+	lodash := Alert{Package: "lodash", VulnerableRange: ">= 4.0.0, < 4.17.21", FirstPatched: "4.17.21"}
+	tests := []struct {
+		name     string
+		alert    Alert
+		from, to string
+		want     bool
+	}{
+		{"patch fix", lodash, "4.17.20", "4.17.21", true},
+		{"past the fix", lodash, "4.17.15", "4.18.0", true},
+		{"not far enough", lodash, "4.17.15", "4.17.20", false},
+		{"from outside the range", lodash, "3.10.1", "4.17.21", false},
+		{"major bump of an unaffected version", lodash, "4.17.21", "5.0.0", false},
+		{"unknown from", lodash, "", "4.17.21", false},
+		{"no patched version", Alert{VulnerableRange: "< 2.0.0"}, "1.0.0", "2.0.0", false},
+		{"exact range", Alert{VulnerableRange: "= 1.2.3", FirstPatched: "1.2.4"}, "1.2.3", "1.2.4", true},
+		{"unparsable range", Alert{VulnerableRange: "all", FirstPatched: "1.2.4"}, "1.2.3", "1.2.4", false},
+	}
+	for _, tt := range tests {
+		if got := tt.alert.Fixes(tt.from, tt.to); got != tt.want {
+			t.Errorf("%s: Fixes(%q, %q) = %v, want %v", tt.name, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestFixesAlert(t *testing.T) {
+	alerts := map[string][]Alert{
+		"lodash": {{Package: "lodash", VulnerableRange: "< 4.17.21", FirstPatched: "4.17.21"}},
+	}
+	if !fixesAlert(alerts, dependency{Package: "Lodash", From: "4.17.20", To: "4.17.21"}) {
+		t.Error("fixesAlert() = false for a fixing bump")
+	}
+	if fixesAlert(alerts, dependency{Package: "lodash", From: "4.17.21", To: "5.0.0"}) {
+		t.Error("fixesAlert() = true for a bump that does not fix the alert")
+	}
+	if fixesAlert(alerts, dependency{Package: "react", From: "18.0.0", To: "19.0.0"}) {
+		t.Error("fixesAlert() = true for a package without alerts")
+	}
+}
@@ -0,0 +1,128 @@
+package bouncer
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+// RetryPolicy sets how gh calls that fail transiently, with a server error
+// or a network failure, or are refused by a GitHub rate limit are retried.
+type RetryPolicy struct {
+	Attempts int           // tries in all, the first included; 1 disables retries
+	Backoff  time.Duration // wait before the first retry, doubled for every further one
+	Jitter   float64       // each wait is varied at random by up to this fraction of it
+	// MaxRateLimitWait is the longest a call refused by a rate limit waits
+	// for it to lift before it is retried; zero fails such calls right away.
+	MaxRateLimitWait time.Duration
+}
+
+// DefaultRetryPolicy tries a call 3 times, 1s and then 2s apart, give or take
+// 20%, and waits up to an hour, a full window of the primary rate limit, for
+// a rate limit to lift.
+var DefaultRetryPolicy = RetryPolicy{Attempts: 3, Backoff: time.Second, Jitter: 0.2, MaxRateLimitWait: time.Hour}
+
+// retry returns the client's retry policy, DefaultRetryPolicy when unset.
+func (g GitHub) retry() RetryPolicy {
+	if g.Retry == (RetryPolicy{}) {
+		return DefaultRetryPolicy
+	}
+	return g.Retry
+}
+
+// transientErrors are the parts of gh error messages that mark a failure as
+// likely to pass: GitHub's server errors, and network failures reaching it.
+var transientErrors = []string{
+	"http 500", "http 502", "http 503", "http 504",
+	"something went wrong while executing your query",
+	"connection reset by peer",
+	"connection refused",
+	"i/o timeout",
+	"tls handshake timeout",
+	"unexpected eof",
+	"timeout awaiting response headers",
+}
+
+// isTransient reports whether gh's error message is one of transientErrors.
+func isTransient(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, e := range transientErrors {
+		if strings.Contains(msg, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// transientDelay returns how long to wait before retrying a call that failed
+// transiently after attempt retries; ok is false when the policy allows no
+// more. rnd returns a number in [0, 1).
+func transientDelay(p RetryPolicy, attempt int, rnd func() float64) (delay time.Duration, ok bool) {
+	if attempt+1 >= p.Attempts {
+		return 0, false
+	}
+	delay = p.Backoff << attempt
+	if p.Jitter > 0 {
+		delay += time.Duration(float64(delay) * p.Jitter * (2*rnd() - 1))
+	}
+	return max(delay, 0), true
+}
+
+// idempotentVerbs are the gh pr subcommands that change something but come
+// to the same when repeated, so a transient failure can be retried even if
+// GitHub carried the first try out. gh pr merge --auto covers the GraphQL
+// auto-merge request. gh pr review is left out: each try submits another
+// review, so a repeat would approve a PR twice and post its body again.
+var idempotentVerbs = map[string]bool{"merge": true, "ready": true, "close": true}
+
+// retryCall reports whether a gh call made with token that failed with msg
+// should be tried again after attempt retries, and waits before it: for the
+// rate limit the call hit to lift, or under the retry policy when it failed
+// transiently. GitHub refuses rate-limited calls outright, so any call is
+// retried then; after a transient failure, calls that change something are
+// only retried when repeating them is harmless (see idempotentVerbs), not
+// comments, say. A call is not retried once ctx is done, and the wait ends
+// early, without a retry, when it is done.
+func (g GitHub) retryCall(ctx context.Context, args []string, token, msg string, attempt int) bool {
+	if args[0] != "gh" || ctx.Err() != nil {
+		return false
+	}
+	var delay time.Duration
+	if classifyRateLimit(msg) != notRateLimited {
+		var ok bool
+		if delay, ok = g.rateLimitDelay(msg, token, attempt, time.Now()); !ok {
+			return false
+		}
+		if delay > 0 {
+			log.Printf("GitHub rate limit hit; retrying in %s\n", delay.Round(time.Second))
+		}
+	} else {
+		if !isTransient(msg) || (isMutation(args) && !(args[1] == "pr" && len(args) > 2 && idempotentVerbs[args[2]])) {
+			return false
+		}
+		var ok bool
+		if delay, ok = transientDelay(g.retry(), attempt, rand.Float64); !ok {
+			return false
+		}
+		log.Printf("Retrying in %s: %s\n", delay.Round(time.Millisecond), firstLine(msg))
+	}
+	if delay <= 0 {
+		return true
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,77 @@
+package bouncer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want bool
+	}{
+		{"gh: Server Error (HTTP 502)", true},
+		{"HTTP 503: Service Unavailable", true},
+		{"GraphQL: Something went wrong while executing your query. Please include `ABCD` when reporting this issue.", true},
+		{`Post "https://api.github.com/graphql": read tcp 10.0.0.1:5000->140.82.112.6:443: read: connection reset by peer`, true},
+		{`Get "https://api.github.com/user": net/http: TLS handshake timeout`, true},
+		{"gh: Not Found (HTTP 404)", false},
+		{"gh: Resource not accessible by integration (HTTP 403)", false},
+		{"GraphQL: Pull request Auto merge is not allowed for this repository (enablePullRequestAutoMerge)", false},
+	}
+	for _, tt := range tests {
+		if got := isTransient(tt.msg); got != tt.want {
+			t.Errorf("isTransient(%q) = %v, want %v", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestTransientDelay(t *testing.T) {
+	p := RetryPolicy{Attempts: 4, Backoff: time.Second, Jitter: 0.5}
+	tests := []struct {
+		name    string
+		attempt int
+		rnd     float64
+		want    time.Duration
+		wantOK  bool
+	}{
+		{"first retry", 0, 0.5, time.Second, true},
+		{"doubles", 2, 0.5, 4 * time.Second, true},
+		{"shortest jitter", 1, 0, time.Second, true},
+		{"longest jitter", 1, 1, 3 * time.Second, true},
+		{"attempts used up", 3, 0.5, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := transientDelay(p, tt.attempt, func() float64 { return tt.rnd })
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("transientDelay(attempt %d) = %v, %v, want %v, %v", tt.attempt, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	if _, ok := transientDelay(RetryPolicy{Attempts: 1, Backoff: time.Second}, 0, func() float64 { return 0 }); ok {
+		t.Error("transientDelay() with 1 attempt allowed a retry")
+	}
+}
+
+func TestRetryCallSkipsNonIdempotentMutations(t *testing.T) {
+	const msg = "gh: Server Error (HTTP 502)"
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"gh", "pr", "list", "--repo", "acme/api"}, true},
+		{[]string{"gh", "pr", "review", "--approve", "--repo", "acme/api", "1"}, false},
+		{[]string{"gh", "pr", "merge", "--auto", "--squash", "--repo", "acme/api", "1"}, true},
+		{[]string{"gh", "pr", "comment", "--repo", "acme/api", "1", "--body", "@dependabot recreate"}, false},
+		{[]string{"gh", "api", "graphql", "-f", "query=mutation { x }"}, false},
+		{[]string{"glab", "mr", "list"}, false},
+	}
+	for _, tt := range tests {
+		if got := (GitHub{Retry: RetryPolicy{Attempts: 2}}).retryCall(context.Background(), tt.args, "", msg, 0); got != tt.want {
+			t.Errorf("retryCall(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
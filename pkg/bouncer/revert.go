@@ -0,0 +1,96 @@
+package bouncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// revertMutation opens a revert PR using GitHub's revertPullRequest mutation,
+// which has no REST or gh CLI equivalent.
+const revertMutation = `mutation($id: ID!, $title: String, $body: String) {
+  revertPullRequest(input: {pullRequestId: $id, title: $title, body: $body}) {
+    revertPullRequest { number url }
+  }
+}`
+
+// FindMergedPR returns the most recently merged Dependabot PR that updated
+// packageName since the given time. The package name is matched
+// case-insensitively.
+func (g GitHub) FindMergedPR(ctx context.Context, owner, repo, packageName string, since time.Time) (MergedPR, error) {
+	merged, err := g.ListMergedDependabotPRs(ctx, owner, repo, since)
+	if err != nil {
+		return MergedPR{}, err
+	}
+	pr, ok := latestMergedFor(merged, packageName)
+	if !ok {
+		return MergedPR{}, fmt.Errorf("no Dependabot PR for %s merged in %s/%s since %s", packageName, owner, repo, since.Format("2006-01-02"))
+	}
+	return pr, nil
+}
+
+// latestMergedFor picks the most recently merged PR for packageName.
+func latestMergedFor(merged []MergedPR, packageName string) (MergedPR, bool) {
+	var latest MergedPR
+	found := false
+	for _, pr := range merged {
+		if !strings.EqualFold(pr.PackageName, packageName) {
+			continue
+		}
+		if !found || pr.MergedAt.After(latest.MergedAt) {
+			latest, found = pr, true
+		}
+	}
+	return latest, found
+}
+
+// RevertPR opens a pull request reverting a merged pull request and returns
+// the new PR's number and URL.
+func (g GitHub) RevertPR(ctx context.Context, owner, repo string, pr MergedPR) (int, string, error) {
+	id, err := g.output(ctx, "gh pr view", "gh", "pr", "view", fmt.Sprintf("%d", pr.Number),
+		"--repo", owner+"/"+repo,
+		"--json", "id", "--jq", ".id",
+	)
+	if err != nil {
+		return 0, "", err
+	}
+
+	out, err := g.output(ctx, "revert PR", "gh", "api", "graphql",
+		"-f", "query="+revertMutation,
+		"-f", "id="+strings.TrimSpace(string(id)),
+		"-f", fmt.Sprintf(`title=Revert "%s"`, pr.Title),
+		"-f", fmt.Sprintf("body=Reverts #%d", pr.Number),
+	)
+	if err != nil {
+		return 0, "", err
+	}
+
+	var resp struct {
+		Data struct {
+			RevertPullRequest struct {
+				RevertPullRequest struct {
+					Number int    `json:"number"`
+					URL    string `json:"url"`
+				} `json:"revertPullRequest"`
+			} `json:"revertPullRequest"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return 0, "", fmt.Errorf("failed to parse gh output: %w", err)
+	}
+	revert := resp.Data.RevertPullRequest.RevertPullRequest
+	return revert.Number, revert.URL, nil
+}
+
+// IgnoreEntry returns the dependabot.yml ignore entry that stops Dependabot
+// proposing the version a merged PR updated to. Dependabot only acts on
+// @dependabot commands left on open PRs, so commenting on the merged PR would
+// do nothing; the entry goes under the matching updates block instead.
+func IgnoreEntry(pr MergedPR) (string, error) {
+	if pr.PackageName == "" || pr.ToVersion == "" {
+		return "", fmt.Errorf("cannot build an ignore entry for PR #%d: unknown package or version", pr.Number)
+	}
+	return fmt.Sprintf("ignore:\n  - dependency-name: %q\n    versions: [%q]\n", pr.PackageName, pr.ToVersion), nil
+}
@@ -0,0 +1,53 @@
+package bouncer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatestMergedFor(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	merged := []MergedPR{
+		{Number: 1, PackageName: "lodash", MergedAt: day(1)},
+		{Number: 2, PackageName: "react", MergedAt: day(5)},
+		{Number: 3, PackageName: "Lodash", MergedAt: day(3)},
+		{Number: 4, PackageName: "lodash", MergedAt: day(2)},
+	}
+
+	tests := []struct {
+		name    string
+		pkg     string
+		want    int
+		wantNil bool
+	}{
+		{name: "latest merge wins", pkg: "lodash", want: 3},
+		{name: "case-insensitive", pkg: "REACT", want: 2},
+		{name: "no match", pkg: "express", wantNil: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := latestMergedFor(merged, tt.pkg)
+			if ok == tt.wantNil {
+				t.Fatalf("latestMergedFor() found = %v, want %v", ok, !tt.wantNil)
+			}
+			if ok && got.Number != tt.want {
+				t.Errorf("latestMergedFor() = #%d, want #%d", got.Number, tt.want)
+			}
+		})
+	}
+}
+
+func TestIgnoreEntry(t *testing.T) {
+	got, err := IgnoreEntry(MergedPR{Number: 3, PackageName: "lodash", ToVersion: "4.17.21"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "ignore:\n  - dependency-name: \"lodash\"\n    versions: [\"4.17.21\"]\n"
+	if got != want {
+		t.Errorf("IgnoreEntry() = %q, want %q", got, want)
+	}
+	if _, err := IgnoreEntry(MergedPR{Number: 4, PackageName: "web"}); err == nil {
+		t.Error("IgnoreEntry() error = nil, want an error without a version")
+	}
+}
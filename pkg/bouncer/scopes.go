@@ -0,0 +1,83 @@
+package bouncer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// broadScopes are the classic OAuth scopes, beyond repo and read:org, that
+// give a token power the bouncer never uses: administering organizations,
+// hooks, and keys, or deleting repositories and packages.
+var broadScopes = map[string]bool{
+	"delete_repo":     true,
+	"delete:packages": true,
+	"site_admin":      true,
+}
+
+// BroadScopes returns the scopes of a classic token that grant more than
+// the bouncer needs: every admin: scope and the delete scopes.
+func BroadScopes(scopes []string) []string {
+	var broad []string
+	for _, s := range scopes {
+		if strings.HasPrefix(s, "admin:") || broadScopes[s] {
+			broad = append(broad, s)
+		}
+	}
+	return broad
+}
+
+// TokenScopes returns the OAuth scopes of a token, or of the client's token
+// or gh's own authentication when value is empty, as reported in the X-OAuth-Scopes
+// header. ok is false for tokens that have no scopes to report, such as
+// fine-grained personal access tokens and GitHub App tokens, whose
+// permissions are set per repository instead.
+func (g GitHub) TokenScopes(ctx context.Context, value string) (scopes []string, ok bool, err error) {
+	if value == "" {
+		value = g.Token
+	}
+	cmd := exec.Command("gh", "api", "--include", "user")
+	cmd.Env = g.env(value)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, false, fmt.Errorf("token scope lookup failed: %w", err)
+	}
+	scopes, ok = parseScopesHeader(out)
+	return scopes, ok, nil
+}
+
+// parseScopesHeader extracts the X-OAuth-Scopes header from the response
+// headers gh prints with --include.
+func parseScopesHeader(out []byte) ([]string, bool) {
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			break // end of the headers
+		}
+		name, value, found := strings.Cut(line, ":")
+		if !found || !strings.EqualFold(name, "X-OAuth-Scopes") {
+			continue
+		}
+		var scopes []string
+		for _, s := range strings.Split(value, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				scopes = append(scopes, s)
+			}
+		}
+		return scopes, true
+	}
+	return nil, false
+}
+
+// AuthenticatedUser returns the login of the user gh calls authenticate as.
+func (g GitHub) AuthenticatedUser(ctx context.Context) (string, error) {
+	out, err := g.output(ctx, "look up authenticated user", "gh", "api", "user", "--jq", ".login")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
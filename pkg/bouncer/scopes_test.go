@@ -0,0 +1,48 @@
+package bouncer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseScopesHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		out    string
+		want   []string
+		wantOK bool
+	}{
+		{
+			name:   "classic token",
+			out:    "HTTP/2.0 200 OK\r\nContent-Type: application/json\r\nX-Oauth-Scopes: admin:org, delete_repo, repo\r\n\r\n{\"login\":\"bot\"}",
+			want:   []string{"admin:org", "delete_repo", "repo"},
+			wantOK: true,
+		},
+		{
+			name:   "classic token without scopes",
+			out:    "HTTP/2.0 200 OK\r\nX-Oauth-Scopes: \r\n\r\n{}",
+			wantOK: true,
+		},
+		{
+			name: "fine-grained token",
+			out:  "HTTP/2.0 200 OK\r\nContent-Type: application/json\r\n\r\n{\"x-oauth-scopes\":\"repo\"}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseScopesHeader([]byte(tt.out))
+			if !slices.Equal(got, tt.want) || ok != tt.wantOK {
+				t.Errorf("parseScopesHeader() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestBroadScopes(t *testing.T) {
+	got := BroadScopes([]string{"repo", "read:org", "admin:org", "workflow", "delete_repo", "admin:repo_hook"})
+	want := []string{"admin:org", "delete_repo", "admin:repo_hook"}
+	if !slices.Equal(got, want) {
+		t.Errorf("BroadScopes() = %v, want %v", got, want)
+	}
+}
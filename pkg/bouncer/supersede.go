@@ -0,0 +1,115 @@
+package bouncer
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Superseded is an open PR whose updates newer open PRs all propose again.
+type Superseded struct {
+	PR PRInfo
+	By []int // the newer PRs, lowest number first
+}
+
+var (
+	// titleDirRe matches the directory Dependabot names in the titles of
+	// updates outside the repository root, e.g. "Bump lodash from 4.17.20
+	// to 4.17.21 in /web".
+	titleDirRe = regexp.MustCompile(`\bin\s+(/\S*)`)
+	// multiDirRe matches the titles of grouped updates across several
+	// directories, e.g. "Bump the npm group across 2 directories with 3
+	// updates".
+	multiDirRe = regexp.MustCompile(`(?i)\bacross\s+\d+\s+directories\b`)
+)
+
+// anyDir is the directory of a grouped update across several directories.
+const anyDir = "*"
+
+// FindSuperseded returns the PRs among prs that newer PRs supersede: those
+// every package of which a PR opened later bumps too, in the same ecosystem
+// and directory. That happens when a grouped update takes over the
+// packages of single updates left open, or when two updates of a package
+// are open at once. A grouped update across several directories covers
+// them all. PRs whose package is unknown neither supersede nor are
+// superseded. The result is in the order of prs.
+func FindSuperseded(prs []PRInfo) []Superseded {
+	var out []Superseded
+	for _, old := range prs {
+		pkgs := bumpedPackages(old)
+		if len(pkgs) == 0 {
+			continue
+		}
+		var by []int
+		for _, pkg := range pkgs {
+			newest := -1
+			for i, pr := range prs {
+				if !newerPR(pr, old) || !covers(pr, old) || !slices.Contains(bumpedPackages(pr), pkg) {
+					continue
+				}
+				if newest < 0 || newerPR(pr, prs[newest]) {
+					newest = i
+				}
+			}
+			if newest < 0 {
+				by = nil
+				break
+			}
+			by = append(by, prs[newest].Number)
+		}
+		if len(by) == 0 {
+			continue
+		}
+		slices.Sort(by)
+		out = append(out, Superseded{PR: old, By: slices.Compact(by)})
+	}
+	return out
+}
+
+// bumpedPackages returns the lower-cased packages a PR updates: those of a
+// grouped update, or its single package.
+func bumpedPackages(pr PRInfo) []string {
+	if len(pr.Updates) > 0 {
+		pkgs := make([]string, 0, len(pr.Updates))
+		for _, u := range pr.Updates {
+			pkgs = append(pkgs, strings.ToLower(u.Package))
+		}
+		return pkgs
+	}
+	if pr.PackageName == "" {
+		return nil
+	}
+	return []string{strings.ToLower(pr.PackageName)}
+}
+
+// newerPR reports whether a was opened after b, by PR number when they
+// were opened at the same time.
+func newerPR(a, b PRInfo) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.Number > b.Number
+}
+
+// covers reports whether the updates of pr apply where those of old do: in
+// the same ecosystem and the same directory, or across all directories.
+func covers(pr, old PRInfo) bool {
+	if pr.Ecosystem != old.Ecosystem {
+		return false
+	}
+	dir := titleDir(pr.Title)
+	return dir == anyDir || dir == titleDir(old.Title)
+}
+
+// titleDir returns the directory a PR's title names, "" for the repository
+// root, or anyDir for a grouped update across several directories.
+func titleDir(title string) string {
+	if multiDirRe.MatchString(title) {
+		return anyDir
+	}
+	m := titleDirRe.FindStringSubmatch(title)
+	if m == nil {
+		return ""
+	}
+	return strings.TrimRight(m[1], "/")
+}
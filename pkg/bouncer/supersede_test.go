@@ -0,0 +1,117 @@
+package bouncer
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFindSuperseded(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 5, d, 0, 0, 0, 0, time.UTC) }
+	single := func(n, d int, pkg, title string) PRInfo {
+		return PRInfo{Number: n, Title: title, PackageName: pkg, Ecosystem: "npm", CreatedAt: day(d)}
+	}
+	group := func(n, d int, title string, pkgs ...string) PRInfo {
+		pr := PRInfo{Number: n, Title: title, PackageName: "npm-deps", Ecosystem: "npm", CreatedAt: day(d)}
+		for _, p := range pkgs {
+			pr.Updates = append(pr.Updates, Update{Package: p})
+		}
+		return pr
+	}
+
+	tests := []struct {
+		name string
+		prs  []PRInfo
+		want string
+	}{
+		{
+			name: "group takes over singles",
+			prs: []PRInfo{
+				single(1, 1, "lodash", "Bump lodash from 4.17.20 to 4.17.21"),
+				single(2, 2, "react", "Bump react from 18.2.0 to 18.3.0"),
+				single(3, 3, "express", "Bump express from 4.18.0 to 4.19.0"),
+				group(4, 4, "Bump the npm-deps group with 2 updates", "lodash", "React"),
+			},
+			want: "[#1 by [4] #2 by [4]]",
+		},
+		{
+			name: "newer single supersedes older",
+			prs: []PRInfo{
+				single(8, 2, "lodash", "Bump lodash from 4.17.20 to 4.17.22"),
+				single(5, 1, "lodash", "Bump lodash from 4.17.20 to 4.17.21"),
+			},
+			want: "[#5 by [8]]",
+		},
+		{
+			name: "older group is not superseded by part of it",
+			prs: []PRInfo{
+				group(1, 1, "Bump the npm-deps group with 2 updates", "lodash", "react"),
+				single(2, 2, "lodash", "Bump lodash from 4.17.20 to 4.17.21"),
+			},
+			want: "[]",
+		},
+		{
+			name: "group superseded by singles",
+			prs: []PRInfo{
+				group(1, 1, "Bump the npm-deps group with 2 updates", "lodash", "react"),
+				single(2, 2, "lodash", "Bump lodash from 4.17.20 to 4.17.22"),
+				single(3, 3, "react", "Bump react from 18.2.0 to 18.3.1"),
+			},
+			want: "[#1 by [2 3]]",
+		},
+		{
+			name: "different directories",
+			prs: []PRInfo{
+				single(1, 1, "lodash", "Bump lodash from 4.17.20 to 4.17.21 in /web"),
+				single(2, 2, "lodash", "Bump lodash from 4.17.20 to 4.17.21 in /api"),
+				single(3, 3, "lodash", "Bump lodash from 4.17.20 to 4.17.21"),
+			},
+			want: "[]",
+		},
+		{
+			name: "same directory",
+			prs: []PRInfo{
+				single(1, 1, "lodash", "Bump lodash from 4.17.20 to 4.17.21 in /web"),
+				single(2, 2, "lodash", "Bump lodash from 4.17.20 to 4.17.22 in /web/"),
+			},
+			want: "[#1 by [2]]",
+		},
+		{
+			name: "group across directories covers all",
+			prs: []PRInfo{
+				single(1, 1, "lodash", "Bump lodash from 4.17.20 to 4.17.21 in /web"),
+				single(2, 2, "lodash", "Bump lodash from 4.17.20 to 4.17.21"),
+				group(3, 3, "Bump the npm-deps group across 2 directories with 1 update", "lodash"),
+			},
+			want: "[#1 by [3] #2 by [3]]",
+		},
+		{
+			name: "different ecosystems",
+			prs: []PRInfo{
+				single(1, 1, "yaml", "Bump yaml from 2.0 to 2.1"),
+				{Number: 2, Title: "Bump yaml from 6.0 to 6.1", PackageName: "yaml", Ecosystem: "pip", CreatedAt: day(2)},
+			},
+			want: "[]",
+		},
+		{
+			name: "unknown package",
+			prs: []PRInfo{
+				single(1, 1, "", "Update dependencies"),
+				single(2, 2, "", "Update dependencies"),
+			},
+			want: "[]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, s := range FindSuperseded(tt.prs) {
+				got = append(got, fmt.Sprintf("#%d by %v", s.PR.Number, s.By))
+			}
+			if s := fmt.Sprint(got); s != tt.want {
+				t.Errorf("FindSuperseded() = %s, want %s", s, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package bouncer
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenRecheckCalls is how many gh calls a token serves before its rate limit
+// is looked up again. Between lookups usage is estimated by counting calls.
+const tokenRecheckCalls = 100
+
+// Token is a named GitHub token. Name identifies the token in logs and config
+// without revealing its value (typically the environment variable it came from).
+type Token struct {
+	Name  string
+	Value string
+}
+
+// TokenUsage reports how a pooled token was used during a run.
+type TokenUsage struct {
+	Name      string
+	Calls     int
+	Remaining int // estimated remaining requests in the most limited API bucket
+	Reset     time.Time
+}
+
+// TokenPool spreads gh calls across several GitHub tokens of one host. Each
+// call uses the token with the most estimated rate-limit budget left, unless
+// the repository it targets is pinned to a specific token.
+type TokenPool struct {
+	mu     sync.Mutex
+	host   string // as for GitHub.Host
+	tokens []*pooledToken
+	pinned map[string]*pooledToken // lower-cased "owner/repo" -> token
+
+	// rateLimit looks up a token's remaining budget; replaced in tests.
+	rateLimit func(value string) (remaining int, reset time.Time, err error)
+}
+
+type pooledToken struct {
+	Token
+	calls     int // total calls made with the token
+	sinceLook int // calls since the last rate-limit lookup
+	remaining int
+	reset     time.Time
+	looked    bool
+}
+
+// activePool is the pool used by gh calls of GitHub clients on its host that
+// have no token of their own; nil uses gh's own authentication.
+var activePool *TokenPool
+
+// SetTokenPool makes subsequent gh calls to p's host draw tokens from p,
+// unless their client has a token of its own. A nil pool restores gh's own
+// authentication.
+func SetTokenPool(p *TokenPool) {
+	activePool = p
+}
+
+// ActiveTokenPool returns the pool set with SetTokenPool, or nil.
+func ActiveTokenPool() *TokenPool {
+	return activePool
+}
+
+// NewTokenPool returns a pool of tokens of host, given as for GitHub.Host.
+// pinned maps "owner/repo" to the name of the token that must be used for
+// that repository.
+func NewTokenPool(host string, tokens []Token, pinned map[string]string) (*TokenPool, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("token pool is empty")
+	}
+
+	p := &TokenPool{
+		host:      githubHost(host),
+		pinned:    make(map[string]*pooledToken),
+		rateLimit: GitHub{Host: host}.tokenRateLimit,
+	}
+	byName := make(map[string]*pooledToken, len(tokens))
+	for _, t := range tokens {
+		if t.Value == "" {
+			return nil, fmt.Errorf("token %s is empty", t.Name)
+		}
+		pt := &pooledToken{Token: t}
+		p.tokens = append(p.tokens, pt)
+		byName[t.Name] = pt
+	}
+	for repo, name := range pinned {
+		pt, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%s is pinned to unknown token %s", repo, name)
+		}
+		p.pinned[strings.ToLower(repo)] = pt
+	}
+	return p, nil
+}
+
+// pick returns the token to use for a call targeting repo ("owner/repo", or
+// empty when the call is not repository-specific) and counts the call.
+func (p *TokenPool) pick(repo string) Token {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	best, ok := p.pinned[strings.ToLower(repo)]
+	if !ok {
+		for _, t := range p.tokens {
+			p.refresh(t)
+			if best == nil || t.budget() > best.budget() {
+				best = t
+			}
+		}
+	}
+
+	best.calls++
+	best.sinceLook++
+	return best.Token
+}
+
+// refresh looks up the token's rate limit when it has never been looked up,
+// its window has reset, or it has served tokenRecheckCalls calls since the
+// last lookup. Lookup failures keep the previous estimate.
+func (p *TokenPool) refresh(t *pooledToken) {
+	if t.looked && t.sinceLook < tokenRecheckCalls && time.Now().Before(t.reset) {
+		return
+	}
+	remaining, reset, err := p.rateLimit(t.Value)
+	if err != nil {
+		return
+	}
+	t.remaining, t.reset, t.sinceLook, t.looked = remaining, reset, 0, true
+}
+
+// budget estimates the requests the token has left.
+func (t *pooledToken) budget() int {
+	return t.remaining - t.sinceLook
+}
+
+// exhaust records that GitHub refused a call made with the token value for
+// its exhausted rate limit, which resets at reset. It reports whether another
+// token still has budget left.
+func (p *TokenPool) exhaust(value string, reset time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	others := false
+	for _, t := range p.tokens {
+		if t.Value == value {
+			t.remaining, t.sinceLook, t.looked = 0, 0, true
+			if !reset.IsZero() {
+				t.reset = reset
+			}
+			continue
+		}
+		p.refresh(t)
+		if t.budget() > 0 {
+			others = true
+		}
+	}
+	return others
+}
+
+// Usage reports per-token call counts and estimated remaining budget.
+func (p *TokenPool) Usage() []TokenUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	usage := make([]TokenUsage, 0, len(p.tokens))
+	for _, t := range p.tokens {
+		usage = append(usage, TokenUsage{Name: t.Name, Calls: t.calls, Remaining: t.budget(), Reset: t.reset})
+	}
+	return usage
+}
+
+// RateLimit returns the requests left and the reset time of the rate-limit
+// budget the client's gh calls draw from: its token, the pooled token with
+// the most budget left when a token pool serves it, or gh's own
+// authentication otherwise.
+func (g GitHub) RateLimit(ctx context.Context) (int, time.Time, error) {
+	pool := g.pool()
+	if pool == nil {
+		return g.tokenRateLimit(g.Token)
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	var best *pooledToken
+	for _, t := range pool.tokens {
+		pool.refresh(t)
+		if best == nil || t.budget() > best.budget() {
+			best = t
+		}
+	}
+	return best.budget(), best.reset, nil
+}
+
+// pool returns the token pool the client's gh calls draw from: the active
+// pool when it is for the client's host and the client has no token of its
+// own, nil otherwise.
+func (g GitHub) pool() *TokenPool {
+	if g.Token != "" || activePool == nil || activePool.host != githubHost(g.Host) {
+		return nil
+	}
+	return activePool
+}
+
+// tokenRateLimit returns the remaining requests and reset time of the more
+// limited of the REST and GraphQL buckets for a token, or for gh's own
+// authentication when value is empty. Querying the rate limit does not
+// count against it.
+func (g GitHub) tokenRateLimit(value string) (int, time.Time, error) {
+	cmd := exec.Command("gh", "api", "rate_limit",
+		"--jq", `[.resources.core, .resources.graphql] | min_by(.remaining) | "\(.remaining) \(.reset)"`)
+	cmd.Env = g.env(value)
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("rate limit lookup failed: %w", err)
+	}
+
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 {
+		return 0, time.Time{}, fmt.Errorf("unexpected rate limit output: %q", out)
+	}
+	remaining, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("unexpected rate limit output: %q", out)
+	}
+	reset, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("unexpected rate limit output: %q", out)
+	}
+	return remaining, time.Unix(reset, 0), nil
+}
+
+// killWait is how long a killed call's output is waited for.
+const killWait = time.Second
+
+// exec builds the command for a gh (or glab) invocation and counts the API
+// call against the repository it targets. gh calls go to the client's host,
+// authenticated with its token, or with a token drawn from its pool (see
+// pool). The command is killed when ctx is done; its output is abandoned
+// killWait later if processes it started still hold it open. Mutating
+// invocations wait their turn under the write interval, and are refused
+// with ErrReadOnly in read-only mode. The token the call authenticates with
+// is returned too, empty for gh's own authentication.
+func (g GitHub) exec(ctx context.Context, args ...string) (_ *exec.Cmd, token string, _ error) {
+	repo := repoFromArgs(args)
+	if isMutation(args) {
+		if readOnly {
+			return nil, "", ErrReadOnly
+		}
+		if err := writePacer.wait(ctx); err != nil {
+			return nil, "", err
+		}
+	}
+	recordCall(repo, callKind(args))
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.WaitDelay = killWait
+	if args[0] == "gh" {
+		token = g.Token
+		if pool := g.pool(); pool != nil {
+			token = pool.pick(repo).Value
+		}
+		cmd.Env = g.env(token)
+	}
+	return cmd, token, nil
+}
+
+// repoFromArgs returns the "owner/repo" a gh or glab invocation targets,
+// taken from its --repo flag, a repository API path, or the owner and name
+// variables of a GraphQL query, or "" if none.
+func repoFromArgs(args []string) string {
+	for i, a := range args {
+		if a == "--repo" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	for _, a := range args {
+		if repo := repoFromPath(a); repo != "" {
+			return repo
+		}
+	}
+	var owner, name string
+	for i, a := range args {
+		if a != "-f" || i+1 >= len(args) {
+			continue
+		}
+		if v, ok := strings.CutPrefix(args[i+1], "owner="); ok {
+			owner = v
+		} else if v, ok := strings.CutPrefix(args[i+1], "name="); ok {
+			name = v
+		}
+	}
+	if owner != "" && name != "" {
+		return owner + "/" + name
+	}
+	return ""
+}
@@ -0,0 +1,104 @@
+package bouncer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenPoolPick(t *testing.T) {
+	limits := map[string]int{"a": 100, "b": 150, "c": 5000}
+	pool, err := NewTokenPool("",
+		[]Token{{Name: "A", Value: "a"}, {Name: "B", Value: "b"}, {Name: "C", Value: "c"}},
+		map[string]string{"Acme/Pinned": "A"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookups := 0
+	pool.rateLimit = func(value string) (int, time.Time, error) {
+		lookups++
+		return limits[value], time.Now().Add(time.Hour), nil
+	}
+
+	if got := pool.pick("acme/api").Name; got != "C" {
+		t.Errorf("pick() = %s, want C (most budget)", got)
+	}
+	if got := pool.pick("acme/pinned").Name; got != "A" {
+		t.Errorf("pick(pinned) = %s, want A", got)
+	}
+	if lookups != 3 {
+		t.Errorf("rate limit lookups = %d, want 3", lookups)
+	}
+
+	// Calls are counted against the estimate until the next lookup: C has
+	// served one call, so 150 remaining on B beats 150 on C.
+	pool.tokens[2].remaining = 150
+	if got := pool.pick("").Name; got != "B" {
+		t.Errorf("pick() after use = %s, want B", got)
+	}
+	if lookups != 3 {
+		t.Errorf("rate limit lookups = %d, want 3 (cached)", lookups)
+	}
+
+	usage := pool.Usage()
+	if usage[0].Calls != 1 || usage[1].Calls != 1 || usage[2].Calls != 1 || usage[1].Remaining != 149 {
+		t.Errorf("Usage() = %+v", usage)
+	}
+}
+
+func TestNewTokenPoolErrors(t *testing.T) {
+	if _, err := NewTokenPool("", nil, nil); err == nil {
+		t.Error("NewTokenPool(empty) succeeded, want error")
+	}
+	if _, err := NewTokenPool("", []Token{{Name: "A", Value: ""}}, nil); err == nil {
+		t.Error("NewTokenPool(empty value) succeeded, want error")
+	}
+	if _, err := NewTokenPool("", []Token{{Name: "A", Value: "a"}}, map[string]string{"acme/api": "B"}); err == nil {
+		t.Error("NewTokenPool(unknown pin) succeeded, want error")
+	}
+}
+
+func TestGitHubPool(t *testing.T) {
+	defer SetTokenPool(nil)
+	pool, err := NewTokenPool("https://github.example.com/", []Token{{Name: "A", Value: "a"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetTokenPool(pool)
+
+	tests := []struct {
+		name string
+		g    GitHub
+		want *TokenPool
+	}{
+		{"pool's host", GitHub{Host: "github.example.com"}, pool},
+		{"other host", GitHub{}, nil},
+		{"own token", GitHub{Host: "github.example.com", Token: "t1"}, nil},
+	}
+	for _, tt := range tests {
+		if got := tt.g.pool(); got != tt.want {
+			t.Errorf("%s: pool() = %p, want %p", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRepoFromArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"gh", "pr", "list", "--repo", "acme/api", "--json", "number"}, "acme/api"},
+		{[]string{"gh", "api", "--paginate", "repos/acme/api/dependabot/alerts?state=open"}, "acme/api"},
+		{[]string{"gh", "api", "repos/acme/api?x=1"}, "acme/api"},
+		{[]string{"glab", "api", "projects/acme%2Fapi/merge_requests/3/approve"}, "acme/api"},
+		{[]string{"gh", "api", "graphql", "-f", "query=query { x }", "-f", "owner=acme", "-f", "name=api"}, "acme/api"},
+		{[]string{"gh", "api", "graphql", "-f", "query=query { x }", "-f", "owner=acme"}, ""},
+		{[]string{"gh", "repo", "list", "acme"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := repoFromArgs(tt.args); got != tt.want {
+			t.Errorf("repoFromArgs(%v) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package bouncer
+
+import (
+	"strings"
+
+	"github.com/promiseofcake/dependabot-bouncer/internal/tracing"
+)
+
+// tracer records a span for every API call; nil when tracing is off.
+var tracer *tracing.Tracer
+
+// SetTracer records subsequent API calls as spans of t. A nil t turns
+// tracing off.
+func SetTracer(t *tracing.Tracer) {
+	tracer = t
+}
+
+// startCallSpan begins the span of a gh or glab invocation. Only the
+// leading arguments are recorded, so PR bodies and tokens stay out of it.
+func startCallSpan(desc string, args []string) *tracing.Span {
+	return tracer.StartCall(desc,
+		tracing.String("repository", repoFromArgs(args)),
+		tracing.String("command", strings.Join(args[:min(len(args), 3)], " ")),
+		tracing.String("api.kind", callKind(args)),
+	)
+}
@@ -0,0 +1,66 @@
+package bouncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// review is a pull request review as returned by the REST API.
+type review struct {
+	ID    int64  `json:"id"`
+	State string `json:"state"`
+	User  struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// DismissApprovals dismisses the approving reviews login left on a pull
+// request, with message as the reason, and returns how many were dismissed.
+func (g GitHub) DismissApprovals(ctx context.Context, owner, repo string, number int, login, message string) (int, error) {
+	out, err := g.output(ctx, "list reviews", "gh", "api", "--paginate",
+		fmt.Sprintf("repos/%s/%s/pulls/%d/reviews", owner, repo, number),
+		"--jq", ".[]")
+	if err != nil {
+		return 0, err
+	}
+	var reviews []review
+	dec := json.NewDecoder(strings.NewReader(string(out)))
+	for dec.More() {
+		var r review
+		if err := dec.Decode(&r); err != nil {
+			return 0, fmt.Errorf("failed to parse gh output: %w", err)
+		}
+		reviews = append(reviews, r)
+	}
+
+	ids := approvalsBy(reviews, login)
+	for i, id := range ids {
+		err := g.command(ctx, "dismiss review", "gh", "api", "-X", "PUT",
+			fmt.Sprintf("repos/%s/%s/pulls/%d/reviews/%d/dismissals", owner, repo, number, id),
+			"-f", "message="+message, "-f", "event=DISMISS")
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+// approvalsBy returns the IDs of the approving reviews left by login, which
+// is matched case-insensitively.
+func approvalsBy(reviews []review, login string) []int64 {
+	var ids []int64
+	for _, r := range reviews {
+		if r.State == "APPROVED" && strings.EqualFold(r.User.Login, login) {
+			ids = append(ids, r.ID)
+		}
+	}
+	return ids
+}
+
+// DisableAutoMergePR turns auto-merge off on a pull request.
+func (g GitHub) DisableAutoMergePR(ctx context.Context, owner, repo string, number int) error {
+	return g.command(ctx, "disable auto-merge", "gh", "pr", "merge", "--disable-auto",
+		"--repo", owner+"/"+repo, fmt.Sprintf("%d", number))
+}
@@ -0,0 +1,28 @@
+package bouncer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestApprovalsBy(t *testing.T) {
+	mk := func(id int64, state, login string) review {
+		r := review{ID: id, State: state}
+		r.User.Login = login
+		return r
+	}
+	reviews := []review{
+		mk(1, "APPROVED", "bouncer-bot"),
+		mk(2, "COMMENTED", "bouncer-bot"),
+		mk(3, "APPROVED", "alice"),
+		mk(4, "DISMISSED", "bouncer-bot"),
+		mk(5, "APPROVED", "Bouncer-Bot"),
+	}
+
+	if got, want := approvalsBy(reviews, "bouncer-bot"), []int64{1, 5}; !slices.Equal(got, want) {
+		t.Errorf("approvalsBy() = %v, want %v", got, want)
+	}
+	if got := approvalsBy(reviews, "bob"); len(got) != 0 {
+		t.Errorf("approvalsBy() = %v, want none", got)
+	}
+}
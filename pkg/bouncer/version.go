@@ -0,0 +1,243 @@
+package bouncer
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Update types classify the size of a version bump.
+const (
+	UpdateMajor = "major"
+	UpdateMinor = "minor"
+	UpdatePatch = "patch"
+)
+
+var versionRangeRe = regexp.MustCompile(`(?i)\bfrom\s+(\S+)\s+to\s+(\S+)`)
+
+// renovateToRe matches the target version of a Renovate title such as
+// "Update dependency lodash to v5" or "... to v4.17.21 [SECURITY]", which
+// names no "from" version.
+var renovateToRe = regexp.MustCompile(`(?i)\bto\s+(v?\d\S*)`)
+
+// renovateChangeRe matches the version change in the table of a Renovate PR
+// body, e.g. "`4.17.20` -> `5.0.0`" or "`^4.17.20` → `^5.0.0`".
+var renovateChangeRe = regexp.MustCompile("`([^`\\s]+)`\\s*(?:->|→)\\s*`([^`\\s]+)`")
+
+// parseVersions extracts the "from" and "to" versions from a Dependabot PR
+// title such as "Bump foo from 1.2.3 to 1.3.0". Renovate titles name only
+// the "to" version; from is then empty. Either is empty when the title does
+// not name them (e.g. grouped updates).
+func parseVersions(title string) (from, to string) {
+	if m := versionRangeRe.FindStringSubmatch(title); m != nil {
+		return trimVersion(m[1]), trimVersion(m[2])
+	}
+	if m := renovateToRe.FindStringSubmatch(title); m != nil {
+		return "", trimVersion(m[1])
+	}
+	return "", ""
+}
+
+// parseRenovateChange returns the versions of the first change listed in
+// the table of a Renovate PR body, without range operators such as "^".
+func parseRenovateChange(body string) (from, to string, ok bool) {
+	m := renovateChangeRe.FindStringSubmatch(body)
+	if m == nil {
+		return "", "", false
+	}
+	return strings.TrimLeft(m[1], "^~=<>"), strings.TrimLeft(m[2], "^~=<>"), true
+}
+
+// trimVersion strips the punctuation around a version in a PR title: a
+// trailing period and the backticks around Docker digests.
+func trimVersion(v string) string {
+	return strings.Trim(strings.TrimRight(v, ".,;:"), "`")
+}
+
+// updateType classifies a version change as UpdateMajor, UpdateMinor, or
+// UpdatePatch by the first numeric component that differs. It returns "" when
+// either version cannot be parsed or the versions are identical.
+func updateType(from, to string) string {
+	f, ok := versionParts(from)
+	if !ok {
+		return ""
+	}
+	t, ok := versionParts(to)
+	if !ok {
+		return ""
+	}
+
+	switch {
+	case f[0] != t[0]:
+		return UpdateMajor
+	case f[1] != t[1]:
+		return UpdateMinor
+	case f[2] != t[2]:
+		return UpdatePatch
+	}
+	return ""
+}
+
+// versionParts parses the major, minor, and patch numbers of a version string,
+// ignoring a leading "v" and any pre-release or build suffix. Missing minor or
+// patch components are treated as zero.
+func versionParts(v string) ([3]int, bool) {
+	var parts [3]int
+	v = strings.TrimPrefix(strings.ToLower(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	if v == "" {
+		return parts, false
+	}
+
+	fields := strings.Split(v, ".")
+	for i := 0; i < len(fields) && i < 3; i++ {
+		n, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// isInternal reports whether a package matches q.InternalPackages.
+func isInternal(packageName string, q DependencyUpdateQuery) bool {
+	return packageName != "" && isDenied(packageName, "", q.InternalPackages, nil)
+}
+
+// isUpdateTypeDenied reports whether the update type is denied for the package,
+// by the query-wide list, the list for internal or external packages, or a
+// matching per-package entry. An unknown update type ("") is denied whenever
+// any update type is, since it could be any of them.
+func isUpdateTypeDenied(packageName, typ string, q DependencyUpdateQuery) bool {
+	byOrigin := q.DeniedUpdateTypesExternal
+	if isInternal(packageName, q) {
+		byOrigin = q.DeniedUpdateTypesInternal
+	}
+	denied := append(append([]string(nil), q.DeniedUpdateTypes...), byOrigin...)
+	for pattern, types := range q.DeniedUpdateTypesByPackage {
+		if isDenied(packageName, "", []string{pattern}, nil) {
+			denied = append(denied, types...)
+		}
+	}
+	if typ == "" {
+		return len(denied) > 0
+	}
+	return slices.ContainsFunc(denied, func(d string) bool { return strings.EqualFold(d, typ) })
+}
+
+// updateTypeDenial returns the skip reason and rule of a dependency denied
+// by isUpdateTypeDenied.
+func updateTypeDenial(d dependency) (reason, rule string) {
+	if d.UpdateType == "" {
+		return "denied update of unknown type: " + d.Package, "unknown update type"
+	}
+	return "denied " + d.UpdateType + " update: " + d.Package, d.UpdateType + " updates"
+}
+
+// updateTypeDenialRe matches the deny entries that deny update types of a
+// package instead of the package, e.g. "github.com/gin-gonic/gin: deny=major".
+var updateTypeDenialRe = regexp.MustCompile(`^(\S+):\s*deny=(\S+)$`)
+
+// ParseUpdateTypeDenial splits a deny entry of the form "package: deny=types"
+// into its package pattern and the comma-separated update types it denies,
+// e.g. "github.com/gin-gonic/gin: deny=major,minor". ok is false for other
+// entries, which deny the package itself. The types are not checked.
+func ParseUpdateTypeDenial(entry string) (pattern string, types []string, ok bool) {
+	m := updateTypeDenialRe.FindStringSubmatch(strings.TrimSpace(entry))
+	if m == nil {
+		return "", nil, false
+	}
+	for _, t := range strings.Split(m[2], ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, strings.ToLower(t))
+		}
+	}
+	return m[1], types, true
+}
+
+// constraintOps are the comparison operators of version-constrained deny
+// entries, two-character ones first so they are matched whole.
+var constraintOps = []string{"<=", ">=", "==", "!=", "<", ">", "="}
+
+// versionConstraint is the version range of a deny entry such as
+// "react@>=19" or "github.com/aws/aws-sdk-go-v2 < 1.20".
+type versionConstraint struct {
+	op      string
+	version [3]int
+}
+
+// parseVersionConstraint splits a deny entry into its package pattern and
+// version constraint. The constraint follows the package after "@" or
+// whitespace. ok is false for entries without a comparison operator and for
+// regular expressions, which are matched by isDenied alone; err is set when
+// an operator is not between a package and a version.
+func parseVersionConstraint(entry string) (pattern string, c versionConstraint, ok bool, err error) {
+	i := strings.IndexAny(entry, "<>=!")
+	if i < 0 || strings.HasPrefix(entry, regexPrefix) {
+		return "", versionConstraint{}, false, nil
+	}
+	pattern = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(entry[:i]), "@"))
+	rest := entry[i:]
+	for _, op := range constraintOps {
+		if strings.HasPrefix(rest, op) {
+			c.op = op
+			break
+		}
+	}
+	version := strings.TrimSpace(strings.TrimPrefix(rest, c.op))
+	parts, valid := versionParts(version)
+	if pattern == "" || strings.ContainsAny(pattern, " \t") || c.op == "" || !valid {
+		return "", versionConstraint{}, false, fmt.Errorf("invalid deny entry %q (expected a package, an operator such as < or >=, and a version, e.g. \"react@>=19\")", entry)
+	}
+	c.version = parts
+	return pattern, c, true, nil
+}
+
+// matches reports whether version falls in the constraint's range. Versions
+// are compared by their major, minor, and patch numbers; a version that
+// cannot be parsed matches no range.
+func (c versionConstraint) matches(version string) bool {
+	v, ok := versionParts(version)
+	if !ok {
+		return false
+	}
+	cmp := slices.Compare(v[:], c.version[:])
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "!=":
+		return cmp != 0
+	}
+	return cmp == 0
+}
+
+// deniedVersion returns the first version-constrained entry of
+// deniedPackages whose package pattern matches packageName and whose range
+// contains version, the version the PR updates to, or "" when none does.
+// Unconstrained entries are left to isDenied.
+func deniedVersion(packageName, version string, deniedPackages []string) string {
+	if packageName == "" {
+		return ""
+	}
+	for _, entry := range deniedPackages {
+		pattern, c, ok, err := parseVersionConstraint(entry)
+		if !ok || err != nil {
+			continue
+		}
+		if isDenied(packageName, "", []string{pattern}, nil) && c.matches(version) {
+			return entry
+		}
+	}
+	return ""
+}
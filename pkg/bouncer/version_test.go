@@ -0,0 +1,136 @@
+package bouncer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseVersions(t *testing.T) {
+	tests := []struct {
+		title    string
+		wantFrom string
+		wantTo   string
+	}{
+		{title: "Bump github.com/spf13/cobra from 1.6.0 to 1.7.0", wantFrom: "1.6.0", wantTo: "1.7.0"},
+		{title: "⬆️ (deps): Bump golang.org/x/tools from 0.36.0 to 0.37.0", wantFrom: "0.36.0", wantTo: "0.37.0"},
+		{title: "Update github.com/gin-gonic/gin from v1.7.0 to v1.8.0", wantFrom: "v1.7.0", wantTo: "v1.8.0"},
+		{title: "Bump lodash from 4.17.20 to 4.17.21 in /frontend", wantFrom: "4.17.20", wantTo: "4.17.21"},
+		{title: "Bump io.netty:netty-codec-http from 4.1.94.Final to 4.1.100.Final in /services/gateway", wantFrom: "4.1.94.Final", wantTo: "4.1.100.Final"},
+		{title: "Bump node from `1a2b3c4` to `5d6e7f8` in /docker", wantFrom: "1a2b3c4", wantTo: "5d6e7f8"},
+		{title: "Update github.com/elastic/go-elasticsearch to v8", wantFrom: "", wantTo: "v8"},
+		{title: "Update dependency lodash to v4.17.21 [SECURITY]", wantFrom: "", wantTo: "v4.17.21"},
+		{title: "⬆️ (deps): Bump the aws-sdk-go-v2 group with 4 updates", wantFrom: "", wantTo: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			from, to := parseVersions(tt.title)
+			if from != tt.wantFrom || to != tt.wantTo {
+				t.Errorf("parseVersions() = (%q, %q), want (%q, %q)", from, to, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestUpdateType(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+		want string
+	}{
+		{from: "1.6.0", to: "2.0.0", want: UpdateMajor},
+		{from: "1.6.0", to: "1.7.0", want: UpdateMinor},
+		{from: "1.6.0", to: "1.6.1", want: UpdatePatch},
+		{from: "v1.7.0", to: "v1.8.0", want: UpdateMinor},
+		{from: "0.36.0", to: "0.37.0", want: UpdateMinor},
+		{from: "3", to: "4", want: UpdateMajor},
+		{from: "1.2", to: "1.2.1", want: UpdatePatch},
+		{from: "2.0.0-rc.1", to: "2.0.0", want: ""},
+		{from: "1.0.0", to: "2.0.0-beta.1", want: UpdateMajor},
+		{from: "1.6.0", to: "1.6.0", want: ""},
+		{from: "4.1.94.Final", to: "4.1.100.Final", want: UpdatePatch},
+		{from: "32.0.0-jre", to: "32.1.3-jre", want: UpdateMinor},
+		{from: "abc123", to: "def456", want: ""},
+		{from: "", to: "1.0.0", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			if got := updateType(tt.from, tt.to); got != tt.want {
+				t.Errorf("updateType(%q, %q) = %q, want %q", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVersionConstraint(t *testing.T) {
+	tests := []struct {
+		entry       string
+		wantPattern string
+		wantOK      bool
+		wantErr     bool
+		in, out     string // versions inside and outside the range
+	}{
+		{entry: "react@>=19", wantPattern: "react", wantOK: true, in: "19.0.0", out: "18.3.1"},
+		{entry: "github.com/aws/aws-sdk-go-v2 < 1.20", wantPattern: "github.com/aws/aws-sdk-go-v2", wantOK: true, in: "v1.19.9", out: "1.20.0"},
+		{entry: "@babel/core@<=7.24", wantPattern: "@babel/core", wantOK: true, in: "7.24.0", out: "7.24.1"},
+		{entry: "lodash > 4", wantPattern: "lodash", wantOK: true, in: "4.0.1", out: "4.0.0"},
+		{entry: "lodash@=4.17.21", wantPattern: "lodash", wantOK: true, in: "4.17.21", out: "4.17.20"},
+		{entry: "lodash == 4.17.21", wantPattern: "lodash", wantOK: true, in: "v4.17.21", out: "4.17.22"},
+		{entry: "lodash != 4.17.21", wantPattern: "lodash", wantOK: true, in: "4.17.22", out: "4.17.21"},
+		{entry: "lodash", wantOK: false},
+		{entry: "github.com/gin-gonic/gin@v1", wantOK: false},
+		{entry: "react@>=", wantErr: true},
+		{entry: ">= 19", wantErr: true},
+		{entry: "react ~> 19", wantErr: true},
+		{entry: "react@>=latest", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			pattern, c, ok, err := parseVersionConstraint(tt.entry)
+			if pattern != tt.wantPattern || ok != tt.wantOK || (err != nil) != tt.wantErr {
+				t.Fatalf("parseVersionConstraint(%q) = %q, _, %v, %v, want %q, %v, error %v", tt.entry, pattern, ok, err, tt.wantPattern, tt.wantOK, tt.wantErr)
+			}
+			if !ok {
+				return
+			}
+			if !c.matches(tt.in) {
+				t.Errorf("%q does not match %s", tt.entry, tt.in)
+			}
+			if c.matches(tt.out) {
+				t.Errorf("%q matches %s", tt.entry, tt.out)
+			}
+			if c.matches("not-a-version") {
+				t.Errorf("%q matches an unparsable version", tt.entry)
+			}
+		})
+	}
+}
+
+func TestParseUpdateTypeDenial(t *testing.T) {
+	tests := []struct {
+		entry       string
+		wantPattern string
+		wantTypes   []string
+		wantOK      bool
+	}{
+		{entry: "github.com/gin-gonic/gin: deny=major", wantPattern: "github.com/gin-gonic/gin", wantTypes: []string{"major"}, wantOK: true},
+		{entry: "react:deny=Major,minor", wantPattern: "react", wantTypes: []string{"major", "minor"}, wantOK: true},
+		{entry: "github.com/aws/*: deny=minor", wantPattern: "github.com/aws/*", wantTypes: []string{"minor"}, wantOK: true},
+		{entry: "com.fasterxml.jackson.core:jackson-databind: deny=major", wantPattern: "com.fasterxml.jackson.core:jackson-databind", wantTypes: []string{"major"}, wantOK: true},
+		{entry: "com.fasterxml.jackson.core:jackson-databind"},
+		{entry: "github.com/gin-gonic/gin"},
+		{entry: "re:^github\\.com/internal-"},
+		{entry: "react@>=19"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			pattern, types, ok := ParseUpdateTypeDenial(tt.entry)
+			if pattern != tt.wantPattern || !slices.Equal(types, tt.wantTypes) || ok != tt.wantOK {
+				t.Errorf("ParseUpdateTypeDenial(%q) = %q, %v, %v, want %q, %v, %v", tt.entry, pattern, types, ok, tt.wantPattern, tt.wantTypes, tt.wantOK)
+			}
+		})
+	}
+}